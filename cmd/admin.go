@@ -0,0 +1,107 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/aexvir/skladka/internal/errors"
+)
+
+// defaultRotationBatch is how many rows are re-encrypted per transaction
+// when rotate-keys isn't given a batch size.
+const defaultRotationBatch = 500
+
+// admincommand is a maintenance task run once against the configured storage.
+type admincommand func(ctx context.Context, app app, db backend, args []string) error
+
+// admincommands are the maintenance tasks, selected by the argument following admin.
+var admincommands = map[string]admincommand{
+	"rotate-keys": rotatekeys,
+	"reindex":     reindex,
+	"reap":        reap,
+}
+
+// rotator is implemented by the storages that can re-encrypt their data with a new key.
+type rotator interface {
+	RotateKeys(ctx context.Context, batch int) (int, error)
+}
+
+// admincmd runs a maintenance task.
+//
+//	skladka admin rotate-keys [batch-size]
+//	skladka admin reindex
+//	skladka admin reap
+func admincmd(ctx context.Context, app app, args []string) error {
+	var command admincommand
+	if len(args) > 0 {
+		command = admincommands[args[0]]
+	}
+	if command == nil {
+		tasks := slices.Sorted(maps.Keys(admincommands))
+		return errors.Errorf("usage: skladka admin %s", strings.Join(tasks, " | "))
+	}
+
+	db, err := open(ctx, app.cfg)
+	if err != nil {
+		return errors.Wrap(err, "failed to initialize database")
+	}
+	defer db.Close()
+
+	return command(ctx, app, db, args[1:])
+}
+
+// rotatekeyscmd runs the rotate-keys task from where it used to live, before it was
+// moved under admin, so the scripts calling it keep working for now.
+//
+//	skladka rotate-keys [batch-size]
+func rotatekeyscmd(ctx context.Context, app app, args []string) error {
+	fmt.Fprintln(app.stdio.err, "skladka rotate-keys is deprecated, use skladka admin rotate-keys instead")
+	return admincmd(ctx, app, append([]string{"rotate-keys"}, args...))
+}
+
+// rotatekeys re-encrypts all pastes with the current encryption key, with the
+// previous key listed among the retired encryption keys.
+func rotatekeys(ctx context.Context, app app, db backend, args []string) error {
+	batch := defaultRotationBatch
+	if len(args) > 0 {
+		var err error
+		if batch, err = strconv.Atoi(args[0]); err != nil || batch <= 0 {
+			return errors.Errorf("invalid batch size %q", args[0])
+		}
+	}
+
+	rot, ok := db.(rotator)
+	if !ok {
+		return errors.Errorf("the %s storage doesn't support key rotation", app.cfg.Storage.Driver)
+	}
+
+	rotated, err := rot.RotateKeys(ctx, batch)
+	fmt.Fprintf(app.stdio.out, "re-encrypted %d rows\n", rotated)
+
+	return err
+}
+
+// reindex adds the public pastes missing from the search index.
+func reindex(ctx context.Context, app app, db backend, _ []string) error {
+	indexer, ok := db.(reindexer)
+	if !ok {
+		return errors.Errorf("the %s storage doesn't keep a search index", app.cfg.Storage.Driver)
+	}
+
+	indexed, err := indexer.ReindexPastes(ctx)
+	fmt.Fprintf(app.stdio.out, "indexed %d pastes\n", indexed)
+
+	return err
+}
+
+// reap removes the expired pastes right away instead of waiting for the server to.
+func reap(ctx context.Context, app app, db backend, _ []string) error {
+	reaped, err := db.ReapExpiredPastes(ctx)
+	fmt.Fprintf(app.stdio.out, "reaped %d pastes\n", reaped)
+
+	return err
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/aexvir/skladka/internal/config"
+)
+
+// newApp returns the app the server commands are run with against the storage, along
+// with what they print to stdout and stderr.
+func newApp(storage config.Storage) (app, *bytes.Buffer, *bytes.Buffer) {
+	var out, errs bytes.Buffer
+
+	cfg := config.Config{
+		Core: config.Core{
+			EncryptionKey:   "supersecretkey==",
+			EncryptionSalt:  "6370b25f61f2025a0d4fcbb4aaf8859f",
+			SessionDuration: time.Hour,
+		},
+		Storage: storage,
+	}
+
+	return app{cfg: cfg, stdio: stdio{out: &out, err: &errs}}, &out, &errs
+}
+
+func TestRotateKeysAlias(t *testing.T) {
+	ctx := context.Background()
+
+	app, out, errs := newApp(
+		config.Storage{
+			Driver: "sqlite",
+			DSN:    filepath.Join(t.TempDir(), "skladka.db"),
+			Blobs:  config.Blobs{Driver: "filesystem", Path: t.TempDir()},
+		},
+	)
+
+	// the deprecated command is dispatched to the admin task
+	require.NoError(t, servercommands["rotate-keys"](ctx, app, []string{"10"}))
+	require.Equal(t, "re-encrypted 0 rows\n", out.String())
+	require.Equal(t, "skladka rotate-keys is deprecated, use skladka admin rotate-keys instead\n", errs.String())
+
+	out.Reset()
+	errs.Reset()
+
+	require.NoError(t, servercommands["admin"](ctx, app, []string{"rotate-keys", "10"}))
+	require.Equal(t, "re-encrypted 0 rows\n", out.String())
+	require.Empty(t, errs.String())
+
+	err := servercommands["rotate-keys"](ctx, app, []string{"none"})
+	require.EqualError(t, err, `invalid batch size "none"`)
+}
+
+func TestAdmin(t *testing.T) {
+	ctx := context.Background()
+	app, _, _ := newApp(config.Storage{Driver: "memory"})
+
+	err := admincmd(ctx, app, []string{"rotate-keys"})
+	require.EqualError(t, err, "the memory storage doesn't support key rotation")
+
+	err = admincmd(ctx, app, []string{"unknown"})
+	require.EqualError(t, err, "usage: skladka admin reap | reindex | rotate-keys")
+}
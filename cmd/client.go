@@ -0,0 +1,317 @@
+package main
+
+import (
+	"cmp"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/aexvir/skladka/internal/client"
+	"github.com/aexvir/skladka/internal/errors"
+	"github.com/aexvir/skladka/internal/paste"
+)
+
+// defaultServer is the server the client commands talk to unless told otherwise.
+const defaultServer = "http://localhost:3000"
+
+// command is a subcommand of the skladka binary.
+type command func(ctx context.Context, args []string, stdio stdio) error
+
+// stdio are the streams commands read from and write to.
+type stdio struct {
+	in       io.Reader
+	out, err io.Writer
+}
+
+// clientcommands talk to a skladka server over http instead of running one.
+var clientcommands = map[string]command{
+	"paste":  pastecmd,
+	"get":    getcmd,
+	"list":   listcmd,
+	"delete": deletecmd,
+}
+
+// connection holds the flags every client command accepts.
+type connection struct {
+	server string
+	token  string
+}
+
+func (c *connection) register(flags *flag.FlagSet) {
+	flags.StringVar(&c.server, "server", cmp.Or(os.Getenv("SKD_SERVER"), defaultServer), "url of the skladka server, $SKD_SERVER")
+	flags.StringVar(&c.token, "api-token", os.Getenv("SKD_API_TOKEN"), "personal access token, $SKD_API_TOKEN")
+}
+
+func (c *connection) client() (*client.Client, error) {
+	return client.New(c.server, c.token)
+}
+
+// pastecmd creates a paste from each file, or from stdin when there are none.
+//
+//	skladka paste [-title t] [-syntax s] [-tags a,b] [-expire 1d] [-password p] [-unlisted] [file ...]
+func pastecmd(ctx context.Context, args []string, stdio stdio) error {
+	var (
+		conn                connection
+		title, syntax, tags string
+		expire, password    string
+		unlisted            bool
+	)
+
+	flags := newflags("paste [flags] [file ...]", stdio)
+	conn.register(flags)
+	flags.StringVar(&title, "title", "", "title of the paste, the file name by default")
+	flags.StringVar(&syntax, "syntax", "", "syntax highlighting, guessed from the file extension by default")
+	flags.StringVar(&tags, "tags", "", "comma separated tags")
+	flags.StringVar(&expire, "expire", "", "expire the paste after this long, e.g. 10m, 1d or 2w")
+	flags.StringVar(&password, "password", "", "protect the paste with a password")
+	flags.BoolVar(&unlisted, "unlisted", false, "keep the paste out of listings and search")
+
+	files, err := parse(flags, args)
+	if err != nil {
+		return err
+	}
+
+	skd, err := conn.client()
+	if err != nil {
+		return err
+	}
+
+	template := paste.Paste{Title: title, Syntax: syntax, Public: !unlisted}
+
+	if tags != "" {
+		template.Tags = strings.Split(tags, ",")
+	}
+
+	if password != "" {
+		template.Password = &password
+	}
+
+	if expire != "" {
+		duration, err := paste.ParseDuration(expire)
+		if err != nil {
+			return err
+		}
+		expiration := time.Now().UTC().Add(duration)
+		template.Expiration = &expiration
+	}
+
+	if len(files) == 0 {
+		files = []string{"-"}
+	}
+
+	for _, file := range files {
+		p := template
+
+		var content []byte
+		if file == "-" {
+			content, err = io.ReadAll(stdio.in)
+		} else {
+			content, err = os.ReadFile(file)
+			p.Title = cmp.Or(p.Title, filepath.Base(file))
+			p.Syntax = cmp.Or(p.Syntax, paste.SyntaxOf(filepath.Base(file)))
+		}
+		if err != nil {
+			return errors.Wrapf(err, "failed to read %s", file)
+		}
+		p.Content = string(content)
+
+		created, err := skd.CreatePaste(ctx, p)
+		if err != nil {
+			return err
+		}
+
+		// the url goes to stdout so it can be piped, the token is only needed to delete the paste
+		fmt.Fprintln(stdio.out, skd.URL(created.Reference))
+		fmt.Fprintf(stdio.err, "owner token of %s: %s\n", created.Reference, created.Token)
+	}
+
+	return nil
+}
+
+// getcmd prints the content of a paste, decrypting end-to-end encrypted ones with the
+// key in the fragment of their url.
+//
+//	skladka get [-password p] [-key k] <ref | url#key>
+func getcmd(ctx context.Context, args []string, stdio stdio) error {
+	var (
+		conn          connection
+		password, key string
+	)
+
+	flags := newflags("get [flags] <ref | url#key>", stdio)
+	conn.register(flags)
+	flags.StringVar(&password, "password", "", "password of a protected paste")
+	flags.StringVar(&key, "key", "", "key of an end-to-end encrypted paste, the fragment of its url by default")
+
+	refs, err := parse(flags, args)
+	if err != nil {
+		return err
+	}
+	if len(refs) != 1 {
+		return usage(flags)
+	}
+
+	skd, err := conn.client()
+	if err != nil {
+		return err
+	}
+
+	ref, fragment := reference(refs[0])
+
+	content, encrypted, err := skd.Raw(ctx, ref, password)
+	if errors.Is(err, client.ErrPasswordRequired) {
+		return errors.New("the paste is password protected, pass its password with -password")
+	}
+	if err != nil {
+		return err
+	}
+
+	if encrypted {
+		content, err = client.Open(content, cmp.Or(key, fragment))
+		if errors.Is(err, client.ErrKeyRequired) {
+			return errors.New("the paste is end-to-end encrypted, pass its full url including the #key fragment or its key with -key")
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err = io.WriteString(stdio.out, content)
+	return err
+}
+
+// listcmd prints the public pastes, newest first.
+//
+//	skladka list [-tag t] [-syntax s] [-limit n] [-cursor c]
+func listcmd(ctx context.Context, args []string, stdio stdio) error {
+	var (
+		conn                connection
+		tag, syntax, cursor string
+		limit               int
+	)
+
+	flags := newflags("list [flags]", stdio)
+	conn.register(flags)
+	flags.StringVar(&tag, "tag", "", "only list pastes with this tag")
+	flags.StringVar(&syntax, "syntax", "", "only list pastes with this syntax")
+	flags.IntVar(&limit, "limit", 20, "how many pastes to list")
+	flags.StringVar(&cursor, "cursor", "", "cursor of the page to list, printed after the previous one")
+
+	rest, err := parse(flags, args)
+	if err != nil {
+		return err
+	}
+	if len(rest) != 0 {
+		return usage(flags)
+	}
+
+	skd, err := conn.client()
+	if err != nil {
+		return err
+	}
+
+	page, err := skd.ListPastes(ctx, paste.Filter{Tag: tag, Syntax: syntax}, cursor, limit)
+	if err != nil {
+		return err
+	}
+
+	table := tabwriter.NewWriter(stdio.out, 0, 4, 2, ' ', 0)
+	for _, p := range page.Pastes {
+		fmt.Fprintf(
+			table, "%s\t%s\t%s\t%s\t%s\n",
+			p.Reference,
+			cmp.Or(p.Title, "untitled"),
+			cmp.Or(p.Syntax, "plaintext"),
+			p.Creation.Local().Format("2006-01-02 15:04"),
+			strings.Join(p.Tags, ","),
+		)
+	}
+	if err := table.Flush(); err != nil {
+		return err
+	}
+
+	if page.Next != "" {
+		fmt.Fprintf(stdio.err, "more pastes with -cursor %s\n", page.Next)
+	}
+
+	return nil
+}
+
+// deletecmd deletes a paste, authorized by the owner token printed on its creation.
+//
+//	skladka delete -token t <ref>
+func deletecmd(ctx context.Context, args []string, stdio stdio) error {
+	var (
+		conn  connection
+		token string
+	)
+
+	flags := newflags("delete [flags] <ref>", stdio)
+	conn.register(flags)
+	flags.StringVar(&token, "token", "", "owner token printed when the paste was created")
+
+	refs, err := parse(flags, args)
+	if err != nil {
+		return err
+	}
+	if len(refs) != 1 || token == "" {
+		return usage(flags)
+	}
+
+	skd, err := conn.client()
+	if err != nil {
+		return err
+	}
+
+	ref, _ := reference(refs[0])
+	return skd.DeletePaste(ctx, ref, token)
+}
+
+// newflags returns the flag set of a command, printing its usage on errors.
+func newflags(synopsis string, stdio stdio) *flag.FlagSet {
+	flags := flag.NewFlagSet(strings.Fields(synopsis)[0], flag.ContinueOnError)
+	flags.SetOutput(stdio.err)
+	flags.Usage = func() {
+		fmt.Fprintf(stdio.err, "usage: skladka %s\n", synopsis)
+		flags.PrintDefaults()
+	}
+	return flags
+}
+
+// parse parses the flags, which unlike the standard library can come after the
+// positional arguments too, returning the positional ones.
+func parse(flags *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := flags.Parse(args); err != nil {
+			return nil, err
+		}
+
+		if flags.NArg() == 0 {
+			return positional, nil
+		}
+
+		positional = append(positional, flags.Arg(0))
+		args = flags.Args()[1:]
+	}
+}
+
+// usage prints the usage of the command, returning the error reporting its misuse.
+func usage(flags *flag.FlagSet) error {
+	flags.Usage()
+	return errors.Errorf("invalid arguments for %s", flags.Name())
+}
+
+// reference returns the reference of a paste given either as is or as its url,
+// along with the key of end-to-end encrypted pastes kept in the url fragment.
+func reference(arg string) (ref, key string) {
+	arg, key, _ = strings.Cut(arg, "#")
+	return path.Base(strings.TrimSuffix(arg, "/")), key
+}
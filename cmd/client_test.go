@@ -0,0 +1,154 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aexvir/skladka/internal/api"
+	"github.com/aexvir/skladka/internal/client"
+	"github.com/aexvir/skladka/internal/config"
+	"github.com/aexvir/skladka/internal/frontend"
+	"github.com/aexvir/skladka/internal/paste"
+	"github.com/aexvir/skladka/internal/storage"
+)
+
+// newServer returns the url of a server backed by a memory storage, routed the same
+// way serve routes it.
+func newServer(t *testing.T) string {
+	store := storage.NewMemoryStorage(
+		config.Config{
+			Core: config.Core{
+				EncryptionKey:   "supersecretkey==",
+				EncryptionSalt:  "6370b25f61f2025a0d4fcbb4aaf8859f",
+				SessionDuration: time.Hour,
+			},
+		},
+	)
+
+	router := chi.NewRouter()
+	router.With(api.WithTokens(store)).Mount("/api/v1", api.Router(store))
+	router.Mount("/", frontend.DashboardRouter(store))
+
+	server := httptest.NewServer(router)
+	t.Cleanup(server.Close)
+
+	return server.URL
+}
+
+// run runs the client command with the input, returning what it printed to stdout and stderr.
+func run(t *testing.T, command command, input string, args ...string) (string, string, error) {
+	var out, errs bytes.Buffer
+	err := command(context.Background(), args, stdio{in: strings.NewReader(input), out: &out, err: &errs})
+	return out.String(), errs.String(), err
+}
+
+func TestReference(t *testing.T) {
+	for arg, want := range map[string][2]string{
+		"abcd1234":                         {"abcd1234", ""},
+		"http://localhost:3000/abcd1234":   {"abcd1234", ""},
+		"http://localhost:3000/abcd1234/":  {"abcd1234", ""},
+		"http://localhost:3000/abcd1234#k": {"abcd1234", "k"},
+		"abcd1234#a-b_c":                   {"abcd1234", "a-b_c"},
+	} {
+		ref, key := reference(arg)
+		require.Equal(t, want, [2]string{ref, key}, arg)
+	}
+}
+
+func TestPasteAndGet(t *testing.T) {
+	server := newServer(t)
+
+	file := filepath.Join(t.TempDir(), "main.go")
+	require.NoError(t, os.WriteFile(file, []byte("package main"), 0o600))
+
+	// flags are parsed after the positional arguments as well
+	out, errs, err := run(t, pastecmd, "", file, "-server", server, "-tags", "cli")
+	require.NoError(t, err)
+	require.Contains(t, errs, "owner token of")
+
+	url := strings.TrimSpace(out)
+	require.True(t, strings.HasPrefix(url, server+"/"), url)
+
+	out, _, err = run(t, getcmd, "", url, "-server", server)
+	require.NoError(t, err)
+	require.Equal(t, "package main", out)
+
+	out, _, err = run(t, listcmd, "", "-server", server, "-tag", "cli")
+	require.NoError(t, err)
+	require.Contains(t, out, "main.go")
+
+	_, _, err = run(t, getcmd, "", "-server", server)
+	require.ErrorContains(t, err, "invalid arguments for get")
+}
+
+func TestGetPasswordProtected(t *testing.T) {
+	server := newServer(t)
+
+	out, _, err := run(t, pastecmd, "secret stuff", "-server", server, "-password", "hunter2")
+	require.NoError(t, err)
+	url := strings.TrimSpace(out)
+
+	_, _, err = run(t, getcmd, "", "-server", server, url)
+	require.EqualError(t, err, "the paste is password protected, pass its password with -password")
+
+	out, _, err = run(t, getcmd, "", "-server", server, "-password", "hunter2", url)
+	require.NoError(t, err)
+	require.Equal(t, "secret stuff", out)
+}
+
+func TestGetEncrypted(t *testing.T) {
+	server := newServer(t)
+
+	skd, err := client.New(server+"/", "")
+	require.NoError(t, err)
+
+	content, key := seal(t, "end to end")
+	created, err := skd.CreatePaste(context.Background(), paste.Paste{Content: content, ClientEncrypted: true})
+	require.NoError(t, err)
+	url := skd.URL(created.Reference)
+
+	// the key is taken from the fragment of the url
+	out, _, err := run(t, getcmd, "", "-server", server, url+"#"+key)
+	require.NoError(t, err)
+	require.Equal(t, "end to end", out)
+
+	out, _, err = run(t, getcmd, "", "-server", server, "-key", key, created.Reference)
+	require.NoError(t, err)
+	require.Equal(t, "end to end", out)
+
+	_, _, err = run(t, getcmd, "", "-server", server, url)
+	require.EqualError(t, err, "the paste is end-to-end encrypted, pass its full url including the #key fragment or its key with -key")
+}
+
+// seal encrypts the text the way the frontend does, returning the content sent to
+// the server and the key put in the fragment of the paste url.
+func seal(t *testing.T, text string) (content, key string) {
+	raw := make([]byte, 32)
+	_, err := rand.Read(raw)
+	require.NoError(t, err)
+
+	block, err := aes.NewCipher(raw)
+	require.NoError(t, err)
+	gcm, err := cipher.NewGCM(block)
+	require.NoError(t, err)
+
+	nonce := make([]byte, gcm.NonceSize())
+	_, err = rand.Read(nonce)
+	require.NoError(t, err)
+
+	sealed := gcm.Seal(nonce, nonce, []byte(text), nil)
+	return base64.StdEncoding.EncodeToString(sealed), base64.RawURLEncoding.EncodeToString(raw)
+}
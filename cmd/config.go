@@ -0,0 +1,25 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aexvir/skladka/internal/errors"
+)
+
+// configcmd prints the config the binary runs with, with the secrets redacted.
+//
+//	skladka config print
+func configcmd(_ context.Context, app app, args []string) error {
+	if len(args) != 1 || args[0] != "print" {
+		return errors.New("usage: skladka config print")
+	}
+
+	redacted, err := app.cfg.Redacted()
+	if err != nil {
+		return errors.Wrap(err, "failed to print config")
+	}
+
+	_, err = fmt.Fprintln(app.stdio.out, redacted)
+	return err
+}
@@ -1 +1,19 @@
+// Command skladka runs the skladka server along with the tasks maintaining it, and
+// talks to running servers as a client.
+//
+//	skladka [serve]                 run the server, the default
+//	skladka migrate up              apply the pending migrations
+//	skladka migrate down [steps]    revert the last applied migrations, one by default
+//	skladka migrate status          list the migrations and when they were applied
+//	skladka config print            print the config with the secrets redacted
+//	skladka admin rotate-keys [n]   re-encrypt the data with the current encryption key
+//	skladka admin reindex           add the pastes missing from the search index
+//	skladka admin reap              remove the expired pastes
+//	skladka paste|get|list|delete   client commands, see skladka <command> -h
+//
+// skladka rotate-keys still runs admin rotate-keys, printing a deprecation notice,
+// until it's removed.
+//
+// The server commands are configured through SKD_ environment variables or the
+// matching flags, listed by skladka -h.
 package main
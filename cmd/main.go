@@ -0,0 +1,117 @@
+package main
+
+import (
+	"cmp"
+	"context"
+	"flag"
+	"fmt"
+	"maps"
+	"os"
+	"os/signal"
+	"slices"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/aexvir/skladka/internal/config"
+	"github.com/aexvir/skladka/internal/errors"
+	"github.com/aexvir/skladka/internal/logging"
+	"github.com/aexvir/skladka/internal/metrics"
+	"github.com/aexvir/skladka/internal/tracing"
+)
+
+// app is what the server commands are run with.
+type app struct {
+	cfg    config.Config
+	logger *logging.Logger
+	tracer *tracing.Tracer
+	stdio  stdio
+}
+
+// servercommand is a subcommand of the skladka binary run with its config,
+// against the storage it selects.
+type servercommand func(ctx context.Context, app app, args []string) error
+
+// servercommands are selected by the first argument left after the config flags,
+// serve being the default.
+var servercommands = map[string]servercommand{
+	"serve":   serve,
+	"migrate": migratecmd,
+	"config":  configcmd,
+	"admin":   admincmd,
+
+	// deprecated, moved under admin
+	"rotate-keys": rotatekeyscmd,
+}
+
+func main() {
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	stdio := stdio{in: os.Stdin, out: os.Stdout, err: os.Stderr}
+
+	// client commands talk to a running server, they don't need its config
+	if len(os.Args) > 1 {
+		if command, ok := clientcommands[os.Args[1]]; ok {
+			err := command(ctx, os.Args[2:], stdio)
+			if err != nil && !errors.Is(err, flag.ErrHelp) {
+				fmt.Fprintf(os.Stderr, "skladka %s: %v\n", os.Args[1], err)
+				cancel()
+				os.Exit(1)
+			}
+			return
+		}
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		if errors.Is(err, config.ErrHelpWanted) {
+			fmt.Println(err)
+			return
+		}
+		panic(err)
+	}
+
+	name := cmp.Or(cfg.Args.Num(0), "serve")
+	command, ok := servercommands[name]
+	if !ok {
+		commands := append(slices.Sorted(maps.Keys(servercommands)), slices.Sorted(maps.Keys(clientcommands))...)
+		fmt.Fprintf(os.Stderr, "skladka: unknown command %q, expected one of %s\n", name, strings.Join(commands, ", "))
+		cancel()
+		os.Exit(2)
+	}
+
+	logger, tracer, meter, otelshutdown, err := observability(ctx, "skladka", cfg)
+	if err != nil {
+		panic(err)
+	}
+
+	ctx = logging.NewContext(ctx, logger)
+	ctx = tracing.NewContext(ctx, tracer)
+	ctx = metrics.NewContext(ctx, meter)
+
+	var args []string
+	if len(cfg.Args) > 1 {
+		args = cfg.Args[1:]
+	}
+
+	err = command(ctx, app{cfg: cfg, logger: logger, tracer: tracer, stdio: stdio}, args)
+	if err != nil {
+		// only the dev environment logs to stdout, so the error is printed as well
+		logger.Error(err, "cmd."+name, "command failed")
+		fmt.Fprintf(os.Stderr, "skladka %s: %v\n", name, err)
+	}
+
+	shutdownctx, shutdowncancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer shutdowncancel()
+
+	if err := otelshutdown(shutdownctx); err != nil {
+		logger.Error(err, "cmd."+name, "failed to shutdown observability components")
+	}
+
+	if err != nil {
+		shutdowncancel()
+		cancel()
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"github.com/aexvir/skladka/internal/errors"
+	"github.com/aexvir/skladka/internal/storage"
+)
+
+// migratecmd manages the schema of the configured database with the migrations
+// embedded in the binary, so deployments don't need any other tool to migrate it.
+//
+//	skladka migrate up
+//	skladka migrate down [steps]
+//	skladka migrate status
+func migratecmd(ctx context.Context, app app, args []string) error {
+	action, steps := "", 1
+	if len(args) > 0 {
+		action = args[0]
+	}
+
+	switch {
+	case action == "up" && len(args) == 1, action == "status" && len(args) == 1:
+	case action == "down" && len(args) <= 2:
+		if len(args) == 2 {
+			var err error
+			if steps, err = strconv.Atoi(args[1]); err != nil || steps <= 0 {
+				return errors.Errorf("invalid number of migrations to revert %q", args[1])
+			}
+		}
+	default:
+		return errors.New("usage: skladka migrate up | down [steps] | status")
+	}
+
+	migrator, db, err := storage.NewMigrator(ctx, app.cfg)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	switch action {
+	case "up":
+		migrated, err := migrator.Up(ctx)
+		for _, version := range migrated {
+			fmt.Fprintf(app.stdio.out, "applied %s\n", version)
+		}
+		if err == nil && len(migrated) == 0 {
+			fmt.Fprintln(app.stdio.out, "no pending migrations")
+		}
+		return err
+
+	case "down":
+		reverted, err := migrator.Down(ctx, steps)
+		for _, version := range reverted {
+			fmt.Fprintf(app.stdio.out, "reverted %s\n", version)
+		}
+		if err == nil && len(reverted) == 0 {
+			fmt.Fprintln(app.stdio.out, "no applied migrations")
+		}
+		return err
+
+	default:
+		migrations, err := migrator.Status(ctx)
+		if err != nil {
+			return err
+		}
+
+		table := tabwriter.NewWriter(app.stdio.out, 0, 4, 2, ' ', 0)
+		for _, migration := range migrations {
+			applied := "pending"
+			if !migration.Pending() {
+				applied = migration.Applied.Local().Format(time.DateTime)
+			}
+			fmt.Fprintf(table, "%s\t%s\t%s\n", migration.Version, migration.Name, applied)
+		}
+		return table.Flush()
+	}
+}
@@ -3,12 +3,12 @@ package main
 import (
 	"context"
 	"fmt"
+	"io"
 	"log/slog"
 	"net/http"
 	"os"
-	"os/signal"
 	"slices"
-	"syscall"
+	"sync"
 	"time"
 
 	. "github.com/go-chi/chi/v5"
@@ -24,39 +24,64 @@ import (
 	"github.com/aexvir/skladka/internal/tracing"
 )
 
-func main() {
-	rootctx, rootcancel := context.WithCancel(context.Background())
-
-	cfg, err := config.Load()
-	if err != nil {
-		if errors.Is(err, config.ErrHelpWanted) {
-			fmt.Println(err)
-			return
-		}
-		panic(err)
+// serve runs the http server until the context is cancelled, reaping expired
+// pastes in the background.
+//
+//	skladka serve
+func serve(ctx context.Context, app app, args []string) error {
+	if len(args) > 0 {
+		return errors.Errorf("unexpected arguments %v", args)
 	}
 
-	logger, tracer, meter, otelshutdown, err := observability(rootctx, "skladka", cfg)
+	logger := app.logger
+
+	db, err := open(ctx, app.cfg)
 	if err != nil {
-		panic(err)
+		return errors.Wrap(err, "failed to initialize database")
 	}
+	defer func() {
+		if err := db.Close(); err != nil {
+			logger.Error(err, "cmd.serve", "failed to close database")
+		}
+	}()
 
-	rootctx = logging.NewContext(rootctx, logger)
-	rootctx = tracing.NewContext(rootctx, tracer)
-	rootctx = metrics.NewContext(rootctx, meter)
+	// the background work is waited for before the database is closed
+	stopreaper := storage.StartReaper(ctx, db, app.cfg.ReapInterval)
+	defer stopreaper()
 
-	db, err := storage.NewPostgresStorage(rootctx, cfg)
-	if err != nil {
-		logger.Error(err, "init.db", "failed to initialize database")
-		return
+	var background sync.WaitGroup
+	defer background.Wait()
+
+	// stop the background work when the server fails as well
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// index pastes created before search was available
+	if indexer, ok := db.(reindexer); ok {
+		background.Add(1)
+		go func() {
+			defer background.Done()
+
+			indexed, err := indexer.ReindexPastes(ctx)
+			if err != nil {
+				logger.Error(err, "init.search", "failed to index pastes")
+				return
+			}
+			if indexed > 0 {
+				logger.Info("init.search", fmt.Sprintf("indexed %d pastes", indexed))
+			}
+		}()
 	}
 
 	router := NewRouter()
 	router.Use(middleware.RequestID)
 	router.Use(api.WithLogging(logger))
-	router.Use(api.WithTracing(tracer))
+	router.Use(api.WithTracing(app.tracer))
+	router.Use(api.WithUploadLimit(app.cfg.UploadLimit))
+	router.Use(api.WithSessions(db))
 	router.Use(middleware.Heartbeat("/health"))
 
+	router.With(api.WithTokens(db)).Mount("/api/v1", api.Router(db))
 	router.Mount("/", frontend.DashboardRouter(db))
 	router.Handle("/metrics", metrics.Handler())
 
@@ -65,13 +90,12 @@ func main() {
 		Handler: router,
 	}
 
-	exit := make(chan os.Signal, 1)
-	signal.Notify(exit, os.Interrupt, syscall.SIGTERM)
-
+	stopped := make(chan struct{})
 	go func() {
-		sig := <-exit
-		logger.Info("cmd.serve", fmt.Sprintf("received signal: %v; initiating shutdown", sig))
-		rootcancel()
+		defer close(stopped)
+
+		<-ctx.Done()
+		logger.Info("cmd.serve", "received shutdown signal; initiating shutdown")
 
 		shutdownctx, shutdowncancel := context.WithTimeout(context.Background(), 10*time.Second)
 		defer shutdowncancel()
@@ -79,15 +103,45 @@ func main() {
 		if err := server.Shutdown(shutdownctx); err != nil {
 			logger.Error(err, "cmd.serve", "error during server shutdown")
 		}
-
-		if err := otelshutdown(shutdownctx); err != nil {
-			logger.Error(err, "cmd.serve", "failed to shutdown observability components")
-		}
 	}()
 
 	logger.Info("cmd.serve", "server listening on :3000")
 	if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
-		logger.Error(err, "cmd.serve", "server error")
+		return errors.Wrap(err, "server error")
+	}
+
+	// wait for in flight requests before closing the database
+	<-stopped
+
+	return nil
+}
+
+// backend is everything the server needs from the storage.
+type backend interface {
+	frontend.Storage
+	api.Storage
+	api.Sessions
+	api.Tokens
+	storage.Reaper
+	io.Closer
+}
+
+// reindexer is implemented by the storages keeping a search index.
+type reindexer interface {
+	ReindexPastes(ctx context.Context) (int, error)
+}
+
+// open initializes the storage backend selected by the config.
+func open(ctx context.Context, cfg config.Config) (backend, error) {
+	switch cfg.Storage.Driver {
+	case "postgres":
+		return storage.NewPostgresStorage(ctx, cfg)
+	case "sqlite":
+		return storage.NewSQLiteStorage(ctx, cfg)
+	case "memory":
+		return storage.NewMemoryStorage(cfg), nil
+	default:
+		return nil, errors.Errorf("unknown storage driver %q", cfg.Storage.Driver)
 	}
 }
 
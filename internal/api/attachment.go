@@ -0,0 +1,27 @@
+package api
+
+import (
+	"mime"
+	"net/http"
+	"strconv"
+
+	"github.com/aexvir/skladka/internal/paste"
+)
+
+// ServeAttachment writes the content of the attachment with its content type.
+// Images are displayed inline so they can be previewed, anything else is downloaded.
+// Browsers are told not to sniff the content nor run anything in it, attachments are
+// uploaded by anyone and served from the same origin as the rest of skladka.
+func ServeAttachment(w http.ResponseWriter, a paste.Attachment) {
+	disposition := "attachment"
+	if a.Image() {
+		disposition = "inline"
+	}
+
+	w.Header().Set("Content-Type", a.ContentType)
+	w.Header().Set("Content-Length", strconv.Itoa(len(a.Data)))
+	w.Header().Set("Content-Disposition", mime.FormatMediaType(disposition, map[string]string{"filename": a.Name}))
+	w.Header().Set("Content-Security-Policy", "sandbox")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.Write(a.Data)
+}
@@ -0,0 +1,75 @@
+// Package api provides the http building blocks of the skladka service that are not
+// tied to the html frontend.
+//
+// It contains the middleware shared by every route, such as request logging, tracing,
+// path rewriting, cookie based user sessions and personal access tokens, as well as
+// a versioned json api for managing pastes, meant for scripts and other non
+// interactive clients.
+//
+// # Routes
+//
+//	GET    /pastes                           list public pastes, newest first
+//	POST   /pastes                           create a new paste
+//	GET    /pastes/{ref}                     fetch a paste by its reference
+//	PUT    /pastes/{ref}                     edit the title, content, syntax, tags and files of a paste
+//	DELETE /pastes/{ref}                     delete a paste, authorized by its owner token
+//	GET    /pastes/{ref}/attachments/{name}  download an attachment of a paste
+//	GET    /pastes/{ref}/forks               list the public forks of a paste
+//	GET    /pastes/{ref}/revisions           list every revision of a paste, without counting a read
+//	GET    /pastes/{ref}/revisions/{n}       fetch a single revision of a paste
+//	GET    /search?q={query}                 full-text search over public pastes
+//	GET    /tags?prefix={prefix}             most used tags of public pastes, for autocompletion
+//
+// Listings are paginated, at most limit pastes are returned per request and the cursor
+// of the next page is sent in the x-skd-next-cursor header, to be passed back as the
+// cursor query parameter. They can be filtered with the tag, syntax, since and until
+// query parameters, dates being either plain days or RFC 3339 timestamps. Search
+// results are ordered by relevance and only limited, not paginated.
+//
+// Password protected pastes expect the password in the x-skd-password header.
+// Creating a paste returns a secret token alongside it, which has to be sent in
+// the x-skd-token header to edit or delete the paste. Pastes created with a parent
+// reference are recorded as forks of that paste. Pastes created with a slug, e.g.
+// deploy-notes-q3, use the normalized slug as their reference instead of a random one.
+// Pastes created by requests carrying a session are owned by the logged in user.
+//
+// Revisions are read without counting a read of the paste, so the revisions of burn
+// after reading pastes are only served to their owner, authorized by the x-skd-token
+// header or the account owning the paste, and refused with http403 to anyone else.
+//
+// Pastes can hold several named files, each with its own syntax, sent as the files list.
+// The content and syntax of such pastes mirror the first file. Edits without files only
+// replace the first file, while an empty list of files drops them.
+//
+// Pastes can also be created from a multipart/form-data body, every file uploaded in its
+// files field becoming a file of the paste, with the syntax inferred from its name. The rest
+// of the paste can be sent json encoded in the paste field. Request bodies over the
+// configured upload limit, set with WithUploadLimit, are rejected with http413.
+//
+// Uploaded images, and any file that isn't utf-8 encoded text, are attached to the paste
+// instead, with the content type sniffed from their content. Pastes list their attachments
+// without their contents, which are downloaded one by one and served by ServeAttachment,
+// images inline. Attachments can't be sent json encoded, and aren't allowed on password
+// protected, burn after reading nor client encrypted pastes.
+//
+// Pastes created with client_encrypted set are end-to-end encrypted: the content has to
+// be the base64 encoded aes-gcm nonce followed by the ciphertext, it's stored and served
+// as is, and so have to be their edits. The key is never sent to the server.
+//
+// Scripts and ci jobs authenticate with a personal access token sent as a bearer
+// token in the Authorization header. Tokens are granted the read, write and delete
+// scopes, limiting which routes they can be used on. Tokens with the write or delete
+// scope edit or delete the pastes of their user without the x-skd-token header, except
+// for password protected pastes, whose content can only be relocked with their token.
+// Errors are returned as json encoded errors.HTTPError values.
+//
+// # Example Usage
+//
+//	router := chi.NewRouter()
+//	router.Use(api.WithLogging(logger))
+//	router.Use(api.WithTracing(tracer))
+//	router.Use(api.WithUploadLimit(8 << 20))
+//	router.Use(api.WithSessions(storage))
+//
+//	router.With(api.WithTokens(storage)).Mount("/api/v1", api.Router(storage))
+package api
@@ -156,3 +156,23 @@ func WithForbiddenPaths(blacklist []string) func(http.Handler) http.Handler {
 		)
 	}
 }
+
+// WithUploadLimit limits the size of request bodies to the given amount of bytes,
+// uploaded files included. Reading past the limit fails with a *http.MaxBytesError,
+// reported to clients as http413.
+// A limit of zero or less leaves request bodies unlimited.
+func WithUploadLimit(limit int64) func(http.Handler) http.Handler {
+	if limit <= 0 {
+		return func(next http.Handler) http.Handler {
+			return next
+		}
+	}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(
+			func(w http.ResponseWriter, r *http.Request) {
+				r.Body = http.MaxBytesReader(w, r.Body, limit)
+				next.ServeHTTP(w, r)
+			},
+		)
+	}
+}
@@ -0,0 +1,50 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/aexvir/skladka/internal/errors"
+	"github.com/aexvir/skladka/internal/logging"
+)
+
+// respond writes the value as json with the given status code.
+func respond(w http.ResponseWriter, status int, value any) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(value)
+}
+
+// fail writes the error as a json encoded errors.HTTPError.
+// Storage errors are mapped to the matching status codes, anything
+// unknown is reported as an internal server error and logged.
+func fail(w http.ResponseWriter, r *http.Request, err error) {
+	herr := status(err)
+
+	if herr.Code >= http.StatusInternalServerError {
+		logging.
+			FromContext(r.Context()).
+			Error(err, "api.error", "error handling request", "url", r.RequestURI)
+	}
+
+	respond(w, herr.Code, herr)
+}
+
+// status maps an error to the http error that should be sent to the client.
+func status(err error) *errors.HTTPError {
+	if errors.Is(err, pgx.ErrNoRows) {
+		err = errors.NewHTTPError(http.StatusNotFound, "paste not found", err)
+	}
+
+	// bodies cut short by the upload limit are reported as such, even if they
+	// were already reported as malformed by the handler reading them
+	if toolarge(err) {
+		err = errors.NewHTTPError(http.StatusRequestEntityTooLarge, "request body too large", err)
+	}
+
+	// http errors already present in the chain are returned as they are
+	// anything else is reported as internal server error
+	return errors.AsHTTPError(err)
+}
@@ -0,0 +1,592 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/aexvir/skladka/internal/errors"
+	"github.com/aexvir/skladka/internal/logging"
+	"github.com/aexvir/skladka/internal/paste"
+	"github.com/aexvir/skladka/internal/user"
+)
+
+// Storage defines the interface for paste storage operations required by the api.
+// This interface allows the api to be decoupled from the actual storage implementation,
+// making it easier to test and maintain.
+type Storage interface {
+	// GetPaste retrieves a paste by its reference.
+	GetPaste(context.Context, string) (paste.Paste, error)
+
+	// GetPasteWithPassword retrieves a paste by its reference.
+	// Returns nil if the password doesn't match.
+	GetPasteWithPassword(context.Context, string, string) (*paste.Paste, error)
+
+	// PeekPaste retrieves a paste by its reference without counting the read.
+	// Returns nil if the paste is password protected and the password doesn't match.
+	PeekPaste(context.Context, string, string) (*paste.Paste, error)
+
+	// GetPasteWithToken retrieves a paste on behalf of its owner.
+	GetPasteWithToken(context.Context, string, string) (paste.Paste, error)
+
+	// CreatePaste stores a new paste and returns its reference and owner token.
+	CreatePaste(context.Context, paste.Paste) (string, string, error)
+
+	// ListPastes returns a page of public pastes matching the filter, starting after the cursor.
+	ListPastes(context.Context, paste.Filter, string, int) (paste.Page, error)
+
+	// UpdatePaste edits the paste with the given reference, authorized by the owner token.
+	UpdatePaste(context.Context, string, string, paste.Paste) error
+
+	// UpdateOwnerPaste edits the paste with the given reference on behalf of the user owning it.
+	UpdateOwnerPaste(context.Context, user.User, string, paste.Paste) error
+
+	// DeletePaste soft deletes the paste with the given reference, authorized by the owner token.
+	DeletePaste(context.Context, string, string) error
+
+	// DeleteOwnerPastes soft deletes the pastes of the user with the given references.
+	DeleteOwnerPastes(context.Context, user.User, []string) (int64, error)
+
+	// GetAttachment returns an attachment of a paste along with its content, without counting the read.
+	GetAttachment(context.Context, string, string) (paste.Attachment, error)
+
+	// ListRevisions returns every revision of a paste, oldest first.
+	ListRevisions(context.Context, string, string) ([]paste.Revision, error)
+
+	// GetRevision returns a single revision of a paste by its number.
+	GetRevision(context.Context, string, int, string) (paste.Revision, error)
+
+	// ListForks returns the public pastes forked from a paste.
+	ListForks(context.Context, string) ([]paste.Paste, error)
+
+	// SearchPastes returns the public pastes best matching the query, up to the limit.
+	SearchPastes(context.Context, string, int) ([]paste.Paste, error)
+
+	// ListTags returns the most used tags of public pastes starting with the prefix, up to the limit.
+	ListTags(context.Context, string, int) ([]paste.Tag, error)
+}
+
+const (
+	// defaultPageSize is the number of pastes listed per page unless a limit is requested.
+	defaultPageSize = 20
+	// maxPageSize is the largest limit clients can request.
+	maxPageSize = 100
+)
+
+// created is the response sent when a paste is created.
+// The token is only ever returned here, it's required to edit or delete the paste.
+type created struct {
+	paste.Paste
+	Token string `json:"token"`
+}
+
+// Router returns a chi.Router that exposes the paste operations as a json api.
+// It is meant to be mounted under a versioned prefix, e.g. /api/v1.
+//
+// Requests and responses are json encoded pastes, errors are returned as json
+// encoded errors.HTTPError values. Password protected pastes expect the password
+// in the x-skd-password header, the same way the raw endpoint of the frontend does.
+// Editing and deleting pastes requires the token returned on creation in the
+// x-skd-token header, or a request authenticated as the user owning the paste.
+// Requests authenticated with a personal access token are limited to the scopes
+// granted to it.
+func Router(storage Storage) chi.Router {
+	router := chi.NewRouter()
+
+	router.With(RequireScope(user.ScopeRead)).Get(
+		"/pastes",
+		http.HandlerFunc(
+			func(w http.ResponseWriter, r *http.Request) {
+				logger := logging.FromContext(r.Context())
+				logger.Info("api.pastes", "listing pastes")
+
+				filter, err := paste.ParseFilter(r.URL.Query())
+				if err != nil {
+					fail(w, r, errors.NewHTTPError(http.StatusBadRequest, "invalid filter", err))
+					return
+				}
+
+				limit, err := pagesize(r)
+				if err != nil {
+					fail(w, r, err)
+					return
+				}
+
+				page, err := storage.ListPastes(r.Context(), filter, r.URL.Query().Get("cursor"), limit)
+				if err != nil {
+					fail(w, r, errors.Wrap(err, "failed to list pastes"))
+					return
+				}
+
+				pastes := page.Pastes
+				for i := range pastes {
+					// never leak the content of protected pastes on listings
+					if pastes[i].Password != nil {
+						pastes[i].Content = ""
+					}
+					pastes[i] = redact(pastes[i])
+				}
+
+				if page.Next != "" {
+					w.Header().Set("x-skd-next-cursor", page.Next)
+				}
+
+				respond(w, http.StatusOK, pastes)
+			},
+		),
+	)
+
+	router.With(RequireScope(user.ScopeRead)).Get(
+		"/search",
+		http.HandlerFunc(
+			func(w http.ResponseWriter, r *http.Request) {
+				query := strings.TrimSpace(r.URL.Query().Get("q"))
+				if query == "" {
+					fail(w, r, errors.NewHTTPError(http.StatusBadRequest, "search query is required", nil))
+					return
+				}
+
+				limit, err := pagesize(r)
+				if err != nil {
+					fail(w, r, err)
+					return
+				}
+
+				pastes, err := storage.SearchPastes(r.Context(), query, limit)
+				if err != nil {
+					fail(w, r, errors.Wrap(err, "failed to search pastes"))
+					return
+				}
+
+				for i := range pastes {
+					if pastes[i].Password != nil {
+						pastes[i].Content = ""
+					}
+					pastes[i] = redact(pastes[i])
+				}
+
+				logging.
+					FromContext(r.Context()).
+					Info("api.search", "searched pastes", "query", query, "results", len(pastes))
+
+				respond(w, http.StatusOK, pastes)
+			},
+		),
+	)
+
+	router.With(RequireScope(user.ScopeRead)).Get(
+		"/tags",
+		http.HandlerFunc(
+			func(w http.ResponseWriter, r *http.Request) {
+				limit, err := pagesize(r)
+				if err != nil {
+					fail(w, r, err)
+					return
+				}
+
+				tags, err := storage.ListTags(r.Context(), strings.TrimSpace(r.URL.Query().Get("prefix")), limit)
+				if err != nil {
+					fail(w, r, errors.Wrap(err, "failed to list tags"))
+					return
+				}
+
+				respond(w, http.StatusOK, tags)
+			},
+		),
+	)
+
+	router.With(RequireScope(user.ScopeWrite)).Post(
+		"/pastes",
+		http.HandlerFunc(
+			func(w http.ResponseWriter, r *http.Request) {
+				logger := logging.FromContext(r.Context())
+				logger.Info("api.pastes", "creating paste")
+
+				p, err := decode(r)
+				if err != nil {
+					fail(w, r, err)
+					return
+				}
+
+				// fields managed by the storage layer can't be set by clients
+				p.Reference = ""
+				p.Views = 0
+				p.Creation = time.Now()
+				p.Owner = user.FromContext(r.Context())
+
+				if p.Password != nil && *p.Password == "" {
+					p.Password = nil
+				}
+
+				if err := p.Validate(); err != nil {
+					fail(w, r, errors.NewHTTPError(http.StatusBadRequest, "invalid paste", err))
+					return
+				}
+
+				ref, token, err := storage.CreatePaste(r.Context(), p)
+				if err != nil {
+					fail(w, r, errors.Wrap(err, "failed to create paste"))
+					return
+				}
+
+				// the normalized slug is the reference of the paste
+				p.Reference = ref
+				if p.Slug != "" {
+					p.Slug = ref
+				}
+
+				respond(w, http.StatusCreated, created{Paste: redact(p), Token: token})
+			},
+		),
+	)
+
+	router.With(RequireScope(user.ScopeRead)).Get(
+		"/pastes/{ref}",
+		http.HandlerFunc(
+			func(w http.ResponseWriter, r *http.Request) {
+				ref := chi.URLParam(r, "ref")
+
+				p, err := unlock(r, storage, ref)
+				if err != nil {
+					fail(w, r, err)
+					return
+				}
+
+				logging.
+					FromContext(r.Context()).
+					Info("api.pastes", "fetched paste", "ref", ref)
+
+				respond(w, http.StatusOK, redact(p))
+			},
+		),
+	)
+
+	router.With(RequireScope(user.ScopeRead)).Get(
+		"/pastes/{ref}/attachments/{name}",
+		http.HandlerFunc(
+			func(w http.ResponseWriter, r *http.Request) {
+				ref := chi.URLParam(r, "ref")
+				// attachment names are escaped when they're part of the url
+				name, err := url.PathUnescape(chi.URLParam(r, "name"))
+				if err != nil {
+					fail(w, r, errors.NewHTTPError(http.StatusBadRequest, "invalid attachment name", err))
+					return
+				}
+
+				attachment, err := storage.GetAttachment(r.Context(), ref, name)
+				if err != nil {
+					fail(w, r, errors.Wrapf(err, "failed to fetch attachment %q of paste %s", name, ref))
+					return
+				}
+
+				logging.
+					FromContext(r.Context()).
+					Info("api.pastes", "fetched attachment", "ref", ref, "name", name)
+
+				ServeAttachment(w, attachment)
+			},
+		),
+	)
+
+	router.With(RequireScope(user.ScopeRead)).Get(
+		"/pastes/{ref}/forks",
+		http.HandlerFunc(
+			func(w http.ResponseWriter, r *http.Request) {
+				ref := chi.URLParam(r, "ref")
+
+				forks, err := storage.ListForks(r.Context(), ref)
+				if err != nil {
+					fail(w, r, errors.Wrapf(err, "failed to list forks of paste %s", ref))
+					return
+				}
+
+				for i := range forks {
+					if forks[i].Password != nil {
+						forks[i].Content = ""
+					}
+					forks[i] = redact(forks[i])
+				}
+
+				respond(w, http.StatusOK, forks)
+			},
+		),
+	)
+
+	router.With(RequireScope(user.ScopeRead)).Get(
+		"/pastes/{ref}/revisions",
+		http.HandlerFunc(
+			func(w http.ResponseWriter, r *http.Request) {
+				ref := chi.URLParam(r, "ref")
+
+				secret, err := history(r, storage, ref)
+				if err != nil {
+					fail(w, r, err)
+					return
+				}
+
+				revisions, err := storage.ListRevisions(r.Context(), ref, secret)
+				if err != nil {
+					fail(w, r, errors.Wrapf(err, "failed to list revisions of paste %s", ref))
+					return
+				}
+
+				respond(w, http.StatusOK, revisions)
+			},
+		),
+	)
+
+	router.With(RequireScope(user.ScopeRead)).Get(
+		"/pastes/{ref}/revisions/{revision}",
+		http.HandlerFunc(
+			func(w http.ResponseWriter, r *http.Request) {
+				ref := chi.URLParam(r, "ref")
+
+				number, err := strconv.Atoi(chi.URLParam(r, "revision"))
+				if err != nil {
+					fail(w, r, errors.NewHTTPError(http.StatusBadRequest, "invalid revision", err))
+					return
+				}
+
+				secret, err := history(r, storage, ref)
+				if err != nil {
+					fail(w, r, err)
+					return
+				}
+
+				revision, err := storage.GetRevision(r.Context(), ref, number, secret)
+				if err != nil {
+					fail(w, r, errors.Wrapf(err, "failed to fetch revision %d of paste %s", number, ref))
+					return
+				}
+
+				respond(w, http.StatusOK, revision)
+			},
+		),
+	)
+
+	router.With(RequireScope(user.ScopeWrite)).Put(
+		"/pastes/{ref}",
+		http.HandlerFunc(
+			func(w http.ResponseWriter, r *http.Request) {
+				ref := chi.URLParam(r, "ref")
+
+				token, account, err := owner(r)
+				if err != nil {
+					fail(w, r, err)
+					return
+				}
+
+				var p paste.Paste
+				if err := json.NewDecoder(r.Body).Decode(&p); err != nil {
+					fail(w, r, errors.NewHTTPError(http.StatusBadRequest, "invalid request body", err))
+					return
+				}
+
+				if err := p.Validate(); err != nil {
+					fail(w, r, errors.NewHTTPError(http.StatusBadRequest, "invalid paste", err))
+					return
+				}
+
+				if token != "" {
+					err = storage.UpdatePaste(r.Context(), ref, token, p)
+				} else {
+					err = storage.UpdateOwnerPaste(r.Context(), *account, ref, p)
+				}
+				if err != nil {
+					fail(w, r, errors.Wrap(err, "failed to update paste"))
+					return
+				}
+
+				var updated paste.Paste
+				if token != "" {
+					updated, err = storage.GetPasteWithToken(r.Context(), ref, token)
+				} else {
+					// pastes edited on behalf of their owner aren't protected, peeking reveals them whole
+					var peeked *paste.Paste
+					if peeked, err = storage.PeekPaste(r.Context(), ref, ""); peeked != nil {
+						updated = *peeked
+					}
+				}
+				if err != nil {
+					fail(w, r, errors.Wrapf(err, "failed to fetch paste %s", ref))
+					return
+				}
+
+				logging.
+					FromContext(r.Context()).
+					Info("api.pastes", "updated paste", "ref", ref)
+
+				respond(w, http.StatusOK, redact(updated))
+			},
+		),
+	)
+
+	router.With(RequireScope(user.ScopeDelete)).Delete(
+		"/pastes/{ref}",
+		http.HandlerFunc(
+			func(w http.ResponseWriter, r *http.Request) {
+				ref := chi.URLParam(r, "ref")
+
+				token, account, err := owner(r)
+				if err != nil {
+					fail(w, r, err)
+					return
+				}
+
+				if token != "" {
+					err = storage.DeletePaste(r.Context(), ref, token)
+				} else {
+					err = deleteowned(r.Context(), storage, *account, ref)
+				}
+				if err != nil {
+					fail(w, r, errors.Wrap(err, "failed to delete paste"))
+					return
+				}
+
+				logging.
+					FromContext(r.Context()).
+					Info("api.pastes", "deleted paste", "ref", ref)
+
+				w.WriteHeader(http.StatusNoContent)
+			},
+		),
+	)
+
+	return router
+}
+
+// unlock fetches the paste with the given reference, verifying the password
+// from the x-skd-password header if the paste is password protected.
+func unlock(r *http.Request, storage Storage, ref string) (paste.Paste, error) {
+	p, err := storage.GetPaste(r.Context(), ref)
+	if err != nil {
+		return p, errors.Wrapf(err, "failed to fetch paste %s", ref)
+	}
+
+	if p.Password == nil {
+		return p, nil
+	}
+
+	password := r.Header.Get("x-skd-password")
+	if password == "" {
+		return p, errors.NewHTTPError(http.StatusUnauthorized, "paste is password protected", nil)
+	}
+
+	unlocked, err := storage.GetPasteWithPassword(r.Context(), ref, password)
+	if err != nil {
+		return p, errors.Wrapf(err, "failed to fetch paste %s", ref)
+	}
+
+	if unlocked == nil {
+		return p, errors.NewHTTPError(http.StatusForbidden, "invalid password", nil)
+	}
+
+	return *unlocked, nil
+}
+
+// peek verifies access to the paste with the given reference the same way unlock does,
+// without counting the read.
+func peek(r *http.Request, storage Storage, ref string) (*paste.Paste, error) {
+	password := r.Header.Get("x-skd-password")
+
+	p, err := storage.PeekPaste(r.Context(), ref, password)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to fetch paste %s", ref)
+	}
+
+	if p == nil && password == "" {
+		return nil, errors.NewHTTPError(http.StatusUnauthorized, "paste is password protected", nil)
+	}
+
+	if p == nil {
+		return nil, errors.NewHTTPError(http.StatusForbidden, "invalid password", nil)
+	}
+
+	return p, nil
+}
+
+// history authorizes reading the revisions of the paste with the given reference,
+// returning the secret to list them with. Revisions reveal the content of the paste
+// without counting the read, so those of burn after reading pastes are only served
+// to their owner, identified by the owner token or the account owning the paste.
+func history(r *http.Request, storage Storage, ref string) (string, error) {
+	if token := r.Header.Get("x-skd-token"); token != "" {
+		if _, err := storage.GetPasteWithToken(r.Context(), ref, token); err != nil {
+			return "", errors.Wrapf(err, "failed to fetch paste %s", ref)
+		}
+		return token, nil
+	}
+
+	p, err := peek(r, storage, ref)
+	if err != nil {
+		return "", err
+	}
+
+	if p.BurnAfter != nil {
+		account := user.FromContext(r.Context())
+		if account == nil || p.Owner == nil || p.Owner.ID != account.ID {
+			return "", errors.NewHTTPError(
+				http.StatusForbidden, "revisions of burn after reading pastes are only available to their owner", nil,
+			)
+		}
+	}
+
+	return r.Header.Get("x-skd-password"), nil
+}
+
+// pagesize returns the number of pastes requested in the limit query parameter,
+// falling back to the default page size.
+func pagesize(r *http.Request) (int, error) {
+	raw := r.URL.Query().Get("limit")
+	if raw == "" {
+		return defaultPageSize, nil
+	}
+
+	limit, err := strconv.Atoi(raw)
+	if err != nil || limit < 1 || limit > maxPageSize {
+		return 0, errors.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("limit must be between 1 and %d", maxPageSize), err)
+	}
+
+	return limit, nil
+}
+
+// owner returns the owner token sent in the x-skd-token header. Requests without it are
+// authorized as the user they're authenticated as, who can modify the pastes they own.
+func owner(r *http.Request) (string, *user.User, error) {
+	token := r.Header.Get("x-skd-token")
+	account := user.FromContext(r.Context())
+	if token == "" && account == nil {
+		return "", nil, errors.NewHTTPError(http.StatusUnauthorized, "paste token is required", nil)
+	}
+	return token, account, nil
+}
+
+// deleteowned deletes the paste with the given reference on behalf of the user owning it.
+func deleteowned(ctx context.Context, storage Storage, account user.User, ref string) error {
+	deleted, err := storage.DeleteOwnerPastes(ctx, account, []string{ref})
+	if err != nil {
+		return err
+	}
+
+	if deleted == 0 {
+		return errors.Wrapf(errors.ErrForbidden, "paste %s isn't owned by %s", ref, account.Username)
+	}
+
+	return nil
+}
+
+// redact strips the password hash from the paste before it's sent to clients.
+// Protected pastes keep an empty password so clients can still tell them apart.
+func redact(p paste.Paste) paste.Paste {
+	if p.Password != nil {
+		p.Password = new(string)
+	}
+	return p
+}
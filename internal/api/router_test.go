@@ -0,0 +1,764 @@
+package api_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aexvir/skladka/internal/api"
+	"github.com/aexvir/skladka/internal/errors"
+	"github.com/aexvir/skladka/internal/paste"
+	"github.com/aexvir/skladka/internal/user"
+)
+
+type fakestorage struct {
+	pastes    map[string]paste.Paste
+	owners    map[string]int64
+	tokens    map[string]string
+	revisions map[string][]paste.Revision
+}
+
+func (s *fakestorage) GetPaste(_ context.Context, ref string) (paste.Paste, error) {
+	p, ok := s.pastes[ref]
+	if !ok {
+		return p, pgx.ErrNoRows
+	}
+	p.Views++
+	if p.Burned() {
+		delete(s.pastes, ref)
+	} else {
+		s.pastes[ref] = p
+	}
+	return p, nil
+}
+
+func (s *fakestorage) PeekPaste(_ context.Context, ref, password string) (*paste.Paste, error) {
+	p, ok := s.pastes[ref]
+	if !ok {
+		return nil, pgx.ErrNoRows
+	}
+	if p.Password != nil && *p.Password != password {
+		return nil, nil
+	}
+	if id, ok := s.owners[ref]; ok {
+		p.Owner = &user.User{ID: id}
+	}
+	return &p, nil
+}
+
+func (s *fakestorage) GetPasteWithPassword(ctx context.Context, ref, password string) (*paste.Paste, error) {
+	p, err := s.GetPaste(ctx, ref)
+	if err != nil {
+		return nil, err
+	}
+	if p.Password == nil || *p.Password != password {
+		return nil, nil
+	}
+	return &p, nil
+}
+
+func (s *fakestorage) GetPasteWithToken(ctx context.Context, ref, token string) (paste.Paste, error) {
+	if err := s.authorize(ref, token); err != nil {
+		return paste.Paste{}, err
+	}
+	return s.pastes[ref], nil
+}
+
+func (s *fakestorage) CreatePaste(_ context.Context, p paste.Paste) (string, string, error) {
+	p.Reference = "abcd1234"
+	s.pastes[p.Reference] = p
+	s.tokens[p.Reference] = "token"
+	return p.Reference, "token", nil
+}
+
+func (s *fakestorage) ListPastes(_ context.Context, filter paste.Filter, _ string, limit int) (paste.Page, error) {
+	pastes := make([]paste.Paste, 0, len(s.pastes))
+	for _, p := range s.pastes {
+		if filter.Syntax != "" && p.Syntax != filter.Syntax {
+			continue
+		}
+		pastes = append(pastes, p)
+	}
+
+	var next string
+	if len(pastes) > limit {
+		pastes, next = pastes[:limit], "next"
+	}
+
+	return paste.Page{Pastes: pastes, Next: next}, nil
+}
+
+func (s *fakestorage) UpdatePaste(_ context.Context, ref, token string, p paste.Paste) error {
+	if err := s.authorize(ref, token); err != nil {
+		return err
+	}
+	existing := s.pastes[ref]
+	existing.Title, existing.Content, existing.Syntax, existing.Tags = p.Title, p.Content, p.Syntax, p.Tags
+	s.pastes[ref] = existing
+	return nil
+}
+
+func (s *fakestorage) UpdateOwnerPaste(ctx context.Context, account user.User, ref string, p paste.Paste) error {
+	if _, ok := s.pastes[ref]; !ok {
+		return pgx.ErrNoRows
+	}
+	if s.owners[ref] != account.ID {
+		return errors.Wrap(errors.ErrForbidden, "not the owner")
+	}
+	s.tokens[ref] = "owned"
+	return s.UpdatePaste(ctx, ref, "owned", p)
+}
+
+func (s *fakestorage) DeleteOwnerPastes(_ context.Context, account user.User, refs []string) (int64, error) {
+	var deleted int64
+	for _, ref := range refs {
+		if _, ok := s.pastes[ref]; ok && s.owners[ref] == account.ID {
+			delete(s.pastes, ref)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
+
+func (s *fakestorage) DeletePaste(_ context.Context, ref, token string) error {
+	if err := s.authorize(ref, token); err != nil {
+		return err
+	}
+	delete(s.pastes, ref)
+	return nil
+}
+
+func (s *fakestorage) GetAttachment(ctx context.Context, ref, name string) (paste.Attachment, error) {
+	p, err := s.GetPaste(ctx, ref)
+	if err != nil {
+		return paste.Attachment{}, err
+	}
+	attachment, ok := p.Attachment(name)
+	if !ok {
+		return paste.Attachment{}, pgx.ErrNoRows
+	}
+	return attachment, nil
+}
+
+func (s *fakestorage) ListRevisions(_ context.Context, ref, _ string) ([]paste.Revision, error) {
+	if _, ok := s.pastes[ref]; !ok {
+		return nil, pgx.ErrNoRows
+	}
+	return s.revisions[ref], nil
+}
+
+func (s *fakestorage) GetRevision(ctx context.Context, ref string, number int, secret string) (paste.Revision, error) {
+	revisions, err := s.ListRevisions(ctx, ref, secret)
+	if err != nil {
+		return paste.Revision{}, err
+	}
+	if number < 1 || number > len(revisions) {
+		return paste.Revision{}, pgx.ErrNoRows
+	}
+	return revisions[number-1], nil
+}
+
+func (s *fakestorage) ListForks(_ context.Context, ref string) ([]paste.Paste, error) {
+	var forks []paste.Paste
+	for _, p := range s.pastes {
+		if p.Public && p.Parent != nil && *p.Parent == ref {
+			forks = append(forks, p)
+		}
+	}
+	return forks, nil
+}
+
+func (s *fakestorage) SearchPastes(_ context.Context, query string, limit int) ([]paste.Paste, error) {
+	var pastes []paste.Paste
+	for _, p := range s.pastes {
+		if p.Public && (strings.Contains(p.Title, query) || strings.Contains(p.Content, query)) {
+			pastes = append(pastes, p)
+		}
+	}
+	if len(pastes) > limit {
+		pastes = pastes[:limit]
+	}
+	return pastes, nil
+}
+
+func (s *fakestorage) ListTags(_ context.Context, prefix string, limit int) ([]paste.Tag, error) {
+	counts := make(map[string]int)
+	for _, p := range s.pastes {
+		for _, tag := range p.Tags {
+			if p.Public && strings.HasPrefix(tag, prefix) {
+				counts[tag]++
+			}
+		}
+	}
+
+	tags := make([]paste.Tag, 0, len(counts))
+	for name, count := range counts {
+		tags = append(tags, paste.Tag{Name: name, Pastes: count})
+	}
+	slices.SortFunc(tags, func(a, b paste.Tag) int { return b.Pastes - a.Pastes })
+
+	if len(tags) > limit {
+		tags = tags[:limit]
+	}
+	return tags, nil
+}
+
+func (s *fakestorage) authorize(ref, token string) error {
+	if _, ok := s.pastes[ref]; !ok {
+		return pgx.ErrNoRows
+	}
+	if s.tokens[ref] == "" || s.tokens[ref] != token {
+		return errors.Wrap(errors.ErrForbidden, "invalid token")
+	}
+	return nil
+}
+
+func TestRouterCreateAndGet(t *testing.T) {
+	router := api.Router(&fakestorage{pastes: map[string]paste.Paste{}, tokens: map[string]string{}})
+
+	body := `{"title": "hello", "content": "fmt.Println(\"hello\")", "syntax": "go", "public": true}`
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/pastes", strings.NewReader(body)))
+	require.Equal(t, http.StatusCreated, rec.Code)
+
+	var created struct {
+		paste.Paste
+		Token string `json:"token"`
+	}
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&created))
+	require.Equal(t, "abcd1234", created.Reference)
+	require.Equal(t, "token", created.Token)
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/pastes/abcd1234", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var fetched paste.Paste
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&fetched))
+	require.Equal(t, "hello", fetched.Title)
+	require.Equal(t, "go", fetched.Syntax)
+}
+
+func TestRouterUpload(t *testing.T) {
+	storage := &fakestorage{pastes: map[string]paste.Paste{}, tokens: map[string]string{}}
+	router := api.WithUploadLimit(1024)(api.Router(storage))
+
+	image := []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+	archive := []byte{0x1f, 0x8b, 0x08, 0x00, 0xff}
+
+	uploads := map[string][]byte{
+		"main.go":        []byte("content of main.go"),
+		"notes":          []byte("content of notes"),
+		"screenshot.png": image,
+		"logs.gz":        archive,
+	}
+
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	require.NoError(t, writer.WriteField("paste", `{"title": "upload", "public": true}`))
+	for _, name := range []string{"main.go", "notes", "screenshot.png", "logs.gz"} {
+		part, err := writer.CreateFormFile("files", name)
+		require.NoError(t, err)
+		_, err = part.Write(uploads[name])
+		require.NoError(t, err)
+	}
+	require.NoError(t, writer.Close())
+
+	req := httptest.NewRequest(http.MethodPost, "/pastes", &body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusCreated, rec.Code)
+
+	created := storage.pastes["abcd1234"]
+	require.Equal(t, "upload", created.Title)
+	require.True(t, created.Public)
+	require.Equal(
+		t, []paste.File{
+			{Name: "main.go", Syntax: "go", Content: "content of main.go"},
+			{Name: "notes", Syntax: "plaintext", Content: "content of notes"},
+		},
+		created.Files,
+	)
+
+	// images and binary files are attached instead, with their sniffed content type
+	require.Equal(
+		t, []paste.Attachment{
+			{Name: "screenshot.png", ContentType: "image/png", Size: int64(len(image)), Data: image},
+			{Name: "logs.gz", ContentType: "application/x-gzip", Size: int64(len(archive)), Data: archive},
+		},
+		created.Attachments,
+	)
+
+	// bodies over the limit are rejected whether they're uploads or plain json
+	large := `{"content": "` + strings.Repeat("a", 2048) + `"}`
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/pastes", strings.NewReader(large)))
+	require.Equal(t, http.StatusRequestEntityTooLarge, rec.Code)
+}
+
+func TestRouterAttachments(t *testing.T) {
+	image := []byte("\x89PNG\r\n\x1a\n")
+	archive := []byte{0x1f, 0x8b, 0x08, 0x00, 0xff}
+
+	router := api.Router(
+		&fakestorage{
+			pastes: map[string]paste.Paste{
+				"abcd1234": {
+					Reference: "abcd1234",
+					Attachments: []paste.Attachment{
+						{Name: "screen shot.png", ContentType: "image/png", Size: int64(len(image)), Data: image},
+						{Name: "logs.gz", ContentType: "application/x-gzip", Size: int64(len(archive)), Data: archive},
+					},
+				},
+			},
+		},
+	)
+
+	tests := map[string]struct {
+		path        string
+		status      int
+		contenttype string
+		disposition string
+		body        []byte
+	}{
+		"image": {
+			path:        "/pastes/abcd1234/attachments/screen%20shot.png",
+			status:      http.StatusOK,
+			contenttype: "image/png",
+			disposition: `inline; filename="screen shot.png"`,
+			body:        image,
+		},
+		"download": {
+			path:        "/pastes/abcd1234/attachments/logs.gz",
+			status:      http.StatusOK,
+			contenttype: "application/x-gzip",
+			disposition: `attachment; filename=logs.gz`,
+			body:        archive,
+		},
+		"missing attachment": {path: "/pastes/abcd1234/attachments/nope.png", status: http.StatusNotFound},
+		"missing paste":      {path: "/pastes/nope/attachments/logs.gz", status: http.StatusNotFound},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, test.path, nil))
+			require.Equal(t, test.status, rec.Code)
+
+			if test.body == nil {
+				return
+			}
+
+			require.Equal(t, test.contenttype, rec.Header().Get("Content-Type"))
+			require.Equal(t, test.disposition, rec.Header().Get("Content-Disposition"))
+			require.Equal(t, "nosniff", rec.Header().Get("X-Content-Type-Options"))
+			require.Equal(t, test.body, rec.Body.Bytes())
+		})
+	}
+}
+
+func TestRouterErrors(t *testing.T) {
+	secret := "secret"
+	router := api.Router(
+		&fakestorage{
+			pastes: map[string]paste.Paste{
+				"locked":  {Reference: "locked", Content: "hidden", Password: &secret},
+				"ownless": {Reference: "ownless", Content: "legacy"},
+			},
+			tokens: map[string]string{"locked": "token"},
+		},
+	)
+
+	tests := map[string]struct {
+		method   string
+		path     string
+		body     string
+		password string
+		token    string
+		status   int
+	}{
+		"missing paste":    {method: http.MethodGet, path: "/pastes/nope", status: http.StatusNotFound},
+		"invalid body":     {method: http.MethodPost, path: "/pastes", body: "{", status: http.StatusBadRequest},
+		"empty content":    {method: http.MethodPost, path: "/pastes", body: `{"content": " "}`, status: http.StatusBadRequest},
+		"missing password": {method: http.MethodGet, path: "/pastes/locked", status: http.StatusUnauthorized},
+		"wrong password":   {method: http.MethodGet, path: "/pastes/locked", password: "nope", status: http.StatusForbidden},
+		"delete missing":   {method: http.MethodDelete, path: "/pastes/nope", token: "token", status: http.StatusNotFound},
+		"delete no token":  {method: http.MethodDelete, path: "/pastes/locked", status: http.StatusUnauthorized},
+		"delete bad token": {method: http.MethodDelete, path: "/pastes/locked", token: "nope", status: http.StatusForbidden},
+		"delete tokenless": {method: http.MethodDelete, path: "/pastes/ownless", token: "token", status: http.StatusForbidden},
+		"update no token":  {method: http.MethodPut, path: "/pastes/locked", body: `{"content": "x"}`, status: http.StatusUnauthorized},
+		"update bad token": {method: http.MethodPut, path: "/pastes/locked", body: `{"content": "x"}`, token: "nope", status: http.StatusForbidden},
+		"update empty":     {method: http.MethodPut, path: "/pastes/locked", body: `{"content": ""}`, token: "token", status: http.StatusBadRequest},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			req := httptest.NewRequest(test.method, test.path, strings.NewReader(test.body))
+			if test.password != "" {
+				req.Header.Set("x-skd-password", test.password)
+			}
+			if test.token != "" {
+				req.Header.Set("x-skd-token", test.token)
+			}
+
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+			require.Equal(t, test.status, rec.Code)
+
+			var herr errors.HTTPError
+			require.NoError(t, json.NewDecoder(rec.Body).Decode(&herr))
+			require.Equal(t, test.status, herr.Code)
+		})
+	}
+}
+
+func TestRouterUpdateAndDelete(t *testing.T) {
+	storage := &fakestorage{
+		pastes: map[string]paste.Paste{"abcd1234": {Reference: "abcd1234", Content: "old"}},
+		tokens: map[string]string{"abcd1234": "token"},
+	}
+	router := api.Router(storage)
+
+	req := httptest.NewRequest(http.MethodPut, "/pastes/abcd1234", strings.NewReader(`{"title": "new", "content": "new"}`))
+	req.Header.Set("x-skd-token", "token")
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var updated paste.Paste
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&updated))
+	require.Equal(t, "new", updated.Title)
+	require.Equal(t, "new", updated.Content)
+
+	req = httptest.NewRequest(http.MethodDelete, "/pastes/abcd1234", nil)
+	req.Header.Set("x-skd-token", "token")
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusNoContent, rec.Code)
+	require.Empty(t, storage.pastes)
+}
+
+func TestRouterOwnerIdentity(t *testing.T) {
+	storage := &fakestorage{
+		pastes: map[string]paste.Paste{
+			"mine":   {Reference: "mine", Content: "old"},
+			"theirs": {Reference: "theirs", Content: "old"},
+		},
+		owners: map[string]int64{"mine": 1, "theirs": 2},
+		tokens: map[string]string{"mine": "token", "theirs": "token"},
+	}
+	router := api.Router(storage)
+
+	owner := &user.User{ID: 1, Username: "owner"}
+	scoped := func(req *http.Request, scopes ...user.Scope) *http.Request {
+		return req.WithContext(user.NewScopedContext(req.Context(), owner, scopes))
+	}
+
+	// the steps run in order, the own paste is deleted along the way
+	steps := []struct {
+		name   string
+		method string
+		ref    string
+		scopes []user.Scope
+		status int
+	}{
+		{name: "update own paste", method: http.MethodPut, ref: "mine", scopes: []user.Scope{user.ScopeWrite}, status: http.StatusOK},
+		{name: "update foreign paste", method: http.MethodPut, ref: "theirs", scopes: []user.Scope{user.ScopeWrite}, status: http.StatusForbidden},
+		{name: "update without scope", method: http.MethodPut, ref: "mine", scopes: []user.Scope{user.ScopeRead}, status: http.StatusForbidden},
+		{name: "delete foreign paste", method: http.MethodDelete, ref: "theirs", scopes: []user.Scope{user.ScopeDelete}, status: http.StatusForbidden},
+		{name: "delete without scope", method: http.MethodDelete, ref: "mine", scopes: []user.Scope{user.ScopeWrite}, status: http.StatusForbidden},
+		{name: "delete own paste", method: http.MethodDelete, ref: "mine", scopes: []user.Scope{user.ScopeDelete}, status: http.StatusNoContent},
+		{name: "delete own paste again", method: http.MethodDelete, ref: "mine", scopes: []user.Scope{user.ScopeDelete}, status: http.StatusForbidden},
+	}
+
+	for _, step := range steps {
+		t.Run(step.name, func(t *testing.T) {
+			req := scoped(
+				httptest.NewRequest(step.method, "/pastes/"+step.ref, strings.NewReader(`{"content": "new"}`)),
+				step.scopes...,
+			)
+
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+			require.Equal(t, step.status, rec.Code)
+		})
+	}
+
+	require.NotContains(t, storage.pastes, "mine")
+	require.Equal(t, "old", storage.pastes["theirs"].Content)
+}
+
+func TestRouterRevisions(t *testing.T) {
+	once, secret := 1, "secret"
+	storage := &fakestorage{
+		pastes: map[string]paste.Paste{
+			"abcd1234": {Reference: "abcd1234", Content: "new"},
+			"burning":  {Reference: "burning", Content: "new", BurnAfter: &once},
+			"locked":   {Reference: "locked", Content: "hidden", Password: &secret},
+		},
+		owners: map[string]int64{"burning": 1},
+		tokens: map[string]string{"burning": "token"},
+		revisions: map[string][]paste.Revision{
+			"abcd1234": {{Number: 1, Content: "old"}, {Number: 2, Content: "new"}},
+			"burning":  {{Number: 1, Content: "old"}, {Number: 2, Content: "new"}},
+		},
+	}
+	router := api.Router(storage)
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/pastes/abcd1234/revisions", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var revisions []paste.Revision
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&revisions))
+	require.Len(t, revisions, 2)
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/pastes/abcd1234/revisions/1", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var revision paste.Revision
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&revision))
+	require.Equal(t, "old", revision.Content)
+
+	for path, status := range map[string]int{
+		"/pastes/abcd1234/revisions/3":   http.StatusNotFound,
+		"/pastes/abcd1234/revisions/one": http.StatusBadRequest,
+		"/pastes/locked/revisions":       http.StatusUnauthorized,
+		// revisions reveal the content without counting the read, burning pastes only show them to their owner
+		"/pastes/burning/revisions":   http.StatusForbidden,
+		"/pastes/burning/revisions/2": http.StatusForbidden,
+	} {
+		rec = httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		require.Equal(t, status, rec.Code, path)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/pastes/locked/revisions", nil)
+	req.Header.Set("x-skd-password", "nope")
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusForbidden, rec.Code)
+
+	owners := map[string]func(*http.Request) *http.Request{
+		"owner token": func(req *http.Request) *http.Request {
+			req.Header.Set("x-skd-token", "token")
+			return req
+		},
+		"owner account": func(req *http.Request) *http.Request {
+			return req.WithContext(user.NewScopedContext(req.Context(), &user.User{ID: 1}, []user.Scope{user.ScopeRead}))
+		},
+	}
+	for name, authorize := range owners {
+		rec = httptest.NewRecorder()
+		router.ServeHTTP(rec, authorize(httptest.NewRequest(http.MethodGet, "/pastes/burning/revisions", nil)))
+		require.Equal(t, http.StatusOK, rec.Code, name)
+	}
+
+	stranger := httptest.NewRequest(http.MethodGet, "/pastes/burning/revisions", nil)
+	stranger = stranger.WithContext(user.NewScopedContext(stranger.Context(), &user.User{ID: 2}, []user.Scope{user.ScopeRead}))
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, stranger)
+	require.Equal(t, http.StatusForbidden, rec.Code)
+
+	// the refused requests leave the paste to be read, and burned, once
+	require.Zero(t, storage.pastes["burning"].Views)
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/pastes/burning", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.NotContains(t, storage.pastes, "burning")
+}
+
+func TestRouterForks(t *testing.T) {
+	secret, parent := "secret", "abcd1234"
+	router := api.Router(
+		&fakestorage{
+			pastes: map[string]paste.Paste{
+				"abcd1234": {Reference: "abcd1234", Content: "original", Public: true},
+				"fork0001": {Reference: "fork0001", Content: "fork", Public: true, Parent: &parent},
+				"fork0002": {Reference: "fork0002", Content: "hidden", Public: true, Parent: &parent, Password: &secret},
+				"fork0003": {Reference: "fork0003", Content: "unlisted", Parent: &parent},
+			},
+		},
+	)
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/pastes/abcd1234/forks", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var forks []paste.Paste
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&forks))
+	require.Len(t, forks, 2)
+	for _, fork := range forks {
+		require.Equal(t, parent, *fork.Parent)
+		if fork.Password != nil {
+			require.Empty(t, fork.Content)
+		}
+	}
+}
+
+func TestRouterListRedactsProtectedPastes(t *testing.T) {
+	secret := "secret"
+	router := api.Router(
+		&fakestorage{
+			pastes: map[string]paste.Paste{
+				"locked": {Reference: "locked", Content: "hidden", Password: &secret, Public: true},
+			},
+		},
+	)
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/pastes", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var pastes []paste.Paste
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&pastes))
+	require.Len(t, pastes, 1)
+	require.Empty(t, pastes[0].Content)
+	require.NotNil(t, pastes[0].Password)
+	require.Empty(t, *pastes[0].Password)
+}
+
+func TestRouterListPaginatesAndFilters(t *testing.T) {
+	router := api.Router(
+		&fakestorage{
+			pastes: map[string]paste.Paste{
+				"first":  {Reference: "first", Content: "a", Syntax: "go", Public: true},
+				"second": {Reference: "second", Content: "b", Syntax: "go", Public: true},
+				"third":  {Reference: "third", Content: "c", Syntax: "python", Public: true},
+			},
+		},
+	)
+
+	tests := map[string]struct {
+		query  string
+		status int
+		count  int
+		next   bool
+	}{
+		"everything":      {query: "", status: http.StatusOK, count: 3},
+		"filtered":        {query: "?syntax=go", status: http.StatusOK, count: 2},
+		"limited":         {query: "?limit=1", status: http.StatusOK, count: 1, next: true},
+		"invalid limit":   {query: "?limit=0", status: http.StatusBadRequest},
+		"too large limit": {query: "?limit=1000", status: http.StatusBadRequest},
+		"invalid date":    {query: "?since=yesterday", status: http.StatusBadRequest},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/pastes"+test.query, nil))
+			require.Equal(t, test.status, rec.Code)
+
+			if test.status != http.StatusOK {
+				return
+			}
+
+			var pastes []paste.Paste
+			require.NoError(t, json.NewDecoder(rec.Body).Decode(&pastes))
+			require.Len(t, pastes, test.count)
+			require.Equal(t, test.next, rec.Header().Get("x-skd-next-cursor") != "")
+		})
+	}
+}
+
+func TestRouterSearch(t *testing.T) {
+	password := "secret"
+	router := api.Router(
+		&fakestorage{
+			pastes: map[string]paste.Paste{
+				"open":   {Reference: "open", Title: "hello", Content: "hello world", Public: true},
+				"locked": {Reference: "locked", Title: "hello again", Content: "hidden", Public: true, Password: &password},
+				"other":  {Reference: "other", Title: "bye", Content: "goodbye", Public: true},
+			},
+		},
+	)
+
+	tests := map[string]struct {
+		query  string
+		status int
+		count  int
+	}{
+		"matches":       {query: "?q=hello", status: http.StatusOK, count: 2},
+		"no matches":    {query: "?q=nothing", status: http.StatusOK, count: 0},
+		"limited":       {query: "?q=hello&limit=1", status: http.StatusOK, count: 1},
+		"missing query": {query: "", status: http.StatusBadRequest},
+		"blank query":   {query: "?q=+", status: http.StatusBadRequest},
+		"invalid limit": {query: "?q=hello&limit=abc", status: http.StatusBadRequest},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/search"+test.query, nil))
+			require.Equal(t, test.status, rec.Code)
+
+			if test.status != http.StatusOK {
+				return
+			}
+
+			var pastes []paste.Paste
+			require.NoError(t, json.NewDecoder(rec.Body).Decode(&pastes))
+			require.Len(t, pastes, test.count)
+
+			for _, p := range pastes {
+				if p.Password != nil {
+					require.Empty(t, *p.Password)
+					require.Empty(t, p.Content)
+				}
+			}
+		})
+	}
+}
+
+func TestRouterTags(t *testing.T) {
+	router := api.Router(
+		&fakestorage{
+			pastes: map[string]paste.Paste{
+				"first":  {Reference: "first", Tags: []string{"golang", "cli"}, Public: true},
+				"second": {Reference: "second", Tags: []string{"golang"}, Public: true},
+				"third":  {Reference: "third", Tags: []string{"gopher"}, Public: false},
+			},
+		},
+	)
+
+	tests := map[string]struct {
+		query  string
+		status int
+		tags   []paste.Tag
+	}{
+		"everything":    {query: "", status: http.StatusOK, tags: []paste.Tag{{Name: "golang", Pastes: 2}, {Name: "cli", Pastes: 1}}},
+		"prefix":        {query: "?prefix=go", status: http.StatusOK, tags: []paste.Tag{{Name: "golang", Pastes: 2}}},
+		"limited":       {query: "?limit=1", status: http.StatusOK, tags: []paste.Tag{{Name: "golang", Pastes: 2}}},
+		"no matches":    {query: "?prefix=rust", status: http.StatusOK, tags: []paste.Tag{}},
+		"invalid limit": {query: "?limit=-1", status: http.StatusBadRequest},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/tags"+test.query, nil))
+			require.Equal(t, test.status, rec.Code)
+
+			if test.status != http.StatusOK {
+				return
+			}
+
+			var tags []paste.Tag
+			require.NoError(t, json.NewDecoder(rec.Body).Decode(&tags))
+			require.Equal(t, test.tags, tags)
+		})
+	}
+}
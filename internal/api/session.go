@@ -0,0 +1,85 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/aexvir/skladka/internal/errors"
+	"github.com/aexvir/skladka/internal/logging"
+	"github.com/aexvir/skladka/internal/user"
+)
+
+// SessionCookie is the name of the cookie holding the session token of logged in users.
+const SessionCookie = "skd-session"
+
+// Sessions resolves session tokens to the users they belong to.
+type Sessions interface {
+	// GetSessionUser returns the user the session token belongs to.
+	GetSessionUser(context.Context, string) (user.User, error)
+}
+
+// WithSessions returns a middleware that authenticates requests carrying a session cookie.
+// The user the session belongs to is injected into the request context, so any downstream
+// handler can obtain it with user.FromContext. Requests without a valid session are served
+// anonymously, stale cookies are removed.
+func WithSessions(sessions Sessions) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(
+			func(w http.ResponseWriter, r *http.Request) {
+				cookie, err := r.Cookie(SessionCookie)
+				if err != nil || cookie.Value == "" {
+					next.ServeHTTP(w, r)
+					return
+				}
+
+				account, err := sessions.GetSessionUser(r.Context(), cookie.Value)
+				if err != nil {
+					if !errors.Is(err, pgx.ErrNoRows) {
+						logging.
+							FromContext(r.Context()).
+							Error(err, "api.session", "error resolving session")
+					}
+
+					EndSession(w)
+					next.ServeHTTP(w, r)
+					return
+				}
+
+				ctx := user.NewContext(r.Context(), &account)
+				next.ServeHTTP(w, r.WithContext(ctx))
+			},
+		)
+	}
+}
+
+// StartSession sets the session cookie for a newly created session.
+func StartSession(w http.ResponseWriter, token string, expiration time.Time) {
+	http.SetCookie(
+		w,
+		&http.Cookie{
+			Name:     SessionCookie,
+			Value:    token,
+			Path:     "/",
+			Expires:  expiration,
+			HttpOnly: true,
+			SameSite: http.SameSiteLaxMode,
+		},
+	)
+}
+
+// EndSession removes the session cookie.
+func EndSession(w http.ResponseWriter) {
+	http.SetCookie(
+		w,
+		&http.Cookie{
+			Name:     SessionCookie,
+			Path:     "/",
+			MaxAge:   -1,
+			HttpOnly: true,
+			SameSite: http.SameSiteLaxMode,
+		},
+	)
+}
@@ -0,0 +1,72 @@
+package api_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aexvir/skladka/internal/api"
+	"github.com/aexvir/skladka/internal/user"
+)
+
+type fakesessions map[string]user.User
+
+func (s fakesessions) GetSessionUser(_ context.Context, token string) (user.User, error) {
+	account, ok := s[token]
+	if !ok {
+		return account, pgx.ErrNoRows
+	}
+	return account, nil
+}
+
+func TestWithSessions(t *testing.T) {
+	middleware := api.WithSessions(fakesessions{"valid": {ID: 1, Username: "aexvir"}})
+
+	tests := map[string]struct {
+		cookie   string
+		username string
+		cleared  bool
+	}{
+		"anonymous":     {},
+		"valid session": {cookie: "valid", username: "aexvir"},
+		"stale session": {cookie: "stale", cleared: true},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			var account *user.User
+			handler := middleware(
+				http.HandlerFunc(
+					func(w http.ResponseWriter, r *http.Request) {
+						account = user.FromContext(r.Context())
+					},
+				),
+			)
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if test.cookie != "" {
+				req.AddCookie(&http.Cookie{Name: api.SessionCookie, Value: test.cookie})
+			}
+
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			if test.username == "" {
+				require.Nil(t, account)
+			} else {
+				require.NotNil(t, account)
+				require.Equal(t, test.username, account.Username)
+			}
+
+			cleared := false
+			for _, cookie := range rec.Result().Cookies() {
+				cleared = cleared || (cookie.Name == api.SessionCookie && cookie.MaxAge < 0)
+			}
+			require.Equal(t, test.cleared, cleared)
+		})
+	}
+}
@@ -0,0 +1,72 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/aexvir/skladka/internal/errors"
+	"github.com/aexvir/skladka/internal/user"
+)
+
+// Tokens resolves personal access tokens to the users they belong to.
+type Tokens interface {
+	// GetTokenUser returns the user the token belongs to and the scopes granted to it.
+	GetTokenUser(context.Context, string) (user.User, []user.Scope, error)
+}
+
+// WithTokens returns a middleware that authenticates requests carrying a personal access
+// token in the Authorization header as a bearer token. The user the token belongs to is
+// injected into the request context together with the scopes granted to the token.
+// Requests without the header are left untouched, while invalid tokens are rejected, as
+// clients sending one expect to act on behalf of its user.
+func WithTokens(tokens Tokens) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(
+			func(w http.ResponseWriter, r *http.Request) {
+				header := r.Header.Get("Authorization")
+				if header == "" {
+					next.ServeHTTP(w, r)
+					return
+				}
+
+				secret, ok := strings.CutPrefix(header, "Bearer ")
+				if !ok || secret == "" {
+					fail(w, r, errors.NewHTTPError(http.StatusUnauthorized, "authorization must be a bearer token", nil))
+					return
+				}
+
+				account, scopes, err := tokens.GetTokenUser(r.Context(), secret)
+				if err != nil {
+					if errors.Is(err, pgx.ErrNoRows) {
+						err = errors.NewHTTPError(http.StatusUnauthorized, "invalid api token", nil)
+					}
+					fail(w, r, errors.Wrap(err, "failed to resolve api token"))
+					return
+				}
+
+				ctx := user.NewScopedContext(r.Context(), &account, scopes)
+				next.ServeHTTP(w, r.WithContext(ctx))
+			},
+		)
+	}
+}
+
+// RequireScope returns a middleware that rejects requests authenticated with a
+// personal access token that wasn't granted the scope.
+func RequireScope(scope user.Scope) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(
+			func(w http.ResponseWriter, r *http.Request) {
+				if !user.Permits(r.Context(), scope) {
+					fail(w, r, errors.NewHTTPError(http.StatusForbidden, "token is missing the "+string(scope)+" scope", nil))
+					return
+				}
+
+				next.ServeHTTP(w, r)
+			},
+		)
+	}
+}
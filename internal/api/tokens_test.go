@@ -0,0 +1,73 @@
+package api_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aexvir/skladka/internal/api"
+	"github.com/aexvir/skladka/internal/user"
+)
+
+type faketokens map[string][]user.Scope
+
+func (t faketokens) GetTokenUser(_ context.Context, secret string) (user.User, []user.Scope, error) {
+	scopes, ok := t[secret]
+	if !ok {
+		return user.User{}, nil, pgx.ErrNoRows
+	}
+	return user.User{ID: 1, Username: "ci"}, scopes, nil
+}
+
+func TestWithTokens(t *testing.T) {
+	tokens := faketokens{
+		"reader": {user.ScopeRead},
+		"writer": {user.ScopeRead, user.ScopeWrite},
+	}
+
+	handler := api.WithTokens(tokens)(
+		api.RequireScope(user.ScopeWrite)(
+			http.HandlerFunc(
+				func(w http.ResponseWriter, r *http.Request) {
+					if account := user.FromContext(r.Context()); account != nil {
+						w.Write([]byte(account.Username))
+					}
+				},
+			),
+		),
+	)
+
+	tests := map[string]struct {
+		header string
+		status int
+		body   string
+	}{
+		"anonymous":     {status: http.StatusOK},
+		"granted scope": {header: "Bearer writer", status: http.StatusOK, body: "ci"},
+		"missing scope": {header: "Bearer reader", status: http.StatusForbidden},
+		"unknown token": {header: "Bearer unknown", status: http.StatusUnauthorized},
+		"not bearer":    {header: "Basic writer", status: http.StatusUnauthorized},
+		"empty bearer":  {header: "Bearer ", status: http.StatusUnauthorized},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/", nil)
+			if test.header != "" {
+				req.Header.Set("Authorization", test.header)
+			}
+
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			require.Equal(t, test.status, rec.Code)
+			if test.status == http.StatusOK {
+				require.Equal(t, test.body, rec.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,126 @@
+package api
+
+import (
+	"encoding/json"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"path"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/aexvir/skladka/internal/errors"
+	"github.com/aexvir/skladka/internal/paste"
+)
+
+// uploadMemory is how much of a multipart form is kept in memory while parsing it,
+// the rest of the uploaded files is buffered in temporary files.
+const uploadMemory = 1 << 20
+
+// ParseUploads parses the form of the request, splitting the files uploaded in its
+// files field into the files of a paste, their syntax inferred from their names, and
+// its attachments. Images and anything that isn't utf-8 encoded text are attached,
+// with the content type sniffed from their content.
+// Forms that aren't multipart are parsed as usual and have no uploads.
+// Bodies over the upload limit are reported as http413.
+func ParseUploads(r *http.Request) ([]paste.File, []paste.Attachment, error) {
+	err := r.ParseMultipartForm(uploadMemory)
+	if errors.Is(err, http.ErrNotMultipart) {
+		return nil, nil, nil
+	}
+	if err != nil {
+		if toolarge(err) {
+			return nil, nil, errors.NewHTTPError(http.StatusRequestEntityTooLarge, "upload too large", err)
+		}
+		return nil, nil, errors.NewHTTPError(http.StatusBadRequest, "invalid form", err)
+	}
+
+	var (
+		files       []paste.File
+		attachments []paste.Attachment
+	)
+	for _, header := range r.MultipartForm.File["files"] {
+		content, err := upload(header)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		name := path.Base(header.Filename)
+
+		attachment := paste.Attachment{Name: name, ContentType: sniff(content), Size: int64(len(content)), Data: content}
+		if attachment.Image() || !utf8.Valid(content) {
+			attachments = append(attachments, attachment)
+			continue
+		}
+
+		files = append(files, paste.File{Name: name, Syntax: paste.SyntaxOf(name), Content: string(content)})
+	}
+
+	return files, attachments, nil
+}
+
+// decode reads the paste sent in the body of the request, either json encoded or as a
+// multipart form, whose paste field holds the json encoded paste and whose uploaded
+// files become the files and attachments of the paste. Attachments can only be uploaded.
+func decode(r *http.Request) (paste.Paste, error) {
+	var p paste.Paste
+
+	if media, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); media != "multipart/form-data" {
+		if err := json.NewDecoder(r.Body).Decode(&p); err != nil {
+			return p, errors.NewHTTPError(http.StatusBadRequest, "invalid request body", err)
+		}
+		p.Attachments = nil
+		return p, nil
+	}
+
+	uploads, attachments, err := ParseUploads(r)
+	if err != nil {
+		return p, err
+	}
+
+	if metadata := r.FormValue("paste"); metadata != "" {
+		if err := json.Unmarshal([]byte(metadata), &p); err != nil {
+			return p, errors.NewHTTPError(http.StatusBadRequest, "invalid paste field", err)
+		}
+	}
+
+	if len(uploads) > 0 {
+		p.Files = uploads
+	}
+	p.Attachments = attachments
+
+	return p, nil
+}
+
+// upload reads the content of an uploaded file.
+func upload(header *multipart.FileHeader) ([]byte, error) {
+	file, err := header.Open()
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to open upload %q", header.Filename)
+	}
+	defer file.Close()
+
+	content, err := io.ReadAll(file)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read upload %q", header.Filename)
+	}
+
+	return content, nil
+}
+
+// sniff returns the content type of an uploaded file. Binary files that happen to look
+// like text, or html, are served as a generic stream rather than trusted.
+func sniff(content []byte) string {
+	media, _, _ := mime.ParseMediaType(http.DetectContentType(content))
+	if media == "" || strings.HasPrefix(media, "text/") {
+		return "application/octet-stream"
+	}
+	return media
+}
+
+// toolarge reports whether the error comes from reading past the upload limit.
+func toolarge(err error) bool {
+	var maxerr *http.MaxBytesError
+	return errors.As(err, &maxerr)
+}
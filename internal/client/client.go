@@ -0,0 +1,203 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aexvir/skladka/internal/errors"
+	"github.com/aexvir/skladka/internal/paste"
+)
+
+// ErrPasswordRequired is returned when fetching a password protected paste without a password.
+var ErrPasswordRequired = errors.NewHTTPError(http.StatusUnauthorized, "paste is password protected", nil)
+
+// Client talks to a skladka server over http, through its json api and raw endpoint.
+type Client struct {
+	server *url.URL
+	token  string
+	http   *http.Client
+}
+
+// New returns a client for the server at the given url.
+// The token is an optional personal access token, sent as a bearer token on every request.
+func New(server, token string) (*Client, error) {
+	parsed, err := url.Parse(strings.TrimSuffix(server, "/"))
+	if err != nil || parsed.Scheme == "" || parsed.Host == "" {
+		return nil, errors.Errorf("invalid server url %q", server)
+	}
+
+	return &Client{
+		server: parsed,
+		token:  token,
+		http:   &http.Client{Timeout: 30 * time.Second},
+	}, nil
+}
+
+// Created is a paste as returned on creation, along with the token required to
+// edit or delete it.
+type Created struct {
+	paste.Paste
+	Token string `json:"token"`
+}
+
+// URL returns the url the paste with the given reference is served at.
+func (c *Client) URL(ref string) string {
+	return c.server.JoinPath(ref).String()
+}
+
+// CreatePaste creates the paste, returning it along with its owner token.
+func (c *Client) CreatePaste(ctx context.Context, p paste.Paste) (Created, error) {
+	body, err := json.Marshal(p)
+	if err != nil {
+		return Created{}, errors.Wrap(err, "failed to encode paste")
+	}
+
+	res, err := c.do(ctx, http.MethodPost, c.api("pastes"), bytes.NewReader(body), nil)
+	if err != nil {
+		return Created{}, errors.Wrap(err, "failed to create paste")
+	}
+	defer res.Body.Close()
+
+	var created Created
+	if err := json.NewDecoder(res.Body).Decode(&created); err != nil {
+		return Created{}, errors.Wrap(err, "failed to decode paste")
+	}
+
+	return created, nil
+}
+
+// Raw fetches the content of the paste as served by the raw endpoint, unlocking
+// password protected pastes with the password. The content of end-to-end encrypted
+// pastes is returned as the ciphertext the server holds, flagged by encrypted, which
+// Open decrypts with the key from the paste url.
+func (c *Client) Raw(ctx context.Context, ref, password string) (content string, encrypted bool, err error) {
+	header := http.Header{}
+	if password != "" {
+		header.Set("x-skd-password", password)
+	}
+
+	res, err := c.do(ctx, http.MethodGet, c.server.JoinPath(ref, "raw"), nil, header)
+	if err != nil {
+		return "", false, errors.Wrapf(err, "failed to fetch paste %s", ref)
+	}
+	defer res.Body.Close()
+
+	// protected pastes fetched without a password get the html prompt instead
+	if media, _, _ := mime.ParseMediaType(res.Header.Get("Content-Type")); media != "text/plain" {
+		return "", false, ErrPasswordRequired
+	}
+
+	raw, err := io.ReadAll(res.Body)
+	if err != nil {
+		return "", false, errors.Wrapf(err, "failed to read paste %s", ref)
+	}
+
+	return string(raw), res.Header.Get("X-Skd-Client-Encrypted") == "true", nil
+}
+
+// ListPastes returns a page of at most limit public pastes matching the filter, newest
+// first. The cursor of the following page is in the returned page, empty on the last one.
+func (c *Client) ListPastes(ctx context.Context, filter paste.Filter, cursor string, limit int) (paste.Page, error) {
+	endpoint := c.api("pastes")
+
+	query := filter.Values()
+	if cursor != "" {
+		query.Set("cursor", cursor)
+	}
+	if limit > 0 {
+		query.Set("limit", strconv.Itoa(limit))
+	}
+	endpoint.RawQuery = query.Encode()
+
+	res, err := c.do(ctx, http.MethodGet, endpoint, nil, nil)
+	if err != nil {
+		return paste.Page{}, errors.Wrap(err, "failed to list pastes")
+	}
+	defer res.Body.Close()
+
+	page := paste.Page{Next: res.Header.Get("x-skd-next-cursor")}
+	if err := json.NewDecoder(res.Body).Decode(&page.Pastes); err != nil {
+		return paste.Page{}, errors.Wrap(err, "failed to decode pastes")
+	}
+
+	return page, nil
+}
+
+// DeletePaste deletes the paste, authorized by the token returned on its creation.
+func (c *Client) DeletePaste(ctx context.Context, ref, token string) error {
+	header := http.Header{}
+	header.Set("x-skd-token", token)
+
+	res, err := c.do(ctx, http.MethodDelete, c.api("pastes", ref), nil, header)
+	if err != nil {
+		return errors.Wrapf(err, "failed to delete paste %s", ref)
+	}
+	res.Body.Close()
+
+	return nil
+}
+
+// api returns the url of the json api endpoint.
+func (c *Client) api(path ...string) *url.URL {
+	return c.server.JoinPath(append([]string{"api", "v1"}, path...)...)
+}
+
+// do sends the request, turning error responses into errors.HTTPError values.
+// The body of successful responses has to be closed by the caller.
+func (c *Client) do(ctx context.Context, method string, endpoint *url.URL, body io.Reader, header http.Header) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, endpoint.String(), body)
+	if err != nil {
+		return nil, err
+	}
+
+	for key, values := range header {
+		req.Header[key] = values
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+
+	res, err := c.http.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if res.StatusCode < http.StatusBadRequest {
+		return res, nil
+	}
+	defer res.Body.Close()
+
+	return nil, failure(res)
+}
+
+// failure reads the error sent by the server. The api sends json encoded errors while
+// the raw endpoint sends plain text, anything unexpected falls back to the status.
+func failure(res *http.Response) error {
+	raw, _ := io.ReadAll(io.LimitReader(res.Body, 64*1024))
+
+	var herr struct {
+		Message string `json:"message"`
+	}
+	if json.Unmarshal(raw, &herr) == nil && herr.Message != "" {
+		return errors.NewHTTPError(res.StatusCode, herr.Message, nil)
+	}
+
+	message := strings.TrimSpace(string(raw))
+	if message == "" {
+		message = fmt.Sprintf("unexpected status %s", res.Status)
+	}
+
+	return errors.NewHTTPError(res.StatusCode, message, nil)
+}
@@ -0,0 +1,166 @@
+package client_test
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aexvir/skladka/internal/api"
+	"github.com/aexvir/skladka/internal/client"
+	"github.com/aexvir/skladka/internal/config"
+	"github.com/aexvir/skladka/internal/errors"
+	"github.com/aexvir/skladka/internal/frontend"
+	"github.com/aexvir/skladka/internal/paste"
+	"github.com/aexvir/skladka/internal/storage"
+)
+
+// newClient returns a client talking to a server backed by a memory storage,
+// routed the same way the skladka binary routes it.
+func newClient(t *testing.T) *client.Client {
+	store := storage.NewMemoryStorage(
+		config.Config{
+			Core: config.Core{
+				EncryptionKey:   "supersecretkey==",
+				EncryptionSalt:  "6370b25f61f2025a0d4fcbb4aaf8859f",
+				SessionDuration: time.Hour,
+			},
+		},
+	)
+
+	router := chi.NewRouter()
+	router.With(api.WithTokens(store)).Mount("/api/v1", api.Router(store))
+	router.Mount("/", frontend.DashboardRouter(store))
+
+	server := httptest.NewServer(router)
+	t.Cleanup(server.Close)
+
+	skd, err := client.New(server.URL+"/", "")
+	require.NoError(t, err)
+
+	return skd
+}
+
+func TestClient(t *testing.T) {
+	ctx := context.Background()
+	skd := newClient(t)
+
+	created, err := skd.CreatePaste(ctx, paste.Paste{Title: "hello", Content: "world", Syntax: "go", Tags: []string{"cli"}, Public: true})
+	require.NoError(t, err)
+	require.NotEmpty(t, created.Reference)
+	require.NotEmpty(t, created.Token)
+
+	content, encrypted, err := skd.Raw(ctx, created.Reference, "")
+	require.NoError(t, err)
+	require.False(t, encrypted)
+	require.Equal(t, "world", content)
+
+	page, err := skd.ListPastes(ctx, paste.Filter{Tag: "cli"}, "", 10)
+	require.NoError(t, err)
+	require.Len(t, page.Pastes, 1)
+	require.Equal(t, "hello", page.Pastes[0].Title)
+
+	err = skd.DeletePaste(ctx, created.Reference, "wrong")
+	require.True(t, errors.IsForbidden(err))
+
+	require.NoError(t, skd.DeletePaste(ctx, created.Reference, created.Token))
+
+	_, _, err = skd.Raw(ctx, created.Reference, "")
+	require.Error(t, err)
+}
+
+func TestClientPassword(t *testing.T) {
+	ctx := context.Background()
+	skd := newClient(t)
+
+	password := "hunter2"
+	created, err := skd.CreatePaste(ctx, paste.Paste{Content: "secret", Password: &password})
+	require.NoError(t, err)
+
+	tests := map[string]struct {
+		password string
+		content  string
+		status   int
+	}{
+		"no password":    {password: "", status: http.StatusUnauthorized},
+		"wrong password": {password: "wrong", status: http.StatusForbidden},
+		"password":       {password: password, content: "secret"},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			content, _, err := skd.Raw(ctx, created.Reference, test.password)
+			if test.status != 0 {
+				require.Error(t, err)
+				require.Equal(t, test.status, errors.AsHTTPError(err).Code)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, test.content, content)
+		})
+	}
+}
+
+// seal encrypts the text the way the frontend does, returning the content sent to
+// the server and the key put in the fragment of the paste url.
+func seal(t *testing.T, text string) (content, key string) {
+	raw := make([]byte, 32)
+	_, err := rand.Read(raw)
+	require.NoError(t, err)
+
+	block, err := aes.NewCipher(raw)
+	require.NoError(t, err)
+	gcm, err := cipher.NewGCM(block)
+	require.NoError(t, err)
+
+	nonce := make([]byte, gcm.NonceSize())
+	_, err = rand.Read(nonce)
+	require.NoError(t, err)
+
+	sealed := gcm.Seal(nonce, nonce, []byte(text), nil)
+	return base64.StdEncoding.EncodeToString(sealed), base64.RawURLEncoding.EncodeToString(raw)
+}
+
+func TestClientEncrypted(t *testing.T) {
+	ctx := context.Background()
+	skd := newClient(t)
+
+	sealed, key := seal(t, "sealed secret")
+	created, err := skd.CreatePaste(ctx, paste.Paste{Content: sealed, ClientEncrypted: true})
+	require.NoError(t, err)
+
+	content, encrypted, err := skd.Raw(ctx, created.Reference, "")
+	require.NoError(t, err)
+	require.True(t, encrypted)
+	require.Equal(t, sealed, content)
+
+	plaintext, err := client.Open(content, key)
+	require.NoError(t, err)
+	require.Equal(t, "sealed secret", plaintext)
+
+	_, err = client.Open(content, "")
+	require.ErrorIs(t, err, client.ErrKeyRequired)
+
+	_, wrong := seal(t, "other")
+	_, err = client.Open(content, wrong)
+	require.Error(t, err)
+}
+
+func TestNew(t *testing.T) {
+	for _, server := range []string{"", "localhost:3000", "://nope"} {
+		_, err := client.New(server, "")
+		require.Error(t, err, server)
+	}
+
+	skd, err := client.New("https://skladka.example.com/", "")
+	require.NoError(t, err)
+	require.Equal(t, "https://skladka.example.com/abcd1234", skd.URL("abcd1234"))
+}
@@ -0,0 +1,24 @@
+// Package client implements an http client for a skladka server, backing the paste,
+// get, list and delete commands of the skladka binary.
+//
+// Pastes are created, listed and deleted through the json api mounted at /api/v1,
+// while their content is read from the raw endpoint of the frontend, the same one
+// curl users hit. Password protected pastes are unlocked with the password sent in
+// the x-skd-password header. End-to-end encrypted pastes are decrypted locally by
+// Open, with the key kept in the fragment of their url. Errors sent by the server
+// are returned as errors.HTTPError values carrying its status code and message.
+//
+// # Example Usage
+//
+//	skd, err := client.New("https://skladka.example.com", os.Getenv("SKD_API_TOKEN"))
+//	if err != nil {
+//		return err
+//	}
+//
+//	created, err := skd.CreatePaste(ctx, paste.Paste{Content: "hello", Public: true})
+//	if err != nil {
+//		return err
+//	}
+//
+//	content, _, err := skd.Raw(ctx, created.Reference, "")
+package client
@@ -0,0 +1,55 @@
+package client
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"encoding/base64"
+	"net/http"
+	"strings"
+
+	"github.com/aexvir/skladka/internal/errors"
+)
+
+// ErrKeyRequired is returned when opening an end-to-end encrypted paste without its key.
+var ErrKeyRequired = errors.NewHTTPError(http.StatusUnauthorized, "paste is end-to-end encrypted", nil)
+
+// Open decrypts the content of an end-to-end encrypted paste the same way the frontend
+// does: the content is the base64 encoded aes-gcm nonce followed by the ciphertext,
+// while the key is the base64url encoded fragment of the paste url.
+func Open(content, key string) (string, error) {
+	if key == "" {
+		return "", ErrKeyRequired
+	}
+
+	raw, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(key, "="))
+	if err != nil {
+		return "", errors.Wrap(err, "invalid paste key")
+	}
+
+	sealed, err := base64.StdEncoding.DecodeString(strings.TrimSpace(content))
+	if err != nil {
+		return "", errors.Wrap(err, "invalid encrypted content")
+	}
+
+	block, err := aes.NewCipher(raw)
+	if err != nil {
+		return "", errors.Wrap(err, "invalid paste key")
+	}
+
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return "", errors.Wrap(err, "invalid paste key")
+	}
+
+	if len(sealed) < gcm.NonceSize() {
+		return "", errors.New("invalid encrypted content")
+	}
+
+	nonce, ciphertext := sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():]
+	plaintext, err := gcm.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return "", errors.New("failed to decrypt the paste, the key doesn't match")
+	}
+
+	return string(plaintext), nil
+}
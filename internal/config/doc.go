@@ -4,7 +4,15 @@
 // prefixed with "SKD_" automatically.
 //
 // Configuration can be provided via environment variables or command line flags.
-// Command line flags take precedence over environment variables.
+// Command line flags take precedence over environment variables. Arguments left after
+// the flags are kept in Config.Args, the first one selecting the command to run.
+// Secrets are tagged with mask, so Config.Redacted, used by `skladka config print`,
+// prints the config without revealing them.
+//
+// Attachments of pastes are kept in the blob store of the storage section, a directory
+// by default, e.g. SKD_BLOBS_DRIVER=filesystem and SKD_BLOBS_PATH=/var/lib/skladka/blobs,
+// or an s3 compatible bucket with SKD_BLOBS_DRIVER=s3 and the SKD_BLOBS_ENDPOINT,
+// SKD_BLOBS_BUCKET, SKD_BLOBS_REGION, SKD_BLOBS_ACCESS_KEY and SKD_BLOBS_SECRET_KEY.
 //
 // Example usage:
 //
@@ -19,5 +27,6 @@
 //
 //	// Access configuration values
 //	fmt.Printf("Running in %s environment\n", cfg.Environment)
+//	fmt.Printf("Storage driver: %s\n", cfg.Storage.Driver)
 //	fmt.Printf("Database host: %s:%d\n", cfg.Postgres.Host, cfg.Postgres.Port)
 package config
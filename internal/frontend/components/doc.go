@@ -9,13 +9,18 @@
 //   - Styled: Components use TailwindCSS for consistent styling
 //
 // # Available Components
-//   - Editor: Monaco-based code editor with syntax highlighting
+//   - Attachments: Inline image previews and download links of the attachments of a paste
+//   - Author: Username of the owner of a paste, or anonymous
+//   - Editor: Monaco-based code editor with syntax highlighting, also usable as a diff editor
+//   - History: Revision picker for comparing versions of a paste
 //   - Entry: Individual paste entry display component
+//   - Files: Tabs switching between the files of a paste
 //   - Input: Form input fields with consistent styling
 //   - Nav: Navigation bar component
-//   - Palette: Color scheme selection component
+//   - Palette: Command palette with quick links and full-text search of pastes
 //   - Sidebar: Collapsible sidebar for navigation
 //   - Toggle: Interactive toggle switch component
+//   - Upload: File picker and editor drop zone for creating pastes from files
 //
 // # Example Usage
 //
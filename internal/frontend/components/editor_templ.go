@@ -28,7 +28,7 @@ func Editor(content, syntax string, readonly bool) templ.Component {
 			templ_7745c5c3_Var1 = templ.NopComponent
 		}
 		ctx = templ.ClearChildren(ctx)
-		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 1, "<div class=\"h-full w-full flex-1\"><div id=\"container\" class=\"w-full h-full\"></div></div><script src=\"/static/monaco/loader.js\"></script><script type=\"module\">\n        import { getHighlighter } from 'https://esm.sh/shiki'\n        import { shikiToMonaco } from 'https://esm.sh/@shikijs/monaco'\n\n        const ayuLightTheme = {\n            name: \"ayu-light\",\n            type: \"light\",\n            colors: {\n                \"focusBorder\": \"#ffaa33b3\",\n                \"foreground\": \"#8a9199\",\n                \"widget.shadow\": \"#00000026\",\n                \"selection.background\": \"#035bd626\",\n                \"icon.foreground\": \"#8a9199\",\n                \"errorForeground\": \"#e65050\",\n                \"descriptionForeground\": \"#8a9199\",\n                \"textBlockQuote.background\": \"#f3f4f5\",\n                \"textLink.foreground\": \"#ffaa33\",\n                \"textLink.activeForeground\": \"#ffaa33\",\n                \"textPreformat.foreground\": \"#5c6166\",\n                \"button.background\": \"#ffaa33\",\n                \"button.foreground\": \"#f8f9fa\",\n                \"button.hoverBackground\": \"#f9a52e\",\n                \"button.secondaryBackground\": \"#8a919933\",\n                \"button.secondaryForeground\": \"#5c6166\",\n                \"button.secondaryHoverBackground\": \"#8a919980\",\n                \"dropdown.background\": \"#fcfcfc\",\n                \"dropdown.foreground\": \"#8a9199\",\n                \"dropdown.border\": \"#8a919945\",\n                \"input.background\": \"#fcfcfc\",\n                \"input.border\": \"#8a919945\",\n                \"input.foreground\": \"#5c6166\",\n                \"input.placeholderForeground\": \"#8a919980\",\n                \"inputOption.activeBorder\": \"#f4a0284d\",\n                \"inputOption.activeBackground\": \"#ffaa3333\",\n                \"inputOption.activeForeground\": \"#f4a028\",\n                \"inputValidation.errorBackground\": \"#fcfcfc\",\n                \"inputValidation.errorBorder\": \"#e65050\",\n                \"inputValidation.infoBackground\": \"#f8f9fa\",\n                \"inputValidation.infoBorder\": \"#55b4d4\",\n                \"inputValidation.warningBackground\": \"#f8f9fa\",\n                \"inputValidation.warningBorder\": \"#f2ae49\",\n                \"scrollbar.shadow\": \"#6b7d8f00\",\n                \"scrollbarSlider.background\": \"#8a919966\",\n                \"scrollbarSlider.hoverBackground\": \"#8a919999\",\n                \"scrollbarSlider.activeBackground\": \"#8a9199b3\",\n                \"badge.background\": \"#ffaa3333\",\n                \"badge.foreground\": \"#f4a028\",\n                \"progressBar.background\": \"#ffaa33\",\n                \"list.activeSelectionBackground\": \"#56728f1f\",\n                \"list.activeSelectionForeground\": \"#5c6166\",\n                \"list.focusBackground\": \"#56728f1f\",\n                \"list.focusForeground\": \"#5c6166\",\n                \"list.focusOutline\": \"#56728f1f\",\n                \"list.highlightForeground\": \"#ffaa33\",\n                \"list.deemphasizedForeground\": \"#e65050\",\n                \"list.hoverBackground\": \"#56728f1f\",\n                \"list.inactiveSelectionBackground\": \"#6b7d8f1f\",\n                \"list.inactiveSelectionForeground\": \"#8a9199\",\n                \"list.invalidItemForeground\": \"#8a91994d\",\n                \"list.errorForeground\": \"#e65050\",\n                \"tree.indentGuidesStroke\": \"#8a919959\",\n                \"listFilterWidget.background\": \"#f3f4f5\",\n                \"listFilterWidget.outline\": \"#ffaa33\",\n                \"listFilterWidget.noMatchesOutline\": \"#e65050\",\n                \"list.filterMatchBackground\": \"#8f30efcc\",\n                \"list.filterMatchBorder\": \"#9f40ffcc\",\n                \"activityBar.background\": \"#f8f9fa\",\n                \"activityBar.foreground\": \"#8a9199cc\",\n                \"activityBar.inactiveForeground\": \"#8a919999\",\n                \"activityBar.border\": \"#f8f9fa\",\n                \"activityBar.activeBorder\": \"#ffaa33b3\",\n                \"activityBarBadge.background\": \"#ffaa33\",\n                \"activityBarBadge.foreground\": \"#f8f9fa\",\n                \"sideBar.background\": \"#f8f9fa\",\n                \"sideBar.border\": \"#f8f9fa\",\n                \"sideBarTitle.foreground\": \"#8a9199\",\n                \"sideBarSectionHeader.background\": \"#f8f9fa\",\n                \"sideBarSectionHeader.foreground\": \"#8a9199\",\n                \"sideBarSectionHeader.border\": \"#f8f9fa\",\n                \"minimap.background\": \"#f8f9fa\",\n                \"minimap.selectionHighlight\": \"#035bd626\",\n                \"minimap.errorHighlight\": \"#e65050\",\n                \"minimap.findMatchHighlight\": \"#9f40ff2b\",\n                \"minimapGutter.addedBackground\": \"#6cbf43\",\n                \"minimapGutter.modifiedBackground\": \"#478acc\",\n                \"minimapGutter.deletedBackground\": \"#ff7383\",\n                \"editorGroup.border\": \"#6b7d8f1f\",\n                \"editorGroup.background\": \"#f3f4f5\",\n                \"editorGroupHeader.noTabsBackground\": \"#f8f9fa\",\n                \"editorGroupHeader.tabsBackground\": \"#f8f9fa\",\n                \"editorGroupHeader.tabsBorder\": \"#f8f9fa\",\n                \"tab.activeBackground\": \"#f8f9fa\",\n                \"tab.activeForeground\": \"#5c6166\",\n                \"tab.border\": \"#f8f9fa\",\n                \"tab.activeBorder\": \"#ffaa33\",\n                \"tab.unfocusedActiveBorder\": \"#8a9199\",\n                \"tab.inactiveBackground\": \"#f8f9fa\",\n                \"tab.inactiveForeground\": \"#8a9199\",\n                \"tab.unfocusedActiveForeground\": \"#8a9199\",\n                \"tab.unfocusedInactiveForeground\": \"#8a9199\",\n                \"editor.background\": \"#f8f9fa\",\n                \"editor.foreground\": \"#5c6166\",\n                \"editorLineNumber.foreground\": \"#8a919966\",\n                \"editorLineNumber.activeForeground\": \"#8a9199cc\",\n                \"editorCursor.foreground\": \"#ffaa33\",\n                \"editor.inactiveSelectionBackground\": \"#035bd612\",\n                \"editor.selectionBackground\": \"#035bd626\",\n                \"editor.selectionHighlightBackground\": \"#6cbf4326\",\n                \"editor.selectionHighlightBorder\": \"#6cbf4300\",\n                \"editor.wordHighlightBackground\": \"#478acc14\",\n                \"editor.wordHighlightStrongBackground\": \"#6cbf4314\",\n                \"editor.wordHighlightBorder\": \"#478acc80\",\n                \"editor.wordHighlightStrongBorder\": \"#6cbf4380\",\n                \"editor.findMatchBackground\": \"#9f40ff2b\",\n                \"editor.findMatchBorder\": \"#9f40ff2b\",\n                \"editor.findMatchHighlightBackground\": \"#9f40ffcc\",\n                \"editor.findMatchHighlightBorder\": \"#8f30efcc\",\n                \"editor.findRangeHighlightBackground\": \"#9f40ff40\",\n                \"editor.rangeHighlightBackground\": \"#9f40ff33\",\n                \"editor.lineHighlightBackground\": \"#8a91991a\",\n                \"editorLink.activeForeground\": \"#ffaa33\",\n                \"editorWhitespace.foreground\": \"#8a919966\",\n                \"editorIndentGuide.background\": \"#8a91992e\",\n                \"editorIndentGuide.activeBackground\": \"#8a919959\",\n                \"editorRuler.foreground\": \"#8a91992e\",\n                \"editorCodeLens.foreground\": \"#787b8099\",\n                \"editorBracketMatch.background\": \"#8a91994d\",\n                \"editorBracketMatch.border\": \"#8a91994d\",\n                \"editor.snippetTabstopHighlightBackground\": \"#6cbf4333\",\n                \"editorOverviewRuler.border\": \"#6b7d8f1f\",\n                \"editorOverviewRuler.modifiedForeground\": \"#478acc\",\n                \"editorOverviewRuler.addedForeground\": \"#6cbf43\",\n                \"editorOverviewRuler.deletedForeground\": \"#ff7383\",\n                \"editorOverviewRuler.errorForeground\": \"#e65050\",\n                \"editorOverviewRuler.warningForeground\": \"#ffaa33\",\n                \"editorOverviewRuler.bracketMatchForeground\": \"#8a9199b3\",\n                \"editorOverviewRuler.wordHighlightForeground\": \"#478acc66\",\n                \"editorOverviewRuler.wordHighlightStrongForeground\": \"#6cbf4366\",\n                \"editorOverviewRuler.findMatchForeground\": \"#9f40ff2b\",\n                \"editorError.foreground\": \"#e65050\",\n                \"editorWarning.foreground\": \"#ffaa33\",\n                \"editorGutter.modifiedBackground\": \"#478acccc\",\n                \"editorGutter.addedBackground\": \"#6cbf43cc\",\n                \"editorGutter.deletedBackground\": \"#ff7383cc\",\n                \"diffEditor.insertedTextBackground\": \"#6cbf431f\",\n                \"diffEditor.removedTextBackground\": \"#ff73831f\",\n                \"diffEditor.diagonalFill\": \"#6b7d8f1f\",\n                \"editorWidget.background\": \"#f3f4f5\",\n                \"editorWidget.border\": \"#6b7d8f1f\",\n                \"editorHoverWidget.background\": \"#f3f4f5\",\n                \"editorHoverWidget.border\": \"#6b7d8f1f\",\n                \"editorSuggestWidget.background\": \"#f3f4f5\",\n                \"editorSuggestWidget.border\": \"#6b7d8f1f\",\n                \"editorSuggestWidget.highlightForeground\": \"#ffaa33\",\n                \"editorSuggestWidget.selectedBackground\": \"#56728f1f\",\n                \"debugExceptionWidget.border\": \"#6b7d8f1f\",\n                \"debugExceptionWidget.background\": \"#f3f4f5\",\n                \"editorMarkerNavigation.background\": \"#f3f4f5\",\n                \"peekView.border\": \"#56728f1f\",\n                \"peekViewTitle.background\": \"#56728f1f\",\n                \"peekViewTitleDescription.foreground\": \"#8a9199\",\n                \"peekViewTitleLabel.foreground\": \"#5c6166\",\n                \"peekViewEditor.background\": \"#f3f4f5\",\n                \"peekViewEditor.matchHighlightBackground\": \"#9f40ffcc\",\n                \"peekViewEditor.matchHighlightBorder\": \"#8f30efcc\",\n                \"peekViewResult.background\": \"#f3f4f5\",\n                \"peekViewResult.fileForeground\": \"#5c6166\",\n                \"peekViewResult.lineForeground\": \"#8a9199\",\n                \"peekViewResult.matchHighlightBackground\": \"#9f40ffcc\",\n                \"peekViewResult.selectionBackground\": \"#56728f1f\",\n                \"panel.background\": \"#f8f9fa\",\n                \"panel.border\": \"#6b7d8f1f\",\n                \"panelTitle.activeBorder\": \"#ffaa33\",\n                \"panelTitle.activeForeground\": \"#5c6166\",\n                \"panelTitle.inactiveForeground\": \"#8a9199\",\n                \"statusBar.background\": \"#f8f9fa\",\n                \"statusBar.foreground\": \"#8a9199\",\n                \"statusBar.border\": \"#f8f9fa\",\n                \"statusBar.debuggingBackground\": \"#ed9366\",\n                \"statusBar.debuggingForeground\": \"#fcfcfc\",\n                \"statusBar.noFolderBackground\": \"#f3f4f5\",\n                \"statusBarItem.activeBackground\": \"#8a919933\",\n                \"statusBarItem.hoverBackground\": \"#8a919933\",\n                \"statusBarItem.prominentBackground\": \"#6b7d8f1f\",\n                \"statusBarItem.prominentHoverBackground\": \"#00000030\",\n                \"statusBarItem.remoteBackground\": \"#ffaa33\",\n                \"statusBarItem.remoteForeground\": \"#fcfcfc\",\n                \"titleBar.activeBackground\": \"#f8f9fa\",\n                \"titleBar.activeForeground\": \"#5c6166\",\n                \"titleBar.inactiveBackground\": \"#f8f9fa\",\n                \"titleBar.inactiveForeground\": \"#8a9199\",\n                \"titleBar.border\": \"#f8f9fa\",\n                \"extensionButton.prominentForeground\": \"#fcfcfc\",\n                \"extensionButton.prominentBackground\": \"#ffaa33\",\n                \"extensionButton.prominentHoverBackground\": \"#f9a52e\",\n                \"pickerGroup.border\": \"#6b7d8f1f\",\n                \"pickerGroup.foreground\": \"#8a919980\",\n                \"debugToolBar.background\": \"#f3f4f5\",\n                \"debugIcon.breakpointForeground\": \"#ed9366\",\n                \"debugIcon.breakpointDisabledForeground\": \"#ed936680\",\n                \"debugConsoleInputIcon.foreground\": \"#ffaa33\",\n                \"welcomePage.tileBackground\": \"#f8f9fa\",\n                \"welcomePage.tileShadow\": \"#00000026\",\n                \"welcomePage.progress.background\": \"#8a91991a\",\n                \"welcomePage.buttonBackground\": \"#ffaa3366\",\n                \"walkThrough.embeddedEditorBackground\": \"#f3f4f5\",\n                \"gitDecoration.modifiedResourceForeground\": \"#478accb3\",\n                \"gitDecoration.deletedResourceForeground\": \"#ff7383b3\",\n                \"gitDecoration.untrackedResourceForeground\": \"#6cbf43b3\",\n                \"gitDecoration.ignoredResourceForeground\": \"#8a919980\",\n                \"gitDecoration.conflictingResourceForeground\": \"\",\n                \"gitDecoration.submoduleResourceForeground\": \"#a37accb3\",\n                \"settings.headerForeground\": \"#5c6166\",\n                \"settings.modifiedItemIndicator\": \"#478acc\",\n                \"keybindingLabel.background\": \"#8a91991a\",\n                \"keybindingLabel.foreground\": \"#5c6166\",\n                \"keybindingLabel.border\": \"#5c61661a\",\n                \"keybindingLabel.bottomBorder\": \"#5c61661a\",\n                \"terminal.background\": \"#f8f9fa\",\n                \"terminal.foreground\": \"#5c6166\",\n                \"terminal.ansiBlack\": \"#000000\",\n                \"terminal.ansiRed\": \"#ea6c6d\",\n                \"terminal.ansiGreen\": \"#6cbf43\",\n                \"terminal.ansiYellow\": \"#eca944\",\n                \"terminal.ansiBlue\": \"#3199e1\",\n                \"terminal.ansiMagenta\": \"#9e75c7\",\n                \"terminal.ansiCyan\": \"#46ba94\",\n                \"terminal.ansiWhite\": \"#c7c7c7\",\n                \"terminal.ansiBrightBlack\": \"#686868\",\n                \"terminal.ansiBrightRed\": \"#f07171\",\n                \"terminal.ansiBrightGreen\": \"#86b300\",\n                \"terminal.ansiBrightYellow\": \"#f2ae49\",\n                \"terminal.ansiBrightBlue\": \"#399ee6\",\n                \"terminal.ansiBrightMagenta\": \"#a37acc\",\n                \"terminal.ansiBrightCyan\": \"#4cbf99\",\n                \"terminal.ansiBrightWhite\": \"#d1d1d1\"\n            },\n            tokenColors: [\n                {\n                    \"settings\": {\n                        \"background\": \"#f8f9fa\",\n                        \"foreground\": \"#5c6166\"\n                    }\n                },\n                {\n                    \"name\": \"Comment\",\n                    \"scope\": [\n                        \"comment\"\n                    ],\n                    \"settings\": {\n                        \"fontStyle\": \"italic\",\n                        \"foreground\": \"#787b8099\"\n                    }\n                },\n                {\n                    \"name\": \"String\",\n                    \"scope\": [\n                        \"string\",\n                        \"constant.other.symbol\"\n                    ],\n                    \"settings\": {\n                        \"foreground\": \"#86b300\"\n                    }\n                },\n                {\n                    \"name\": \"Regular Expressions and Escape Characters\",\n                    \"scope\": [\n                        \"string.regexp\",\n                        \"constant.character\",\n                        \"constant.other\"\n                    ],\n                    \"settings\": {\n                        \"foreground\": \"#4cbf99\"\n                    }\n                },\n                {\n                    \"name\": \"Number\",\n                    \"scope\": [\n                        \"constant.numeric\"\n                    ],\n                    \"settings\": {\n                        \"foreground\": \"#a37acc\"\n                    }\n                },\n                {\n                    \"name\": \"Built-in constants\",\n                    \"scope\": [\n                        \"constant.language\"\n                    ],\n                    \"settings\": {\n                        \"foreground\": \"#a37acc\"\n                    }\n                },\n                {\n                    \"name\": \"Variable\",\n                    \"scope\": [\n                        \"variable\",\n                        \"variable.parameter.function-call\"\n                    ],\n                    \"settings\": {\n                        \"foreground\": \"#5c6166\"\n                    }\n                },\n                {\n                    \"name\": \"Member Variable\",\n                    \"scope\": [\n                        \"variable.member\"\n                    ],\n                    \"settings\": {\n                        \"foreground\": \"#f07171\"\n                    }\n                },\n                {\n                    \"name\": \"Language variable\",\n                    \"scope\": [\n                        \"variable.language\"\n                    ],\n                    \"settings\": {\n                        \"fontStyle\": \"italic\",\n                        \"foreground\": \"#55b4d4\"\n                    }\n                },\n                {\n                    \"name\": \"Storage\",\n                    \"scope\": [\n                        \"storage\"\n                    ],\n                    \"settings\": {\n                        \"foreground\": \"#fa8d3e\"\n                    }\n                },\n                {\n                    \"name\": \"Keyword\",\n                    \"scope\": [\n                        \"keyword\"\n                    ],\n                    \"settings\": {\n                        \"foreground\": \"#fa8d3e\"\n                    }\n                },\n                {\n                    \"name\": \"Operators\",\n                    \"scope\": [\n                        \"keyword.operator\"\n                    ],\n                    \"settings\": {\n                        \"foreground\": \"#ed9366\"\n                    }\n                },\n                {\n                    \"name\": \"Separators like  or ,\",\n                    \"scope\": [\n                        \"punctuation.separator\",\n                        \"punctuation.terminator\"\n                    ],\n                    \"settings\": {\n                        \"foreground\": \"#5c6166b3\"\n                    }\n                },\n                {\n                    \"name\": \"Punctuation\",\n                    \"scope\": [\n                        \"punctuation.section\"\n                    ],\n                    \"settings\": {\n                        \"foreground\": \"#5c6166\"\n                    }\n                },\n                {\n                    \"name\": \"Accessor\",\n                    \"scope\": [\n                        \"punctuation.accessor\"\n                    ],\n                    \"settings\": {\n                        \"foreground\": \"#ed9366\"\n                    }\n                },\n                {\n                    \"name\": \"JavaScript/TypeScript interpolation punctuation\",\n                    \"scope\": [\n                        \"punctuation.definition.template-expression\"\n                    ],\n                    \"settings\": {\n                        \"foreground\": \"#fa8d3e\"\n                    }\n                },\n                {\n                    \"name\": \"Ruby interpolation punctuation\",\n                    \"scope\": [\n                        \"punctuation.section.embedded\"\n                    ],\n                    \"settings\": {\n                        \"foreground\": \"#fa8d3e\"\n                    }\n                },\n                {\n                    \"name\": \"Interpolation text\",\n                    \"scope\": [\n                        \"meta.embedded\"\n                    ],\n                    \"settings\": {\n                        \"foreground\": \"#5c6166\"\n                    }\n                },\n                {\n                    \"name\": \"Types fixes\",\n                    \"scope\": [\n                        \"source.java storage.type\",\n                        \"source.haskell storage.type\",\n                        \"source.c storage.type\"\n                    ],\n                    \"settings\": {\n                        \"foreground\": \"#399ee6\"\n                    }\n                },\n                {\n                    \"name\": \"Inherited class type\",\n                    \"scope\": [\n                        \"entity.other.inherited-class\"\n                    ],\n                    \"settings\": {\n                        \"foreground\": \"#55b4d4\"\n                    }\n                },\n                {\n                    \"name\": \"Lambda arrow\",\n                    \"scope\": [\n                        \"storage.type.function\"\n                    ],\n                    \"settings\": {\n                        \"foreground\": \"#fa8d3e\"\n                    }\n                },\n                {\n                    \"name\": \"Java primitive variable types\",\n                    \"scope\": [\n                        \"source.java storage.type.primitive\"\n                    ],\n                    \"settings\": {\n                        \"foreground\": \"#55b4d4\"\n                    }\n                },\n                {\n                    \"name\": \"Function name\",\n                    \"scope\": [\n                        \"entity.name.function\"\n                    ],\n                    \"settings\": {\n                        \"foreground\": \"#f2ae49\"\n                    }\n                },\n                {\n                    \"name\": \"Function arguments\",\n                    \"scope\": [\n                        \"variable.parameter\",\n                        \"meta.parameter\"\n                    ],\n                    \"settings\": {\n                        \"foreground\": \"#a37acc\"\n                    }\n                },\n                {\n                    \"name\": \"Function call\",\n                    \"scope\": [\n                        \"variable.function\",\n                        \"variable.annotation\",\n                        \"meta.function-call.generic\",\n                        \"support.function.go\"\n                    ],\n                    \"settings\": {\n                        \"foreground\": \"#f2ae49\"\n                    }\n                },\n                {\n                    \"name\": \"Library function\",\n                    \"scope\": [\n                        \"support.function\",\n                        \"support.macro\"\n                    ],\n                    \"settings\": {\n                        \"foreground\": \"#f07171\"\n                    }\n                },\n                {\n                    \"name\": \"Imports and packages\",\n                    \"scope\": [\n                        \"entity.name.import\",\n                        \"entity.name.package\"\n                    ],\n                    \"settings\": {\n                        \"foreground\": \"#86b300\"\n                    }\n                },\n                {\n                    \"name\": \"Entity name\",\n                    \"scope\": [\n                        \"entity.name\"\n                    ],\n                    \"settings\": {\n                        \"foreground\": \"#399ee6\"\n                    }\n                },\n                {\n                    \"name\": \"Tag\",\n                    \"scope\": [\n                        \"entity.name.tag\",\n                        \"meta.tag.sgml\"\n                    ],\n                    \"settings\": {\n                        \"foreground\": \"#55b4d4\"\n                    }\n                },\n                {\n                    \"name\": \"JSX Component\",\n                    \"scope\": [\n                        \"support.class.component\"\n                    ],\n                    \"settings\": {\n                        \"foreground\": \"#399ee6\"\n                    }\n                },\n                {\n                    \"name\": \"Tag start/end\",\n                    \"scope\": [\n                        \"punctuation.definition.tag.end\",\n                        \"punctuation.definition.tag.begin\",\n                        \"punctuation.definition.tag\"\n                    ],\n                    \"settings\": {\n                        \"foreground\": \"#55b4d480\"\n                    }\n                },\n                {\n                    \"name\": \"Tag attribute\",\n                    \"scope\": [\n                        \"entity.other.attribute-name\"\n                    ],\n                    \"settings\": {\n                        \"foreground\": \"#f2ae49\"\n                    }\n                },\n                {\n                    \"name\": \"Library constant\",\n                    \"scope\": [\n                        \"support.constant\"\n                    ],\n                    \"settings\": {\n                        \"fontStyle\": \"italic\",\n                        \"foreground\": \"#ed9366\"\n                    }\n                },\n                {\n                    \"name\": \"Library class/type\",\n                    \"scope\": [\n                        \"support.type\",\n                        \"support.class\",\n                        \"source.go storage.type\"\n                    ],\n                    \"settings\": {\n                        \"foreground\": \"#55b4d4\"\n                    }\n                },\n                {\n                    \"name\": \"Decorators/annotation\",\n                    \"scope\": [\n                        \"meta.decorator variable.other\",\n                        \"meta.decorator punctuation.decorator\",\n                        \"storage.type.annotation\"\n                    ],\n                    \"settings\": {\n                        \"foreground\": \"#e6ba7e\"\n                    }\n                },\n                {\n                    \"name\": \"Invalid\",\n                    \"scope\": [\n                        \"invalid\"\n                    ],\n                    \"settings\": {\n                        \"foreground\": \"#e65050\"\n                    }\n                },\n                {\n                    \"name\": \"diff.header\",\n                    \"scope\": [\n                        \"meta.diff\",\n                        \"meta.diff.header\"\n                    ],\n                    \"settings\": {\n                        \"foreground\": \"#c594c5\"\n                    }\n                },\n                {\n                    \"name\": \"Ruby class methods\",\n                    \"scope\": [\n                        \"source.ruby variable.other.readwrite\"\n                    ],\n                    \"settings\": {\n                        \"foreground\": \"#f2ae49\"\n                    }\n                },\n                {\n                    \"name\": \"CSS tag names\",\n                    \"scope\": [\n                        \"source.css entity.name.tag\",\n                        \"source.sass entity.name.tag\",\n                        \"source.scss entity.name.tag\",\n                        \"source.less entity.name.tag\",\n                        \"source.stylus entity.name.tag\"\n                    ],\n                    \"settings\": {\n                        \"foreground\": \"#399ee6\"\n                    }\n                },\n                {\n                    \"name\": \"CSS browser prefix\",\n                    \"scope\": [\n                        \"source.css support.type\",\n                        \"source.sass support.type\",\n                        \"source.scss support.type\",\n                        \"source.less support.type\",\n                        \"source.stylus support.type\"\n                    ],\n                    \"settings\": {\n                        \"foreground\": \"#787b8099\"\n                    }\n                },\n                {\n                    \"name\": \"CSS Properties\",\n                    \"scope\": [\n                        \"support.type.property-name\"\n                    ],\n                    \"settings\": {\n                        \"fontStyle\": \"normal\",\n                        \"foreground\": \"#55b4d4\"\n                    }\n                },\n                {\n                    \"name\": \"Search Results Numbers\",\n                    \"scope\": [\n                        \"constant.numeric.line-number.find-in-files - match\"\n                    ],\n                    \"settings\": {\n                        \"foreground\": \"#787b8099\"\n                    }\n                },\n                {\n                    \"name\": \"Search Results Match Numbers\",\n                    \"scope\": [\n                        \"constant.numeric.line-number.match\"\n                    ],\n                    \"settings\": {\n                        \"foreground\": \"#fa8d3e\"\n                    }\n                },\n                {\n                    \"name\": \"Search Results Lines\",\n                    \"scope\": [\n                        \"entity.name.filename.find-in-files\"\n                    ],\n                    \"settings\": {\n                        \"foreground\": \"#86b300\"\n                    }\n                },\n                {\n                    \"scope\": [\n                        \"message.error\"\n                    ],\n                    \"settings\": {\n                        \"foreground\": \"#e65050\"\n                    }\n                },\n                {\n                    \"name\": \"Markup heading\",\n                    \"scope\": [\n                        \"markup.heading\",\n                        \"markup.heading entity.name\"\n                    ],\n                    \"settings\": {\n                        \"fontStyle\": \"bold\",\n                        \"foreground\": \"#86b300\"\n                    }\n                },\n                {\n                    \"name\": \"Markup links\",\n                    \"scope\": [\n                        \"markup.underline.link\",\n                        \"string.other.link\"\n                    ],\n                    \"settings\": {\n                        \"foreground\": \"#55b4d4\"\n                    }\n                },\n                {\n                    \"name\": \"Markup Italic\",\n                    \"scope\": [\n                        \"markup.italic\"\n                    ],\n                    \"settings\": {\n                        \"fontStyle\": \"italic\",\n                        \"foreground\": \"#f07171\"\n                    }\n                },\n                {\n                    \"name\": \"Markup Bold\",\n                    \"scope\": [\n                        \"markup.bold\"\n                    ],\n                    \"settings\": {\n                        \"fontStyle\": \"bold\",\n                        \"foreground\": \"#f07171\"\n                    }\n                },\n                {\n                    \"name\": \"Markup Bold/italic\",\n                    \"scope\": [\n                        \"markup.italic markup.bold\",\n                        \"markup.bold markup.italic\"\n                    ],\n                    \"settings\": {\n                        \"fontStyle\": \"bold italic\"\n                    }\n                },\n                {\n                    \"name\": \"Markup Code\",\n                    \"scope\": [\n                        \"markup.raw\"\n                    ],\n                    \"settings\": {\n                        \"background\": \"#5c616605\"\n                    }\n                },\n                {\n                    \"name\": \"Markup Code Inline\",\n                    \"scope\": [\n                        \"markup.raw.inline\"\n                    ],\n                    \"settings\": {\n                        \"background\": \"#5c61660f\"\n                    }\n                },\n                {\n                    \"name\": \"Markdown Separator\",\n                    \"scope\": [\n                        \"meta.separator\"\n                    ],\n                    \"settings\": {\n                        \"fontStyle\": \"bold\",\n                        \"background\": \"#5c61660f\",\n                        \"foreground\": \"#787b8099\"\n                    }\n                },\n                {\n                    \"name\": \"Markup Blockquote\",\n                    \"scope\": [\n                        \"markup.quote\"\n                    ],\n                    \"settings\": {\n                        \"foreground\": \"#4cbf99\",\n                        \"fontStyle\": \"italic\"\n                    }\n                },\n                {\n                    \"name\": \"Markup List Bullet\",\n                    \"scope\": [\n                        \"markup.list punctuation.definition.list.begin\"\n                    ],\n                    \"settings\": {\n                        \"foreground\": \"#f2ae49\"\n                    }\n                },\n                {\n                    \"name\": \"Markup added\",\n                    \"scope\": [\n                        \"markup.inserted\"\n                    ],\n                    \"settings\": {\n                        \"foreground\": \"#6cbf43\"\n                    }\n                },\n                {\n                    \"name\": \"Markup modified\",\n                    \"scope\": [\n                        \"markup.changed\"\n                    ],\n                    \"settings\": {\n                        \"foreground\": \"#478acc\"\n                    }\n                },\n                {\n                    \"name\": \"Markup removed\",\n                    \"scope\": [\n                        \"markup.deleted\"\n                    ],\n                    \"settings\": {\n                        \"foreground\": \"#ff7383\"\n                    }\n                },\n                {\n                    \"name\": \"Markup Strike\",\n                    \"scope\": [\n                        \"markup.strike\"\n                    ],\n                    \"settings\": {\n                        \"foreground\": \"#e6ba7e\"\n                    }\n                },\n                {\n                    \"name\": \"Markup Table\",\n                    \"scope\": [\n                        \"markup.table\"\n                    ],\n                    \"settings\": {\n                        \"background\": \"#5c61660f\",\n                        \"foreground\": \"#55b4d4\"\n                    }\n                },\n                {\n                    \"name\": \"Markup Raw Inline\",\n                    \"scope\": [\n                        \"text.html.markdown markup.inline.raw\"\n                    ],\n                    \"settings\": {\n                        \"foreground\": \"#ed9366\"\n                    }\n                },\n                {\n                    \"name\": \"Markdown - Line Break\",\n                    \"scope\": [\n                        \"text.html.markdown meta.dummy.line-break\"\n                    ],\n                    \"settings\": {\n                        \"background\": \"#787b8099\",\n                        \"foreground\": \"#787b8099\"\n                    }\n                },\n                {\n                    \"name\": \"Markdown - Raw Block Fenced\",\n                    \"scope\": [\n                        \"punctuation.definition.markdown\"\n                    ],\n                    \"settings\": {\n                        \"background\": \"#5c6166\",\n                        \"foreground\": \"#787b8099\"\n                    }\n                }\n            ],\n            semanticHighlighting: true,\n            semanticTokenColors: {\n                \"parameter.label\": \"#5c6166\"\n            }\n        }\n\n        const ayuDarkTheme = {\n            name: \"ayu-dark\",\n            type: \"dark\",\n            colors: {\n                \"focusBorder\": \"#ffcc66b3\",\n                \"foreground\": \"#707a8c\",\n                \"widget.shadow\": \"#12151cb3\",\n                \"selection.background\": \"#409fff40\",\n                \"icon.foreground\": \"#707a8c\",\n                \"errorForeground\": \"#ff6666\",\n                \"descriptionForeground\": \"#707a8c\",\n                \"textBlockQuote.background\": \"#1c212b\",\n                \"textLink.foreground\": \"#ffcc66\",\n                \"textLink.activeForeground\": \"#ffcc66\",\n                \"textPreformat.foreground\": \"#cccac2\",\n                \"button.background\": \"#ffcc66\",\n                \"button.foreground\": \"#1f2430\",\n                \"button.hoverBackground\": \"#fac761\",\n                \"button.secondaryBackground\": \"#707a8c33\",\n                \"button.secondaryForeground\": \"#cccac2\",\n                \"button.secondaryHoverBackground\": \"#707a8c80\",\n                \"dropdown.background\": \"#242936\",\n                \"dropdown.foreground\": \"#707a8c\",\n                \"dropdown.border\": \"#707a8c45\",\n                \"input.background\": \"#242936\",\n                \"input.border\": \"#707a8c45\",\n                \"input.foreground\": \"#cccac2\",\n                \"input.placeholderForeground\": \"#707a8c80\",\n                \"inputOption.activeBorder\": \"#ffcc664d\",\n                \"inputOption.activeBackground\": \"#ffcc6633\",\n                \"inputOption.activeForeground\": \"#ffcc66\",\n                \"inputValidation.errorBackground\": \"#242936\",\n                \"inputValidation.errorBorder\": \"#ff6666\",\n                \"inputValidation.infoBackground\": \"#1f2430\",\n                \"inputValidation.infoBorder\": \"#5ccfe6\",\n                \"inputValidation.warningBackground\": \"#1f2430\",\n                \"inputValidation.warningBorder\": \"#ffd173\",\n                \"scrollbar.shadow\": \"#171b2400\",\n                \"scrollbarSlider.background\": \"#707a8c66\",\n                \"scrollbarSlider.hoverBackground\": \"#707a8c99\",\n                \"scrollbarSlider.activeBackground\": \"#707a8cb3\",\n                \"badge.background\": \"#ffcc6633\",\n                \"badge.foreground\": \"#ffcc66\",\n                \"progressBar.background\": \"#ffcc66\",\n                \"list.activeSelectionBackground\": \"#63759926\",\n                \"list.activeSelectionForeground\": \"#cccac2\",\n                \"list.focusBackground\": \"#63759926\",\n                \"list.focusForeground\": \"#cccac2\",\n                \"list.focusOutline\": \"#63759926\",\n                \"list.highlightForeground\": \"#ffcc66\",\n                \"list.deemphasizedForeground\": \"#ff6666\",\n                \"list.hoverBackground\": \"#63759926\",\n                \"list.inactiveSelectionBackground\": \"#69758c1f\",\n                \"list.inactiveSelectionForeground\": \"#707a8c\",\n                \"list.invalidItemForeground\": \"#707a8c4d\",\n                \"list.errorForeground\": \"#ff6666\",\n                \"tree.indentGuidesStroke\": \"#8a919959\",\n                \"listFilterWidget.background\": \"#1c212b\",\n                \"listFilterWidget.outline\": \"#ffcc66\",\n                \"listFilterWidget.noMatchesOutline\": \"#ff6666\",\n                \"list.filterMatchBackground\": \"#5c467266\",\n                \"list.filterMatchBorder\": \"#69538066\",\n                \"activityBar.background\": \"#1f2430\",\n                \"activityBar.foreground\": \"#707a8ccc\",\n                \"activityBar.inactiveForeground\": \"#707a8c99\",\n                \"activityBar.border\": \"#1f2430\",\n                \"activityBar.activeBorder\": \"#ffcc66b3\",\n                \"activityBarBadge.background\": \"#ffcc66\",\n                \"activityBarBadge.foreground\": \"#1f2430\",\n                \"sideBar.background\": \"#1f2430\",\n                \"sideBar.border\": \"#1f2430\",\n                \"sideBarTitle.foreground\": \"#707a8c\",\n                \"sideBarSectionHeader.background\": \"#1f2430\",\n                \"sideBarSectionHeader.foreground\": \"#707a8c\",\n                \"sideBarSectionHeader.border\": \"#1f2430\",\n                \"minimap.background\": \"#1f2430\",\n                \"minimap.selectionHighlight\": \"#409fff40\",\n                \"minimap.errorHighlight\": \"#ff6666\",\n                \"minimap.findMatchHighlight\": \"#695380\",\n                \"minimapGutter.addedBackground\": \"#87d96c\",\n                \"minimapGutter.modifiedBackground\": \"#80bfff\",\n                \"minimapGutter.deletedBackground\": \"#f27983\",\n                \"editorGroup.border\": \"#171b24\",\n                \"editorGroup.background\": \"#1c212b\",\n                \"editorGroupHeader.noTabsBackground\": \"#1f2430\",\n                \"editorGroupHeader.tabsBackground\": \"#1f2430\",\n                \"editorGroupHeader.tabsBorder\": \"#1f2430\",\n                \"tab.activeBackground\": \"#1f2430\",\n                \"tab.activeForeground\": \"#cccac2\",\n                \"tab.border\": \"#1f2430\",\n                \"tab.activeBorder\": \"#ffcc66\",\n                \"tab.unfocusedActiveBorder\": \"#707a8c\",\n                \"tab.inactiveBackground\": \"#1f2430\",\n                \"tab.inactiveForeground\": \"#707a8c\",\n                \"tab.unfocusedActiveForeground\": \"#707a8c\",\n                \"tab.unfocusedInactiveForeground\": \"#707a8c\",\n                \"editor.background\": \"#1f2430\",\n                \"editor.foreground\": \"#cccac2\",\n                \"editorLineNumber.foreground\": \"#8a919966\",\n                \"editorLineNumber.activeForeground\": \"#8a9199cc\",\n                \"editorCursor.foreground\": \"#ffcc66\",\n                \"editor.inactiveSelectionBackground\": \"#409fff21\",\n                \"editor.selectionBackground\": \"#409fff40\",\n                \"editor.selectionHighlightBackground\": \"#87d96c26\",\n                \"editor.selectionHighlightBorder\": \"#87d96c00\",\n                \"editor.wordHighlightBackground\": \"#80bfff14\",\n                \"editor.wordHighlightStrongBackground\": \"#87d96c14\",\n                \"editor.wordHighlightBorder\": \"#80bfff80\",\n                \"editor.wordHighlightStrongBorder\": \"#87d96c80\",\n                \"editor.findMatchBackground\": \"#695380\",\n                \"editor.findMatchBorder\": \"#695380\",\n                \"editor.findMatchHighlightBackground\": \"#69538066\",\n                \"editor.findMatchHighlightBorder\": \"#5c467266\",\n                \"editor.findRangeHighlightBackground\": \"#69538040\",\n                \"editor.rangeHighlightBackground\": \"#69538033\",\n                \"editor.lineHighlightBackground\": \"#1a1f29\",\n                \"editorLink.activeForeground\": \"#ffcc66\",\n                \"editorWhitespace.foreground\": \"#8a919966\",\n                \"editorIndentGuide.background\": \"#8a91992e\",\n                \"editorIndentGuide.activeBackground\": \"#8a919959\",\n                \"editorRuler.foreground\": \"#8a91992e\",\n                \"editorCodeLens.foreground\": \"#b8cfe680\",\n                \"editorBracketMatch.background\": \"#8a91994d\",\n                \"editorBracketMatch.border\": \"#8a91994d\",\n                \"editor.snippetTabstopHighlightBackground\": \"#87d96c33\",\n                \"editorOverviewRuler.border\": \"#171b24\",\n                \"editorOverviewRuler.modifiedForeground\": \"#80bfff\",\n                \"editorOverviewRuler.addedForeground\": \"#87d96c\",\n                \"editorOverviewRuler.deletedForeground\": \"#f27983\",\n                \"editorOverviewRuler.errorForeground\": \"#ff6666\",\n                \"editorOverviewRuler.warningForeground\": \"#ffcc66\",\n                \"editorOverviewRuler.bracketMatchForeground\": \"#8a9199b3\",\n                \"editorOverviewRuler.wordHighlightForeground\": \"#80bfff66\",\n                \"editorOverviewRuler.wordHighlightStrongForeground\": \"#87d96c66\",\n                \"editorOverviewRuler.findMatchForeground\": \"#695380\",\n                \"editorError.foreground\": \"#ff6666\",\n                \"editorWarning.foreground\": \"#ffcc66\",\n                \"editorGutter.modifiedBackground\": \"#80bfffcc\",\n                \"editorGutter.addedBackground\": \"#87d96ccc\",\n                \"editorGutter.deletedBackground\": \"#f27983cc\",\n                \"diffEditor.insertedTextBackground\": \"#87d96c1f\",\n                \"diffEditor.removedTextBackground\": \"#f279831f\",\n                \"diffEditor.diagonalFill\": \"#171b24\",\n                \"editorWidget.background\": \"#1c212b\",\n                \"editorWidget.border\": \"#171b24\",\n                \"editorHoverWidget.background\": \"#1c212b\",\n                \"editorHoverWidget.border\": \"#171b24\",\n                \"editorSuggestWidget.background\": \"#1c212b\",\n                \"editorSuggestWidget.border\": \"#171b24\",\n                \"editorSuggestWidget.highlightForeground\": \"#ffcc66\",\n                \"editorSuggestWidget.selectedBackground\": \"#63759926\",\n                \"debugExceptionWidget.border\": \"#171b24\",\n                \"debugExceptionWidget.background\": \"#1c212b\",\n                \"editorMarkerNavigation.background\": \"#1c212b\",\n                \"peekView.border\": \"#63759926\",\n                \"peekViewTitle.background\": \"#63759926\",\n                \"peekViewTitleDescription.foreground\": \"#707a8c\",\n                \"peekViewTitleLabel.foreground\": \"#cccac2\",\n                \"peekViewEditor.background\": \"#1c212b\",\n                \"peekViewEditor.matchHighlightBackground\": \"#69538066\",\n                \"peekViewEditor.matchHighlightBorder\": \"#5c467266\",\n                \"peekViewResult.background\": \"#1c212b\",\n                \"peekViewResult.fileForeground\": \"#cccac2\",\n                \"peekViewResult.lineForeground\": \"#707a8c\",\n                \"peekViewResult.matchHighlightBackground\": \"#69538066\",\n                \"peekViewResult.selectionBackground\": \"#63759926\",\n                \"panel.background\": \"#1f2430\",\n                \"panel.border\": \"#171b24\",\n                \"panelTitle.activeBorder\": \"#ffcc66\",\n                \"panelTitle.activeForeground\": \"#cccac2\",\n                \"panelTitle.inactiveForeground\": \"#707a8c\",\n                \"statusBar.background\": \"#1f2430\",\n                \"statusBar.foreground\": \"#707a8c\",\n                \"statusBar.border\": \"#1f2430\",\n                \"statusBar.debuggingBackground\": \"#f29e74\",\n                \"statusBar.debuggingForeground\": \"#242936\",\n                \"statusBar.noFolderBackground\": \"#1c212b\",\n                \"statusBarItem.activeBackground\": \"#707a8c33\",\n                \"statusBarItem.hoverBackground\": \"#707a8c33\",\n                \"statusBarItem.prominentBackground\": \"#171b24\",\n                \"statusBarItem.prominentHoverBackground\": \"#00000030\",\n                \"statusBarItem.remoteBackground\": \"#ffcc66\",\n                \"statusBarItem.remoteForeground\": \"#242936\",\n                \"titleBar.activeBackground\": \"#1f2430\",\n                \"titleBar.activeForeground\": \"#cccac2\",\n                \"titleBar.inactiveBackground\": \"#1f2430\",\n                \"titleBar.inactiveForeground\": \"#707a8c\",\n                \"titleBar.border\": \"#1f2430\",\n                \"extensionButton.prominentForeground\": \"#242936\",\n                \"extensionButton.prominentBackground\": \"#ffcc66\",\n                \"extensionButton.prominentHoverBackground\": \"#fac761\",\n                \"pickerGroup.border\": \"#171b24\",\n                \"pickerGroup.foreground\": \"#707a8c80\",\n                \"debugToolBar.background\": \"#1c212b\",\n                \"debugIcon.breakpointForeground\": \"#f29e74\",\n                \"debugIcon.breakpointDisabledForeground\": \"#f29e7480\",\n                \"debugConsoleInputIcon.foreground\": \"#ffcc66\",\n                \"welcomePage.tileBackground\": \"#1f2430\",\n                \"welcomePage.tileShadow\": \"#12151cb3\",\n                \"welcomePage.progress.background\": \"#1a1f29\",\n                \"welcomePage.buttonBackground\": \"#ffcc6666\",\n                \"walkThrough.embeddedEditorBackground\": \"#1c212b\",\n                \"gitDecoration.modifiedResourceForeground\": \"#80bfffb3\",\n                \"gitDecoration.deletedResourceForeground\": \"#f27983b3\",\n                \"gitDecoration.untrackedResourceForeground\": \"#87d96cb3\",\n                \"gitDecoration.ignoredResourceForeground\": \"#707a8c80\",\n                \"gitDecoration.conflictingResourceForeground\": \"\",\n                \"gitDecoration.submoduleResourceForeground\": \"#dfbfffb3\",\n                \"settings.headerForeground\": \"#cccac2\",\n                \"settings.modifiedItemIndicator\": \"#80bfff\",\n                \"keybindingLabel.background\": \"#707a8c1a\",\n                \"keybindingLabel.foreground\": \"#cccac2\",\n                \"keybindingLabel.border\": \"#cccac21a\",\n                \"keybindingLabel.bottomBorder\": \"#cccac21a\",\n                \"terminal.background\": \"#1f2430\",\n                \"terminal.foreground\": \"#cccac2\",\n                \"terminal.ansiBlack\": \"#171b24\",\n                \"terminal.ansiRed\": \"#ed8274\",\n                \"terminal.ansiGreen\": \"#87d96c\",\n                \"terminal.ansiYellow\": \"#facc6e\",\n                \"terminal.ansiBlue\": \"#6dcbfa\",\n                \"terminal.ansiMagenta\": \"#dabafa\",\n                \"terminal.ansiCyan\": \"#90e1c6\",\n                \"terminal.ansiWhite\": \"#c7c7c7\",\n                \"terminal.ansiBrightBlack\": \"#686868\",\n                \"terminal.ansiBrightRed\": \"#f28779\",\n                \"terminal.ansiBrightGreen\": \"#d5ff80\",\n                \"terminal.ansiBrightYellow\": \"#ffd173\",\n                \"terminal.ansiBrightBlue\": \"#73d0ff\",\n                \"terminal.ansiBrightMagenta\": \"#dfbfff\",\n                \"terminal.ansiBrightCyan\": \"#95e6cb\",\n                \"terminal.ansiBrightWhite\": \"#ffffff\"\n            },\n            tokenColors: [\n                {\n                    \"settings\": {\n                        \"background\": \"#1f2430\",\n                        \"foreground\": \"#cccac2\"\n                    }\n                },\n                {\n                    \"name\": \"Comment\",\n                    \"scope\": [\n                        \"comment\"\n                    ],\n                    \"settings\": {\n                        \"fontStyle\": \"italic\",\n                        \"foreground\": \"#b8cfe680\"\n                    }\n                },\n                {\n                    \"name\": \"String\",\n                    \"scope\": [\n                        \"string\",\n                        \"constant.other.symbol\"\n                    ],\n                    \"settings\": {\n                        \"foreground\": \"#d5ff80\"\n                    }\n                },\n                {\n                    \"name\": \"Regular Expressions and Escape Characters\",\n                    \"scope\": [\n                        \"string.regexp\",\n                        \"constant.character\",\n                        \"constant.other\"\n                    ],\n                    \"settings\": {\n                        \"foreground\": \"#95e6cb\"\n                    }\n                },\n                {\n                    \"name\": \"Number\",\n                    \"scope\": [\n                        \"constant.numeric\"\n                    ],\n                    \"settings\": {\n                        \"foreground\": \"#dfbfff\"\n                    }\n                },\n                {\n                    \"name\": \"Built-in constants\",\n                    \"scope\": [\n                        \"constant.language\"\n                    ],\n                    \"settings\": {\n                        \"foreground\": \"#dfbfff\"\n                    }\n                },\n                {\n                    \"name\": \"Variable\",\n                    \"scope\": [\n                        \"variable\",\n                        \"variable.parameter.function-call\"\n                    ],\n                    \"settings\": {\n                        \"foreground\": \"#cccac2\"\n                    }\n                },\n                {\n                    \"name\": \"Member Variable\",\n                    \"scope\": [\n                        \"variable.member\"\n                    ],\n                    \"settings\": {\n                        \"foreground\": \"#f28779\"\n                    }\n                },\n                {\n                    \"name\": \"Language variable\",\n                    \"scope\": [\n                        \"variable.language\"\n                    ],\n                    \"settings\": {\n                        \"fontStyle\": \"italic\",\n                        \"foreground\": \"#5ccfe6\"\n                    }\n                },\n                {\n                    \"name\": \"Storage\",\n                    \"scope\": [\n                        \"storage\"\n                    ],\n                    \"settings\": {\n                        \"foreground\": \"#ffad66\"\n                    }\n                },\n                {\n                    \"name\": \"Keyword\",\n                    \"scope\": [\n                        \"keyword\"\n                    ],\n                    \"settings\": {\n                        \"foreground\": \"#ffad66\"\n                    }\n                },\n                {\n                    \"name\": \"Operators\",\n                    \"scope\": [\n                        \"keyword.operator\"\n                    ],\n                    \"settings\": {\n                        \"foreground\": \"#f29e74\"\n                    }\n                },\n                {\n                    \"name\": \"Separators like  or ,\",\n                    \"scope\": [\n                        \"punctuation.separator\",\n                        \"punctuation.terminator\"\n                    ],\n                    \"settings\": {\n                        \"foreground\": \"#cccac2b3\"\n                    }\n                },\n                {\n                    \"name\": \"Punctuation\",\n                    \"scope\": [\n                        \"punctuation.section\"\n                    ],\n                    \"settings\": {\n                        \"foreground\": \"#cccac2\"\n                    }\n                },\n                {\n                    \"name\": \"Accessor\",\n                    \"scope\": [\n                        \"punctuation.accessor\"\n                    ],\n                    \"settings\": {\n                        \"foreground\": \"#f29e74\"\n                    }\n                },\n                {\n                    \"name\": \"JavaScript/TypeScript interpolation punctuation\",\n                    \"scope\": [\n                        \"punctuation.definition.template-expression\"\n                    ],\n                    \"settings\": {\n                        \"foreground\": \"#ffad66\"\n                    }\n                },\n                {\n                    \"name\": \"Ruby interpolation punctuation\",\n                    \"scope\": [\n                        \"punctuation.section.embedded\"\n                    ],\n                    \"settings\": {\n                        \"foreground\": \"#ffad66\"\n                    }\n                },\n                {\n                    \"name\": \"Interpolation text\",\n                    \"scope\": [\n                        \"meta.embedded\"\n                    ],\n                    \"settings\": {\n                        \"foreground\": \"#cccac2\"\n                    }\n                },\n                {\n                    \"name\": \"Types fixes\",\n                    \"scope\": [\n                        \"source.java storage.type\",\n                        \"source.haskell storage.type\",\n                        \"source.c storage.type\"\n                    ],\n                    \"settings\": {\n                        \"foreground\": \"#73d0ff\"\n                    }\n                },\n                {\n                    \"name\": \"Inherited class type\",\n                    \"scope\": [\n                        \"entity.other.inherited-class\"\n                    ],\n                    \"settings\": {\n                        \"foreground\": \"#5ccfe6\"\n                    }\n                },\n                {\n                    \"name\": \"Lambda arrow\",\n                    \"scope\": [\n                        \"storage.type.function\"\n                    ],\n                    \"settings\": {\n                        \"foreground\": \"#ffad66\"\n                    }\n                },\n                {\n                    \"name\": \"Java primitive variable types\",\n                    \"scope\": [\n                        \"source.java storage.type.primitive\"\n                    ],\n                    \"settings\": {\n                        \"foreground\": \"#5ccfe6\"\n                    }\n                },\n                {\n                    \"name\": \"Function name\",\n                    \"scope\": [\n                        \"entity.name.function\"\n                    ],\n                    \"settings\": {\n                        \"foreground\": \"#ffd173\"\n                    }\n                },\n                {\n                    \"name\": \"Function arguments\",\n                    \"scope\": [\n                        \"variable.parameter\",\n                        \"meta.parameter\"\n                    ],\n                    \"settings\": {\n                        \"foreground\": \"#dfbfff\"\n                    }\n                },\n                {\n                    \"name\": \"Function call\",\n                    \"scope\": [\n                        \"variable.function\",\n                        \"variable.annotation\",\n                        \"meta.function-call.generic\",\n                        \"support.function.go\"\n                    ],\n                    \"settings\": {\n                        \"foreground\": \"#ffd173\"\n                    }\n                },\n                {\n                    \"name\": \"Library function\",\n                    \"scope\": [\n                        \"support.function\",\n                        \"support.macro\"\n                    ],\n                    \"settings\": {\n                        \"foreground\": \"#f28779\"\n                    }\n                },\n                {\n                    \"name\": \"Imports and packages\",\n                    \"scope\": [\n                        \"entity.name.import\",\n                        \"entity.name.package\"\n                    ],\n                    \"settings\": {\n                        \"foreground\": \"#d5ff80\"\n                    }\n                },\n                {\n                    \"name\": \"Entity name\",\n                    \"scope\": [\n                        \"entity.name\"\n                    ],\n                    \"settings\": {\n                        \"foreground\": \"#73d0ff\"\n                    }\n                },\n                {\n                    \"name\": \"Tag\",\n                    \"scope\": [\n                        \"entity.name.tag\",\n                        \"meta.tag.sgml\"\n                    ],\n                    \"settings\": {\n                        \"foreground\": \"#5ccfe6\"\n                    }\n                },\n                {\n                    \"name\": \"JSX Component\",\n                    \"scope\": [\n                        \"support.class.component\"\n                    ],\n                    \"settings\": {\n                        \"foreground\": \"#73d0ff\"\n                    }\n                },\n                {\n                    \"name\": \"Tag start/end\",\n                    \"scope\": [\n                        \"punctuation.definition.tag.end\",\n                        \"punctuation.definition.tag.begin\",\n                        \"punctuation.definition.tag\"\n                    ],\n                    \"settings\": {\n                        \"foreground\": \"#5ccfe680\"\n                    }\n                },\n                {\n                    \"name\": \"Tag attribute\",\n                    \"scope\": [\n                        \"entity.other.attribute-name\"\n                    ],\n                    \"settings\": {\n                        \"foreground\": \"#ffd173\"\n                    }\n                },\n                {\n                    \"name\": \"Library constant\",\n                    \"scope\": [\n                        \"support.constant\"\n                    ],\n                    \"settings\": {\n                        \"fontStyle\": \"italic\",\n                        \"foreground\": \"#f29e74\"\n                    }\n                },\n                {\n                    \"name\": \"Library class/type\",\n                    \"scope\": [\n                        \"support.type\",\n                        \"support.class\",\n                        \"source.go storage.type\"\n                    ],\n                    \"settings\": {\n                        \"foreground\": \"#5ccfe6\"\n                    }\n                },\n                {\n                    \"name\": \"Decorators/annotation\",\n                    \"scope\": [\n                        \"meta.decorator variable.other\",\n                        \"meta.decorator punctuation.decorator\",\n                        \"storage.type.annotation\"\n                    ],\n                    \"settings\": {\n                        \"foreground\": \"#ffdfb3\"\n                    }\n                },\n                {\n                    \"name\": \"Invalid\",\n                    \"scope\": [\n                        \"invalid\"\n                    ],\n                    \"settings\": {\n                        \"foreground\": \"#ff6666\"\n                    }\n                },\n                {\n                    \"name\": \"diff.header\",\n                    \"scope\": [\n                        \"meta.diff\",\n                        \"meta.diff.header\"\n                    ],\n                    \"settings\": {\n                        \"foreground\": \"#c594c5\"\n                    }\n                },\n                {\n                    \"name\": \"Ruby class methods\",\n                    \"scope\": [\n                        \"source.ruby variable.other.readwrite\"\n                    ],\n                    \"settings\": {\n                        \"foreground\": \"#ffd173\"\n                    }\n                },\n                {\n                    \"name\": \"CSS tag names\",\n                    \"scope\": [\n                        \"source.css entity.name.tag\",\n                        \"source.sass entity.name.tag\",\n                        \"source.scss entity.name.tag\",\n                        \"source.less entity.name.tag\",\n                        \"source.stylus entity.name.tag\"\n                    ],\n                    \"settings\": {\n                        \"foreground\": \"#73d0ff\"\n                    }\n                },\n                {\n                    \"name\": \"CSS browser prefix\",\n                    \"scope\": [\n                        \"source.css support.type\",\n                        \"source.sass support.type\",\n                        \"source.scss support.type\",\n                        \"source.less support.type\",\n                        \"source.stylus support.type\"\n                    ],\n                    \"settings\": {\n                        \"foreground\": \"#b8cfe680\"\n                    }\n                },\n                {\n                    \"name\": \"CSS Properties\",\n                    \"scope\": [\n                        \"support.type.property-name\"\n                    ],\n                    \"settings\": {\n                        \"fontStyle\": \"normal\",\n                        \"foreground\": \"#5ccfe6\"\n                    }\n                },\n                {\n                    \"name\": \"Search Results Numbers\",\n                    \"scope\": [\n                        \"constant.numeric.line-number.find-in-files - match\"\n                    ],\n                    \"settings\": {\n                        \"foreground\": \"#b8cfe680\"\n                    }\n                },\n                {\n                    \"name\": \"Search Results Match Numbers\",\n                    \"scope\": [\n                        \"constant.numeric.line-number.match\"\n                    ],\n                    \"settings\": {\n                        \"foreground\": \"#ffad66\"\n                    }\n                },\n                {\n                    \"name\": \"Search Results Lines\",\n                    \"scope\": [\n                        \"entity.name.filename.find-in-files\"\n                    ],\n                    \"settings\": {\n                        \"foreground\": \"#d5ff80\"\n                    }\n                },\n                {\n                    \"scope\": [\n                        \"message.error\"\n                    ],\n                    \"settings\": {\n                        \"foreground\": \"#ff6666\"\n                    }\n                },\n                {\n                    \"name\": \"Markup heading\",\n                    \"scope\": [\n                        \"markup.heading\",\n                        \"markup.heading entity.name\"\n                    ],\n                    \"settings\": {\n                        \"fontStyle\": \"bold\",\n                        \"foreground\": \"#d5ff80\"\n                    }\n                },\n                {\n                    \"name\": \"Markup links\",\n                    \"scope\": [\n                        \"markup.underline.link\",\n                        \"string.other.link\"\n                    ],\n                    \"settings\": {\n                        \"foreground\": \"#5ccfe6\"\n                    }\n                },\n                {\n                    \"name\": \"Markup Italic\",\n                    \"scope\": [\n                        \"markup.italic\"\n                    ],\n                    \"settings\": {\n                        \"fontStyle\": \"italic\",\n                        \"foreground\": \"#f28779\"\n                    }\n                },\n                {\n                    \"name\": \"Markup Bold\",\n                    \"scope\": [\n                        \"markup.bold\"\n                    ],\n                    \"settings\": {\n                        \"fontStyle\": \"bold\",\n                        \"foreground\": \"#f28779\"\n                    }\n                },\n                {\n                    \"name\": \"Markup Bold/italic\",\n                    \"scope\": [\n                        \"markup.italic markup.bold\",\n                        \"markup.bold markup.italic\"\n                    ],\n                    \"settings\": {\n                        \"fontStyle\": \"bold italic\"\n                    }\n                },\n                {\n                    \"name\": \"Markup Code\",\n                    \"scope\": [\n                        \"markup.raw\"\n                    ],\n                    \"settings\": {\n                        \"background\": \"#cccac205\"\n                    }\n                },\n                {\n                    \"name\": \"Markup Code Inline\",\n                    \"scope\": [\n                        \"markup.raw.inline\"\n                    ],\n                    \"settings\": {\n                        \"background\": \"#cccac20f\"\n                    }\n                },\n                {\n                    \"name\": \"Markdown Separator\",\n                    \"scope\": [\n                        \"meta.separator\"\n                    ],\n                    \"settings\": {\n                        \"fontStyle\": \"bold\",\n                        \"background\": \"#cccac20f\",\n                        \"foreground\": \"#b8cfe680\"\n                    }\n                },\n                {\n                    \"name\": \"Markup Blockquote\",\n                    \"scope\": [\n                        \"markup.quote\"\n                    ],\n                    \"settings\": {\n                        \"foreground\": \"#95e6cb\",\n                        \"fontStyle\": \"italic\"\n                    }\n                },\n                {\n                    \"name\": \"Markup List Bullet\",\n                    \"scope\": [\n                        \"markup.list punctuation.definition.list.begin\"\n                    ],\n                    \"settings\": {\n                        \"foreground\": \"#ffd173\"\n                    }\n                },\n                {\n                    \"name\": \"Markup added\",\n                    \"scope\": [\n                        \"markup.inserted\"\n                    ],\n                    \"settings\": {\n                        \"foreground\": \"#87d96c\"\n                    }\n                },\n                {\n                    \"name\": \"Markup modified\",\n                    \"scope\": [\n                        \"markup.changed\"\n                    ],\n                    \"settings\": {\n                        \"foreground\": \"#80bfff\"\n                    }\n                },\n                {\n                    \"name\": \"Markup removed\",\n                    \"scope\": [\n                        \"markup.deleted\"\n                    ],\n                    \"settings\": {\n                        \"foreground\": \"#f27983\"\n                    }\n                },\n                {\n                    \"name\": \"Markup Strike\",\n                    \"scope\": [\n                        \"markup.strike\"\n                    ],\n                    \"settings\": {\n                        \"foreground\": \"#ffdfb3\"\n                    }\n                },\n                {\n                    \"name\": \"Markup Table\",\n                    \"scope\": [\n                        \"markup.table\"\n                    ],\n                    \"settings\": {\n                        \"background\": \"#cccac20f\",\n                        \"foreground\": \"#5ccfe6\"\n                    }\n                },\n                {\n                    \"name\": \"Markup Raw Inline\",\n                    \"scope\": [\n                        \"text.html.markdown markup.inline.raw\"\n                    ],\n                    \"settings\": {\n                        \"foreground\": \"#f29e74\"\n                    }\n                },\n                {\n                    \"name\": \"Markdown - Line Break\",\n                    \"scope\": [\n                        \"text.html.markdown meta.dummy.line-break\"\n                    ],\n                    \"settings\": {\n                        \"background\": \"#b8cfe680\",\n                        \"foreground\": \"#b8cfe680\"\n                    }\n                },\n                {\n                    \"name\": \"Markdown - Raw Block Fenced\",\n                    \"scope\": [\n                        \"punctuation.definition.markdown\"\n                    ],\n                    \"settings\": {\n                        \"background\": \"#cccac2\",\n                        \"foreground\": \"#b8cfe680\"\n                    }\n                }\n            ],\n            semanticHighlighting: true,\n            semanticTokenColors: {\n                \"parameter.label\": \"#cccac2\"\n            }\n        }\n\n       \tconst langs = [\"go\", \"python\", \"yaml\", \"hcl\"]\n        const highlighter = await getHighlighter(\n            {\n                themes: [ayuDarkTheme, ayuLightTheme],\n                langs: langs,\n            }\n        )\n\n        window.require.config({ paths: { vs: '/static/monaco/core' } })\n        window.require(\n            ['vs/editor/editor.main'],\n            async function() {\n                for (const lang of langs) {\n                    monaco.languages.register({ id: lang })\n                }\n\n                shikiToMonaco(highlighter, monaco)\n\n                const container = document.getElementById('container')\n                let editor = monaco.editor.create(\n                    container,\n                    {\n                        theme: window.matchMedia('(prefers-color-scheme: dark)').matches ? 'ayu-dark' : 'ayu-light',\n                        fontFamily: 'JetBrains Mono',\n                        fontWeight: '500',\n                        fontSize: 14,\n                        minimap: {\n                            enabled: false,\n                        },\n                        contextmenu: false,\n                        stickyScroll: {\n                            enabled: true,\n                            defaultModel: \"foldingProviderModel\",\n                        },\n                        placeholder: `\n                        skladka(1)                                General Commands Manual                               skladka(1)\n\n                        NAME\n                            skladka - a highly opinionated and minimalistic pastebin\n\n                        WEB USAGE\n                            Drag a file and drop it here, or\n                            Paste an image from your clipboard using Ctrl + v, or\n                            After typing, press the big yellow button to paste, or\n                            Just press Ctrl + Enter once done typing.\n\n                        MOBILE USAGE\n                            All of the above, or\n                            Use the upload button.\n\n                        ROUTES\n                            GET /<id>\n                                Get raw pastes\n                            GET /p/<id>\n                                Get highlighted pastes\n                            GET /p/<id>.<ext>\n                                Get syntax highlighted pastes.\n\n                        SEE ALSO\n                            github.com/aexvir/skladka\n                        `,\n                    }\n                )\n\n                // export Editor object globally, so other components can interact with it\n                window.Editor = (\n                    function() {\n                        return {\n                            getEditor: function() {\n                                return editor\n                            },\n                            getContent: function() {\n                                return editor.getValue()\n                            },\n                            setContent: function(text) {\n                                return editor.setValue(text)\n                            },\n                            setSyntax: function(lang) {\n                                return editor.getModel().setLanguage(lang)\n                            },\n                            setTheme: function(name) {\n                                return editor.updateOptions({ theme: name })\n                            },\n                            setReadOnly: function() {\n                                return editor.updateOptions({ readOnly: true})\n                            },\n                            copyToClipboard: function() {\n                                window.Toaster.show(\n                                    'content copied to clipboard!', {\n                                        type: 'success',\n                                        duration: 3000\n                                    }\n                                )\n\n                                return navigator.clipboard.writeText(editor.getValue())\n                            },\n                            downloadAsFile: function() {\n                                const blob = new Blob([editor.getValue()], { type: 'text/plain' })\n                                const url = URL.createObjectURL(blob)\n\n                                const title = document.\n                                    getElementById(\"paste-title\").\n                                    innerText.\n                                    toLowerCase().\n                                    replace(/ /g, '-')\n\n                                const language = monaco.\n                                    languages.\n                                    getLanguages().\n                                    find((lang) => lang.id === editor.getModel().getLanguageId()\n                                )\n\n                                let extension = \"txt\"\n                                if (language !== undefined && language.extensions.length > 0) {\n                                    extension = language.extensions[0]\n                                }\n\n                                const link = document.createElement('a')\n                                link.href = url\n                                link.download = `${title}${extension}`\n                                link.click()\n\n                                URL.revokeObjectURL(url)\n                            }\n                        }\n                    }\n                )()\n\n                // weird hack to properly get monaco to resize inside a flex container\n                window.addEventListener(\n                    'resize',\n                    () => {\n                        editor.layout({ width: 0, height: 0 })\n                        window.requestAnimationFrame(\n                            () => {\n                                const rect = container.getBoundingClientRect()\n                                editor.layout({ width: rect.width, height: rect.height })\n                            }\n                        )\n                    }\n                )\n\n                window.\n                    matchMedia('(prefers-color-scheme: dark)').\n                    addEventListener(\n                        'change',\n                        (e) => {\n                            console.log(e.matches)\n                            window.Editor.setTheme(e.matches ? 'ayu-dark' : 'ayu-light')\n                        }\n                    )\n            }\n        )\n    </script>")
+		templ_7745c5c3_Err = editor("").Render(ctx, templ_7745c5c3_Buffer)
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
@@ -40,44 +40,238 @@ func Editor(content, syntax string, readonly bool) templ.Component {
 	})
 }
 
-func monaco(content, syntax string, readonly bool) templ.ComponentScript {
-	return templ.ComponentScript{
-		Name: `__templ_monaco_913f`,
-		Function: `function __templ_monaco_913f(content, syntax, readonly){const waitForMonaco = new Promise(
-        (resolve, reject) => {
-            const start = Date.now()
-            const timeout = 30000
+func DiffEditor(original, modified, syntax string) templ.Component {
+	return templruntime.GeneratedTemplate(func(templ_7745c5c3_Input templruntime.GeneratedComponentInput) (templ_7745c5c3_Err error) {
+		templ_7745c5c3_W, ctx := templ_7745c5c3_Input.Writer, templ_7745c5c3_Input.Context
+		if templ_7745c5c3_CtxErr := ctx.Err(); templ_7745c5c3_CtxErr != nil {
+			return templ_7745c5c3_CtxErr
+		}
+		templ_7745c5c3_Buffer, templ_7745c5c3_IsBuffer := templruntime.GetBuffer(templ_7745c5c3_W)
+		if !templ_7745c5c3_IsBuffer {
+			defer func() {
+				templ_7745c5c3_BufErr := templruntime.ReleaseBuffer(templ_7745c5c3_Buffer)
+				if templ_7745c5c3_Err == nil {
+					templ_7745c5c3_Err = templ_7745c5c3_BufErr
+				}
+			}()
+		}
+		ctx = templ.InitializeContext(ctx)
+		templ_7745c5c3_Var2 := templ.GetChildren(ctx)
+		if templ_7745c5c3_Var2 == nil {
+			templ_7745c5c3_Var2 = templ.NopComponent
+		}
+		ctx = templ.ClearChildren(ctx)
+		templ_7745c5c3_Err = editor("diff").Render(ctx, templ_7745c5c3_Buffer)
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		templ_7745c5c3_Err = monacodiff(original, modified, syntax).Render(ctx, templ_7745c5c3_Buffer)
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		return nil
+	})
+}
 
-            const check = () => {
-                if (typeof window["Editor"] !== 'undefined') {
-                    resolve(window.Editor)
-                    return
-                }
+// SealedEditor is the editor for client encrypted pastes, the content is decrypted in
+// the browser with the key found in the fragment of the url.
+func SealedEditor(reference, content, syntax string, readonly bool) templ.Component {
+	return templruntime.GeneratedTemplate(func(templ_7745c5c3_Input templruntime.GeneratedComponentInput) (templ_7745c5c3_Err error) {
+		templ_7745c5c3_W, ctx := templ_7745c5c3_Input.Writer, templ_7745c5c3_Input.Context
+		if templ_7745c5c3_CtxErr := ctx.Err(); templ_7745c5c3_CtxErr != nil {
+			return templ_7745c5c3_CtxErr
+		}
+		templ_7745c5c3_Buffer, templ_7745c5c3_IsBuffer := templruntime.GetBuffer(templ_7745c5c3_W)
+		if !templ_7745c5c3_IsBuffer {
+			defer func() {
+				templ_7745c5c3_BufErr := templruntime.ReleaseBuffer(templ_7745c5c3_Buffer)
+				if templ_7745c5c3_Err == nil {
+					templ_7745c5c3_Err = templ_7745c5c3_BufErr
+				}
+			}()
+		}
+		ctx = templ.InitializeContext(ctx)
+		templ_7745c5c3_Var3 := templ.GetChildren(ctx)
+		if templ_7745c5c3_Var3 == nil {
+			templ_7745c5c3_Var3 = templ.NopComponent
+		}
+		ctx = templ.ClearChildren(ctx)
+		templ_7745c5c3_Err = editor("").Render(ctx, templ_7745c5c3_Buffer)
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		templ_7745c5c3_Err = sealedmonaco(reference, content, syntax, readonly).Render(ctx, templ_7745c5c3_Buffer)
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		return nil
+	})
+}
 
-                if (Date.now() - start > timeout) {
-                    reject(new Error("timeout waiting for monaco editor"))
-                    return
-                }
+// SealedDiffEditor is the diff editor for revisions of client encrypted pastes.
+func SealedDiffEditor(reference, original, modified, syntax string) templ.Component {
+	return templruntime.GeneratedTemplate(func(templ_7745c5c3_Input templruntime.GeneratedComponentInput) (templ_7745c5c3_Err error) {
+		templ_7745c5c3_W, ctx := templ_7745c5c3_Input.Writer, templ_7745c5c3_Input.Context
+		if templ_7745c5c3_CtxErr := ctx.Err(); templ_7745c5c3_CtxErr != nil {
+			return templ_7745c5c3_CtxErr
+		}
+		templ_7745c5c3_Buffer, templ_7745c5c3_IsBuffer := templruntime.GetBuffer(templ_7745c5c3_W)
+		if !templ_7745c5c3_IsBuffer {
+			defer func() {
+				templ_7745c5c3_BufErr := templruntime.ReleaseBuffer(templ_7745c5c3_Buffer)
+				if templ_7745c5c3_Err == nil {
+					templ_7745c5c3_Err = templ_7745c5c3_BufErr
+				}
+			}()
+		}
+		ctx = templ.InitializeContext(ctx)
+		templ_7745c5c3_Var4 := templ.GetChildren(ctx)
+		if templ_7745c5c3_Var4 == nil {
+			templ_7745c5c3_Var4 = templ.NopComponent
+		}
+		ctx = templ.ClearChildren(ctx)
+		templ_7745c5c3_Err = editor("diff").Render(ctx, templ_7745c5c3_Buffer)
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		templ_7745c5c3_Err = sealedmonacodiff(reference, original, modified, syntax).Render(ctx, templ_7745c5c3_Buffer)
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		return nil
+	})
+}
 
-                setTimeout(check, 10)
-            }
+func editor(mode string) templ.Component {
+	return templruntime.GeneratedTemplate(func(templ_7745c5c3_Input templruntime.GeneratedComponentInput) (templ_7745c5c3_Err error) {
+		templ_7745c5c3_W, ctx := templ_7745c5c3_Input.Writer, templ_7745c5c3_Input.Context
+		if templ_7745c5c3_CtxErr := ctx.Err(); templ_7745c5c3_CtxErr != nil {
+			return templ_7745c5c3_CtxErr
+		}
+		templ_7745c5c3_Buffer, templ_7745c5c3_IsBuffer := templruntime.GetBuffer(templ_7745c5c3_W)
+		if !templ_7745c5c3_IsBuffer {
+			defer func() {
+				templ_7745c5c3_BufErr := templruntime.ReleaseBuffer(templ_7745c5c3_Buffer)
+				if templ_7745c5c3_Err == nil {
+					templ_7745c5c3_Err = templ_7745c5c3_BufErr
+				}
+			}()
+		}
+		ctx = templ.InitializeContext(ctx)
+		templ_7745c5c3_Var5 := templ.GetChildren(ctx)
+		if templ_7745c5c3_Var5 == nil {
+			templ_7745c5c3_Var5 = templ.NopComponent
+		}
+		ctx = templ.ClearChildren(ctx)
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 1, "<div class=\"h-full w-full flex-1\"><div id=\"container\" class=\"w-full h-full\" data-mode=\"")
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		var templ_7745c5c3_Var6 string
+		templ_7745c5c3_Var6, templ_7745c5c3_Err = templ.JoinStringErrs(mode)
+		if templ_7745c5c3_Err != nil {
+			return templ.Error{Err: templ_7745c5c3_Err, FileName: `internal/frontend/components/editor.templ`, Line: 28, Col: 60}
+		}
+		_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var6))
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 2, "\"></div></div><script src=\"/static/monaco/loader.js\"></script><script>\n        // resolves once the monaco editor has been initialized and exported as window.Editor\n        const waitForMonaco = () => new Promise(\n            (resolve, reject) => {\n                const start = Date.now()\n                const timeout = 30000\n\n                const check = () => {\n                    if (typeof window[\"Editor\"] !== 'undefined') {\n                        resolve(window.Editor)\n                        return\n                    }\n\n                    if (Date.now() - start > timeout) {\n                        reject(new Error(\"timeout waiting for monaco editor\"))\n                        return\n                    }\n\n                    setTimeout(check, 10)\n                }\n\n                check()\n            }\n        )\n\t</script><script type=\"module\">\n        import { getHighlighter } from 'https://esm.sh/shiki'\n        import { shikiToMonaco } from 'https://esm.sh/@shikijs/monaco'\n\n        const ayuLightTheme = {\n            name: \"ayu-light\",\n            type: \"light\",\n            colors: {\n                \"focusBorder\": \"#ffaa33b3\",\n                \"foreground\": \"#8a9199\",\n                \"widget.shadow\": \"#00000026\",\n                \"selection.background\": \"#035bd626\",\n                \"icon.foreground\": \"#8a9199\",\n                \"errorForeground\": \"#e65050\",\n                \"descriptionForeground\": \"#8a9199\",\n                \"textBlockQuote.background\": \"#f3f4f5\",\n                \"textLink.foreground\": \"#ffaa33\",\n                \"textLink.activeForeground\": \"#ffaa33\",\n                \"textPreformat.foreground\": \"#5c6166\",\n                \"button.background\": \"#ffaa33\",\n                \"button.foreground\": \"#f8f9fa\",\n                \"button.hoverBackground\": \"#f9a52e\",\n                \"button.secondaryBackground\": \"#8a919933\",\n                \"button.secondaryForeground\": \"#5c6166\",\n                \"button.secondaryHoverBackground\": \"#8a919980\",\n                \"dropdown.background\": \"#fcfcfc\",\n                \"dropdown.foreground\": \"#8a9199\",\n                \"dropdown.border\": \"#8a919945\",\n                \"input.background\": \"#fcfcfc\",\n                \"input.border\": \"#8a919945\",\n                \"input.foreground\": \"#5c6166\",\n                \"input.placeholderForeground\": \"#8a919980\",\n                \"inputOption.activeBorder\": \"#f4a0284d\",\n                \"inputOption.activeBackground\": \"#ffaa3333\",\n                \"inputOption.activeForeground\": \"#f4a028\",\n                \"inputValidation.errorBackground\": \"#fcfcfc\",\n                \"inputValidation.errorBorder\": \"#e65050\",\n                \"inputValidation.infoBackground\": \"#f8f9fa\",\n                \"inputValidation.infoBorder\": \"#55b4d4\",\n                \"inputValidation.warningBackground\": \"#f8f9fa\",\n                \"inputValidation.warningBorder\": \"#f2ae49\",\n                \"scrollbar.shadow\": \"#6b7d8f00\",\n                \"scrollbarSlider.background\": \"#8a919966\",\n                \"scrollbarSlider.hoverBackground\": \"#8a919999\",\n                \"scrollbarSlider.activeBackground\": \"#8a9199b3\",\n                \"badge.background\": \"#ffaa3333\",\n                \"badge.foreground\": \"#f4a028\",\n                \"progressBar.background\": \"#ffaa33\",\n                \"list.activeSelectionBackground\": \"#56728f1f\",\n                \"list.activeSelectionForeground\": \"#5c6166\",\n                \"list.focusBackground\": \"#56728f1f\",\n                \"list.focusForeground\": \"#5c6166\",\n                \"list.focusOutline\": \"#56728f1f\",\n                \"list.highlightForeground\": \"#ffaa33\",\n                \"list.deemphasizedForeground\": \"#e65050\",\n                \"list.hoverBackground\": \"#56728f1f\",\n                \"list.inactiveSelectionBackground\": \"#6b7d8f1f\",\n                \"list.inactiveSelectionForeground\": \"#8a9199\",\n                \"list.invalidItemForeground\": \"#8a91994d\",\n                \"list.errorForeground\": \"#e65050\",\n                \"tree.indentGuidesStroke\": \"#8a919959\",\n                \"listFilterWidget.background\": \"#f3f4f5\",\n                \"listFilterWidget.outline\": \"#ffaa33\",\n                \"listFilterWidget.noMatchesOutline\": \"#e65050\",\n                \"list.filterMatchBackground\": \"#8f30efcc\",\n                \"list.filterMatchBorder\": \"#9f40ffcc\",\n                \"activityBar.background\": \"#f8f9fa\",\n                \"activityBar.foreground\": \"#8a9199cc\",\n                \"activityBar.inactiveForeground\": \"#8a919999\",\n                \"activityBar.border\": \"#f8f9fa\",\n                \"activityBar.activeBorder\": \"#ffaa33b3\",\n                \"activityBarBadge.background\": \"#ffaa33\",\n                \"activityBarBadge.foreground\": \"#f8f9fa\",\n                \"sideBar.background\": \"#f8f9fa\",\n                \"sideBar.border\": \"#f8f9fa\",\n                \"sideBarTitle.foreground\": \"#8a9199\",\n                \"sideBarSectionHeader.background\": \"#f8f9fa\",\n                \"sideBarSectionHeader.foreground\": \"#8a9199\",\n                \"sideBarSectionHeader.border\": \"#f8f9fa\",\n                \"minimap.background\": \"#f8f9fa\",\n                \"minimap.selectionHighlight\": \"#035bd626\",\n                \"minimap.errorHighlight\": \"#e65050\",\n                \"minimap.findMatchHighlight\": \"#9f40ff2b\",\n                \"minimapGutter.addedBackground\": \"#6cbf43\",\n                \"minimapGutter.modifiedBackground\": \"#478acc\",\n                \"minimapGutter.deletedBackground\": \"#ff7383\",\n                \"editorGroup.border\": \"#6b7d8f1f\",\n                \"editorGroup.background\": \"#f3f4f5\",\n                \"editorGroupHeader.noTabsBackground\": \"#f8f9fa\",\n                \"editorGroupHeader.tabsBackground\": \"#f8f9fa\",\n                \"editorGroupHeader.tabsBorder\": \"#f8f9fa\",\n                \"tab.activeBackground\": \"#f8f9fa\",\n                \"tab.activeForeground\": \"#5c6166\",\n                \"tab.border\": \"#f8f9fa\",\n                \"tab.activeBorder\": \"#ffaa33\",\n                \"tab.unfocusedActiveBorder\": \"#8a9199\",\n                \"tab.inactiveBackground\": \"#f8f9fa\",\n                \"tab.inactiveForeground\": \"#8a9199\",\n                \"tab.unfocusedActiveForeground\": \"#8a9199\",\n                \"tab.unfocusedInactiveForeground\": \"#8a9199\",\n                \"editor.background\": \"#f8f9fa\",\n                \"editor.foreground\": \"#5c6166\",\n                \"editorLineNumber.foreground\": \"#8a919966\",\n                \"editorLineNumber.activeForeground\": \"#8a9199cc\",\n                \"editorCursor.foreground\": \"#ffaa33\",\n                \"editor.inactiveSelectionBackground\": \"#035bd612\",\n                \"editor.selectionBackground\": \"#035bd626\",\n                \"editor.selectionHighlightBackground\": \"#6cbf4326\",\n                \"editor.selectionHighlightBorder\": \"#6cbf4300\",\n                \"editor.wordHighlightBackground\": \"#478acc14\",\n                \"editor.wordHighlightStrongBackground\": \"#6cbf4314\",\n                \"editor.wordHighlightBorder\": \"#478acc80\",\n                \"editor.wordHighlightStrongBorder\": \"#6cbf4380\",\n                \"editor.findMatchBackground\": \"#9f40ff2b\",\n                \"editor.findMatchBorder\": \"#9f40ff2b\",\n                \"editor.findMatchHighlightBackground\": \"#9f40ffcc\",\n                \"editor.findMatchHighlightBorder\": \"#8f30efcc\",\n                \"editor.findRangeHighlightBackground\": \"#9f40ff40\",\n                \"editor.rangeHighlightBackground\": \"#9f40ff33\",\n                \"editor.lineHighlightBackground\": \"#8a91991a\",\n                \"editorLink.activeForeground\": \"#ffaa33\",\n                \"editorWhitespace.foreground\": \"#8a919966\",\n                \"editorIndentGuide.background\": \"#8a91992e\",\n                \"editorIndentGuide.activeBackground\": \"#8a919959\",\n                \"editorRuler.foreground\": \"#8a91992e\",\n                \"editorCodeLens.foreground\": \"#787b8099\",\n                \"editorBracketMatch.background\": \"#8a91994d\",\n                \"editorBracketMatch.border\": \"#8a91994d\",\n                \"editor.snippetTabstopHighlightBackground\": \"#6cbf4333\",\n                \"editorOverviewRuler.border\": \"#6b7d8f1f\",\n                \"editorOverviewRuler.modifiedForeground\": \"#478acc\",\n                \"editorOverviewRuler.addedForeground\": \"#6cbf43\",\n                \"editorOverviewRuler.deletedForeground\": \"#ff7383\",\n                \"editorOverviewRuler.errorForeground\": \"#e65050\",\n                \"editorOverviewRuler.warningForeground\": \"#ffaa33\",\n                \"editorOverviewRuler.bracketMatchForeground\": \"#8a9199b3\",\n                \"editorOverviewRuler.wordHighlightForeground\": \"#478acc66\",\n                \"editorOverviewRuler.wordHighlightStrongForeground\": \"#6cbf4366\",\n                \"editorOverviewRuler.findMatchForeground\": \"#9f40ff2b\",\n                \"editorError.foreground\": \"#e65050\",\n                \"editorWarning.foreground\": \"#ffaa33\",\n                \"editorGutter.modifiedBackground\": \"#478acccc\",\n                \"editorGutter.addedBackground\": \"#6cbf43cc\",\n                \"editorGutter.deletedBackground\": \"#ff7383cc\",\n                \"diffEditor.insertedTextBackground\": \"#6cbf431f\",\n                \"diffEditor.removedTextBackground\": \"#ff73831f\",\n                \"diffEditor.diagonalFill\": \"#6b7d8f1f\",\n                \"editorWidget.background\": \"#f3f4f5\",\n                \"editorWidget.border\": \"#6b7d8f1f\",\n                \"editorHoverWidget.background\": \"#f3f4f5\",\n                \"editorHoverWidget.border\": \"#6b7d8f1f\",\n                \"editorSuggestWidget.background\": \"#f3f4f5\",\n                \"editorSuggestWidget.border\": \"#6b7d8f1f\",\n                \"editorSuggestWidget.highlightForeground\": \"#ffaa33\",\n                \"editorSuggestWidget.selectedBackground\": \"#56728f1f\",\n                \"debugExceptionWidget.border\": \"#6b7d8f1f\",\n                \"debugExceptionWidget.background\": \"#f3f4f5\",\n                \"editorMarkerNavigation.background\": \"#f3f4f5\",\n                \"peekView.border\": \"#56728f1f\",\n                \"peekViewTitle.background\": \"#56728f1f\",\n                \"peekViewTitleDescription.foreground\": \"#8a9199\",\n                \"peekViewTitleLabel.foreground\": \"#5c6166\",\n                \"peekViewEditor.background\": \"#f3f4f5\",\n                \"peekViewEditor.matchHighlightBackground\": \"#9f40ffcc\",\n                \"peekViewEditor.matchHighlightBorder\": \"#8f30efcc\",\n                \"peekViewResult.background\": \"#f3f4f5\",\n                \"peekViewResult.fileForeground\": \"#5c6166\",\n                \"peekViewResult.lineForeground\": \"#8a9199\",\n                \"peekViewResult.matchHighlightBackground\": \"#9f40ffcc\",\n                \"peekViewResult.selectionBackground\": \"#56728f1f\",\n                \"panel.background\": \"#f8f9fa\",\n                \"panel.border\": \"#6b7d8f1f\",\n                \"panelTitle.activeBorder\": \"#ffaa33\",\n                \"panelTitle.activeForeground\": \"#5c6166\",\n                \"panelTitle.inactiveForeground\": \"#8a9199\",\n                \"statusBar.background\": \"#f8f9fa\",\n                \"statusBar.foreground\": \"#8a9199\",\n                \"statusBar.border\": \"#f8f9fa\",\n                \"statusBar.debuggingBackground\": \"#ed9366\",\n                \"statusBar.debuggingForeground\": \"#fcfcfc\",\n                \"statusBar.noFolderBackground\": \"#f3f4f5\",\n                \"statusBarItem.activeBackground\": \"#8a919933\",\n                \"statusBarItem.hoverBackground\": \"#8a919933\",\n                \"statusBarItem.prominentBackground\": \"#6b7d8f1f\",\n                \"statusBarItem.prominentHoverBackground\": \"#00000030\",\n                \"statusBarItem.remoteBackground\": \"#ffaa33\",\n                \"statusBarItem.remoteForeground\": \"#fcfcfc\",\n                \"titleBar.activeBackground\": \"#f8f9fa\",\n                \"titleBar.activeForeground\": \"#5c6166\",\n                \"titleBar.inactiveBackground\": \"#f8f9fa\",\n                \"titleBar.inactiveForeground\": \"#8a9199\",\n                \"titleBar.border\": \"#f8f9fa\",\n                \"extensionButton.prominentForeground\": \"#fcfcfc\",\n                \"extensionButton.prominentBackground\": \"#ffaa33\",\n                \"extensionButton.prominentHoverBackground\": \"#f9a52e\",\n                \"pickerGroup.border\": \"#6b7d8f1f\",\n                \"pickerGroup.foreground\": \"#8a919980\",\n                \"debugToolBar.background\": \"#f3f4f5\",\n                \"debugIcon.breakpointForeground\": \"#ed9366\",\n                \"debugIcon.breakpointDisabledForeground\": \"#ed936680\",\n                \"debugConsoleInputIcon.foreground\": \"#ffaa33\",\n                \"welcomePage.tileBackground\": \"#f8f9fa\",\n                \"welcomePage.tileShadow\": \"#00000026\",\n                \"welcomePage.progress.background\": \"#8a91991a\",\n                \"welcomePage.buttonBackground\": \"#ffaa3366\",\n                \"walkThrough.embeddedEditorBackground\": \"#f3f4f5\",\n                \"gitDecoration.modifiedResourceForeground\": \"#478accb3\",\n                \"gitDecoration.deletedResourceForeground\": \"#ff7383b3\",\n                \"gitDecoration.untrackedResourceForeground\": \"#6cbf43b3\",\n                \"gitDecoration.ignoredResourceForeground\": \"#8a919980\",\n                \"gitDecoration.conflictingResourceForeground\": \"\",\n                \"gitDecoration.submoduleResourceForeground\": \"#a37accb3\",\n                \"settings.headerForeground\": \"#5c6166\",\n                \"settings.modifiedItemIndicator\": \"#478acc\",\n                \"keybindingLabel.background\": \"#8a91991a\",\n                \"keybindingLabel.foreground\": \"#5c6166\",\n                \"keybindingLabel.border\": \"#5c61661a\",\n                \"keybindingLabel.bottomBorder\": \"#5c61661a\",\n                \"terminal.background\": \"#f8f9fa\",\n                \"terminal.foreground\": \"#5c6166\",\n                \"terminal.ansiBlack\": \"#000000\",\n                \"terminal.ansiRed\": \"#ea6c6d\",\n                \"terminal.ansiGreen\": \"#6cbf43\",\n                \"terminal.ansiYellow\": \"#eca944\",\n                \"terminal.ansiBlue\": \"#3199e1\",\n                \"terminal.ansiMagenta\": \"#9e75c7\",\n                \"terminal.ansiCyan\": \"#46ba94\",\n                \"terminal.ansiWhite\": \"#c7c7c7\",\n                \"terminal.ansiBrightBlack\": \"#686868\",\n                \"terminal.ansiBrightRed\": \"#f07171\",\n                \"terminal.ansiBrightGreen\": \"#86b300\",\n                \"terminal.ansiBrightYellow\": \"#f2ae49\",\n                \"terminal.ansiBrightBlue\": \"#399ee6\",\n                \"terminal.ansiBrightMagenta\": \"#a37acc\",\n                \"terminal.ansiBrightCyan\": \"#4cbf99\",\n                \"terminal.ansiBrightWhite\": \"#d1d1d1\"\n            },\n            tokenColors: [\n                {\n                    \"settings\": {\n                        \"background\": \"#f8f9fa\",\n                        \"foreground\": \"#5c6166\"\n                    }\n                },\n                {\n                    \"name\": \"Comment\",\n                    \"scope\": [\n                        \"comment\"\n                    ],\n                    \"settings\": {\n                        \"fontStyle\": \"italic\",\n                        \"foreground\": \"#787b8099\"\n                    }\n                },\n                {\n                    \"name\": \"String\",\n                    \"scope\": [\n                        \"string\",\n                        \"constant.other.symbol\"\n                    ],\n                    \"settings\": {\n                        \"foreground\": \"#86b300\"\n                    }\n                },\n                {\n                    \"name\": \"Regular Expressions and Escape Characters\",\n                    \"scope\": [\n                        \"string.regexp\",\n                        \"constant.character\",\n                        \"constant.other\"\n                    ],\n                    \"settings\": {\n                        \"foreground\": \"#4cbf99\"\n                    }\n                },\n                {\n                    \"name\": \"Number\",\n                    \"scope\": [\n                        \"constant.numeric\"\n                    ],\n                    \"settings\": {\n                        \"foreground\": \"#a37acc\"\n                    }\n                },\n                {\n                    \"name\": \"Built-in constants\",\n                    \"scope\": [\n                        \"constant.language\"\n                    ],\n                    \"settings\": {\n                        \"foreground\": \"#a37acc\"\n                    }\n                },\n                {\n                    \"name\": \"Variable\",\n                    \"scope\": [\n                        \"variable\",\n                        \"variable.parameter.function-call\"\n                    ],\n                    \"settings\": {\n                        \"foreground\": \"#5c6166\"\n                    }\n                },\n                {\n                    \"name\": \"Member Variable\",\n                    \"scope\": [\n                        \"variable.member\"\n                    ],\n                    \"settings\": {\n                        \"foreground\": \"#f07171\"\n                    }\n                },\n                {\n                    \"name\": \"Language variable\",\n                    \"scope\": [\n                        \"variable.language\"\n                    ],\n                    \"settings\": {\n                        \"fontStyle\": \"italic\",\n                        \"foreground\": \"#55b4d4\"\n                    }\n                },\n                {\n                    \"name\": \"Storage\",\n                    \"scope\": [\n                        \"storage\"\n                    ],\n                    \"settings\": {\n                        \"foreground\": \"#fa8d3e\"\n                    }\n                },\n                {\n                    \"name\": \"Keyword\",\n                    \"scope\": [\n                        \"keyword\"\n                    ],\n                    \"settings\": {\n                        \"foreground\": \"#fa8d3e\"\n                    }\n                },\n                {\n                    \"name\": \"Operators\",\n                    \"scope\": [\n                        \"keyword.operator\"\n                    ],\n                    \"settings\": {\n                        \"foreground\": \"#ed9366\"\n                    }\n                },\n                {\n                    \"name\": \"Separators like  or ,\",\n                    \"scope\": [\n                        \"punctuation.separator\",\n                        \"punctuation.terminator\"\n                    ],\n                    \"settings\": {\n                        \"foreground\": \"#5c6166b3\"\n                    }\n                },\n                {\n                    \"name\": \"Punctuation\",\n                    \"scope\": [\n                        \"punctuation.section\"\n                    ],\n                    \"settings\": {\n                        \"foreground\": \"#5c6166\"\n                    }\n                },\n                {\n                    \"name\": \"Accessor\",\n                    \"scope\": [\n                        \"punctuation.accessor\"\n                    ],\n                    \"settings\": {\n                        \"foreground\": \"#ed9366\"\n                    }\n                },\n                {\n                    \"name\": \"JavaScript/TypeScript interpolation punctuation\",\n                    \"scope\": [\n                        \"punctuation.definition.template-expression\"\n                    ],\n                    \"settings\": {\n                        \"foreground\": \"#fa8d3e\"\n                    }\n                },\n                {\n                    \"name\": \"Ruby interpolation punctuation\",\n                    \"scope\": [\n                        \"punctuation.section.embedded\"\n                    ],\n                    \"settings\": {\n                        \"foreground\": \"#fa8d3e\"\n                    }\n                },\n                {\n                    \"name\": \"Interpolation text\",\n                    \"scope\": [\n                        \"meta.embedded\"\n                    ],\n                    \"settings\": {\n                        \"foreground\": \"#5c6166\"\n                    }\n                },\n                {\n                    \"name\": \"Types fixes\",\n                    \"scope\": [\n                        \"source.java storage.type\",\n                        \"source.haskell storage.type\",\n                        \"source.c storage.type\"\n                    ],\n                    \"settings\": {\n                        \"foreground\": \"#399ee6\"\n                    }\n                },\n                {\n                    \"name\": \"Inherited class type\",\n                    \"scope\": [\n                        \"entity.other.inherited-class\"\n                    ],\n                    \"settings\": {\n                        \"foreground\": \"#55b4d4\"\n                    }\n                },\n                {\n                    \"name\": \"Lambda arrow\",\n                    \"scope\": [\n                        \"storage.type.function\"\n                    ],\n                    \"settings\": {\n                        \"foreground\": \"#fa8d3e\"\n                    }\n                },\n                {\n                    \"name\": \"Java primitive variable types\",\n                    \"scope\": [\n                        \"source.java storage.type.primitive\"\n                    ],\n                    \"settings\": {\n                        \"foreground\": \"#55b4d4\"\n                    }\n                },\n                {\n                    \"name\": \"Function name\",\n                    \"scope\": [\n                        \"entity.name.function\"\n                    ],\n                    \"settings\": {\n                        \"foreground\": \"#f2ae49\"\n                    }\n                },\n                {\n                    \"name\": \"Function arguments\",\n                    \"scope\": [\n                        \"variable.parameter\",\n                        \"meta.parameter\"\n                    ],\n                    \"settings\": {\n                        \"foreground\": \"#a37acc\"\n                    }\n                },\n                {\n                    \"name\": \"Function call\",\n                    \"scope\": [\n                        \"variable.function\",\n                        \"variable.annotation\",\n                        \"meta.function-call.generic\",\n                        \"support.function.go\"\n                    ],\n                    \"settings\": {\n                        \"foreground\": \"#f2ae49\"\n                    }\n                },\n                {\n                    \"name\": \"Library function\",\n                    \"scope\": [\n                        \"support.function\",\n                        \"support.macro\"\n                    ],\n                    \"settings\": {\n                        \"foreground\": \"#f07171\"\n                    }\n                },\n                {\n                    \"name\": \"Imports and packages\",\n                    \"scope\": [\n                        \"entity.name.import\",\n                        \"entity.name.package\"\n                    ],\n                    \"settings\": {\n                        \"foreground\": \"#86b300\"\n                    }\n                },\n                {\n                    \"name\": \"Entity name\",\n                    \"scope\": [\n                        \"entity.name\"\n                    ],\n                    \"settings\": {\n                        \"foreground\": \"#399ee6\"\n                    }\n                },\n                {\n                    \"name\": \"Tag\",\n                    \"scope\": [\n                        \"entity.name.tag\",\n                        \"meta.tag.sgml\"\n                    ],\n                    \"settings\": {\n                        \"foreground\": \"#55b4d4\"\n                    }\n                },\n                {\n                    \"name\": \"JSX Component\",\n                    \"scope\": [\n                        \"support.class.component\"\n                    ],\n                    \"settings\": {\n                        \"foreground\": \"#399ee6\"\n                    }\n                },\n                {\n                    \"name\": \"Tag start/end\",\n                    \"scope\": [\n                        \"punctuation.definition.tag.end\",\n                        \"punctuation.definition.tag.begin\",\n                        \"punctuation.definition.tag\"\n                    ],\n                    \"settings\": {\n                        \"foreground\": \"#55b4d480\"\n                    }\n                },\n                {\n                    \"name\": \"Tag attribute\",\n                    \"scope\": [\n                        \"entity.other.attribute-name\"\n                    ],\n                    \"settings\": {\n                        \"foreground\": \"#f2ae49\"\n                    }\n                },\n                {\n                    \"name\": \"Library constant\",\n                    \"scope\": [\n                        \"support.constant\"\n                    ],\n                    \"settings\": {\n                        \"fontStyle\": \"italic\",\n                        \"foreground\": \"#ed9366\"\n                    }\n                },\n                {\n                    \"name\": \"Library class/type\",\n                    \"scope\": [\n                        \"support.type\",\n                        \"support.class\",\n                        \"source.go storage.type\"\n                    ],\n                    \"settings\": {\n                        \"foreground\": \"#55b4d4\"\n                    }\n                },\n                {\n                    \"name\": \"Decorators/annotation\",\n                    \"scope\": [\n                        \"meta.decorator variable.other\",\n                        \"meta.decorator punctuation.decorator\",\n                        \"storage.type.annotation\"\n                    ],\n                    \"settings\": {\n                        \"foreground\": \"#e6ba7e\"\n                    }\n                },\n                {\n                    \"name\": \"Invalid\",\n                    \"scope\": [\n                        \"invalid\"\n                    ],\n                    \"settings\": {\n                        \"foreground\": \"#e65050\"\n                    }\n                },\n                {\n                    \"name\": \"diff.header\",\n                    \"scope\": [\n                        \"meta.diff\",\n                        \"meta.diff.header\"\n                    ],\n                    \"settings\": {\n                        \"foreground\": \"#c594c5\"\n                    }\n                },\n                {\n                    \"name\": \"Ruby class methods\",\n                    \"scope\": [\n                        \"source.ruby variable.other.readwrite\"\n                    ],\n                    \"settings\": {\n                        \"foreground\": \"#f2ae49\"\n                    }\n                },\n                {\n                    \"name\": \"CSS tag names\",\n                    \"scope\": [\n                        \"source.css entity.name.tag\",\n                        \"source.sass entity.name.tag\",\n                        \"source.scss entity.name.tag\",\n                        \"source.less entity.name.tag\",\n                        \"source.stylus entity.name.tag\"\n                    ],\n                    \"settings\": {\n                        \"foreground\": \"#399ee6\"\n                    }\n                },\n                {\n                    \"name\": \"CSS browser prefix\",\n                    \"scope\": [\n                        \"source.css support.type\",\n                        \"source.sass support.type\",\n                        \"source.scss support.type\",\n                        \"source.less support.type\",\n                        \"source.stylus support.type\"\n                    ],\n                    \"settings\": {\n                        \"foreground\": \"#787b8099\"\n                    }\n                },\n                {\n                    \"name\": \"CSS Properties\",\n                    \"scope\": [\n                        \"support.type.property-name\"\n                    ],\n                    \"settings\": {\n                        \"fontStyle\": \"normal\",\n                        \"foreground\": \"#55b4d4\"\n                    }\n                },\n                {\n                    \"name\": \"Search Results Numbers\",\n                    \"scope\": [\n                        \"constant.numeric.line-number.find-in-files - match\"\n                    ],\n                    \"settings\": {\n                        \"foreground\": \"#787b8099\"\n                    }\n                },\n                {\n                    \"name\": \"Search Results Match Numbers\",\n                    \"scope\": [\n                        \"constant.numeric.line-number.match\"\n                    ],\n                    \"settings\": {\n                        \"foreground\": \"#fa8d3e\"\n                    }\n                },\n                {\n                    \"name\": \"Search Results Lines\",\n                    \"scope\": [\n                        \"entity.name.filename.find-in-files\"\n                    ],\n                    \"settings\": {\n                        \"foreground\": \"#86b300\"\n                    }\n                },\n                {\n                    \"scope\": [\n                        \"message.error\"\n                    ],\n                    \"settings\": {\n                        \"foreground\": \"#e65050\"\n                    }\n                },\n                {\n                    \"name\": \"Markup heading\",\n                    \"scope\": [\n                        \"markup.heading\",\n                        \"markup.heading entity.name\"\n                    ],\n                    \"settings\": {\n                        \"fontStyle\": \"bold\",\n                        \"foreground\": \"#86b300\"\n                    }\n                },\n                {\n                    \"name\": \"Markup links\",\n                    \"scope\": [\n                        \"markup.underline.link\",\n                        \"string.other.link\"\n                    ],\n                    \"settings\": {\n                        \"foreground\": \"#55b4d4\"\n                    }\n                },\n                {\n                    \"name\": \"Markup Italic\",\n                    \"scope\": [\n                        \"markup.italic\"\n                    ],\n                    \"settings\": {\n                        \"fontStyle\": \"italic\",\n                        \"foreground\": \"#f07171\"\n                    }\n                },\n                {\n                    \"name\": \"Markup Bold\",\n                    \"scope\": [\n                        \"markup.bold\"\n                    ],\n                    \"settings\": {\n                        \"fontStyle\": \"bold\",\n                        \"foreground\": \"#f07171\"\n                    }\n                },\n                {\n                    \"name\": \"Markup Bold/italic\",\n                    \"scope\": [\n                        \"markup.italic markup.bold\",\n                        \"markup.bold markup.italic\"\n                    ],\n                    \"settings\": {\n                        \"fontStyle\": \"bold italic\"\n                    }\n                },\n                {\n                    \"name\": \"Markup Code\",\n                    \"scope\": [\n                        \"markup.raw\"\n                    ],\n                    \"settings\": {\n                        \"background\": \"#5c616605\"\n                    }\n                },\n                {\n                    \"name\": \"Markup Code Inline\",\n                    \"scope\": [\n                        \"markup.raw.inline\"\n                    ],\n                    \"settings\": {\n                        \"background\": \"#5c61660f\"\n                    }\n                },\n                {\n                    \"name\": \"Markdown Separator\",\n                    \"scope\": [\n                        \"meta.separator\"\n                    ],\n                    \"settings\": {\n                        \"fontStyle\": \"bold\",\n                        \"background\": \"#5c61660f\",\n                        \"foreground\": \"#787b8099\"\n                    }\n                },\n                {\n                    \"name\": \"Markup Blockquote\",\n                    \"scope\": [\n                        \"markup.quote\"\n                    ],\n                    \"settings\": {\n                        \"foreground\": \"#4cbf99\",\n                        \"fontStyle\": \"italic\"\n                    }\n                },\n                {\n                    \"name\": \"Markup List Bullet\",\n                    \"scope\": [\n                        \"markup.list punctuation.definition.list.begin\"\n                    ],\n                    \"settings\": {\n                        \"foreground\": \"#f2ae49\"\n                    }\n                },\n                {\n                    \"name\": \"Markup added\",\n                    \"scope\": [\n                        \"markup.inserted\"\n                    ],\n                    \"settings\": {\n                        \"foreground\": \"#6cbf43\"\n                    }\n                },\n                {\n                    \"name\": \"Markup modified\",\n                    \"scope\": [\n                        \"markup.changed\"\n                    ],\n                    \"settings\": {\n                        \"foreground\": \"#478acc\"\n                    }\n                },\n                {\n                    \"name\": \"Markup removed\",\n                    \"scope\": [\n                        \"markup.deleted\"\n                    ],\n                    \"settings\": {\n                        \"foreground\": \"#ff7383\"\n                    }\n                },\n                {\n                    \"name\": \"Markup Strike\",\n                    \"scope\": [\n                        \"markup.strike\"\n                    ],\n                    \"settings\": {\n                        \"foreground\": \"#e6ba7e\"\n                    }\n                },\n                {\n                    \"name\": \"Markup Table\",\n                    \"scope\": [\n                        \"markup.table\"\n                    ],\n                    \"settings\": {\n                        \"background\": \"#5c61660f\",\n                        \"foreground\": \"#55b4d4\"\n                    }\n                },\n                {\n                    \"name\": \"Markup Raw Inline\",\n                    \"scope\": [\n                        \"text.html.markdown markup.inline.raw\"\n                    ],\n                    \"settings\": {\n                        \"foreground\": \"#ed9366\"\n                    }\n                },\n                {\n                    \"name\": \"Markdown - Line Break\",\n                    \"scope\": [\n                        \"text.html.markdown meta.dummy.line-break\"\n                    ],\n                    \"settings\": {\n                        \"background\": \"#787b8099\",\n                        \"foreground\": \"#787b8099\"\n                    }\n                },\n                {\n                    \"name\": \"Markdown - Raw Block Fenced\",\n                    \"scope\": [\n                        \"punctuation.definition.markdown\"\n                    ],\n                    \"settings\": {\n                        \"background\": \"#5c6166\",\n                        \"foreground\": \"#787b8099\"\n                    }\n                }\n            ],\n            semanticHighlighting: true,\n            semanticTokenColors: {\n                \"parameter.label\": \"#5c6166\"\n            }\n        }\n\n        const ayuDarkTheme = {\n            name: \"ayu-dark\",\n            type: \"dark\",\n            colors: {\n                \"focusBorder\": \"#ffcc66b3\",\n                \"foreground\": \"#707a8c\",\n                \"widget.shadow\": \"#12151cb3\",\n                \"selection.background\": \"#409fff40\",\n                \"icon.foreground\": \"#707a8c\",\n                \"errorForeground\": \"#ff6666\",\n                \"descriptionForeground\": \"#707a8c\",\n                \"textBlockQuote.background\": \"#1c212b\",\n                \"textLink.foreground\": \"#ffcc66\",\n                \"textLink.activeForeground\": \"#ffcc66\",\n                \"textPreformat.foreground\": \"#cccac2\",\n                \"button.background\": \"#ffcc66\",\n                \"button.foreground\": \"#1f2430\",\n                \"button.hoverBackground\": \"#fac761\",\n                \"button.secondaryBackground\": \"#707a8c33\",\n                \"button.secondaryForeground\": \"#cccac2\",\n                \"button.secondaryHoverBackground\": \"#707a8c80\",\n                \"dropdown.background\": \"#242936\",\n                \"dropdown.foreground\": \"#707a8c\",\n                \"dropdown.border\": \"#707a8c45\",\n                \"input.background\": \"#242936\",\n                \"input.border\": \"#707a8c45\",\n                \"input.foreground\": \"#cccac2\",\n                \"input.placeholderForeground\": \"#707a8c80\",\n                \"inputOption.activeBorder\": \"#ffcc664d\",\n                \"inputOption.activeBackground\": \"#ffcc6633\",\n                \"inputOption.activeForeground\": \"#ffcc66\",\n                \"inputValidation.errorBackground\": \"#242936\",\n                \"inputValidation.errorBorder\": \"#ff6666\",\n                \"inputValidation.infoBackground\": \"#1f2430\",\n                \"inputValidation.infoBorder\": \"#5ccfe6\",\n                \"inputValidation.warningBackground\": \"#1f2430\",\n                \"inputValidation.warningBorder\": \"#ffd173\",\n                \"scrollbar.shadow\": \"#171b2400\",\n                \"scrollbarSlider.background\": \"#707a8c66\",\n                \"scrollbarSlider.hoverBackground\": \"#707a8c99\",\n                \"scrollbarSlider.activeBackground\": \"#707a8cb3\",\n                \"badge.background\": \"#ffcc6633\",\n                \"badge.foreground\": \"#ffcc66\",\n                \"progressBar.background\": \"#ffcc66\",\n                \"list.activeSelectionBackground\": \"#63759926\",\n                \"list.activeSelectionForeground\": \"#cccac2\",\n                \"list.focusBackground\": \"#63759926\",\n                \"list.focusForeground\": \"#cccac2\",\n                \"list.focusOutline\": \"#63759926\",\n                \"list.highlightForeground\": \"#ffcc66\",\n                \"list.deemphasizedForeground\": \"#ff6666\",\n                \"list.hoverBackground\": \"#63759926\",\n                \"list.inactiveSelectionBackground\": \"#69758c1f\",\n                \"list.inactiveSelectionForeground\": \"#707a8c\",\n                \"list.invalidItemForeground\": \"#707a8c4d\",\n                \"list.errorForeground\": \"#ff6666\",\n                \"tree.indentGuidesStroke\": \"#8a919959\",\n                \"listFilterWidget.background\": \"#1c212b\",\n                \"listFilterWidget.outline\": \"#ffcc66\",\n                \"listFilterWidget.noMatchesOutline\": \"#ff6666\",\n                \"list.filterMatchBackground\": \"#5c467266\",\n                \"list.filterMatchBorder\": \"#69538066\",\n                \"activityBar.background\": \"#1f2430\",\n                \"activityBar.foreground\": \"#707a8ccc\",\n                \"activityBar.inactiveForeground\": \"#707a8c99\",\n                \"activityBar.border\": \"#1f2430\",\n                \"activityBar.activeBorder\": \"#ffcc66b3\",\n                \"activityBarBadge.background\": \"#ffcc66\",\n                \"activityBarBadge.foreground\": \"#1f2430\",\n                \"sideBar.background\": \"#1f2430\",\n                \"sideBar.border\": \"#1f2430\",\n                \"sideBarTitle.foreground\": \"#707a8c\",\n                \"sideBarSectionHeader.background\": \"#1f2430\",\n                \"sideBarSectionHeader.foreground\": \"#707a8c\",\n                \"sideBarSectionHeader.border\": \"#1f2430\",\n                \"minimap.background\": \"#1f2430\",\n                \"minimap.selectionHighlight\": \"#409fff40\",\n                \"minimap.errorHighlight\": \"#ff6666\",\n                \"minimap.findMatchHighlight\": \"#695380\",\n                \"minimapGutter.addedBackground\": \"#87d96c\",\n                \"minimapGutter.modifiedBackground\": \"#80bfff\",\n                \"minimapGutter.deletedBackground\": \"#f27983\",\n                \"editorGroup.border\": \"#171b24\",\n                \"editorGroup.background\": \"#1c212b\",\n                \"editorGroupHeader.noTabsBackground\": \"#1f2430\",\n                \"editorGroupHeader.tabsBackground\": \"#1f2430\",\n                \"editorGroupHeader.tabsBorder\": \"#1f2430\",\n                \"tab.activeBackground\": \"#1f2430\",\n                \"tab.activeForeground\": \"#cccac2\",\n                \"tab.border\": \"#1f2430\",\n                \"tab.activeBorder\": \"#ffcc66\",\n                \"tab.unfocusedActiveBorder\": \"#707a8c\",\n                \"tab.inactiveBackground\": \"#1f2430\",\n                \"tab.inactiveForeground\": \"#707a8c\",\n                \"tab.unfocusedActiveForeground\": \"#707a8c\",\n                \"tab.unfocusedInactiveForeground\": \"#707a8c\",\n                \"editor.background\": \"#1f2430\",\n                \"editor.foreground\": \"#cccac2\",\n                \"editorLineNumber.foreground\": \"#8a919966\",\n                \"editorLineNumber.activeForeground\": \"#8a9199cc\",\n                \"editorCursor.foreground\": \"#ffcc66\",\n                \"editor.inactiveSelectionBackground\": \"#409fff21\",\n                \"editor.selectionBackground\": \"#409fff40\",\n                \"editor.selectionHighlightBackground\": \"#87d96c26\",\n                \"editor.selectionHighlightBorder\": \"#87d96c00\",\n                \"editor.wordHighlightBackground\": \"#80bfff14\",\n                \"editor.wordHighlightStrongBackground\": \"#87d96c14\",\n                \"editor.wordHighlightBorder\": \"#80bfff80\",\n                \"editor.wordHighlightStrongBorder\": \"#87d96c80\",\n                \"editor.findMatchBackground\": \"#695380\",\n                \"editor.findMatchBorder\": \"#695380\",\n                \"editor.findMatchHighlightBackground\": \"#69538066\",\n                \"editor.findMatchHighlightBorder\": \"#5c467266\",\n                \"editor.findRangeHighlightBackground\": \"#69538040\",\n                \"editor.rangeHighlightBackground\": \"#69538033\",\n                \"editor.lineHighlightBackground\": \"#1a1f29\",\n                \"editorLink.activeForeground\": \"#ffcc66\",\n                \"editorWhitespace.foreground\": \"#8a919966\",\n                \"editorIndentGuide.background\": \"#8a91992e\",\n                \"editorIndentGuide.activeBackground\": \"#8a919959\",\n                \"editorRuler.foreground\": \"#8a91992e\",\n                \"editorCodeLens.foreground\": \"#b8cfe680\",\n                \"editorBracketMatch.background\": \"#8a91994d\",\n                \"editorBracketMatch.border\": \"#8a91994d\",\n                \"editor.snippetTabstopHighlightBackground\": \"#87d96c33\",\n                \"editorOverviewRuler.border\": \"#171b24\",\n                \"editorOverviewRuler.modifiedForeground\": \"#80bfff\",\n                \"editorOverviewRuler.addedForeground\": \"#87d96c\",\n                \"editorOverviewRuler.deletedForeground\": \"#f27983\",\n                \"editorOverviewRuler.errorForeground\": \"#ff6666\",\n                \"editorOverviewRuler.warningForeground\": \"#ffcc66\",\n                \"editorOverviewRuler.bracketMatchForeground\": \"#8a9199b3\",\n                \"editorOverviewRuler.wordHighlightForeground\": \"#80bfff66\",\n                \"editorOverviewRuler.wordHighlightStrongForeground\": \"#87d96c66\",\n                \"editorOverviewRuler.findMatchForeground\": \"#695380\",\n                \"editorError.foreground\": \"#ff6666\",\n                \"editorWarning.foreground\": \"#ffcc66\",\n                \"editorGutter.modifiedBackground\": \"#80bfffcc\",\n                \"editorGutter.addedBackground\": \"#87d96ccc\",\n                \"editorGutter.deletedBackground\": \"#f27983cc\",\n                \"diffEditor.insertedTextBackground\": \"#87d96c1f\",\n                \"diffEditor.removedTextBackground\": \"#f279831f\",\n                \"diffEditor.diagonalFill\": \"#171b24\",\n                \"editorWidget.background\": \"#1c212b\",\n                \"editorWidget.border\": \"#171b24\",\n                \"editorHoverWidget.background\": \"#1c212b\",\n                \"editorHoverWidget.border\": \"#171b24\",\n                \"editorSuggestWidget.background\": \"#1c212b\",\n                \"editorSuggestWidget.border\": \"#171b24\",\n                \"editorSuggestWidget.highlightForeground\": \"#ffcc66\",\n                \"editorSuggestWidget.selectedBackground\": \"#63759926\",\n                \"debugExceptionWidget.border\": \"#171b24\",\n                \"debugExceptionWidget.background\": \"#1c212b\",\n                \"editorMarkerNavigation.background\": \"#1c212b\",\n                \"peekView.border\": \"#63759926\",\n                \"peekViewTitle.background\": \"#63759926\",\n                \"peekViewTitleDescription.foreground\": \"#707a8c\",\n                \"peekViewTitleLabel.foreground\": \"#cccac2\",\n                \"peekViewEditor.background\": \"#1c212b\",\n                \"peekViewEditor.matchHighlightBackground\": \"#69538066\",\n                \"peekViewEditor.matchHighlightBorder\": \"#5c467266\",\n                \"peekViewResult.background\": \"#1c212b\",\n                \"peekViewResult.fileForeground\": \"#cccac2\",\n                \"peekViewResult.lineForeground\": \"#707a8c\",\n                \"peekViewResult.matchHighlightBackground\": \"#69538066\",\n                \"peekViewResult.selectionBackground\": \"#63759926\",\n                \"panel.background\": \"#1f2430\",\n                \"panel.border\": \"#171b24\",\n                \"panelTitle.activeBorder\": \"#ffcc66\",\n                \"panelTitle.activeForeground\": \"#cccac2\",\n                \"panelTitle.inactiveForeground\": \"#707a8c\",\n                \"statusBar.background\": \"#1f2430\",\n                \"statusBar.foreground\": \"#707a8c\",\n                \"statusBar.border\": \"#1f2430\",\n                \"statusBar.debuggingBackground\": \"#f29e74\",\n                \"statusBar.debuggingForeground\": \"#242936\",\n                \"statusBar.noFolderBackground\": \"#1c212b\",\n                \"statusBarItem.activeBackground\": \"#707a8c33\",\n                \"statusBarItem.hoverBackground\": \"#707a8c33\",\n                \"statusBarItem.prominentBackground\": \"#171b24\",\n                \"statusBarItem.prominentHoverBackground\": \"#00000030\",\n                \"statusBarItem.remoteBackground\": \"#ffcc66\",\n                \"statusBarItem.remoteForeground\": \"#242936\",\n                \"titleBar.activeBackground\": \"#1f2430\",\n                \"titleBar.activeForeground\": \"#cccac2\",\n                \"titleBar.inactiveBackground\": \"#1f2430\",\n                \"titleBar.inactiveForeground\": \"#707a8c\",\n                \"titleBar.border\": \"#1f2430\",\n                \"extensionButton.prominentForeground\": \"#242936\",\n                \"extensionButton.prominentBackground\": \"#ffcc66\",\n                \"extensionButton.prominentHoverBackground\": \"#fac761\",\n                \"pickerGroup.border\": \"#171b24\",\n                \"pickerGroup.foreground\": \"#707a8c80\",\n                \"debugToolBar.background\": \"#1c212b\",\n                \"debugIcon.breakpointForeground\": \"#f29e74\",\n                \"debugIcon.breakpointDisabledForeground\": \"#f29e7480\",\n                \"debugConsoleInputIcon.foreground\": \"#ffcc66\",\n                \"welcomePage.tileBackground\": \"#1f2430\",\n                \"welcomePage.tileShadow\": \"#12151cb3\",\n                \"welcomePage.progress.background\": \"#1a1f29\",\n                \"welcomePage.buttonBackground\": \"#ffcc6666\",\n                \"walkThrough.embeddedEditorBackground\": \"#1c212b\",\n                \"gitDecoration.modifiedResourceForeground\": \"#80bfffb3\",\n                \"gitDecoration.deletedResourceForeground\": \"#f27983b3\",\n                \"gitDecoration.untrackedResourceForeground\": \"#87d96cb3\",\n                \"gitDecoration.ignoredResourceForeground\": \"#707a8c80\",\n                \"gitDecoration.conflictingResourceForeground\": \"\",\n                \"gitDecoration.submoduleResourceForeground\": \"#dfbfffb3\",\n                \"settings.headerForeground\": \"#cccac2\",\n                \"settings.modifiedItemIndicator\": \"#80bfff\",\n                \"keybindingLabel.background\": \"#707a8c1a\",\n                \"keybindingLabel.foreground\": \"#cccac2\",\n                \"keybindingLabel.border\": \"#cccac21a\",\n                \"keybindingLabel.bottomBorder\": \"#cccac21a\",\n                \"terminal.background\": \"#1f2430\",\n                \"terminal.foreground\": \"#cccac2\",\n                \"terminal.ansiBlack\": \"#171b24\",\n                \"terminal.ansiRed\": \"#ed8274\",\n                \"terminal.ansiGreen\": \"#87d96c\",\n                \"terminal.ansiYellow\": \"#facc6e\",\n                \"terminal.ansiBlue\": \"#6dcbfa\",\n                \"terminal.ansiMagenta\": \"#dabafa\",\n                \"terminal.ansiCyan\": \"#90e1c6\",\n                \"terminal.ansiWhite\": \"#c7c7c7\",\n                \"terminal.ansiBrightBlack\": \"#686868\",\n                \"terminal.ansiBrightRed\": \"#f28779\",\n                \"terminal.ansiBrightGreen\": \"#d5ff80\",\n                \"terminal.ansiBrightYellow\": \"#ffd173\",\n                \"terminal.ansiBrightBlue\": \"#73d0ff\",\n                \"terminal.ansiBrightMagenta\": \"#dfbfff\",\n                \"terminal.ansiBrightCyan\": \"#95e6cb\",\n                \"terminal.ansiBrightWhite\": \"#ffffff\"\n            },\n            tokenColors: [\n                {\n                    \"settings\": {\n                        \"background\": \"#1f2430\",\n                        \"foreground\": \"#cccac2\"\n                    }\n                },\n                {\n                    \"name\": \"Comment\",\n                    \"scope\": [\n                        \"comment\"\n                    ],\n                    \"settings\": {\n                        \"fontStyle\": \"italic\",\n                        \"foreground\": \"#b8cfe680\"\n                    }\n                },\n                {\n                    \"name\": \"String\",\n                    \"scope\": [\n                        \"string\",\n                        \"constant.other.symbol\"\n                    ],\n                    \"settings\": {\n                        \"foreground\": \"#d5ff80\"\n                    }\n                },\n                {\n                    \"name\": \"Regular Expressions and Escape Characters\",\n                    \"scope\": [\n                        \"string.regexp\",\n                        \"constant.character\",\n                        \"constant.other\"\n                    ],\n                    \"settings\": {\n                        \"foreground\": \"#95e6cb\"\n                    }\n                },\n                {\n                    \"name\": \"Number\",\n                    \"scope\": [\n                        \"constant.numeric\"\n                    ],\n                    \"settings\": {\n                        \"foreground\": \"#dfbfff\"\n                    }\n                },\n                {\n                    \"name\": \"Built-in constants\",\n                    \"scope\": [\n                        \"constant.language\"\n                    ],\n                    \"settings\": {\n                        \"foreground\": \"#dfbfff\"\n                    }\n                },\n                {\n                    \"name\": \"Variable\",\n                    \"scope\": [\n                        \"variable\",\n                        \"variable.parameter.function-call\"\n                    ],\n                    \"settings\": {\n                        \"foreground\": \"#cccac2\"\n                    }\n                },\n                {\n                    \"name\": \"Member Variable\",\n                    \"scope\": [\n                        \"variable.member\"\n                    ],\n                    \"settings\": {\n                        \"foreground\": \"#f28779\"\n                    }\n                },\n                {\n                    \"name\": \"Language variable\",\n                    \"scope\": [\n                        \"variable.language\"\n                    ],\n                    \"settings\": {\n                        \"fontStyle\": \"italic\",\n                        \"foreground\": \"#5ccfe6\"\n                    }\n                },\n                {\n                    \"name\": \"Storage\",\n                    \"scope\": [\n                        \"storage\"\n                    ],\n                    \"settings\": {\n                        \"foreground\": \"#ffad66\"\n                    }\n                },\n                {\n                    \"name\": \"Keyword\",\n                    \"scope\": [\n                        \"keyword\"\n                    ],\n                    \"settings\": {\n                        \"foreground\": \"#ffad66\"\n                    }\n                },\n                {\n                    \"name\": \"Operators\",\n                    \"scope\": [\n                        \"keyword.operator\"\n                    ],\n                    \"settings\": {\n                        \"foreground\": \"#f29e74\"\n                    }\n                },\n                {\n                    \"name\": \"Separators like  or ,\",\n                    \"scope\": [\n                        \"punctuation.separator\",\n                        \"punctuation.terminator\"\n                    ],\n                    \"settings\": {\n                        \"foreground\": \"#cccac2b3\"\n                    }\n                },\n                {\n                    \"name\": \"Punctuation\",\n                    \"scope\": [\n                        \"punctuation.section\"\n                    ],\n                    \"settings\": {\n                        \"foreground\": \"#cccac2\"\n                    }\n                },\n                {\n                    \"name\": \"Accessor\",\n                    \"scope\": [\n                        \"punctuation.accessor\"\n                    ],\n                    \"settings\": {\n                        \"foreground\": \"#f29e74\"\n                    }\n                },\n                {\n                    \"name\": \"JavaScript/TypeScript interpolation punctuation\",\n                    \"scope\": [\n                        \"punctuation.definition.template-expression\"\n                    ],\n                    \"settings\": {\n                        \"foreground\": \"#ffad66\"\n                    }\n                },\n                {\n                    \"name\": \"Ruby interpolation punctuation\",\n                    \"scope\": [\n                        \"punctuation.section.embedded\"\n                    ],\n                    \"settings\": {\n                        \"foreground\": \"#ffad66\"\n                    }\n                },\n                {\n                    \"name\": \"Interpolation text\",\n                    \"scope\": [\n                        \"meta.embedded\"\n                    ],\n                    \"settings\": {\n                        \"foreground\": \"#cccac2\"\n                    }\n                },\n                {\n                    \"name\": \"Types fixes\",\n                    \"scope\": [\n                        \"source.java storage.type\",\n                        \"source.haskell storage.type\",\n                        \"source.c storage.type\"\n                    ],\n                    \"settings\": {\n                        \"foreground\": \"#73d0ff\"\n                    }\n                },\n                {\n                    \"name\": \"Inherited class type\",\n                    \"scope\": [\n                        \"entity.other.inherited-class\"\n                    ],\n                    \"settings\": {\n                        \"foreground\": \"#5ccfe6\"\n                    }\n                },\n                {\n                    \"name\": \"Lambda arrow\",\n                    \"scope\": [\n                        \"storage.type.function\"\n                    ],\n                    \"settings\": {\n                        \"foreground\": \"#ffad66\"\n                    }\n                },\n                {\n                    \"name\": \"Java primitive variable types\",\n                    \"scope\": [\n                        \"source.java storage.type.primitive\"\n                    ],\n                    \"settings\": {\n                        \"foreground\": \"#5ccfe6\"\n                    }\n                },\n                {\n                    \"name\": \"Function name\",\n                    \"scope\": [\n                        \"entity.name.function\"\n                    ],\n                    \"settings\": {\n                        \"foreground\": \"#ffd173\"\n                    }\n                },\n                {\n                    \"name\": \"Function arguments\",\n                    \"scope\": [\n                        \"variable.parameter\",\n                        \"meta.parameter\"\n                    ],\n                    \"settings\": {\n                        \"foreground\": \"#dfbfff\"\n                    }\n                },\n                {\n                    \"name\": \"Function call\",\n                    \"scope\": [\n                        \"variable.function\",\n                        \"variable.annotation\",\n                        \"meta.function-call.generic\",\n                        \"support.function.go\"\n                    ],\n                    \"settings\": {\n                        \"foreground\": \"#ffd173\"\n                    }\n                },\n                {\n                    \"name\": \"Library function\",\n                    \"scope\": [\n                        \"support.function\",\n                        \"support.macro\"\n                    ],\n                    \"settings\": {\n                        \"foreground\": \"#f28779\"\n                    }\n                },\n                {\n                    \"name\": \"Imports and packages\",\n                    \"scope\": [\n                        \"entity.name.import\",\n                        \"entity.name.package\"\n                    ],\n                    \"settings\": {\n                        \"foreground\": \"#d5ff80\"\n                    }\n                },\n                {\n                    \"name\": \"Entity name\",\n                    \"scope\": [\n                        \"entity.name\"\n                    ],\n                    \"settings\": {\n                        \"foreground\": \"#73d0ff\"\n                    }\n                },\n                {\n                    \"name\": \"Tag\",\n                    \"scope\": [\n                        \"entity.name.tag\",\n                        \"meta.tag.sgml\"\n                    ],\n                    \"settings\": {\n                        \"foreground\": \"#5ccfe6\"\n                    }\n                },\n                {\n                    \"name\": \"JSX Component\",\n                    \"scope\": [\n                        \"support.class.component\"\n                    ],\n                    \"settings\": {\n                        \"foreground\": \"#73d0ff\"\n                    }\n                },\n                {\n                    \"name\": \"Tag start/end\",\n                    \"scope\": [\n                        \"punctuation.definition.tag.end\",\n                        \"punctuation.definition.tag.begin\",\n                        \"punctuation.definition.tag\"\n                    ],\n                    \"settings\": {\n                        \"foreground\": \"#5ccfe680\"\n                    }\n                },\n                {\n                    \"name\": \"Tag attribute\",\n                    \"scope\": [\n                        \"entity.other.attribute-name\"\n                    ],\n                    \"settings\": {\n                        \"foreground\": \"#ffd173\"\n                    }\n                },\n                {\n                    \"name\": \"Library constant\",\n                    \"scope\": [\n                        \"support.constant\"\n                    ],\n                    \"settings\": {\n                        \"fontStyle\": \"italic\",\n                        \"foreground\": \"#f29e74\"\n                    }\n                },\n                {\n                    \"name\": \"Library class/type\",\n                    \"scope\": [\n                        \"support.type\",\n                        \"support.class\",\n                        \"source.go storage.type\"\n                    ],\n                    \"settings\": {\n                        \"foreground\": \"#5ccfe6\"\n                    }\n                },\n                {\n                    \"name\": \"Decorators/annotation\",\n                    \"scope\": [\n                        \"meta.decorator variable.other\",\n                        \"meta.decorator punctuation.decorator\",\n                        \"storage.type.annotation\"\n                    ],\n                    \"settings\": {\n                        \"foreground\": \"#ffdfb3\"\n                    }\n                },\n                {\n                    \"name\": \"Invalid\",\n                    \"scope\": [\n                        \"invalid\"\n                    ],\n                    \"settings\": {\n                        \"foreground\": \"#ff6666\"\n                    }\n                },\n                {\n                    \"name\": \"diff.header\",\n                    \"scope\": [\n                        \"meta.diff\",\n                        \"meta.diff.header\"\n                    ],\n                    \"settings\": {\n                        \"foreground\": \"#c594c5\"\n                    }\n                },\n                {\n                    \"name\": \"Ruby class methods\",\n                    \"scope\": [\n                        \"source.ruby variable.other.readwrite\"\n                    ],\n                    \"settings\": {\n                        \"foreground\": \"#ffd173\"\n                    }\n                },\n                {\n                    \"name\": \"CSS tag names\",\n                    \"scope\": [\n                        \"source.css entity.name.tag\",\n                        \"source.sass entity.name.tag\",\n                        \"source.scss entity.name.tag\",\n                        \"source.less entity.name.tag\",\n                        \"source.stylus entity.name.tag\"\n                    ],\n                    \"settings\": {\n                        \"foreground\": \"#73d0ff\"\n                    }\n                },\n                {\n                    \"name\": \"CSS browser prefix\",\n                    \"scope\": [\n                        \"source.css support.type\",\n                        \"source.sass support.type\",\n                        \"source.scss support.type\",\n                        \"source.less support.type\",\n                        \"source.stylus support.type\"\n                    ],\n                    \"settings\": {\n                        \"foreground\": \"#b8cfe680\"\n                    }\n                },\n                {\n                    \"name\": \"CSS Properties\",\n                    \"scope\": [\n                        \"support.type.property-name\"\n                    ],\n                    \"settings\": {\n                        \"fontStyle\": \"normal\",\n                        \"foreground\": \"#5ccfe6\"\n                    }\n                },\n                {\n                    \"name\": \"Search Results Numbers\",\n                    \"scope\": [\n                        \"constant.numeric.line-number.find-in-files - match\"\n                    ],\n                    \"settings\": {\n                        \"foreground\": \"#b8cfe680\"\n                    }\n                },\n                {\n                    \"name\": \"Search Results Match Numbers\",\n                    \"scope\": [\n                        \"constant.numeric.line-number.match\"\n                    ],\n                    \"settings\": {\n                        \"foreground\": \"#ffad66\"\n                    }\n                },\n                {\n                    \"name\": \"Search Results Lines\",\n                    \"scope\": [\n                        \"entity.name.filename.find-in-files\"\n                    ],\n                    \"settings\": {\n                        \"foreground\": \"#d5ff80\"\n                    }\n                },\n                {\n                    \"scope\": [\n                        \"message.error\"\n                    ],\n                    \"settings\": {\n                        \"foreground\": \"#ff6666\"\n                    }\n                },\n                {\n                    \"name\": \"Markup heading\",\n                    \"scope\": [\n                        \"markup.heading\",\n                        \"markup.heading entity.name\"\n                    ],\n                    \"settings\": {\n                        \"fontStyle\": \"bold\",\n                        \"foreground\": \"#d5ff80\"\n                    }\n                },\n                {\n                    \"name\": \"Markup links\",\n                    \"scope\": [\n                        \"markup.underline.link\",\n                        \"string.other.link\"\n                    ],\n                    \"settings\": {\n                        \"foreground\": \"#5ccfe6\"\n                    }\n                },\n                {\n                    \"name\": \"Markup Italic\",\n                    \"scope\": [\n                        \"markup.italic\"\n                    ],\n                    \"settings\": {\n                        \"fontStyle\": \"italic\",\n                        \"foreground\": \"#f28779\"\n                    }\n                },\n                {\n                    \"name\": \"Markup Bold\",\n                    \"scope\": [\n                        \"markup.bold\"\n                    ],\n                    \"settings\": {\n                        \"fontStyle\": \"bold\",\n                        \"foreground\": \"#f28779\"\n                    }\n                },\n                {\n                    \"name\": \"Markup Bold/italic\",\n                    \"scope\": [\n                        \"markup.italic markup.bold\",\n                        \"markup.bold markup.italic\"\n                    ],\n                    \"settings\": {\n                        \"fontStyle\": \"bold italic\"\n                    }\n                },\n                {\n                    \"name\": \"Markup Code\",\n                    \"scope\": [\n                        \"markup.raw\"\n                    ],\n                    \"settings\": {\n                        \"background\": \"#cccac205\"\n                    }\n                },\n                {\n                    \"name\": \"Markup Code Inline\",\n                    \"scope\": [\n                        \"markup.raw.inline\"\n                    ],\n                    \"settings\": {\n                        \"background\": \"#cccac20f\"\n                    }\n                },\n                {\n                    \"name\": \"Markdown Separator\",\n                    \"scope\": [\n                        \"meta.separator\"\n                    ],\n                    \"settings\": {\n                        \"fontStyle\": \"bold\",\n                        \"background\": \"#cccac20f\",\n                        \"foreground\": \"#b8cfe680\"\n                    }\n                },\n                {\n                    \"name\": \"Markup Blockquote\",\n                    \"scope\": [\n                        \"markup.quote\"\n                    ],\n                    \"settings\": {\n                        \"foreground\": \"#95e6cb\",\n                        \"fontStyle\": \"italic\"\n                    }\n                },\n                {\n                    \"name\": \"Markup List Bullet\",\n                    \"scope\": [\n                        \"markup.list punctuation.definition.list.begin\"\n                    ],\n                    \"settings\": {\n                        \"foreground\": \"#ffd173\"\n                    }\n                },\n                {\n                    \"name\": \"Markup added\",\n                    \"scope\": [\n                        \"markup.inserted\"\n                    ],\n                    \"settings\": {\n                        \"foreground\": \"#87d96c\"\n                    }\n                },\n                {\n                    \"name\": \"Markup modified\",\n                    \"scope\": [\n                        \"markup.changed\"\n                    ],\n                    \"settings\": {\n                        \"foreground\": \"#80bfff\"\n                    }\n                },\n                {\n                    \"name\": \"Markup removed\",\n                    \"scope\": [\n                        \"markup.deleted\"\n                    ],\n                    \"settings\": {\n                        \"foreground\": \"#f27983\"\n                    }\n                },\n                {\n                    \"name\": \"Markup Strike\",\n                    \"scope\": [\n                        \"markup.strike\"\n                    ],\n                    \"settings\": {\n                        \"foreground\": \"#ffdfb3\"\n                    }\n                },\n                {\n                    \"name\": \"Markup Table\",\n                    \"scope\": [\n                        \"markup.table\"\n                    ],\n                    \"settings\": {\n                        \"background\": \"#cccac20f\",\n                        \"foreground\": \"#5ccfe6\"\n                    }\n                },\n                {\n                    \"name\": \"Markup Raw Inline\",\n                    \"scope\": [\n                        \"text.html.markdown markup.inline.raw\"\n                    ],\n                    \"settings\": {\n                        \"foreground\": \"#f29e74\"\n                    }\n                },\n                {\n                    \"name\": \"Markdown - Line Break\",\n                    \"scope\": [\n                        \"text.html.markdown meta.dummy.line-break\"\n                    ],\n                    \"settings\": {\n                        \"background\": \"#b8cfe680\",\n                        \"foreground\": \"#b8cfe680\"\n                    }\n                },\n                {\n                    \"name\": \"Markdown - Raw Block Fenced\",\n                    \"scope\": [\n                        \"punctuation.definition.markdown\"\n                    ],\n                    \"settings\": {\n                        \"background\": \"#cccac2\",\n                        \"foreground\": \"#b8cfe680\"\n                    }\n                }\n            ],\n            semanticHighlighting: true,\n            semanticTokenColors: {\n                \"parameter.label\": \"#cccac2\"\n            }\n        }\n\n       \tconst langs = [\"go\", \"python\", \"yaml\", \"hcl\"]\n        const highlighter = await getHighlighter(\n            {\n                themes: [ayuDarkTheme, ayuLightTheme],\n                langs: langs,\n            }\n        )\n\n        window.require.config({ paths: { vs: '/static/monaco/core' } })\n        window.require(\n            ['vs/editor/editor.main'],\n            async function() {\n                for (const lang of langs) {\n                    monaco.languages.register({ id: lang })\n                }\n\n                shikiToMonaco(highlighter, monaco)\n\n                const container = document.getElementById('container')\n                const options = {\n                    theme: window.matchMedia('(prefers-color-scheme: dark)').matches ? 'ayu-dark' : 'ayu-light',\n                    fontFamily: 'JetBrains Mono',\n                    fontWeight: '500',\n                    fontSize: 14,\n                    minimap: {\n                        enabled: false,\n                    },\n                    contextmenu: false,\n                    stickyScroll: {\n                        enabled: true,\n                        defaultModel: \"foldingProviderModel\",\n                    },\n                    placeholder: `\n                        skladka(1)                                General Commands Manual                               skladka(1)\n\n                        NAME\n                            skladka - a highly opinionated and minimalistic pastebin\n\n                        WEB USAGE\n                            Drag a file and drop it here, or\n                            Paste an image from your clipboard using Ctrl + v, or\n                            After typing, press the big yellow button to paste, or\n                            Just press Ctrl + Enter once done typing.\n\n                        MOBILE USAGE\n                            All of the above, or\n                            Use the upload button.\n\n                        ROUTES\n                            GET /<id>\n                                Get raw pastes\n                            GET /p/<id>\n                                Get highlighted pastes\n                            GET /p/<id>.<ext>\n                                Get syntax highlighted pastes.\n\n                        SEE ALSO\n                            github.com/aexvir/skladka\n                        `,\n                }\n\n                // diff mode renders a read only inline diff between two versions,\n                // the modified side is exposed as the editor for everything else\n                let diff = null\n                let editor = null\n                if (container.dataset.mode === 'diff') {\n                    diff = monaco.editor.createDiffEditor(\n                        container,\n                        {\n                            ...options,\n                            readOnly: true,\n                            originalEditable: false,\n                            renderSideBySide: false,\n                        }\n                    )\n                    editor = diff.getModifiedEditor()\n                } else {\n                    editor = monaco.editor.create(container, options)\n                }\n\n                // export Editor object globally, so other components can interact with it\n                window.Editor = (\n                    function() {\n                        return {\n                            getEditor: function() {\n                                return editor\n                            },\n                            getContent: function() {\n                                return editor.getValue()\n                            },\n                            setContent: function(text) {\n                                return editor.setValue(text)\n                            },\n                            setSyntax: function(lang) {\n                                return editor.getModel().setLanguage(lang)\n                            },\n                            setTheme: function(name) {\n                                return editor.updateOptions({ theme: name })\n                            },\n                            setReadOnly: function() {\n                                return editor.updateOptions({ readOnly: true})\n                            },\n                            setDiff: function(original, modified, lang) {\n                                return diff.setModel(\n                                    {\n                                        original: monaco.editor.createModel(original, lang),\n                                        modified: monaco.editor.createModel(modified, lang),\n                                    }\n                                )\n                            },\n                            copyToClipboard: function() {\n                                window.Toaster.show(\n                                    'content copied to clipboard!', {\n                                        type: 'success',\n                                        duration: 3000\n                                    }\n                                )\n\n                                return navigator.clipboard.writeText(editor.getValue())\n                            },\n                            downloadAsFile: function() {\n                                const blob = new Blob([editor.getValue()], { type: 'text/plain' })\n                                const url = URL.createObjectURL(blob)\n\n                                const title = document.\n                                    getElementById(\"paste-title\").\n                                    innerText.\n                                    toLowerCase().\n                                    replace(/ /g, '-')\n\n                                const language = monaco.\n                                    languages.\n                                    getLanguages().\n                                    find((lang) => lang.id === editor.getModel().getLanguageId()\n                                )\n\n                                let extension = \"txt\"\n                                if (language !== undefined && language.extensions.length > 0) {\n                                    extension = language.extensions[0]\n                                }\n\n                                const link = document.createElement('a')\n                                link.href = url\n                                link.download = `${title}${extension}`\n                                link.click()\n\n                                URL.revokeObjectURL(url)\n                            }\n                        }\n                    }\n                )()\n\n                // weird hack to properly get monaco to resize inside a flex container\n                window.addEventListener(\n                    'resize',\n                    () => {\n                        const root = diff || editor\n                        root.layout({ width: 0, height: 0 })\n                        window.requestAnimationFrame(\n                            () => {\n                                const rect = container.getBoundingClientRect()\n                                root.layout({ width: rect.width, height: rect.height })\n                            }\n                        )\n                    }\n                )\n\n                window.\n                    matchMedia('(prefers-color-scheme: dark)').\n                    addEventListener(\n                        'change',\n                        (e) => {\n                            console.log(e.matches)\n                            window.Editor.setTheme(e.matches ? 'ayu-dark' : 'ayu-light')\n                        }\n                    )\n            }\n        )\n    </script>")
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		return nil
+	})
+}
 
-            check()
+func monaco(content, syntax string, readonly bool) templ.ComponentScript {
+	return templ.ComponentScript{
+		Name: `__templ_monaco_49a0`,
+		Function: `function __templ_monaco_49a0(content, syntax, readonly){waitForMonaco().then(
+        (editor) => {
+            editor.setContent(content)
+            editor.setSyntax(syntax)
+            if (readonly) {
+                editor.setReadOnly()
+            }
         }
     )
+}`,
+		Call:       templ.SafeScript(`__templ_monaco_49a0`, content, syntax, readonly),
+		CallInline: templ.SafeScriptInline(`__templ_monaco_49a0`, content, syntax, readonly),
+	}
+}
 
-    waitForMonaco.then(
+func monacodiff(original, modified, syntax string) templ.ComponentScript {
+	return templ.ComponentScript{
+		Name: `__templ_monacodiff_321d`,
+		Function: `function __templ_monacodiff_321d(original, modified, syntax){waitForMonaco().then(
         (editor) => {
-            editor.setContent(content)
+            editor.setDiff(original, modified, syntax)
+        }
+    )
+}`,
+		Call:       templ.SafeScript(`__templ_monacodiff_321d`, original, modified, syntax),
+		CallInline: templ.SafeScriptInline(`__templ_monacodiff_321d`, original, modified, syntax),
+	}
+}
+
+func sealedmonaco(reference, content, syntax string, readonly bool) templ.ComponentScript {
+	return templ.ComponentScript{
+		Name: `__templ_sealedmonaco_4977`,
+		Function: `function __templ_sealedmonaco_4977(reference, content, syntax, readonly){waitForMonaco().then(
+        async (editor) => {
+            window.Sealed.propagate(reference)
             editor.setSyntax(syntax)
             if (readonly) {
                 editor.setReadOnly()
             }
+
+            try {
+                editor.setContent(await window.Sealed.open(content, window.Sealed.key()))
+            } catch (e) {
+                window.Toaster.show(
+                    'this paste is end-to-end encrypted, the link is missing its key', {
+                        type: 'error',
+                        duration: 5000
+                    }
+                )
+            }
+        }
+    )
+}`,
+		Call:       templ.SafeScript(`__templ_sealedmonaco_4977`, reference, content, syntax, readonly),
+		CallInline: templ.SafeScriptInline(`__templ_sealedmonaco_4977`, reference, content, syntax, readonly),
+	}
+}
+
+func sealedmonacodiff(reference, original, modified, syntax string) templ.ComponentScript {
+	return templ.ComponentScript{
+		Name: `__templ_sealedmonacodiff_e1fe`,
+		Function: `function __templ_sealedmonacodiff_e1fe(reference, original, modified, syntax){waitForMonaco().then(
+        async (editor) => {
+            window.Sealed.propagate(reference)
+
+            try {
+                const key = window.Sealed.key()
+                editor.setDiff(
+                    await window.Sealed.open(original, key),
+                    await window.Sealed.open(modified, key),
+                    syntax
+                )
+            } catch (e) {
+                window.Toaster.show(
+                    'this paste is end-to-end encrypted, the link is missing its key', {
+                        type: 'error',
+                        duration: 5000
+                    }
+                )
+            }
         }
     )
 }`,
-		Call:       templ.SafeScript(`__templ_monaco_913f`, content, syntax, readonly),
-		CallInline: templ.SafeScriptInline(`__templ_monaco_913f`, content, syntax, readonly),
+		Call:       templ.SafeScript(`__templ_sealedmonacodiff_e1fe`, reference, original, modified, syntax),
+		CallInline: templ.SafeScriptInline(`__templ_sealedmonacodiff_e1fe`, reference, original, modified, syntax),
 	}
 }
 
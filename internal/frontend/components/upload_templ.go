@@ -0,0 +1,188 @@
+// Code generated by templ - DO NOT EDIT.
+
+package components
+
+//lint:file-ignore SA4006 This context is only used if a nested component is present.
+
+import "github.com/a-h/templ"
+import templruntime "github.com/a-h/templ/runtime"
+
+import (
+	"github.com/aexvir/skladka/internal/frontend/icons"
+	"github.com/aexvir/skladka/internal/paste"
+)
+
+// Upload lets files be uploaded as the content of a new paste, either picked from disk
+// or dropped into the editor. The first of them is previewed in the editor, its syntax
+// inferred from its name. Uploaded files replace whatever was typed into the editor,
+// while images and binary files are attached to the paste, next to its content.
+func Upload() templ.Component {
+	return templruntime.GeneratedTemplate(func(templ_7745c5c3_Input templruntime.GeneratedComponentInput) (templ_7745c5c3_Err error) {
+		templ_7745c5c3_W, ctx := templ_7745c5c3_Input.Writer, templ_7745c5c3_Input.Context
+		if templ_7745c5c3_CtxErr := ctx.Err(); templ_7745c5c3_CtxErr != nil {
+			return templ_7745c5c3_CtxErr
+		}
+		templ_7745c5c3_Buffer, templ_7745c5c3_IsBuffer := templruntime.GetBuffer(templ_7745c5c3_W)
+		if !templ_7745c5c3_IsBuffer {
+			defer func() {
+				templ_7745c5c3_BufErr := templruntime.ReleaseBuffer(templ_7745c5c3_Buffer)
+				if templ_7745c5c3_Err == nil {
+					templ_7745c5c3_Err = templ_7745c5c3_BufErr
+				}
+			}()
+		}
+		ctx = templ.InitializeContext(ctx)
+		templ_7745c5c3_Var1 := templ.GetChildren(ctx)
+		if templ_7745c5c3_Var1 == nil {
+			templ_7745c5c3_Var1 = templ.NopComponent
+		}
+		ctx = templ.ClearChildren(ctx)
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 1, "<div class=\"space-y-1\"><label for=\"paste-files\" class=\"text-sm flex flex-row gap-2 items-center cursor-pointer hover:text-accent\">")
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		templ_7745c5c3_Err = icons.Document(14, 14, "text-muted").Render(ctx, templ_7745c5c3_Buffer)
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 2, "upload files</label> <input type=\"file\" name=\"files\" id=\"paste-files\" multiple class=\"hidden\"><div id=\"paste-files-selection\" class=\"hidden flex flex-row items-center justify-between gap-2 text-sm text-muted\"><span id=\"paste-files-names\" class=\"truncate\"></span> <button type=\"button\" id=\"paste-files-clear\" class=\"hover:text-accent\">clear</button></div></div>")
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		templ_7745c5c3_Err = initUpload(paste.Syntaxes()).Render(ctx, templ_7745c5c3_Buffer)
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		return nil
+	})
+}
+
+func initUpload(syntaxes map[string]string) templ.ComponentScript {
+	return templ.ComponentScript{
+		Name: `__templ_initUpload_38d5`,
+		Function: `function __templ_initUpload_38d5(syntaxes){document.addEventListener(
+        'DOMContentLoaded', () => {
+            const input = document.getElementById('paste-files')
+            const selection = document.getElementById('paste-files-selection')
+            const names = document.getElementById('paste-files-names')
+            const container = document.getElementById('container')
+            const encrypted = document.getElementById('toggle-encrypted')
+
+            // mirrors paste.SyntaxOf, the server infers the syntax again on its own
+            const syntaxOf = (name) => {
+                name = name.toLowerCase()
+                if (name === 'dockerfile') {
+                    return 'dockerfile'
+                }
+
+                const dot = name.lastIndexOf('.')
+                return (dot >= 0 && syntaxes[name.slice(dot)]) || 'plaintext'
+            }
+
+            // mirrors api.ParseUploads, previewable images and anything that isn't
+            // utf-8 encoded text are attached by the server
+            const text = async (file) => {
+                if (/^image\/(png|jpeg|gif|webp)$/.test(file.type)) {
+                    return null
+                }
+
+                try {
+                    return new TextDecoder('utf-8', { fatal: true }).decode(await file.arrayBuffer())
+                } catch (e) {
+                    return null
+                }
+            }
+
+            const clear = () => {
+                input.value = ''
+                selection.classList.add('hidden')
+            }
+
+            const preview = async () => {
+                if (input.files.length === 0) {
+                    clear()
+                    return
+                }
+
+                // only the editor content can be encrypted by the browser
+                if (encrypted && encrypted.checked) {
+                    window.Toaster.show(
+                        'uploaded files can\'t be end-to-end encrypted', {
+                            type: 'error',
+                            duration: 5000
+                        }
+                    )
+                    clear()
+                    return
+                }
+
+                names.textContent = Array.from(input.files).map((file) => file.name).join(', ')
+                names.title = names.textContent
+                selection.classList.remove('hidden')
+
+                // images and binary files are attached, only text is previewed
+                let first, content
+                for (const file of input.files) {
+                    content = await text(file)
+                    if (content !== null) {
+                        first = file
+                        break
+                    }
+                }
+                if (!first) {
+                    return
+                }
+
+                const syntax = syntaxOf(first.name)
+
+                const editor = await waitForMonaco()
+                editor.setContent(content)
+                editor.setSyntax(syntax)
+
+                const select = document.querySelector('select[name="syntax"]')
+                if (select && Array.from(select.options).some((option) => option.value === syntax)) {
+                    select.value = syntax
+                }
+            }
+
+            input.addEventListener('change', preview)
+            document.getElementById('paste-files-clear').addEventListener('click', clear)
+
+            // listeners are registered on capture so monaco doesn't handle the drop itself
+            container.addEventListener(
+                'dragover', (e) => {
+                    if (!e.dataTransfer.types.includes('Files')) {
+                        return
+                    }
+                    e.preventDefault()
+                    e.stopPropagation()
+                    container.classList.add('opacity-50')
+                }, true
+            )
+
+            container.addEventListener(
+                'dragleave', () => container.classList.remove('opacity-50'), true
+            )
+
+            container.addEventListener(
+                'drop', (e) => {
+                    container.classList.remove('opacity-50')
+                    if (e.dataTransfer.files.length === 0) {
+                        return
+                    }
+                    e.preventDefault()
+                    e.stopPropagation()
+
+                    input.files = e.dataTransfer.files
+                    preview()
+                }, true
+            )
+        }
+    )
+}`,
+		Call:       templ.SafeScript(`__templ_initUpload_38d5`, syntaxes),
+		CallInline: templ.SafeScriptInline(`__templ_initUpload_38d5`, syntaxes),
+	}
+}
+
+var _ = templruntime.GeneratedTemplate
@@ -14,6 +14,15 @@
 //   - Type-safe templates compiled at build time using templ
 //   - Static asset serving from an embedded filesystem
 //   - Chi-based routing for clean URL structure
+//   - End-to-end encrypted pastes, encrypted and decrypted in the browser with a key kept
+//     in the fragment of the url; their raw view only serves the ciphertext to anything
+//     but browsers, flagged by the x-skd-client-encrypted header
+//   - Pastes with several files, shown as tabs above the editor, each file having its own
+//     raw view at /{ref}/raw/{filename}
+//   - File uploads, picked from disk or dropped into the editor, creating a paste with a
+//     file for each of them, their syntax inferred from their names
+//   - Images and binary files uploaded along with a paste, attached to it and listed below
+//     the editor, images previewed inline; each is served at /{ref}/attachments/{name}
 //
 // # example Usage
 //
@@ -5,14 +5,22 @@ import (
 	"embed"
 	"fmt"
 	"net/http"
+	"net/url"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/go-chi/chi/v5"
+	"github.com/jackc/pgx/v5"
 
+	"github.com/aexvir/skladka/internal/api"
+	"github.com/aexvir/skladka/internal/errors"
+	"github.com/aexvir/skladka/internal/frontend/components"
 	"github.com/aexvir/skladka/internal/frontend/layouts"
 	"github.com/aexvir/skladka/internal/frontend/views"
 	"github.com/aexvir/skladka/internal/logging"
 	"github.com/aexvir/skladka/internal/paste"
+	"github.com/aexvir/skladka/internal/user"
 )
 
 // Storage defines the interface for paste storage operations required by the frontend.
@@ -25,13 +33,76 @@ type Storage interface {
 	// GetPasteWithPassword retrieves a paste by its reference.
 	GetPasteWithPassword(context.Context, string, string) (*paste.Paste, error)
 
-	// CreatePaste stores a new paste and returns its reference.
-	CreatePaste(context.Context, paste.Paste) (string, error)
+	// GetPasteWithToken retrieves a paste on behalf of its owner.
+	GetPasteWithToken(context.Context, string, string) (paste.Paste, error)
 
-	// ListPastes returns all public pastes.
-	ListPastes(context.Context) ([]paste.Paste, error)
+	// PeekPaste retrieves a paste by its reference without counting the read.
+	// Returns nil if the paste is password protected and the password doesn't match.
+	PeekPaste(context.Context, string, string) (*paste.Paste, error)
+
+	// CreatePaste stores a new paste and returns its reference and owner token.
+	CreatePaste(context.Context, paste.Paste) (string, string, error)
+
+	// ListPastes returns a page of public pastes matching the filter, starting after the cursor.
+	ListPastes(context.Context, paste.Filter, string, int) (paste.Page, error)
+
+	// UpdatePaste edits a paste, authorized by the owner token.
+	UpdatePaste(context.Context, string, string, paste.Paste) error
+
+	// DeletePaste soft deletes a paste, authorized by the owner token.
+	DeletePaste(context.Context, string, string) error
+
+	// GetAttachment returns an attachment of a paste along with its content, without counting the read.
+	GetAttachment(context.Context, string, string) (paste.Attachment, error)
+
+	// ListRevisions returns every revision of a paste, oldest first.
+	ListRevisions(context.Context, string, string) ([]paste.Revision, error)
+
+	// ListForks returns the public pastes forked from a paste.
+	ListForks(context.Context, string) ([]paste.Paste, error)
+
+	// CreateUser registers a new user with the given username and password.
+	CreateUser(context.Context, string, string) (user.User, error)
+
+	// Authenticate verifies the credentials of a user.
+	// Returns nil if they don't match.
+	Authenticate(context.Context, string, string) (*user.User, error)
+
+	// CreateSession starts a new session for the user, returning its token and expiration.
+	CreateSession(context.Context, user.User) (string, time.Time, error)
+
+	// DeleteSession ends the session with the given token.
+	DeleteSession(context.Context, string) error
+
+	// CreateAPIToken creates a personal access token for the user, returning it with its secret.
+	CreateAPIToken(context.Context, user.User, string, []user.Scope) (user.Token, string, error)
+
+	// ListAPITokens returns the personal access tokens of the user.
+	ListAPITokens(context.Context, user.User) ([]user.Token, error)
+
+	// RevokeAPIToken deletes a personal access token of the user.
+	RevokeAPIToken(context.Context, user.User, int64) error
+
+	// ListOwnerPastes returns every paste of the user, including unlisted and expired ones.
+	ListOwnerPastes(context.Context, user.User) ([]paste.Paste, error)
+
+	// DeleteOwnerPastes soft deletes the pastes of the user with the given references.
+	DeleteOwnerPastes(context.Context, user.User, []string) (int64, error)
+
+	// SearchPastes returns the public pastes best matching the query, up to the limit.
+	SearchPastes(context.Context, string, int) ([]paste.Paste, error)
+
+	// ListTags returns the most used tags of public pastes starting with the prefix, up to the limit.
+	ListTags(context.Context, string, int) ([]paste.Tag, error)
 }
 
+const (
+	// archivePageSize is the number of pastes loaded at once on the archive.
+	archivePageSize = 20
+	// tagsPageSize is the number of most used tags listed on the tags page.
+	tagsPageSize = 200
+)
+
 //go:embed static/*
 var static embed.FS
 
@@ -66,7 +137,7 @@ func DashboardRouter(storage Storage) chi.Router {
 				logger.Info("frontend.dashboard", "rendering creation page")
 
 				layouts.Base(
-					views.Creation("Skládka"),
+					views.Creation("Skládka", paste.Paste{}),
 				).Render(r.Context(), w)
 
 				return
@@ -81,8 +152,11 @@ func DashboardRouter(storage Storage) chi.Router {
 				logger := logging.FromContext(r.Context())
 				logger.Info("frontend.dashboard", "creating paste")
 
-				if err := r.ParseForm(); err != nil {
-					w.WriteHeader(http.StatusBadRequest)
+				// files uploaded or dropped into the editor replace its content,
+				// images and binary files are attached to the paste instead
+				uploads, attachments, err := api.ParseUploads(r)
+				if err != nil {
+					w.WriteHeader(errors.AsHTTPError(err).Code)
 					w.Write([]byte(fmt.Sprintf("error parsing form: %v", err)))
 					return
 				}
@@ -92,7 +166,25 @@ func DashboardRouter(storage Storage) chi.Router {
 					Title:   r.FormValue("title"),
 					Content: r.FormValue("content"),
 					Syntax:  r.FormValue("syntax"),
+					Slug:    strings.TrimSpace(r.FormValue("slug")),
 					Public:  r.FormValue("unlisted") != "on",
+					Owner:   user.FromContext(r.Context()),
+
+					// the content was encrypted by the browser, the key never leaves it
+					ClientEncrypted: r.FormValue("encrypted") == "on",
+				}
+
+				if len(uploads) > 0 || len(attachments) > 0 {
+					// the browser only encrypts the content of the editor
+					if p.ClientEncrypted {
+						w.WriteHeader(http.StatusBadRequest)
+						w.Write([]byte("error creating paste: uploaded files can't be end-to-end encrypted"))
+						return
+					}
+					if len(uploads) > 0 {
+						p.Files = uploads
+					}
+					p.Attachments = attachments
 				}
 
 				if tags := r.FormValue("tags"); tags != "" {
@@ -103,6 +195,33 @@ func DashboardRouter(storage Storage) chi.Router {
 					p.Password = &password
 				}
 
+				if parent := r.FormValue("parent"); parent != "" {
+					p.Parent = &parent
+				}
+
+				if r.FormValue("expire") == "on" {
+					duration, err := paste.ParseDuration(r.FormValue("expiration"))
+					if err != nil {
+						w.WriteHeader(http.StatusBadRequest)
+						w.Write([]byte(fmt.Sprintf("error creating paste: %v", err)))
+						return
+					}
+
+					expiration := time.Now().UTC().Add(duration)
+					p.Expiration = &expiration
+				}
+
+				if r.FormValue("burn") == "on" {
+					reads, err := strconv.Atoi(r.FormValue("burnafter"))
+					if err != nil {
+						w.WriteHeader(http.StatusBadRequest)
+						w.Write([]byte(fmt.Sprintf("error creating paste: %v", err)))
+						return
+					}
+
+					p.BurnAfter = &reads
+				}
+
 				if err := p.Validate(); err != nil {
 					w.WriteHeader(http.StatusBadRequest)
 					w.Write([]byte(fmt.Sprintf("error creating paste: %v", err)))
@@ -110,13 +229,21 @@ func DashboardRouter(storage Storage) chi.Router {
 				}
 
 				// Save to storage
-				ref, err := storage.CreatePaste(r.Context(), p)
+				ref, token, err := storage.CreatePaste(r.Context(), p)
 				if err != nil {
+					if errors.IsBadRequest(err) {
+						w.WriteHeader(http.StatusBadRequest)
+						w.Write([]byte(fmt.Sprintf("error creating paste: %v", err)))
+						return
+					}
 					w.WriteHeader(http.StatusInternalServerError)
 					w.Write([]byte(fmt.Sprintf("error creating paste: %v", err)))
 					return
 				}
 
+				// the token is only known to this browser from now on
+				remember(w, ref, token)
+
 				// Redirect to the paste view
 				http.Redirect(w, r, fmt.Sprintf("/%s", ref), http.StatusSeeOther)
 				return
@@ -125,147 +252,884 @@ func DashboardRouter(storage Storage) chi.Router {
 	)
 
 	router.Get(
-		"/archive",
+		"/login",
+		http.HandlerFunc(
+			func(w http.ResponseWriter, r *http.Request) {
+				layouts.Base(
+					views.Login(""),
+				).Render(r.Context(), w)
+			},
+		),
+	)
+
+	router.Post(
+		"/login",
 		http.HandlerFunc(
 			func(w http.ResponseWriter, r *http.Request) {
 				logger := logging.FromContext(r.Context())
-				logger.Info("frontend.archive", "rendering archive page")
 
-				pastes, err := storage.ListPastes(r.Context())
+				account, err := storage.Authenticate(r.Context(), r.FormValue("username"), r.FormValue("password"))
 				if err != nil {
-					logger.Error(err, "frontend.archive", "error listing pastes")
+					logger.Error(err, "frontend.account", "error authenticating user")
+					http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+					return
+				}
+
+				if account == nil {
+					w.WriteHeader(http.StatusForbidden)
+					layouts.Base(
+						views.Login("invalid username or password"),
+					).Render(r.Context(), w)
+					return
+				}
+
+				if err := login(w, r, storage, *account); err != nil {
+					logger.Error(err, "frontend.account", "error creating session")
 					http.Error(w, "Internal Server Error", http.StatusInternalServerError)
 					return
 				}
 
+				logger.Info("frontend.account", "user logged in", "username", account.Username)
+
+				http.Redirect(w, r, "/", http.StatusSeeOther)
+			},
+		),
+	)
+
+	router.Get(
+		"/register",
+		http.HandlerFunc(
+			func(w http.ResponseWriter, r *http.Request) {
 				layouts.Base(
-					views.Archive("Recent Pastes", pastes),
+					views.Register(""),
 				).Render(r.Context(), w)
 			},
 		),
 	)
 
-	router.Get(
-		"/{ref}",
+	router.Post(
+		"/register",
 		http.HandlerFunc(
 			func(w http.ResponseWriter, r *http.Request) {
-				ref := chi.URLParam(r, "ref")
+				logger := logging.FromContext(r.Context())
 
-				paste, err := storage.GetPaste(r.Context(), ref)
+				account, err := storage.CreateUser(r.Context(), r.FormValue("username"), r.FormValue("password"))
 				if err != nil {
-					w.WriteHeader(422)
-					w.Write([]byte(fmt.Sprintf("error fetching paste %s: %v", ref, err)))
+					if errors.IsBadRequest(err) {
+						w.WriteHeader(http.StatusBadRequest)
+						layouts.Base(
+							views.Register(errors.AsHTTPError(err).Message),
+						).Render(r.Context(), w)
+						return
+					}
+					logger.Error(err, "frontend.account", "error registering user")
+					http.Error(w, "Internal Server Error", http.StatusInternalServerError)
 					return
 				}
 
-				if paste.Password != nil {
-					layouts.Base(
-						views.PasswordPrompt(ref),
-					).Render(r.Context(), w)
+				if err := login(w, r, storage, account); err != nil {
+					logger.Error(err, "frontend.account", "error creating session")
+					http.Error(w, "Internal Server Error", http.StatusInternalServerError)
 					return
 				}
 
-				logging.
-					FromContext(r.Context()).
-					Info(
-						"frontend.dashboard", "rendering document page",
-						"ref", ref,
-						"title", paste.Title,
-						"syntax", paste.Syntax,
-						"tags", paste.Tags,
-					)
+				logger.Info("frontend.account", "user registered", "username", account.Username)
+
+				http.Redirect(w, r, "/", http.StatusSeeOther)
+			},
+		),
+	)
+
+	router.Get(
+		"/logout",
+		http.HandlerFunc(
+			func(w http.ResponseWriter, r *http.Request) {
+				if cookie, err := r.Cookie(api.SessionCookie); err == nil {
+					if err := storage.DeleteSession(r.Context(), cookie.Value); err != nil {
+						logging.
+							FromContext(r.Context()).
+							Error(err, "frontend.account", "error deleting session")
+					}
+				}
+
+				api.EndSession(w)
+
+				http.Redirect(w, r, "/", http.StatusSeeOther)
+			},
+		),
+	)
+
+	router.Get(
+		"/me",
+		http.HandlerFunc(
+			func(w http.ResponseWriter, r *http.Request) {
+				account := user.FromContext(r.Context())
+				if account == nil {
+					http.Redirect(w, r, "/login", http.StatusSeeOther)
+					return
+				}
+
+				logger := logging.FromContext(r.Context())
+				logger.Info("frontend.dashboard", "rendering owner pastes", "username", account.Username)
+
+				pastes, err := storage.ListOwnerPastes(r.Context(), *account)
+				if err != nil {
+					logger.Error(err, "frontend.dashboard", "error listing owner pastes")
+					http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+					return
+				}
 
 				layouts.Base(
-					views.Document(paste),
+					views.Dashboard(pastes),
 				).Render(r.Context(), w)
-				return
 			},
 		),
 	)
 
-	router.Post("/{ref}/unlock", func(w http.ResponseWriter, r *http.Request) {
-		ref := chi.URLParam(r, "ref")
-		password := r.FormValue("password")
+	router.Post(
+		"/me/delete",
+		http.HandlerFunc(
+			func(w http.ResponseWriter, r *http.Request) {
+				account := user.FromContext(r.Context())
+				if account == nil {
+					http.Redirect(w, r, "/login", http.StatusSeeOther)
+					return
+				}
 
-		paste, err := storage.GetPasteWithPassword(r.Context(), ref, password)
-		if err != nil {
-			w.WriteHeader(422)
-			w.Write([]byte(fmt.Sprintf("error fetching paste %s: %v", ref, err)))
-			return
-		}
+				if err := r.ParseForm(); err != nil {
+					w.WriteHeader(http.StatusBadRequest)
+					w.Write([]byte(fmt.Sprintf("error parsing form: %v", err)))
+					return
+				}
 
-		if paste == nil {
-			http.Error(w, "Invalid password", http.StatusForbidden)
-			return
-		}
+				logger := logging.FromContext(r.Context())
 
-		logging.
-			FromContext(r.Context()).
-			Info(
-				"frontend.dashboard", "rendering document page",
-				"ref", ref,
-				"title", paste.Title,
-				"syntax", paste.Syntax,
-				"tags", paste.Tags,
-			)
+				refs := r.Form["refs"]
+				if len(refs) > 0 {
+					deleted, err := storage.DeleteOwnerPastes(r.Context(), *account, refs)
+					if err != nil {
+						logger.Error(err, "frontend.dashboard", "error deleting owner pastes")
+						http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+						return
+					}
 
-		layouts.Base(
-			views.Document(*paste),
-		).Render(r.Context(), w)
-		return
-	})
+					logger.Info("frontend.dashboard", "deleted owner pastes", "username", account.Username, "deleted", deleted)
+				}
+
+				http.Redirect(w, r, "/me", http.StatusSeeOther)
+			},
+		),
+	)
 
 	router.Get(
-		"/{ref}/raw",
+		"/settings",
 		http.HandlerFunc(
 			func(w http.ResponseWriter, r *http.Request) {
-				ref := chi.URLParam(r, "ref")
-				password := r.Header.Get("x-skd-password")
+				account := user.FromContext(r.Context())
+				if account == nil {
+					http.Redirect(w, r, "/login", http.StatusSeeOther)
+					return
+				}
 
-				paste, err := storage.GetPaste(r.Context(), ref)
+				settings(w, r, storage, *account, "", "")
+			},
+		),
+	)
+
+	router.Post(
+		"/settings/tokens",
+		http.HandlerFunc(
+			func(w http.ResponseWriter, r *http.Request) {
+				account := user.FromContext(r.Context())
+				if account == nil {
+					http.Redirect(w, r, "/login", http.StatusSeeOther)
+					return
+				}
+
+				if err := r.ParseForm(); err != nil {
+					w.WriteHeader(http.StatusBadRequest)
+					w.Write([]byte(fmt.Sprintf("error parsing form: %v", err)))
+					return
+				}
+
+				scopes, err := user.ParseScopes(r.Form["scopes"])
 				if err != nil {
-					w.WriteHeader(422)
-					w.Write([]byte(fmt.Sprintf("error fetching paste %s: %v", ref, err)))
+					w.WriteHeader(http.StatusBadRequest)
+					settings(w, r, storage, *account, "", err.Error())
 					return
 				}
 
-				if paste.Password != nil {
-					if password == "" {
-						layouts.Base(
-							views.RawPasswordPrompt(ref),
-						).Render(r.Context(), w)
+				token, secret, err := storage.CreateAPIToken(r.Context(), *account, r.FormValue("name"), scopes)
+				if err != nil {
+					if errors.IsBadRequest(err) {
+						w.WriteHeader(http.StatusBadRequest)
+						settings(w, r, storage, *account, "", errors.AsHTTPError(err).Message)
 						return
 					}
+					logging.
+						FromContext(r.Context()).
+						Error(err, "frontend.settings", "error creating api token")
+					http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+					return
+				}
 
-					paste, err := storage.GetPasteWithPassword(r.Context(), ref, password)
-					if err != nil {
-						w.WriteHeader(422)
-						w.Write([]byte(fmt.Sprintf("error fetching paste %s: %v", ref, err)))
-						return
-					}
+				logging.
+					FromContext(r.Context()).
+					Info("frontend.settings", "created api token", "username", account.Username, "token", token.ID)
 
-					if paste == nil {
-						http.Error(w, "Invalid password", http.StatusForbidden)
+				// the secret is rendered right away, it can't be retrieved later
+				settings(w, r, storage, *account, secret, "")
+			},
+		),
+	)
+
+	router.Post(
+		"/settings/tokens/{id}/revoke",
+		http.HandlerFunc(
+			func(w http.ResponseWriter, r *http.Request) {
+				account := user.FromContext(r.Context())
+				if account == nil {
+					http.Redirect(w, r, "/login", http.StatusSeeOther)
+					return
+				}
+
+				id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+				if err != nil {
+					http.Error(w, "Invalid token", http.StatusBadRequest)
+					return
+				}
+
+				if err := storage.RevokeAPIToken(r.Context(), *account, id); err != nil {
+					if errors.IsNotFound(err) {
+						http.Error(w, "Token not found", http.StatusNotFound)
 						return
 					}
+					logging.
+						FromContext(r.Context()).
+						Error(err, "frontend.settings", "error revoking api token")
+					http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+					return
 				}
 
 				logging.
 					FromContext(r.Context()).
-					Info(
-						"frontend.dashboard", "rendering raw document page",
-						"ref", ref,
-						"title", paste.Title,
-						"syntax", paste.Syntax,
-						"tags", paste.Tags,
-					)
+					Info("frontend.settings", "revoked api token", "username", account.Username, "token", id)
 
-				w.Header().Set("Content-Type", "text/plain; charset=utf-8")
-				w.Write([]byte(paste.Content))
-				return
+				http.Redirect(w, r, "/settings", http.StatusSeeOther)
 			},
 		),
 	)
 
-	return router
+	router.Get(
+		"/archive",
+		http.HandlerFunc(
+			func(w http.ResponseWriter, r *http.Request) {
+				logger := logging.FromContext(r.Context())
+				logger.Info("frontend.archive", "rendering archive page")
+
+				query := r.URL.Query()
+
+				filter, err := paste.ParseFilter(query)
+				if err != nil {
+					http.Error(w, fmt.Sprintf("Invalid filter: %v", err), http.StatusBadRequest)
+					return
+				}
+
+				page, err := storage.ListPastes(r.Context(), filter, query.Get("cursor"), archivePageSize)
+				if err != nil {
+					if errors.IsBadRequest(err) {
+						http.Error(w, "Invalid cursor", http.StatusBadRequest)
+						return
+					}
+					logger.Error(err, "frontend.archive", "error listing pastes")
+					http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+					return
+				}
+
+				// pages loaded with htmx are appended to the ones already rendered
+				query.Del("cursor")
+				if r.Header.Get("HX-Request") == "true" {
+					views.ArchivePage(page, query).Render(r.Context(), w)
+					return
+				}
+
+				layouts.Base(
+					views.Archive("Recent Pastes", page, query),
+				).Render(r.Context(), w)
+			},
+		),
+	)
+
+	router.Get(
+		"/tags",
+		http.HandlerFunc(
+			func(w http.ResponseWriter, r *http.Request) {
+				logger := logging.FromContext(r.Context())
+				logger.Info("frontend.tags", "rendering tags page")
+
+				tags, err := storage.ListTags(r.Context(), "", tagsPageSize)
+				if err != nil {
+					logger.Error(err, "frontend.tags", "error listing tags")
+					http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+					return
+				}
+
+				layouts.Base(
+					views.Tags(tags),
+				).Render(r.Context(), w)
+			},
+		),
+	)
+
+	router.Get(
+		"/tags/{tag}",
+		http.HandlerFunc(
+			func(w http.ResponseWriter, r *http.Request) {
+				logger := logging.FromContext(r.Context())
+
+				tag, err := url.PathUnescape(chi.URLParam(r, "tag"))
+				if err != nil {
+					http.Error(w, "Invalid tag", http.StatusBadRequest)
+					return
+				}
+
+				page, err := storage.ListPastes(r.Context(), paste.Filter{Tag: tag}, "", archivePageSize)
+				if err != nil {
+					logger.Error(err, "frontend.tags", "error listing pastes", "tag", tag)
+					http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+					return
+				}
+
+				layouts.Base(
+					views.Tag(tag, page),
+				).Render(r.Context(), w)
+			},
+		),
+	)
+
+	router.Get(
+		"/search",
+		http.HandlerFunc(
+			func(w http.ResponseWriter, r *http.Request) {
+				logger := logging.FromContext(r.Context())
+
+				query := strings.TrimSpace(r.URL.Query().Get("q"))
+
+				var pastes []paste.Paste
+				if query != "" {
+					var err error
+					pastes, err = storage.SearchPastes(r.Context(), query, archivePageSize)
+					if err != nil {
+						logger.Error(err, "frontend.search", "error searching pastes", "query", query)
+						http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+						return
+					}
+				}
+
+				// the command palette only swaps in the matching pastes
+				if r.Header.Get("HX-Request") == "true" {
+					components.SearchResults(pastes).Render(r.Context(), w)
+					return
+				}
+
+				layouts.Base(
+					views.Search(query, pastes),
+				).Render(r.Context(), w)
+			},
+		),
+	)
+
+	router.Get(
+		"/{ref}",
+		http.HandlerFunc(
+			func(w http.ResponseWriter, r *http.Request) {
+				ref := chi.URLParam(r, "ref")
+
+				// owners see their pastes without unlocking them and without counting reads
+				token := ownertoken(r)
+				if token != "" {
+					paste, err := storage.GetPasteWithToken(r.Context(), ref, token)
+					if err == nil {
+						layouts.Base(
+							views.Document(paste, token, forks(r, storage, ref)),
+						).Render(r.Context(), w)
+						return
+					}
+					token = ""
+				}
+
+				paste, err := storage.GetPaste(r.Context(), ref)
+				if err != nil {
+					w.WriteHeader(422)
+					w.Write([]byte(fmt.Sprintf("error fetching paste %s: %v", ref, err)))
+					return
+				}
+
+				if paste.Password != nil {
+					layouts.Base(
+						views.PasswordPrompt(ref),
+					).Render(r.Context(), w)
+					return
+				}
+
+				logging.
+					FromContext(r.Context()).
+					Info(
+						"frontend.dashboard", "rendering document page",
+						"ref", ref,
+						"title", paste.Title,
+						"syntax", paste.Syntax,
+						"tags", paste.Tags,
+					)
+
+				layouts.Base(
+					views.Document(paste, token, forks(r, storage, ref)),
+				).Render(r.Context(), w)
+				return
+			},
+		),
+	)
+
+	router.Get(
+		"/{ref}/fork",
+		http.HandlerFunc(
+			func(w http.ResponseWriter, r *http.Request) {
+				ref := chi.URLParam(r, "ref")
+
+				// forking reads the paste, owners can fork their protected pastes
+				// while anyone else goes through the regular read path
+				source, err := storage.GetPasteWithToken(r.Context(), ref, ownertoken(r))
+				if err != nil {
+					if !errors.IsForbidden(err) {
+						w.WriteHeader(422)
+						w.Write([]byte(fmt.Sprintf("error fetching paste %s: %v", ref, err)))
+						return
+					}
+
+					source, err = storage.GetPaste(r.Context(), ref)
+					if err != nil {
+						w.WriteHeader(422)
+						w.Write([]byte(fmt.Sprintf("error fetching paste %s: %v", ref, err)))
+						return
+					}
+
+					if source.Password != nil {
+						http.Error(w, "Password protected pastes can only be forked by their owner", http.StatusForbidden)
+						return
+					}
+				}
+
+				logging.
+					FromContext(r.Context()).
+					Info("frontend.dashboard", "rendering fork page", "ref", ref)
+
+				layouts.Base(
+					views.Creation(
+						"Skládka",
+						paste.Paste{
+							Reference: source.Reference,
+							Content:   source.Content,
+							Syntax:    source.Syntax,
+							Tags:      source.Tags,
+
+							ClientEncrypted: source.ClientEncrypted,
+						},
+					),
+				).Render(r.Context(), w)
+			},
+		),
+	)
+
+	router.Get(
+		"/{ref}/edit",
+		http.HandlerFunc(
+			func(w http.ResponseWriter, r *http.Request) {
+				ref := chi.URLParam(r, "ref")
+				token := ownertoken(r)
+
+				paste, err := storage.GetPasteWithToken(r.Context(), ref, token)
+				if err != nil {
+					if errors.IsForbidden(err) {
+						http.Error(w, "Invalid token", http.StatusForbidden)
+						return
+					}
+					w.WriteHeader(422)
+					w.Write([]byte(fmt.Sprintf("error fetching paste %s: %v", ref, err)))
+					return
+				}
+
+				logging.
+					FromContext(r.Context()).
+					Info("frontend.dashboard", "rendering edit page", "ref", ref)
+
+				layouts.Base(
+					views.Edition(paste, token),
+				).Render(r.Context(), w)
+			},
+		),
+	)
+
+	router.Post(
+		"/{ref}/edit",
+		http.HandlerFunc(
+			func(w http.ResponseWriter, r *http.Request) {
+				ref := chi.URLParam(r, "ref")
+
+				if err := r.ParseForm(); err != nil {
+					w.WriteHeader(http.StatusBadRequest)
+					w.Write([]byte(fmt.Sprintf("error parsing form: %v", err)))
+					return
+				}
+
+				p := paste.Paste{
+					Title:   r.FormValue("title"),
+					Content: r.FormValue("content"),
+					Syntax:  r.FormValue("syntax"),
+				}
+
+				if tags := r.FormValue("tags"); tags != "" {
+					p.Tags = strings.Split(tags, ",")
+				}
+
+				if err := p.Validate(); err != nil {
+					w.WriteHeader(http.StatusBadRequest)
+					w.Write([]byte(fmt.Sprintf("error updating paste: %v", err)))
+					return
+				}
+
+				if err := storage.UpdatePaste(r.Context(), ref, ownertoken(r), p); err != nil {
+					if errors.IsForbidden(err) {
+						http.Error(w, "Invalid token", http.StatusForbidden)
+						return
+					}
+					w.WriteHeader(422)
+					w.Write([]byte(fmt.Sprintf("error updating paste %s: %v", ref, err)))
+					return
+				}
+
+				logging.
+					FromContext(r.Context()).
+					Info("frontend.dashboard", "updated paste", "ref", ref)
+
+				http.Redirect(w, r, fmt.Sprintf("/%s", ref), http.StatusSeeOther)
+			},
+		),
+	)
+
+	router.Post(
+		"/{ref}/delete",
+		http.HandlerFunc(
+			func(w http.ResponseWriter, r *http.Request) {
+				ref := chi.URLParam(r, "ref")
+
+				if err := storage.DeletePaste(r.Context(), ref, ownertoken(r)); err != nil {
+					if errors.IsForbidden(err) {
+						http.Error(w, "Invalid token", http.StatusForbidden)
+						return
+					}
+					w.WriteHeader(422)
+					w.Write([]byte(fmt.Sprintf("error deleting paste %s: %v", ref, err)))
+					return
+				}
+
+				forget(w, ref)
+
+				logging.
+					FromContext(r.Context()).
+					Info("frontend.dashboard", "deleted paste", "ref", ref)
+
+				http.Redirect(w, r, "/", http.StatusSeeOther)
+			},
+		),
+	)
+
+	router.Post(
+		"/{ref}/claim",
+		http.HandlerFunc(
+			func(w http.ResponseWriter, r *http.Request) {
+				ref := chi.URLParam(r, "ref")
+				token := ownertoken(r)
+
+				if _, err := storage.GetPasteWithToken(r.Context(), ref, token); err != nil {
+					if errors.IsForbidden(err) {
+						http.Error(w, "Invalid token", http.StatusForbidden)
+						return
+					}
+					w.WriteHeader(422)
+					w.Write([]byte(fmt.Sprintf("error fetching paste %s: %v", ref, err)))
+					return
+				}
+
+				// the token is remembered so the owner actions show up on this browser too
+				remember(w, ref, token)
+
+				logging.
+					FromContext(r.Context()).
+					Info("frontend.dashboard", "claimed paste", "ref", ref)
+
+				http.Redirect(w, r, fmt.Sprintf("/%s", ref), http.StatusSeeOther)
+			},
+		),
+	)
+
+	router.Post("/{ref}/unlock", func(w http.ResponseWriter, r *http.Request) {
+		ref := chi.URLParam(r, "ref")
+		password := r.FormValue("password")
+
+		paste, err := storage.GetPasteWithPassword(r.Context(), ref, password)
+		if err != nil {
+			w.WriteHeader(422)
+			w.Write([]byte(fmt.Sprintf("error fetching paste %s: %v", ref, err)))
+			return
+		}
+
+		if paste == nil {
+			http.Error(w, "Invalid password", http.StatusForbidden)
+			return
+		}
+
+		logging.
+			FromContext(r.Context()).
+			Info(
+				"frontend.dashboard", "rendering document page",
+				"ref", ref,
+				"title", paste.Title,
+				"syntax", paste.Syntax,
+				"tags", paste.Tags,
+			)
+
+		layouts.Base(
+			views.Document(*paste, "", forks(r, storage, ref)),
+		).Render(r.Context(), w)
+		return
+	})
+
+	router.Get(
+		"/{ref}/history",
+		http.HandlerFunc(
+			func(w http.ResponseWriter, r *http.Request) {
+				ref := chi.URLParam(r, "ref")
+
+				// the history is looked at without counting the read, the same as through the api,
+				// so burn after reading pastes only show it to their owner
+				token := ownertoken(r)
+				if _, err := storage.GetPasteWithToken(r.Context(), ref, token); err != nil {
+					if !errors.IsForbidden(err) {
+						w.WriteHeader(422)
+						w.Write([]byte(fmt.Sprintf("error fetching paste %s: %v", ref, err)))
+						return
+					}
+
+					paste, err := storage.PeekPaste(r.Context(), ref, "")
+					if err != nil {
+						w.WriteHeader(422)
+						w.Write([]byte(fmt.Sprintf("error fetching paste %s: %v", ref, err)))
+						return
+					}
+
+					if paste == nil {
+						http.Error(w, "History of password protected pastes is only available to their owner", http.StatusForbidden)
+						return
+					}
+
+					account := user.FromContext(r.Context())
+					if paste.BurnAfter != nil && (account == nil || paste.Owner == nil || paste.Owner.ID != account.ID) {
+						http.Error(w, "History of burn after reading pastes is only available to their owner", http.StatusForbidden)
+						return
+					}
+				}
+
+				revisions, err := storage.ListRevisions(r.Context(), ref, token)
+				if err != nil {
+					w.WriteHeader(422)
+					w.Write([]byte(fmt.Sprintf("error fetching revisions of paste %s: %v", ref, err)))
+					return
+				}
+
+				// compare the latest revision with the previous one by default
+				to := len(revisions)
+				from := max(to-1, 1)
+
+				for param, value := range map[string]*int{"from": &from, "to": &to} {
+					if raw := r.URL.Query().Get(param); raw != "" {
+						number, err := strconv.Atoi(raw)
+						if err != nil || number < 1 || number > len(revisions) {
+							http.Error(w, fmt.Sprintf("Invalid revision %q", raw), http.StatusBadRequest)
+							return
+						}
+						*value = number
+					}
+				}
+
+				logging.
+					FromContext(r.Context()).
+					Info(
+						"frontend.dashboard", "rendering history page",
+						"ref", ref,
+						"from", from,
+						"to", to,
+					)
+
+				layouts.Base(
+					views.History(ref, revisions, revisions[from-1], revisions[to-1]),
+				).Render(r.Context(), w)
+			},
+		),
+	)
+
+	// the raw view of a paste serves its content, or the content of one of its files
+	raw := http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			ref := chi.URLParam(r, "ref")
+			// file names are escaped when they're part of the url
+			filename, err := url.PathUnescape(chi.URLParam(r, "filename"))
+			if err != nil {
+				http.Error(w, fmt.Sprintf("invalid file name: %v", err), http.StatusBadRequest)
+				return
+			}
+			password := r.Header.Get("x-skd-password")
+
+			paste, err := storage.GetPaste(r.Context(), ref)
+			if err != nil {
+				w.WriteHeader(422)
+				w.Write([]byte(fmt.Sprintf("error fetching paste %s: %v", ref, err)))
+				return
+			}
+
+			if paste.Password != nil {
+				if password == "" {
+					layouts.Base(
+						views.RawPasswordPrompt(ref),
+					).Render(r.Context(), w)
+					return
+				}
+
+				unlocked, err := storage.GetPasteWithPassword(r.Context(), ref, password)
+				if err != nil {
+					w.WriteHeader(422)
+					w.Write([]byte(fmt.Sprintf("error fetching paste %s: %v", ref, err)))
+					return
+				}
+
+				if unlocked == nil {
+					http.Error(w, "Invalid password", http.StatusForbidden)
+					return
+				}
+
+				// the content of protected pastes is only readable once unlocked
+				paste = *unlocked
+			}
+
+			content := paste.Content
+			if filename != "" {
+				file, ok := paste.File(filename)
+				if !ok {
+					http.Error(w, fmt.Sprintf("paste %s has no file %s", ref, filename), http.StatusNotFound)
+					return
+				}
+				content = file.Content
+			}
+
+			logging.
+				FromContext(r.Context()).
+				Info(
+					"frontend.dashboard", "rendering raw document page",
+					"ref", ref,
+					"title", paste.Title,
+					"syntax", paste.Syntax,
+					"tags", paste.Tags,
+					"file", filename,
+				)
+
+			// the server can't decrypt end-to-end encrypted pastes, browsers decrypt them
+			// with the key in the url while anything else gets the ciphertext, flagged
+			// by a header so clients holding the key know to decrypt it
+			if paste.ClientEncrypted {
+				w.Header().Set("X-Skd-Client-Encrypted", "true")
+
+				if strings.Contains(r.Header.Get("Accept"), "text/html") {
+					views.SealedRaw(content).Render(r.Context(), w)
+					return
+				}
+			}
+
+			w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+			w.Write([]byte(content))
+		},
+	)
+
+	router.Get("/{ref}/raw", raw)
+	router.Get("/{ref}/raw/{filename}", raw)
+
+	// attachments are served as they are, images inline so they're previewed on the document page
+	router.Get(
+		"/{ref}/attachments/{name}",
+		http.HandlerFunc(
+			func(w http.ResponseWriter, r *http.Request) {
+				ref := chi.URLParam(r, "ref")
+				// attachment names are escaped when they're part of the url
+				name, err := url.PathUnescape(chi.URLParam(r, "name"))
+				if err != nil {
+					http.Error(w, fmt.Sprintf("invalid attachment name: %v", err), http.StatusBadRequest)
+					return
+				}
+
+				attachment, err := storage.GetAttachment(r.Context(), ref, name)
+				if errors.Is(err, pgx.ErrNoRows) {
+					http.Error(w, fmt.Sprintf("paste %s has no attachment %s", ref, name), http.StatusNotFound)
+					return
+				}
+				if err != nil {
+					w.WriteHeader(422)
+					w.Write([]byte(fmt.Sprintf("error fetching attachment %s of paste %s: %v", name, ref, err)))
+					return
+				}
+
+				api.ServeAttachment(w, attachment)
+			},
+		),
+	)
+
+	return router
+}
+
+// login starts a new session for the user and sets its cookie.
+func login(w http.ResponseWriter, r *http.Request, storage Storage, account user.User) error {
+	token, expiration, err := storage.CreateSession(r.Context(), account)
+	if err != nil {
+		return err
+	}
+
+	api.StartSession(w, token, expiration)
+	return nil
+}
+
+// settings renders the settings page of the user, listing their api tokens.
+func settings(w http.ResponseWriter, r *http.Request, storage Storage, account user.User, secret, message string) {
+	tokens, err := storage.ListAPITokens(r.Context(), account)
+	if err != nil {
+		logging.
+			FromContext(r.Context()).
+			Error(err, "frontend.settings", "error listing api tokens")
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+
+	layouts.Base(
+		views.Settings(tokens, secret, message),
+	).Render(r.Context(), w)
+}
+
+// forks returns the public forks of a paste to be listed on its document page.
+// Failing to list them shouldn't prevent the paste from being rendered.
+func forks(r *http.Request, storage Storage, ref string) []paste.Paste {
+	forks, err := storage.ListForks(r.Context(), ref)
+	if err != nil {
+		logging.
+			FromContext(r.Context()).
+			Error(err, "frontend.dashboard", "error listing forks", "ref", ref)
+		return nil
+	}
+
+	return forks
 }
@@ -0,0 +1,571 @@
+package frontend_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/cookiejar"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/aexvir/skladka/internal/api"
+	"github.com/aexvir/skladka/internal/config"
+	"github.com/aexvir/skladka/internal/frontend"
+	"github.com/aexvir/skladka/internal/paste"
+	"github.com/aexvir/skladka/internal/storage"
+)
+
+// uploadLimit is the maximum size of the requests sent to the dashboard.
+const uploadLimit = 1 << 16
+
+// dashboard serves the frontend backed by a memory storage.
+type dashboard struct {
+	t      *testing.T
+	store  *storage.MemoryStorage
+	server *httptest.Server
+}
+
+func newDashboard(t *testing.T) *dashboard {
+	store := storage.NewMemoryStorage(
+		config.Config{
+			Core: config.Core{
+				EncryptionKey:   "supersecretkey==",
+				EncryptionSalt:  "6370b25f61f2025a0d4fcbb4aaf8859f",
+				SessionDuration: time.Hour,
+			},
+		},
+	)
+
+	server := httptest.NewServer(
+		api.WithUploadLimit(uploadLimit)(api.WithSessions(store)(frontend.DashboardRouter(store))),
+	)
+	t.Cleanup(server.Close)
+
+	return &dashboard{t: t, store: store, server: server}
+}
+
+// browser returns a client with its own cookies, that doesn't follow redirects.
+func (d *dashboard) browser() *http.Client {
+	jar, err := cookiejar.New(nil)
+	require.NoError(d.t, err)
+
+	return &http.Client{
+		Jar: jar,
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+func (d *dashboard) get(client *http.Client, path string) (int, string) {
+	res, err := client.Get(d.server.URL + path)
+	require.NoError(d.t, err)
+	return read(d.t, res)
+}
+
+func (d *dashboard) post(client *http.Client, path string, form url.Values) (int, string, string) {
+	res, err := client.PostForm(d.server.URL+path, form)
+	require.NoError(d.t, err)
+	status, body := read(d.t, res)
+	return status, body, res.Header.Get("Location")
+}
+
+// upload submits the form as multipart, along with the files, keyed by their names.
+func (d *dashboard) upload(client *http.Client, path string, form url.Values, files map[string][]byte) (int, string, string) {
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+
+	for key, values := range form {
+		for _, value := range values {
+			require.NoError(d.t, writer.WriteField(key, value))
+		}
+	}
+
+	for name, content := range files {
+		part, err := writer.CreateFormFile("files", name)
+		require.NoError(d.t, err)
+		_, err = part.Write(content)
+		require.NoError(d.t, err)
+	}
+	require.NoError(d.t, writer.Close())
+
+	res, err := client.Post(d.server.URL+path, writer.FormDataContentType(), &body)
+	require.NoError(d.t, err)
+	status, text := read(d.t, res)
+	return status, text, res.Header.Get("Location")
+}
+
+// create submits the creation form, returning the reference of the new paste.
+func (d *dashboard) create(client *http.Client, form url.Values) string {
+	status, _, location := d.post(client, "/", form)
+	require.Equal(d.t, http.StatusSeeOther, status)
+	return strings.TrimPrefix(location, "/")
+}
+
+func read(t *testing.T, res *http.Response) (int, string) {
+	defer res.Body.Close()
+	body, err := io.ReadAll(res.Body)
+	require.NoError(t, err)
+	return res.StatusCode, string(body)
+}
+
+func TestDashboardCreateAndRead(t *testing.T) {
+	d := newDashboard(t)
+	owner, visitor := d.browser(), d.browser()
+
+	status, _ := d.get(visitor, "/")
+	require.Equal(t, http.StatusOK, status)
+
+	ref := d.create(
+		owner, url.Values{
+			"title":   {"hello world"},
+			"content": {"fmt.Println(\"hello\")"},
+			"syntax":  {"go"},
+			"tags":    {"golang,example"},
+		},
+	)
+	require.NotEmpty(t, ref)
+
+	status, body := d.get(owner, "/"+ref)
+	require.Equal(t, http.StatusOK, status)
+	require.Contains(t, body, "hello world")
+
+	status, body = d.get(visitor, "/"+ref+"/raw")
+	require.Equal(t, http.StatusOK, status)
+	require.Equal(t, "fmt.Println(\"hello\")", body)
+
+	slug := d.create(owner, url.Values{"content": {"notes"}, "slug": {"Deploy Notes Q3"}})
+	require.Equal(t, "deploy-notes-q3", slug)
+
+	status, body = d.get(visitor, "/deploy-notes-q3/raw")
+	require.Equal(t, http.StatusOK, status)
+	require.Equal(t, "notes", body)
+
+	status, _, _ = d.post(visitor, "/", url.Values{"content": {"taken"}, "slug": {"deploy-notes-q3"}})
+	require.Equal(t, http.StatusBadRequest, status)
+
+	status, _ = d.get(visitor, "/missing")
+	require.Equal(t, 422, status)
+
+	status, _, _ = d.post(visitor, "/", url.Values{"content": {"  "}})
+	require.Equal(t, http.StatusBadRequest, status)
+}
+
+func TestDashboardEditAndDelete(t *testing.T) {
+	d := newDashboard(t)
+	owner, visitor := d.browser(), d.browser()
+
+	ref := d.create(owner, url.Values{"title": {"draft"}, "content": {"first"}})
+
+	// only the browser that created the paste holds its token
+	status, _, _ := d.post(visitor, "/"+ref+"/edit", url.Values{"content": {"hijacked"}})
+	require.Equal(t, http.StatusForbidden, status)
+
+	status, _, location := d.post(owner, "/"+ref+"/edit", url.Values{"title": {"final"}, "content": {"second"}})
+	require.Equal(t, http.StatusSeeOther, status)
+	require.Equal(t, "/"+ref, location)
+
+	status, body := d.get(visitor, "/"+ref+"/raw")
+	require.Equal(t, http.StatusOK, status)
+	require.Equal(t, "second", body)
+
+	status, _ = d.get(owner, "/"+ref+"/history")
+	require.Equal(t, http.StatusOK, status)
+
+	status, _, _ = d.post(visitor, "/"+ref+"/delete", nil)
+	require.Equal(t, http.StatusForbidden, status)
+
+	status, _, _ = d.post(owner, "/"+ref+"/delete", nil)
+	require.Equal(t, http.StatusSeeOther, status)
+
+	status, _ = d.get(visitor, "/"+ref)
+	require.Equal(t, 422, status)
+}
+
+func TestDashboardOwnerTokenStaysOutOfUrls(t *testing.T) {
+	d := newDashboard(t)
+	stranger, owner := d.browser(), d.browser()
+
+	ref, token, err := d.store.CreatePaste(context.Background(), paste.Paste{Content: "mine"})
+	require.NoError(t, err)
+
+	// tokens in the query string are ignored, they'd leak into logs and the browser history
+	status, _ := d.get(stranger, "/"+ref+"/edit?token="+token)
+	require.Equal(t, http.StatusForbidden, status)
+
+	status, _, _ = d.post(stranger, "/"+ref+"/delete?token="+token, nil)
+	require.Equal(t, http.StatusForbidden, status)
+
+	status, _, _ = d.post(stranger, "/"+ref+"/claim", url.Values{"token": {"nope"}})
+	require.Equal(t, http.StatusForbidden, status)
+
+	// the token is posted once and remembered in a cookie from then on
+	status, _, location := d.post(owner, "/"+ref+"/claim", url.Values{"token": {token}})
+	require.Equal(t, http.StatusSeeOther, status)
+	require.Equal(t, "/"+ref, location)
+
+	status, _ = d.get(owner, "/"+ref+"/edit")
+	require.Equal(t, http.StatusOK, status)
+}
+
+func TestDashboardFiles(t *testing.T) {
+	d := newDashboard(t)
+	visitor := d.browser()
+
+	ref, _, err := d.store.CreatePaste(
+		context.Background(), paste.Paste{
+			Title: "project",
+			Files: []paste.File{
+				{Name: "main.go", Syntax: "go", Content: "package main"},
+				{Name: "read me.md", Syntax: "markdown", Content: "# project"},
+			},
+		},
+	)
+	require.NoError(t, err)
+
+	status, body := d.get(visitor, "/"+ref)
+	require.Equal(t, http.StatusOK, status)
+	require.Contains(t, body, "/"+ref+"/raw/read%20me.md")
+
+	tests := map[string]struct {
+		path    string
+		status  int
+		content string
+	}{
+		"paste":        {path: "/raw", status: http.StatusOK, content: "package main"},
+		"first file":   {path: "/raw/main.go", status: http.StatusOK, content: "package main"},
+		"escaped name": {path: "/raw/read%20me.md", status: http.StatusOK, content: "# project"},
+		"missing file": {path: "/raw/missing.go", status: http.StatusNotFound},
+	}
+
+	for name, test := range tests {
+		t.Run(
+			name, func(t *testing.T) {
+				status, body := d.get(visitor, "/"+ref+test.path)
+				require.Equal(t, test.status, status)
+				if test.content != "" {
+					require.Equal(t, test.content, body)
+				}
+			},
+		)
+	}
+}
+
+func TestDashboardUpload(t *testing.T) {
+	d := newDashboard(t)
+	owner := d.browser()
+
+	status, _, location := d.upload(
+		owner, "/",
+		url.Values{"title": {"uploaded"}, "content": {"ignored"}, "syntax": {"plaintext"}},
+		map[string][]byte{"main.go": []byte("package main")},
+	)
+	require.Equal(t, http.StatusSeeOther, status)
+
+	p, err := d.store.GetPaste(context.Background(), strings.TrimPrefix(location, "/"))
+	require.NoError(t, err)
+	require.Equal(t, "uploaded", p.Title)
+	require.Equal(t, "package main", p.Content)
+	require.Equal(t, "go", p.Syntax)
+	require.Equal(t, []paste.File{{Name: "main.go", Syntax: "go", Content: "package main"}}, p.Files)
+
+	tests := map[string]struct {
+		form   url.Values
+		files  map[string][]byte
+		status int
+	}{
+		"several files": {
+			files:  map[string][]byte{"main.go": []byte("package main"), "config.yml": []byte("key: value")},
+			status: http.StatusSeeOther,
+		},
+		"binary file": {
+			files:  map[string][]byte{"image.png": {0x89, 0x50, 0x4e, 0x47, 0xff, 0xfe}},
+			status: http.StatusSeeOther,
+		},
+		"protected attachment": {
+			form:   url.Values{"content": {"see attached"}, "password": {"hunter2"}},
+			files:  map[string][]byte{"image.png": {0x89, 0x50, 0x4e, 0x47, 0xff, 0xfe}},
+			status: http.StatusBadRequest,
+		},
+		"encrypted": {
+			form:   url.Values{"encrypted": {"on"}},
+			files:  map[string][]byte{"main.go": []byte("package main")},
+			status: http.StatusBadRequest,
+		},
+		"too large": {
+			files:  map[string][]byte{"large.txt": bytes.Repeat([]byte("a"), uploadLimit)},
+			status: http.StatusRequestEntityTooLarge,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(
+			name, func(t *testing.T) {
+				status, _, _ := d.upload(owner, "/", test.form, test.files)
+				require.Equal(t, test.status, status)
+			},
+		)
+	}
+}
+
+func TestDashboardAttachments(t *testing.T) {
+	d := newDashboard(t)
+	owner := d.browser()
+	visitor := d.browser()
+
+	image := []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+	archive := []byte{0x1f, 0x8b, 0x08, 0x00, 0xff}
+
+	status, _, location := d.upload(
+		owner, "/",
+		url.Values{"title": {"screenshots"}, "content": {"see attached"}, "syntax": {"plaintext"}},
+		map[string][]byte{"screen shot.png": image, "logs.gz": archive},
+	)
+	require.Equal(t, http.StatusSeeOther, status)
+	ref := strings.TrimPrefix(location, "/")
+
+	// images are previewed on the document page, the rest is linked
+	status, body := d.get(visitor, "/"+ref)
+	require.Equal(t, http.StatusOK, status)
+	require.Contains(t, body, `<img src="/`+ref+`/attachments/screen%20shot.png"`)
+	require.Contains(t, body, `href="/`+ref+`/attachments/logs.gz"`)
+
+	tests := map[string]struct {
+		name        string
+		status      int
+		contenttype string
+		disposition string
+		body        []byte
+	}{
+		"image":    {name: "screen%20shot.png", status: http.StatusOK, contenttype: "image/png", disposition: "inline", body: image},
+		"download": {name: "logs.gz", status: http.StatusOK, contenttype: "application/x-gzip", disposition: "attachment", body: archive},
+		"missing":  {name: "missing.png", status: http.StatusNotFound},
+	}
+
+	for name, test := range tests {
+		t.Run(
+			name, func(t *testing.T) {
+				res, err := visitor.Get(d.server.URL + "/" + ref + "/attachments/" + test.name)
+				require.NoError(t, err)
+				status, body := read(t, res)
+				require.Equal(t, test.status, status)
+
+				if test.body == nil {
+					return
+				}
+
+				require.Equal(t, test.contenttype, res.Header.Get("Content-Type"))
+				require.True(t, strings.HasPrefix(res.Header.Get("Content-Disposition"), test.disposition))
+				require.Equal(t, "nosniff", res.Header.Get("X-Content-Type-Options"))
+				require.Equal(t, string(test.body), body)
+			},
+		)
+	}
+
+	// serving the attachments doesn't count as reading the paste
+	p, err := d.store.GetPaste(context.Background(), ref)
+	require.NoError(t, err)
+	require.Equal(t, 2, p.Views)
+}
+
+func TestDashboardPasswordProtected(t *testing.T) {
+	d := newDashboard(t)
+	visitor := d.browser()
+
+	ref := d.create(d.browser(), url.Values{"content": {"secret stuff"}, "password": {"hunter2"}})
+
+	status, body := d.get(visitor, "/"+ref)
+	require.Equal(t, http.StatusOK, status)
+	require.Contains(t, body, "password protected paste")
+	require.NotContains(t, body, "secret stuff")
+
+	status, _, _ = d.post(visitor, "/"+ref+"/unlock", url.Values{"password": {"wrong"}})
+	require.Equal(t, http.StatusForbidden, status)
+
+	status, _, _ = d.post(visitor, "/"+ref+"/unlock", url.Values{"password": {"hunter2"}})
+	require.Equal(t, http.StatusOK, status)
+}
+
+func TestDashboardClientEncrypted(t *testing.T) {
+	d := newDashboard(t)
+	visitor := d.browser()
+
+	sealed := base64.StdEncoding.EncodeToString([]byte("nonce-012345sealed content and tag"))
+
+	status, _, _ := d.post(visitor, "/", url.Values{"content": {"plaintext"}, "encrypted": {"on"}})
+	require.Equal(t, http.StatusBadRequest, status, "the content must have been encrypted by the browser")
+
+	ref := d.create(d.browser(), url.Values{"content": {sealed}, "encrypted": {"on"}})
+
+	status, body := d.get(visitor, "/"+ref)
+	require.Equal(t, http.StatusOK, status)
+	require.Contains(t, body, "Sealed.open")
+
+	// anything but browsers gets the ciphertext, flagged as such
+	res, err := visitor.Get(d.server.URL + "/" + ref + "/raw")
+	require.NoError(t, err)
+	require.Equal(t, "true", res.Header.Get("X-Skd-Client-Encrypted"))
+	status, body = read(t, res)
+	require.Equal(t, http.StatusOK, status)
+	require.Equal(t, sealed, body)
+
+	req, err := http.NewRequest(http.MethodGet, d.server.URL+"/"+ref+"/raw", nil)
+	require.NoError(t, err)
+	req.Header.Set("Accept", "text/html")
+	res, err = visitor.Do(req)
+	require.NoError(t, err)
+	status, body = read(t, res)
+	require.Equal(t, http.StatusOK, status)
+	require.Contains(t, body, "Sealed.open")
+}
+
+func TestDashboardBurnAfterReading(t *testing.T) {
+	d := newDashboard(t)
+	visitor := d.browser()
+
+	ref := d.create(d.browser(), url.Values{"content": {"read once"}, "burn": {"on"}, "burnafter": {"1"}})
+
+	status, body := d.get(visitor, "/"+ref+"/raw")
+	require.Equal(t, http.StatusOK, status)
+	require.Equal(t, "read once", body)
+
+	status, _ = d.get(visitor, "/"+ref+"/raw")
+	require.Equal(t, 422, status)
+}
+
+func TestDashboardBurnAfterReadingHistory(t *testing.T) {
+	d := newDashboard(t)
+	owner, visitor := d.browser(), d.browser()
+
+	ref := d.create(owner, url.Values{"content": {"read once"}, "burn": {"on"}, "burnafter": {"1"}})
+
+	// the history doesn't count the read, so it's refused to anyone but the owner
+	status, _ := d.get(visitor, "/"+ref+"/history")
+	require.Equal(t, http.StatusForbidden, status)
+
+	status, body := d.get(owner, "/"+ref+"/history")
+	require.Equal(t, http.StatusOK, status)
+	require.Contains(t, body, "read once")
+
+	// neither look burned the paste, which is still there to be read once
+	status, body = d.get(visitor, "/"+ref+"/raw")
+	require.Equal(t, http.StatusOK, status)
+	require.Equal(t, "read once", body)
+
+	status, _ = d.get(visitor, "/"+ref+"/raw")
+	require.Equal(t, 422, status)
+}
+
+func TestDashboardArchive(t *testing.T) {
+	d := newDashboard(t)
+	visitor := d.browser()
+
+	d.create(d.browser(), url.Values{"title": {"listed paste"}, "content": {"a"}, "syntax": {"go"}, "tags": {"shared"}})
+	d.create(d.browser(), url.Values{"title": {"other paste"}, "content": {"b"}, "syntax": {"python"}})
+	d.create(d.browser(), url.Values{"title": {"hidden paste"}, "content": {"c"}, "unlisted": {"on"}, "tags": {"shared"}})
+
+	tests := map[string]struct {
+		path     string
+		status   int
+		contains []string
+		excludes []string
+	}{
+		"archive": {
+			path:     "/archive",
+			status:   http.StatusOK,
+			contains: []string{"listed paste", "other paste"},
+			excludes: []string{"hidden paste"},
+		},
+		"filtered archive": {
+			path:     "/archive?syntax=go",
+			status:   http.StatusOK,
+			contains: []string{"listed paste"},
+			excludes: []string{"other paste", "hidden paste"},
+		},
+		"invalid cursor": {
+			path:   "/archive?cursor=nope",
+			status: http.StatusBadRequest,
+		},
+		"tags": {
+			path:     "/tags",
+			status:   http.StatusOK,
+			contains: []string{"shared"},
+		},
+		"tag": {
+			path:     "/tags/shared",
+			status:   http.StatusOK,
+			contains: []string{"listed paste"},
+			excludes: []string{"other paste", "hidden paste"},
+		},
+		"search": {
+			path:     "/search?q=listed",
+			status:   http.StatusOK,
+			contains: []string{"listed paste"},
+			excludes: []string{"other paste", "hidden paste"},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			status, body := d.get(visitor, test.path)
+			require.Equal(t, test.status, status)
+
+			for _, text := range test.contains {
+				require.Contains(t, body, text)
+			}
+			for _, text := range test.excludes {
+				require.NotContains(t, body, text)
+			}
+		})
+	}
+}
+
+func TestDashboardAccounts(t *testing.T) {
+	d := newDashboard(t)
+	client := d.browser()
+
+	status, _ := d.get(client, "/me")
+	require.Equal(t, http.StatusSeeOther, status)
+
+	status, _, location := d.post(client, "/register", url.Values{"username": {"alice"}, "password": {"correct horse"}})
+	require.Equal(t, http.StatusSeeOther, status)
+	require.Equal(t, "/", location)
+
+	ref := d.create(client, url.Values{"title": {"owned paste"}, "content": {"mine"}})
+
+	status, body := d.get(client, "/me")
+	require.Equal(t, http.StatusOK, status)
+	require.Contains(t, body, "owned paste")
+
+	status, _, _ = d.post(client, "/me/delete", url.Values{"refs": {ref}})
+	require.Equal(t, http.StatusSeeOther, status)
+
+	status, _ = d.get(d.browser(), "/"+ref)
+	require.Equal(t, 422, status)
+
+	status, _ = d.get(client, "/logout")
+	require.Equal(t, http.StatusSeeOther, status)
+
+	status, _ = d.get(client, "/me")
+	require.Equal(t, http.StatusSeeOther, status)
+
+	status, _, _ = d.post(client, "/register", url.Values{"username": {"alice"}, "password": {"another one"}})
+	require.Equal(t, http.StatusBadRequest, status)
+
+	status, _, _ = d.post(client, "/login", url.Values{"username": {"alice"}, "password": {"wrong"}})
+	require.Equal(t, http.StatusForbidden, status)
+
+	status, _, _ = d.post(client, "/login", url.Values{"username": {"alice"}, "password": {"correct horse"}})
+	require.Equal(t, http.StatusSeeOther, status)
+
+	status, _ = d.get(client, "/me")
+	require.Equal(t, http.StatusOK, status)
+}
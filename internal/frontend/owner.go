@@ -0,0 +1,54 @@
+package frontend
+
+import (
+	"net/http"
+	"time"
+)
+
+// tokenCookie holds the owner token of a paste in the browser that created it.
+// The cookie is scoped to the paste path, so each paste gets its own token.
+const tokenCookie = "skd-token"
+
+// ownertoken returns the owner token sent with the request.
+// Tokens explicitly sent in the body of a form take precedence over the cookie. Tokens in
+// the query string are ignored, urls end up in logs, proxies and the browser history.
+func ownertoken(r *http.Request) string {
+	if token := r.PostFormValue("token"); token != "" {
+		return token
+	}
+
+	if cookie, err := r.Cookie(tokenCookie); err == nil {
+		return cookie.Value
+	}
+
+	return ""
+}
+
+// remember stores the owner token of a paste in a cookie scoped to the paste path.
+func remember(w http.ResponseWriter, ref, token string) {
+	http.SetCookie(
+		w,
+		&http.Cookie{
+			Name:     tokenCookie,
+			Value:    token,
+			Path:     "/" + ref,
+			MaxAge:   int((365 * 24 * time.Hour).Seconds()),
+			HttpOnly: true,
+			SameSite: http.SameSiteLaxMode,
+		},
+	)
+}
+
+// forget removes the owner token cookie of a paste.
+func forget(w http.ResponseWriter, ref string) {
+	http.SetCookie(
+		w,
+		&http.Cookie{
+			Name:     tokenCookie,
+			Path:     "/" + ref,
+			MaxAge:   -1,
+			HttpOnly: true,
+			SameSite: http.SameSiteLaxMode,
+		},
+	)
+}
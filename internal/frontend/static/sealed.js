@@ -0,0 +1,80 @@
+// client side encryption of pastes, the server only ever sees the ciphertext.
+// pastes are encrypted with aes-gcm using a random 256 bit key, the content sent to
+// the server is the base64 encoded nonce followed by the ciphertext, while the key
+// is kept in the fragment of the url, which browsers never send along.
+window.Sealed = (
+    function() {
+        const algorithm = 'AES-GCM'
+        const noncelen = 12
+
+        // converted in chunks, spreading large pastes at once overflows the stack
+        const encode = (bytes) => {
+            let binary = ''
+            for (let i = 0; i < bytes.length; i += 0x8000) {
+                binary += String.fromCharCode(...bytes.subarray(i, i + 0x8000))
+            }
+            return btoa(binary)
+        }
+        const decode = (text) => Uint8Array.from(atob(text), (c) => c.charCodeAt(0))
+
+        // keys are base64url encoded without padding, so they can be used as the fragment as is
+        const encodekey = (bytes) => encode(bytes).replace(/\+/g, '-').replace(/\//g, '_').replace(/=+$/, '')
+        const decodekey = (text) => decode(text.replace(/-/g, '+').replace(/_/g, '/'))
+
+        const importkey = (raw) => crypto.subtle.importKey('raw', raw, algorithm, false, ['encrypt', 'decrypt'])
+
+        return {
+            // key returns the key in the fragment of the current url, or an empty string
+            key: function() {
+                return window.location.hash.replace(/^#/, '')
+            },
+            // seal encrypts the text with the given key, generating a new one when omitted.
+            // resolves to the content to send to the server and the key to put in the url.
+            seal: async function(text, key) {
+                const raw = key ? decodekey(key) : crypto.getRandomValues(new Uint8Array(32))
+                const nonce = crypto.getRandomValues(new Uint8Array(noncelen))
+
+                const ciphertext = await crypto.subtle.encrypt(
+                    { name: algorithm, iv: nonce },
+                    await importkey(raw),
+                    new TextEncoder().encode(text),
+                )
+
+                const sealed = new Uint8Array(noncelen + ciphertext.byteLength)
+                sealed.set(nonce)
+                sealed.set(new Uint8Array(ciphertext), noncelen)
+
+                return { content: encode(sealed), key: encodekey(raw) }
+            },
+            // open decrypts content produced by seal, rejecting when the key doesn't match
+            open: async function(content, key) {
+                const sealed = decode(content)
+
+                const plaintext = await crypto.subtle.decrypt(
+                    { name: algorithm, iv: sealed.slice(0, noncelen) },
+                    await importkey(decodekey(key)),
+                    sealed.slice(noncelen),
+                )
+
+                return new TextDecoder().decode(plaintext)
+            },
+            // propagate appends the key to the links pointing to the paste, so it stays
+            // around when navigating to its history, raw view, fork or edit page
+            propagate: function(reference) {
+                const key = this.key()
+                if (!key) {
+                    return
+                }
+
+                for (const element of document.querySelectorAll('a[href], form[method="GET"][action]')) {
+                    const attribute = element.tagName === 'A' ? 'href' : 'action'
+                    const target = element.getAttribute(attribute).replace(/#.*$/, '')
+
+                    if (target === `/${reference}` || target.startsWith(`/${reference}/`)) {
+                        element.setAttribute(attribute, `${target}#${key}`)
+                    }
+                }
+            },
+        }
+    }
+)()
@@ -9,15 +9,22 @@
 //   - Handling data display and user input
 //
 // # Available Views
-//   - Archive: Displays a list of all public pastes
-//   - Creation: Form for creating new pastes
+//   - Archive: Displays the public pastes, filterable and loaded page by page
+//   - Search: Full-text search results over the public pastes
+//   - Tags and Tag: Most used tags with their counts, and the public pastes carrying a tag
+//   - Dashboard: Lists every paste of the logged in user, with bulk deletion
+//   - Login and Register: Forms for logging in and creating user accounts
+//   - Settings: Account settings for managing personal access tokens
+//   - Creation: Form for creating new pastes, optionally pre-filled when forking another paste
 //   - Document: Displays a single paste with its content and metadata
+//   - Edition: Form for owners to edit an existing paste
+//   - History: Diff between any two revisions of a paste
 //
 // # Example Usage
 //
 //	func renderCreationPage(w http.ResponseWriter, r *http.Request) {
 //		layouts.Base(
-//			views.Creation("New Paste"),
+//			views.Creation("New Paste", paste.Paste{}),
 //		).Render(r.Context(), w)
 //	}
 //
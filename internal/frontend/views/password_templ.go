@@ -88,7 +88,7 @@ func RawPasswordPrompt(reference string) templ.Component {
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
-		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 5, "password protected paste</h2><div class=\"flex flex-col lg:flex-row w-full gap-2 lg:gap-0\"><input type=\"password\" id=\"password\" name=\"password\" placeholder=\"password\" required class=\"h-10 p-2 flex-grow bg-muted text-main border border-main rounded lg:rounded-r-none focus:outline-none focus:border-accent\"> <button onclick=\"unlockPasswordProtectedPaste()\" class=\"h-10 px-4 py-2 rounded lg:rounded-l-none bg-accent text-accent-muted hover:bg-accent-muted transition-all duration-200\">unlock</button></div></div></div><script>\n\t\tdocument\n\t\t\t.getElementById('password')\n\t\t\t.addEventListener(\n\t\t\t\t'keyup', event => {\n\t\t\t\t\tif (event.key === 'Enter') {\n\t\t\t\t\t\tunlockPasswordProtectedPaste()\n\t\t\t\t\t}\n\t\t\t\t}\n\t\t\t)\n\n\t\tconst unlockPasswordProtectedPaste = () => {\n\t\t\tconst password = document.getElementById('password').value\n\t\t\tfetch(\n\t\t\t\twindow.location.href,\n\t\t\t\t{ headers: { 'x-skd-password': password } }\n\t\t\t)\n\t\t\t.then(response => response.text())\n\t\t\t.then(\n\t\t\t\ttext => {\n\t\t\t\t\tdocument.open('text/plain')\n\t\t\t\t\tdocument.write(text)\n\t\t\t\t\tdocument.close()\n\t\t\t\t}\n\t\t\t)\n\t\t}\n\t</script>")
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 5, "password protected paste</h2><div class=\"flex flex-col lg:flex-row w-full gap-2 lg:gap-0\"><input type=\"password\" id=\"password\" name=\"password\" placeholder=\"password\" required class=\"h-10 p-2 flex-grow bg-muted text-main border border-main rounded lg:rounded-r-none focus:outline-none focus:border-accent\"> <button onclick=\"unlockPasswordProtectedPaste()\" class=\"h-10 px-4 py-2 rounded lg:rounded-l-none bg-accent text-accent-muted hover:bg-accent-muted transition-all duration-200\">unlock</button></div></div></div><script>\n\t\tdocument\n\t\t\t.getElementById('password')\n\t\t\t.addEventListener(\n\t\t\t\t'keyup', event => {\n\t\t\t\t\tif (event.key === 'Enter') {\n\t\t\t\t\t\tunlockPasswordProtectedPaste()\n\t\t\t\t\t}\n\t\t\t\t}\n\t\t\t)\n\n\t\tconst unlockPasswordProtectedPaste = () => {\n\t\t\tconst password = document.getElementById('password').value\n\t\t\tfetch(\n\t\t\t\twindow.location.href,\n\t\t\t\t{ headers: { 'x-skd-password': password } }\n\t\t\t)\n\t\t\t.then(\n\t\t\t\tasync response => {\n\t\t\t\t\tconst text = await response.text()\n\n\t\t\t\t\t// end-to-end encrypted pastes are decrypted with the key in the url\n\t\t\t\t\tif (response.headers.get('x-skd-client-encrypted') === 'true') {\n\t\t\t\t\t\treturn window.Sealed.open(text, window.Sealed.key())\n\t\t\t\t\t}\n\n\t\t\t\t\treturn text\n\t\t\t\t}\n\t\t\t)\n\t\t\t.then(\n\t\t\t\ttext => {\n\t\t\t\t\tdocument.open('text/plain')\n\t\t\t\t\tdocument.write(text)\n\t\t\t\t\tdocument.close()\n\t\t\t\t}\n\t\t\t)\n\t\t}\n\t</script>")
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
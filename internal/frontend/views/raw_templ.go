@@ -0,0 +1,68 @@
+// Code generated by templ - DO NOT EDIT.
+
+package views
+
+//lint:file-ignore SA4006 This context is only used if a nested component is present.
+
+import "github.com/a-h/templ"
+import templruntime "github.com/a-h/templ/runtime"
+
+// SealedRaw is the raw view of end-to-end encrypted pastes opened in a browser.
+// The content is decrypted with the key found in the fragment of the url and shown as
+// plain text, clients that aren't browsers get the ciphertext instead.
+func SealedRaw(content string) templ.Component {
+	return templruntime.GeneratedTemplate(func(templ_7745c5c3_Input templruntime.GeneratedComponentInput) (templ_7745c5c3_Err error) {
+		templ_7745c5c3_W, ctx := templ_7745c5c3_Input.Writer, templ_7745c5c3_Input.Context
+		if templ_7745c5c3_CtxErr := ctx.Err(); templ_7745c5c3_CtxErr != nil {
+			return templ_7745c5c3_CtxErr
+		}
+		templ_7745c5c3_Buffer, templ_7745c5c3_IsBuffer := templruntime.GetBuffer(templ_7745c5c3_W)
+		if !templ_7745c5c3_IsBuffer {
+			defer func() {
+				templ_7745c5c3_BufErr := templruntime.ReleaseBuffer(templ_7745c5c3_Buffer)
+				if templ_7745c5c3_Err == nil {
+					templ_7745c5c3_Err = templ_7745c5c3_BufErr
+				}
+			}()
+		}
+		ctx = templ.InitializeContext(ctx)
+		templ_7745c5c3_Var1 := templ.GetChildren(ctx)
+		if templ_7745c5c3_Var1 == nil {
+			templ_7745c5c3_Var1 = templ.NopComponent
+		}
+		ctx = templ.ClearChildren(ctx)
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 1, "<html><head><meta charset=\"UTF-8\"><title>skladka</title><script src=\"/static/sealed.js\"></script></head><body><pre id=\"raw-content\" style=\"white-space: pre-wrap; word-wrap: break-word;\"></pre>")
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		templ_7745c5c3_Err = openRaw(content).Render(ctx, templ_7745c5c3_Buffer)
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 2, "</body></html>")
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		return nil
+	})
+}
+
+func openRaw(content string) templ.ComponentScript {
+	return templ.ComponentScript{
+		Name: `__templ_openRaw_802d`,
+		Function: `function __templ_openRaw_802d(content){const target = document.getElementById('raw-content')
+    window.Sealed.open(content, window.Sealed.key()).then(
+        (text) => {
+            target.textContent = text
+        },
+        () => {
+            target.textContent = 'this paste is end-to-end encrypted, the link is missing its key'
+        }
+    )
+}`,
+		Call:       templ.SafeScript(`__templ_openRaw_802d`, content),
+		CallInline: templ.SafeScriptInline(`__templ_openRaw_802d`, content),
+	}
+}
+
+var _ = templruntime.GeneratedTemplate
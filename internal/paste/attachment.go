@@ -0,0 +1,80 @@
+package paste
+
+import (
+	"strings"
+
+	"github.com/aexvir/skladka/internal/errors"
+)
+
+// MaxAttachments is the maximum number of attachments a single paste can hold.
+const MaxAttachments = 10
+
+// previewable are the content types of the images browsers can preview inline.
+var previewable = map[string]bool{
+	"image/png":  true,
+	"image/jpeg": true,
+	"image/gif":  true,
+	"image/webp": true,
+}
+
+// Attachment is a binary file attached to a paste, like a screenshot, stored apart
+// from the paste and served as is with its content type.
+type Attachment struct {
+	Name        string `json:"name"`
+	ContentType string `json:"content_type"`
+	Size        int64  `json:"size"`
+
+	// Data is the content of the attachment, only loaded when it's created or served.
+	Data []byte `json:"-"`
+}
+
+// Image reports whether the attachment is an image that can be previewed inline.
+func (a Attachment) Image() bool {
+	return previewable[a.ContentType]
+}
+
+// Attachment returns the attachment of the paste with the given name, if any.
+func (p *Paste) Attachment(name string) (Attachment, bool) {
+	for _, a := range p.Attachments {
+		if a.Name == name {
+			return a, true
+		}
+	}
+	return Attachment{}, false
+}
+
+// validateAttachments checks the attachments of the paste. They're served apart from the
+// paste, without its password, without counting reads and without the key of the client,
+// so they can't be attached to pastes protected in any of those ways.
+func (p *Paste) validateAttachments() []error {
+	var errs []error
+
+	if len(p.Attachments) > MaxAttachments {
+		errs = append(errs, errors.Errorf("a paste can't have more than %d attachments", MaxAttachments))
+	}
+
+	switch {
+	case p.Password != nil:
+		errs = append(errs, errors.New("password protected pastes can't have attachments"))
+	case p.BurnAfter != nil:
+		errs = append(errs, errors.New("pastes burned after reading can't have attachments"))
+	case p.ClientEncrypted:
+		errs = append(errs, errors.New("client encrypted pastes can't have attachments"))
+	}
+
+	names := make(map[string]bool, len(p.Attachments))
+	for _, a := range p.Attachments {
+		if err := checkname(a.Name); err != nil {
+			errs = append(errs, err)
+		} else if names[a.Name] {
+			errs = append(errs, errors.Errorf("duplicated attachment name %q", a.Name))
+		}
+		names[a.Name] = true
+
+		if strings.TrimSpace(a.ContentType) == "" {
+			errs = append(errs, errors.Errorf("attachment %q has no content type", a.Name))
+		}
+	}
+
+	return errs
+}
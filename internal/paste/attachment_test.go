@@ -0,0 +1,80 @@
+package paste_test
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/aexvir/skladka/internal/paste"
+)
+
+func TestValidateAttachments(t *testing.T) {
+	attachment := func(name, contenttype string) paste.Attachment {
+		return paste.Attachment{Name: name, ContentType: contenttype, Data: []byte{0x89, 0x50, 0x4e, 0x47}}
+	}
+
+	many := make([]paste.Attachment, paste.MaxAttachments+1)
+	for i := range many {
+		many[i] = attachment(strconv.Itoa(i)+".png", "image/png")
+	}
+
+	password := "hunter2"
+	reads := 1
+
+	tests := map[string]struct {
+		paste   paste.Paste
+		invalid bool
+	}{
+		"single attachment": {
+			paste: paste.Paste{Attachments: []paste.Attachment{attachment("screenshot.png", "image/png")}},
+		},
+		"with content": {
+			paste: paste.Paste{Content: "see attached", Attachments: []paste.Attachment{attachment("logs.gz", "application/x-gzip")}},
+		},
+		"missing name": {
+			paste:   paste.Paste{Attachments: []paste.Attachment{attachment(" ", "image/png")}},
+			invalid: true,
+		},
+		"slash in name": {
+			paste:   paste.Paste{Attachments: []paste.Attachment{attachment("img/screenshot.png", "image/png")}},
+			invalid: true,
+		},
+		"duplicated names": {
+			paste:   paste.Paste{Attachments: []paste.Attachment{attachment("a.png", "image/png"), attachment("a.png", "image/png")}},
+			invalid: true,
+		},
+		"missing content type": {
+			paste:   paste.Paste{Attachments: []paste.Attachment{attachment("a.png", "")}},
+			invalid: true,
+		},
+		"too many attachments": {
+			paste:   paste.Paste{Attachments: many},
+			invalid: true,
+		},
+		"password protected": {
+			paste:   paste.Paste{Password: &password, Attachments: []paste.Attachment{attachment("a.png", "image/png")}},
+			invalid: true,
+		},
+		"burn after reading": {
+			paste:   paste.Paste{BurnAfter: &reads, Attachments: []paste.Attachment{attachment("a.png", "image/png")}},
+			invalid: true,
+		},
+		"client encrypted": {
+			paste:   paste.Paste{ClientEncrypted: true, Attachments: []paste.Attachment{attachment("a.png", "image/png")}},
+			invalid: true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := test.paste.Validate()
+			if test.invalid {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+		})
+	}
+}
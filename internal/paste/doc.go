@@ -5,12 +5,28 @@
 // A paste can be:
 //   - Public or private (controlled via the Public field)
 //   - Time-limited (via the optional Expiration field)
+//   - Burned after a number of reads (via the optional BurnAfter field)
 //   - Syntax highlighted (via the optional Syntax field)
 //   - Tagged for organization (via the Tags field)
+//   - Forked from another paste (via the optional Parent field)
+//   - Owned by a registered user (via the optional Owner field)
+//   - End-to-end encrypted by the client (via the ClientEncrypted field), the server
+//     only ever sees the ciphertext while the key travels in the fragment of the url
+//   - Split in several named files (via the optional Files field), each with its own
+//     syntax, the first of them being mirrored as the content and syntax of the paste;
+//     the syntax of uploaded files is inferred from their names with SyntaxOf
+//   - Carrying binary attachments (via the optional Attachments field), like screenshots,
+//     served as they are with their content type; images can be previewed inline
 //
 // The Reference field is a unique identifier generated by the storage layer when
 // creating a new paste. It is used to retrieve the paste later.
 //
+// Editing a paste keeps its previous content around as a Revision, so the history
+// of a paste can be reviewed and compared later on.
+//
+// Tags used across public pastes are summarized as Tag values, carrying how many
+// pastes use each of them, for browsing and autocompleting tags.
+//
 // Example usage:
 //
 //	paste := &paste.Paste{
@@ -23,10 +39,21 @@
 //
 // Field validation rules:
 //   - Title: Optional, but if provided must not be empty
-//   - Content: Required, must not be empty
+//   - Content: Required, must not be empty, unless the paste has files or attachments
+//   - Files: Optional, at most MaxFiles of them, with unique names without slashes
+//     and non-empty contents
+//   - Attachments: Optional, at most MaxAttachments of them, with unique names without
+//     slashes and a content type; not allowed on password protected, burn after reading
+//     nor client encrypted pastes, as attachments are served without unlocking the paste
 //   - Syntax: Optional, but if provided must be a valid syntax highlighter identifier
 //   - Tags: Optional, but if provided each tag must be non-empty
 //   - Expiration: Optional, but if provided must be in the future
+//   - BurnAfter: Optional, but if provided must allow at least one read
 //   - Public: Required, determines paste visibility
+//   - Parent: Optional, reference of the paste this one was forked from
+//   - ClientEncrypted: Optional, if set the content must be the base64 encoded
+//     aes-gcm nonce and ciphertext produced by the client
+//   - Slug: Optional, normalized by the storage layer and used as the reference,
+//     it can't be one of the reserved top level routes nor taken by another paste
 //   - Reference: Read-only, set by storage layer
 package paste
@@ -0,0 +1,50 @@
+package paste
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aexvir/skladka/internal/errors"
+)
+
+const day = 24 * time.Hour
+
+// ParseDuration parses an expiration duration such as the ones offered by the frontend.
+// On top of the units supported by time.ParseDuration it also accepts days and weeks,
+// e.g. 1d or 2w. Only positive durations are considered valid.
+func ParseDuration(value string) (time.Duration, error) {
+	value = strings.TrimSpace(value)
+
+	var (
+		duration time.Duration
+		err      error
+	)
+
+	switch {
+	case strings.HasSuffix(value, "d"):
+		duration, err = multiple(strings.TrimSuffix(value, "d"), day)
+	case strings.HasSuffix(value, "w"):
+		duration, err = multiple(strings.TrimSuffix(value, "w"), 7*day)
+	default:
+		duration, err = time.ParseDuration(value)
+	}
+
+	if err != nil {
+		return 0, errors.Wrapf(err, "invalid duration %q", value)
+	}
+
+	if duration <= 0 {
+		return 0, errors.Errorf("duration %q must be positive", value)
+	}
+
+	return duration, nil
+}
+
+func multiple(value string, unit time.Duration) (time.Duration, error) {
+	count, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, err
+	}
+	return time.Duration(count) * unit, nil
+}
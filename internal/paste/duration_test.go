@@ -0,0 +1,41 @@
+package paste_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/aexvir/skladka/internal/paste"
+)
+
+func TestParseDuration(t *testing.T) {
+	tests := map[string]struct {
+		value    string
+		expected time.Duration
+		invalid  bool
+	}{
+		"minutes":  {value: "10m", expected: 10 * time.Minute},
+		"hours":    {value: "1h", expected: time.Hour},
+		"days":     {value: "1d", expected: 24 * time.Hour},
+		"weeks":    {value: "2w", expected: 14 * 24 * time.Hour},
+		"empty":    {value: "", invalid: true},
+		"garbage":  {value: "soon", invalid: true},
+		"days nan": {value: "xd", invalid: true},
+		"negative": {value: "-1h", invalid: true},
+		"zero":     {value: "0d", invalid: true},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			duration, err := paste.ParseDuration(test.value)
+			if test.invalid {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, test.expected, duration)
+		})
+	}
+}
@@ -0,0 +1,80 @@
+package paste
+
+import (
+	"strings"
+
+	"github.com/aexvir/skladka/internal/errors"
+)
+
+// MaxFiles is the maximum number of files a single paste can hold.
+const MaxFiles = 20
+
+// File is one of the named files of a paste, each highlighted with its own syntax.
+type File struct {
+	Name    string `json:"name"`
+	Syntax  string `json:"syntax"`
+	Content string `json:"content"`
+}
+
+// File returns the file of the paste with the given name, if any.
+func (p *Paste) File(name string) (File, bool) {
+	for _, f := range p.Files {
+		if f.Name == name {
+			return f, true
+		}
+	}
+	return File{}, false
+}
+
+// Flatten mirrors the first file of the paste into its content and syntax, so pastes
+// with files can still be listed, searched and read by clients unaware of files.
+func (p *Paste) Flatten() {
+	if len(p.Files) == 0 {
+		return
+	}
+
+	p.Content = p.Files[0].Content
+	p.Syntax = p.Files[0].Syntax
+}
+
+// validateFiles checks the files of the paste, their names are used in urls so they
+// have to be unique and can't contain slashes.
+func (p *Paste) validateFiles() []error {
+	var errs []error
+
+	if len(p.Files) > MaxFiles {
+		errs = append(errs, errors.Errorf("a paste can't have more than %d files", MaxFiles))
+	}
+
+	names := make(map[string]bool, len(p.Files))
+	for _, f := range p.Files {
+		if err := checkname(f.Name); err != nil {
+			errs = append(errs, err)
+		} else if names[f.Name] {
+			errs = append(errs, errors.Errorf("duplicated file name %q", f.Name))
+		}
+		names[f.Name] = true
+
+		if strings.TrimSpace(f.Content) == "" {
+			errs = append(errs, errors.Errorf("file %q has no content", f.Name))
+		}
+
+		if p.ClientEncrypted && !ciphertext(f.Content) {
+			errs = append(errs, errors.Errorf("client encrypted content of file %q must be base64 encoded ciphertext", f.Name))
+		}
+	}
+
+	return errs
+}
+
+// checkname checks the name of a file or attachment, names are used in urls so they
+// can't contain slashes.
+func checkname(name string) error {
+	switch {
+	case strings.TrimSpace(name) == "":
+		return errors.New("files must have a name")
+	case name == "." || name == ".." || strings.ContainsAny(name, "/\\"):
+		return errors.Errorf("invalid file name %q", name)
+	}
+	return nil
+}
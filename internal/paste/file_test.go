@@ -0,0 +1,71 @@
+package paste_test
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/aexvir/skladka/internal/paste"
+)
+
+func TestValidateFiles(t *testing.T) {
+	file := func(name, content string) paste.File {
+		return paste.File{Name: name, Syntax: "plaintext", Content: content}
+	}
+
+	many := make([]paste.File, paste.MaxFiles+1)
+	for i := range many {
+		many[i] = file(strconv.Itoa(i)+".txt", "content")
+	}
+
+	tests := map[string]struct {
+		files   []paste.File
+		invalid bool
+	}{
+		"single file":      {files: []paste.File{file("main.go", "package main")}},
+		"several files":    {files: []paste.File{file("main.go", "package main"), file("go.mod", "module example")}},
+		"missing name":     {files: []paste.File{file(" ", "content")}, invalid: true},
+		"slash in name":    {files: []paste.File{file("cmd/main.go", "package main")}, invalid: true},
+		"dot dot":          {files: []paste.File{file("..", "content")}, invalid: true},
+		"duplicated names": {files: []paste.File{file("a.txt", "a"), file("a.txt", "b")}, invalid: true},
+		"empty content":    {files: []paste.File{file("a.txt", "a"), file("b.txt", "  ")}, invalid: true},
+		"too many files":   {files: many, invalid: true},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			// the content of the paste isn't required once it has files
+			p := paste.Paste{Files: test.files}
+
+			err := p.Validate()
+			if test.invalid {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestFlatten(t *testing.T) {
+	p := paste.Paste{
+		Content: "stale",
+		Files: []paste.File{
+			{Name: "main.go", Syntax: "go", Content: "package main"},
+			{Name: "go.mod", Syntax: "plaintext", Content: "module example"},
+		},
+	}
+
+	p.Flatten()
+	require.Equal(t, "package main", p.Content)
+	require.Equal(t, "go", p.Syntax)
+
+	file, ok := p.File("go.mod")
+	require.True(t, ok)
+	require.Equal(t, "module example", file.Content)
+
+	_, ok = p.File("missing")
+	require.False(t, ok)
+}
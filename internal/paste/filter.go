@@ -0,0 +1,103 @@
+package paste
+
+import (
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/aexvir/skladka/internal/errors"
+)
+
+const dateLayout = "2006-01-02"
+
+// Filter narrows down listings of pastes. Empty fields don't filter anything.
+type Filter struct {
+	// Tag only keeps pastes tagged with it.
+	Tag string
+	// Syntax only keeps pastes with this syntax.
+	Syntax string
+	// Since only keeps pastes created at or after this time.
+	Since *time.Time
+	// Until only keeps pastes created before this time.
+	Until *time.Time
+}
+
+// Page is a single page of a paste listing.
+type Page struct {
+	Pastes []Paste `json:"pastes"`
+	// Next is the opaque cursor of the following page, empty on the last one.
+	Next string `json:"next"`
+}
+
+// ParseFilter reads a filter from query parameters, the same ones Values produces.
+// Dates are accepted either as plain days, e.g. 2025-01-20, or as RFC 3339 timestamps.
+// A plain until day includes the whole day.
+func ParseFilter(values url.Values) (Filter, error) {
+	filter := Filter{
+		Tag:    strings.TrimSpace(values.Get("tag")),
+		Syntax: strings.TrimSpace(values.Get("syntax")),
+	}
+
+	var errs []error
+
+	if raw := values.Get("since"); raw != "" {
+		since, _, err := parseDate(raw)
+		if err != nil {
+			errs = append(errs, err)
+		}
+		filter.Since = since
+	}
+
+	if raw := values.Get("until"); raw != "" {
+		until, day, err := parseDate(raw)
+		if err != nil {
+			errs = append(errs, err)
+		}
+		if until != nil && day {
+			next := until.Add(24 * time.Hour)
+			until = &next
+		}
+		filter.Until = until
+	}
+
+	if len(errs) > 0 {
+		return Filter{}, errors.Join(errs...)
+	}
+
+	return filter, nil
+}
+
+// Values encodes the filter as query parameters, leaving out empty fields.
+func (f Filter) Values() url.Values {
+	values := url.Values{}
+
+	if f.Tag != "" {
+		values.Set("tag", f.Tag)
+	}
+	if f.Syntax != "" {
+		values.Set("syntax", f.Syntax)
+	}
+	if f.Since != nil {
+		values.Set("since", f.Since.Format(time.RFC3339))
+	}
+	if f.Until != nil {
+		values.Set("until", f.Until.Format(time.RFC3339))
+	}
+
+	return values
+}
+
+// parseDate parses a date either as a plain day or as a timestamp,
+// reporting which one it was.
+func parseDate(raw string) (*time.Time, bool, error) {
+	if date, err := time.Parse(dateLayout, raw); err == nil {
+		return &date, true, nil
+	}
+
+	date, err := time.Parse(time.RFC3339, raw)
+	if err != nil {
+		return nil, false, errors.Errorf("invalid date %q", raw)
+	}
+
+	return &date, false, nil
+}
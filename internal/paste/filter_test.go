@@ -0,0 +1,53 @@
+package paste_test
+
+import (
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/aexvir/skladka/internal/paste"
+)
+
+func TestParseFilter(t *testing.T) {
+	day := time.Date(2025, 1, 20, 0, 0, 0, 0, time.UTC)
+
+	tests := map[string]struct {
+		query    string
+		expected paste.Filter
+		invalid  bool
+	}{
+		"empty":          {query: ""},
+		"tag and syntax": {query: "tag=go&syntax=python", expected: paste.Filter{Tag: "go", Syntax: "python"}},
+		"since day":      {query: "since=2025-01-20", expected: paste.Filter{Since: &day}},
+		"until day":      {query: "until=2025-01-20", expected: paste.Filter{Until: ptr(day.Add(24 * time.Hour))}},
+		"timestamp":      {query: "until=2025-01-20T00:00:00Z", expected: paste.Filter{Until: &day}},
+		"invalid date":   {query: "since=yesterday", invalid: true},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			values, err := url.ParseQuery(test.query)
+			require.NoError(t, err)
+
+			filter, err := paste.ParseFilter(values)
+			if test.invalid {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, test.expected, filter)
+
+			// encoding the filter back yields the same filter
+			roundtrip, err := paste.ParseFilter(filter.Values())
+			require.NoError(t, err)
+			require.Equal(t, filter, roundtrip)
+		})
+	}
+}
+
+func ptr[T any](value T) *T {
+	return &value
+}
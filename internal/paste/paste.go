@@ -1,14 +1,17 @@
 package paste
 
 import (
+	"encoding/base64"
 	"strings"
 	"time"
 
 	"github.com/aexvir/skladka/internal/errors"
+	"github.com/aexvir/skladka/internal/user"
 )
 
 type Paste struct {
 	Reference  string     `json:"reference"`
+	Slug       string     `json:"slug,omitempty"`
 	Title      string     `json:"title"`
 	Content    string     `json:"content"`
 	Syntax     string     `json:"syntax"`
@@ -18,6 +21,20 @@ type Paste struct {
 	Public     bool       `json:"public"`
 	Password   *string    `json:"password"`
 	Views      int        `json:"views"`
+	BurnAfter  *int       `json:"burn_after"`
+	Parent     *string    `json:"parent"`
+	Owner      *user.User `json:"owner"`
+
+	// ClientEncrypted pastes are encrypted by the client before being sent, their content
+	// is stored as received and the key never reaches the server.
+	ClientEncrypted bool `json:"client_encrypted"`
+
+	// Files of the paste, if it has more than a single unnamed content. The content and
+	// syntax of the paste mirror the first of them.
+	Files []File `json:"files,omitempty"`
+
+	// Attachments are the binary files of the paste, like screenshots, served as they are.
+	Attachments []Attachment `json:"attachments,omitempty"`
 }
 
 // Validate checks if the paste meets all validation rules.
@@ -25,11 +42,23 @@ type Paste struct {
 func (p *Paste) Validate() error {
 	var errs []error
 
-	// content is required and must not be empty
-	if strings.TrimSpace(p.Content) == "" {
+	// content is required and must not be empty, unless it's split in files
+	// or the paste only holds attachments
+	if len(p.Files) > 0 {
+		errs = append(errs, p.validateFiles()...)
+	} else if strings.TrimSpace(p.Content) == "" && len(p.Attachments) == 0 {
 		errs = append(errs, errors.New("can't create a paste without content"))
 	}
 
+	if len(p.Attachments) > 0 {
+		errs = append(errs, p.validateAttachments()...)
+	}
+
+	// client encrypted content is the base64 encoded nonce followed by the ciphertext
+	if p.ClientEncrypted && len(p.Files) == 0 && !ciphertext(p.Content) {
+		errs = append(errs, errors.New("client encrypted content must be base64 encoded ciphertext"))
+	}
+
 	// title if provided must not be empty
 	if p.Title != "" && strings.TrimSpace(p.Title) == "" {
 		errs = append(errs, errors.New("title if provided must not be empty"))
@@ -47,9 +76,33 @@ func (p *Paste) Validate() error {
 		errs = append(errs, errors.New("expiration must be in the future"))
 	}
 
+	// burn after if provided must allow at least one read
+	if p.BurnAfter != nil && *p.BurnAfter < 1 {
+		errs = append(errs, errors.New("burn after must allow at least one read"))
+	}
+
 	if len(errs) > 0 {
 		return errors.Join(errs...)
 	}
 
 	return nil
 }
+
+// ciphertext reports whether the content looks like the output of the client side
+// encryption, an aes-gcm nonce and ciphertext, tag included, encoded as base64.
+func ciphertext(content string) bool {
+	raw, err := base64.StdEncoding.DecodeString(content)
+	return err == nil && len(raw) > 12+16
+}
+
+// Burned reports whether the paste has reached its read limit.
+// A burned paste has been deleted and can't be read anymore.
+func (p *Paste) Burned() bool {
+	return p.BurnAfter != nil && p.Views >= *p.BurnAfter
+}
+
+// Expired reports whether the expiration of the paste has passed.
+// An expired paste can't be read anymore and is eventually deleted.
+func (p *Paste) Expired() bool {
+	return p.Expiration != nil && !p.Expiration.After(time.Now())
+}
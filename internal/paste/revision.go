@@ -0,0 +1,18 @@
+package paste
+
+import "time"
+
+// Revision is a snapshot of the editable fields of a paste at some point in time.
+// Revisions are numbered sequentially starting at 1, the original content of the paste,
+// and the latest revision always matches the current state of the paste.
+type Revision struct {
+	Number   int       `json:"number"`
+	Title    string    `json:"title"`
+	Content  string    `json:"content"`
+	Syntax   string    `json:"syntax"`
+	Tags     []string  `json:"tags"`
+	Creation time.Time `json:"creation"`
+
+	// ClientEncrypted is set when the paste is client encrypted, so is the content of every revision.
+	ClientEncrypted bool `json:"client_encrypted"`
+}
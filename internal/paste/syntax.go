@@ -0,0 +1,61 @@
+package paste
+
+import (
+	"maps"
+	"path"
+	"strings"
+)
+
+// syntaxes maps file extensions to the syntax highlighter identifiers of the editor.
+var syntaxes = map[string]string{
+	".c":          "c",
+	".h":          "c",
+	".cpp":        "cpp",
+	".hpp":        "cpp",
+	".cs":         "csharp",
+	".css":        "css",
+	".go":         "go",
+	".html":       "html",
+	".htm":        "html",
+	".java":       "java",
+	".js":         "javascript",
+	".mjs":        "javascript",
+	".json":       "json",
+	".kt":         "kotlin",
+	".lua":        "lua",
+	".md":         "markdown",
+	".php":        "php",
+	".py":         "python",
+	".rb":         "ruby",
+	".rs":         "rust",
+	".sh":         "shell",
+	".bash":       "shell",
+	".sql":        "sql",
+	".swift":      "swift",
+	".ts":         "typescript",
+	".tsx":        "typescript",
+	".xml":        "xml",
+	".yaml":       "yaml",
+	".yml":        "yaml",
+	".dockerfile": "dockerfile",
+}
+
+// SyntaxOf infers the syntax of a file from its name, files without
+// a known extension are plaintext.
+func SyntaxOf(name string) string {
+	base := strings.ToLower(path.Base(name))
+	if base == "dockerfile" {
+		return "dockerfile"
+	}
+
+	if syntax, ok := syntaxes[path.Ext(base)]; ok {
+		return syntax
+	}
+
+	return "plaintext"
+}
+
+// Syntaxes returns the syntax inferred for each known file extension.
+func Syntaxes() map[string]string {
+	return maps.Clone(syntaxes)
+}
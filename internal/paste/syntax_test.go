@@ -0,0 +1,27 @@
+package paste_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/aexvir/skladka/internal/paste"
+)
+
+func TestSyntaxOf(t *testing.T) {
+	tests := map[string]string{
+		"main.go":     "go",
+		"script.PY":   "python",
+		"config.yml":  "yaml",
+		"Dockerfile":  "dockerfile",
+		"notes":       "plaintext",
+		"archive.tgz": "plaintext",
+		".bashrc":     "plaintext",
+	}
+
+	for name, syntax := range tests {
+		t.Run(name, func(t *testing.T) {
+			require.Equal(t, syntax, paste.SyntaxOf(name))
+		})
+	}
+}
@@ -0,0 +1,7 @@
+package paste
+
+// Tag is a tag used on public pastes, along with the number of pastes carrying it.
+type Tag struct {
+	Name   string `json:"name"`
+	Pastes int    `json:"pastes"`
+}
@@ -0,0 +1,207 @@
+package storage
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/aexvir/skladka/internal/errors"
+	"github.com/aexvir/skladka/internal/paste"
+	"github.com/aexvir/skladka/internal/storage/blob"
+	"github.com/aexvir/skladka/internal/storage/sql"
+	"github.com/aexvir/skladka/internal/tracing"
+)
+
+// storedAttachment is an attachment as it's stored, its name encrypted and its content
+// kept in the blob store under the blob key, encrypted with the key sealed in secret.
+type storedAttachment struct {
+	paste.Attachment
+
+	blob   string
+	secret string
+}
+
+// putattachments encrypts the attachments and puts their contents in the blob store,
+// returning them as they have to be stored. Blobs already put are removed if any fails.
+func putattachments(ctx context.Context, blobs blob.Store, cipher *Cipher, attachments []paste.Attachment) ([]storedAttachment, error) {
+	stored := make([]storedAttachment, 0, len(attachments))
+
+	for _, a := range attachments {
+		s, err := putattachment(ctx, blobs, cipher, a)
+		if err != nil {
+			dropattachments(ctx, blobs, stored)
+			return nil, err
+		}
+		stored = append(stored, s)
+	}
+
+	return stored, nil
+}
+
+func putattachment(ctx context.Context, blobs blob.Store, cipher *Cipher, a paste.Attachment) (storedAttachment, error) {
+	// blob keys are random, like tokens, so they can't be guessed
+	key, err := generateToken()
+	if err != nil {
+		return storedAttachment{}, errors.Wrap(err, "failed to generate blob key")
+	}
+
+	sealed, secret, err := cipher.SealBlob(a.Data)
+	if err != nil {
+		return storedAttachment{}, errors.Wrapf(err, "failed to encrypt attachment %q", a.Name)
+	}
+
+	name, err := cipher.Encrypt(a.Name)
+	if err != nil {
+		return storedAttachment{}, errors.Wrapf(err, "failed to encrypt attachment %q", a.Name)
+	}
+
+	if err := blobs.Put(ctx, key, sealed); err != nil {
+		return storedAttachment{}, errors.Wrapf(err, "failed to store attachment %q", a.Name)
+	}
+
+	return storedAttachment{
+		Attachment: paste.Attachment{Name: name, ContentType: a.ContentType, Size: int64(len(a.Data))},
+		blob:       key,
+		secret:     secret,
+	}, nil
+}
+
+// dropattachments removes the blobs of attachments that won't be stored after all.
+// It's best effort, blobs failing to be removed are left behind.
+func dropattachments(ctx context.Context, blobs blob.Store, stored []storedAttachment) {
+	for _, s := range stored {
+		blobs.Delete(ctx, s.blob)
+	}
+}
+
+// openattachments decrypts the names of the stored attachments, leaving their contents in the blob store.
+func openattachments(cipher *Cipher, stored []storedAttachment) ([]paste.Attachment, error) {
+	if len(stored) == 0 {
+		return nil, nil
+	}
+
+	attachments := make([]paste.Attachment, len(stored))
+	for i, s := range stored {
+		name, err := cipher.Decrypt(s.Name)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to decrypt attachment")
+		}
+
+		attachments[i] = paste.Attachment{Name: name, ContentType: s.ContentType, Size: s.Size}
+	}
+
+	return attachments, nil
+}
+
+// readattachment returns the stored attachment with the given name along with its content,
+// or pgx.ErrNoRows if there's none.
+func readattachment(ctx context.Context, blobs blob.Store, cipher *Cipher, stored []storedAttachment, name string) (paste.Attachment, error) {
+	for _, s := range stored {
+		decrypted, err := cipher.Decrypt(s.Name)
+		if err != nil {
+			return paste.Attachment{}, errors.Wrap(err, "failed to decrypt attachment")
+		}
+
+		if decrypted != name {
+			continue
+		}
+
+		sealed, err := blobs.Get(ctx, s.blob)
+		if err != nil {
+			return paste.Attachment{}, errors.Wrapf(err, "failed to read attachment %q", name)
+		}
+
+		data, err := cipher.OpenBlob(sealed, s.secret)
+		if err != nil {
+			return paste.Attachment{}, errors.Wrapf(err, "failed to decrypt attachment %q", name)
+		}
+
+		return paste.Attachment{Name: name, ContentType: s.ContentType, Size: s.Size, Data: data}, nil
+	}
+
+	return paste.Attachment{}, pgx.ErrNoRows
+}
+
+// attachments returns the stored attachments of the paste, in order.
+func (s *PostgresStorage) attachments(ctx context.Context, ref string) ([]storedAttachment, error) {
+	rows, err := s.db.ListPasteAttachments(ctx, ref)
+	if err != nil {
+		s.metrics.PasteErrors.Add(ctx, 1)
+		return nil, errors.Wrapf(err, "failed to list attachments of paste %s", ref)
+	}
+
+	stored := make([]storedAttachment, len(rows))
+	for i, row := range rows {
+		stored[i] = storedAttachment{
+			Attachment: paste.Attachment{Name: row.Name, ContentType: row.ContentType, Size: row.Size},
+			blob:       row.Blob,
+			secret:     row.Secret,
+		}
+	}
+
+	return stored, nil
+}
+
+// storeattachments stores the attachments of the paste, whose blobs were already put.
+func (s *PostgresStorage) storeattachments(ctx context.Context, db *sql.Queries, ref string, stored []storedAttachment) error {
+	for position, a := range stored {
+		err := db.CreatePasteAttachment(
+			ctx, sql.CreatePasteAttachmentParams{
+				Reference:   ref,
+				Position:    int32(position),
+				Name:        a.Name,
+				ContentType: a.ContentType,
+				Size:        a.Size,
+				Blob:        a.blob,
+				Secret:      a.secret,
+			},
+		)
+		if err != nil {
+			return errors.Wrapf(err, "failed to store attachments of paste %s", ref)
+		}
+	}
+
+	return nil
+}
+
+// attach loads the attachments of the paste, without their contents.
+func (s *PostgresStorage) attach(ctx context.Context, p *paste.Paste) error {
+	stored, err := s.attachments(ctx, p.Reference)
+	if err != nil {
+		return err
+	}
+
+	if p.Attachments, err = openattachments(s.cipher, stored); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// GetAttachment returns the attachment of the paste with the given name, along with its content.
+// Reading an attachment doesn't count as a read of the paste; attachments are only allowed on
+// pastes that aren't protected nor burnt after reading, so they're readable without a password.
+func (s *PostgresStorage) GetAttachment(ctx context.Context, ref, name string) (paste.Attachment, error) {
+	var err error
+	ctx, finish := tracing.FromContext(ctx, trace.SpanKindInternal, "PostgresStorage.GetAttachment")
+	defer finish(&err)
+
+	if _, err = s.db.PeekPasteByReference(ctx, ref); err != nil {
+		s.failed(ctx, err)
+		return paste.Attachment{}, err
+	}
+
+	stored, err := s.attachments(ctx, ref)
+	if err != nil {
+		return paste.Attachment{}, err
+	}
+
+	attachment, err := readattachment(ctx, s.blobs, s.cipher, stored, name)
+	if err != nil {
+		s.failed(ctx, err)
+		return paste.Attachment{}, err
+	}
+
+	return attachment, nil
+}
@@ -0,0 +1,49 @@
+package blob
+
+import (
+	"context"
+	"regexp"
+
+	"github.com/aexvir/skladka/internal/config"
+	"github.com/aexvir/skladka/internal/errors"
+)
+
+// ErrNotFound is returned when reading a blob that isn't in the store.
+var ErrNotFound = errors.New("blob not found")
+
+// Store keeps blobs by their keys.
+type Store interface {
+	// Put stores the blob under the key, replacing the one stored before if any.
+	Put(ctx context.Context, key string, data []byte) error
+
+	// Get returns the blob stored under the key, or ErrNotFound.
+	Get(ctx context.Context, key string) ([]byte, error)
+
+	// Delete removes the blob stored under the key, if any.
+	Delete(ctx context.Context, key string) error
+}
+
+// Open returns the store selected by the config.
+func Open(cfg config.Blobs) (Store, error) {
+	switch cfg.Driver {
+	case "filesystem":
+		return NewFilesystem(cfg.Path)
+	case "s3":
+		return NewS3(cfg)
+	case "memory":
+		return NewMemory(), nil
+	default:
+		return nil, errors.Errorf("unknown blobs driver %q", cfg.Driver)
+	}
+}
+
+// keys are safe to use both as file names and in urls without escaping them.
+var keys = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// checkkey fails for keys that can't address a blob.
+func checkkey(key string) error {
+	if !keys.MatchString(key) {
+		return errors.Errorf("invalid blob key %q", key)
+	}
+	return nil
+}
@@ -0,0 +1,211 @@
+package blob_test
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/aexvir/skladka/internal/config"
+	"github.com/aexvir/skladka/internal/errors"
+	"github.com/aexvir/skladka/internal/storage/blob"
+)
+
+func TestStores(t *testing.T) {
+	stores := map[string]func(t *testing.T) blob.Store{
+		"memory": func(t *testing.T) blob.Store {
+			return blob.NewMemory()
+		},
+		"filesystem": func(t *testing.T) blob.Store {
+			store, err := blob.NewFilesystem(t.TempDir())
+			require.NoError(t, err)
+			return store
+		},
+		"s3": func(t *testing.T) blob.Store {
+			server := newS3Server(t, "access", "secret")
+			store, err := blob.NewS3(
+				config.Blobs{
+					Endpoint:  server.URL,
+					Bucket:    "attachments",
+					Region:    "eu-central-1",
+					AccessKey: "access",
+					SecretKey: "secret",
+				},
+			)
+			require.NoError(t, err)
+			return store
+		},
+	}
+
+	for name, open := range stores {
+		t.Run(
+			name, func(t *testing.T) {
+				ctx := context.Background()
+				store := open(t)
+
+				data := []byte{0x89, 'P', 'N', 'G', 0x00, 0xff}
+				require.NoError(t, store.Put(ctx, "a1b2c3", data))
+
+				stored, err := store.Get(ctx, "a1b2c3")
+				require.NoError(t, err)
+				require.Equal(t, data, stored)
+
+				// putting a blob again replaces it
+				require.NoError(t, store.Put(ctx, "a1b2c3", []byte("replaced")))
+				stored, err = store.Get(ctx, "a1b2c3")
+				require.NoError(t, err)
+				require.Equal(t, []byte("replaced"), stored)
+
+				_, err = store.Get(ctx, "missing")
+				require.ErrorIs(t, err, blob.ErrNotFound)
+
+				require.NoError(t, store.Delete(ctx, "a1b2c3"))
+				_, err = store.Get(ctx, "a1b2c3")
+				require.ErrorIs(t, err, blob.ErrNotFound)
+
+				// deleting is idempotent
+				require.NoError(t, store.Delete(ctx, "a1b2c3"))
+
+				for _, key := range []string{"", "../escape", "with/slash", "dotted.key"} {
+					require.Error(t, store.Put(ctx, key, data), key)
+				}
+			},
+		)
+	}
+}
+
+func TestS3Credentials(t *testing.T) {
+	server := newS3Server(t, "access", "secret")
+
+	store, err := blob.NewS3(
+		config.Blobs{
+			Endpoint:  server.URL,
+			Bucket:    "attachments",
+			Region:    "eu-central-1",
+			AccessKey: "access",
+			SecretKey: "wrong",
+		},
+	)
+	require.NoError(t, err)
+
+	err = store.Put(context.Background(), "a1b2c3", []byte("data"))
+	require.ErrorContains(t, err, "403")
+
+	_, err = blob.NewS3(config.Blobs{Endpoint: "not a url", Bucket: "attachments"})
+	require.Error(t, err)
+
+	_, err = blob.NewS3(config.Blobs{Endpoint: server.URL})
+	require.Error(t, err)
+}
+
+// authorization parses the authorization header of signature version 4.
+var authorization = regexp.MustCompile(
+	`^AWS4-HMAC-SHA256 Credential=([^/]+)/(\d{8})/([^/]+)/s3/aws4_request, SignedHeaders=([a-z0-9;-]+), Signature=([0-9a-f]{64})$`,
+)
+
+// newS3Server starts a stand-in of an s3 compatible service, keeping objects in memory
+// and rejecting requests that aren't signed with the given credentials.
+func newS3Server(t *testing.T, accesskey, secretkey string) *httptest.Server {
+	var (
+		mu      sync.Mutex
+		objects = make(map[string][]byte)
+	)
+
+	server := httptest.NewServer(
+		http.HandlerFunc(
+			func(w http.ResponseWriter, r *http.Request) {
+				payload, err := io.ReadAll(r.Body)
+				require.NoError(t, err)
+
+				if err := verify(r, payload, accesskey, secretkey); err != nil {
+					http.Error(w, err.Error(), http.StatusForbidden)
+					return
+				}
+
+				mu.Lock()
+				defer mu.Unlock()
+
+				switch r.Method {
+				case http.MethodPut:
+					objects[r.URL.Path] = payload
+				case http.MethodGet:
+					object, ok := objects[r.URL.Path]
+					if !ok {
+						http.Error(w, "NoSuchKey", http.StatusNotFound)
+						return
+					}
+					w.Write(object)
+				case http.MethodDelete:
+					delete(objects, r.URL.Path)
+					w.WriteHeader(http.StatusNoContent)
+				default:
+					w.WriteHeader(http.StatusMethodNotAllowed)
+				}
+			},
+		),
+	)
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+// verify recomputes the signature of the request the way s3 does.
+func verify(r *http.Request, payload []byte, accesskey, secretkey string) error {
+	match := authorization.FindStringSubmatch(r.Header.Get("Authorization"))
+	if match == nil {
+		return errors.New("malformed authorization")
+	}
+	access, date, region, signed, signature := match[1], match[2], match[3], match[4], match[5]
+
+	if access != accesskey {
+		return errors.New("unknown access key")
+	}
+
+	hash := sha256.Sum256(payload)
+	if r.Header.Get("X-Amz-Content-Sha256") != hex.EncodeToString(hash[:]) {
+		return errors.New("payload hash mismatch")
+	}
+
+	var headers strings.Builder
+	for _, name := range strings.Split(signed, ";") {
+		value := r.Header.Get(name)
+		if name == "host" {
+			value = r.Host
+		}
+		fmt.Fprintf(&headers, "%s:%s\n", name, value)
+	}
+
+	request := sha256.Sum256(
+		[]byte(
+			strings.Join(
+				[]string{r.Method, r.URL.EscapedPath(), r.URL.RawQuery, headers.String(), signed, hex.EncodeToString(hash[:])},
+				"\n",
+			),
+		),
+	)
+	scope := date + "/" + region + "/s3/aws4_request"
+	tosign := "AWS4-HMAC-SHA256\n" + r.Header.Get("X-Amz-Date") + "\n" + scope + "\n" + hex.EncodeToString(request[:])
+
+	key := []byte("AWS4" + secretkey)
+	for _, part := range []string{date, region, "s3", "aws4_request", tosign} {
+		mac := hmac.New(sha256.New, key)
+		mac.Write([]byte(part))
+		key = mac.Sum(nil)
+	}
+
+	if hex.EncodeToString(key) != signature {
+		return errors.New("signature mismatch")
+	}
+
+	return nil
+}
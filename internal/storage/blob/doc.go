@@ -0,0 +1,31 @@
+// Package blob stores the binary content of paste attachments, apart from the database
+// that only keeps their metadata.
+//
+// Every store implements Store, addressing blobs by opaque keys made of letters, digits,
+// dashes and underscores. Blobs are written and read whole, attachments being bounded by
+// the upload limit. Stores don't encrypt anything on their own, the storage layer hands
+// them blobs already encrypted with its keyring.
+//
+// Three stores are available, selected by the blobs section of the storage config:
+//   - Filesystem keeps every blob in a file under a root directory, sharded by the first
+//     characters of their keys
+//   - S3 keeps them as objects of a bucket of any s3 compatible service, addressed by path
+//     and authenticated with signature version 4
+//   - Memory keeps them in memory, for tests and local development
+//
+// # Example Usage
+//
+//	store, err := blob.Open(cfg.Storage.Blobs)
+//	if err != nil {
+//		return err
+//	}
+//
+//	if err := store.Put(ctx, key, data); err != nil {
+//		return err
+//	}
+//
+//	data, err = store.Get(ctx, key)
+//	if errors.Is(err, blob.ErrNotFound) {
+//		// the blob was deleted or never stored
+//	}
+package blob
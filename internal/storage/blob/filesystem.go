@@ -0,0 +1,95 @@
+package blob
+
+import (
+	"context"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/aexvir/skladka/internal/errors"
+)
+
+// Filesystem keeps every blob in a file under its root directory. Files are sharded in
+// directories named after the first two characters of their keys, so no directory ends
+// up with too many of them.
+type Filesystem struct {
+	root string
+}
+
+// NewFilesystem returns a store keeping blobs under the root directory, creating it if needed.
+func NewFilesystem(root string) (*Filesystem, error) {
+	if root == "" {
+		return nil, errors.New("the filesystem blob store needs a path")
+	}
+
+	if err := os.MkdirAll(root, 0o750); err != nil {
+		return nil, errors.Wrapf(err, "failed to create blob directory %s", root)
+	}
+
+	return &Filesystem{root: root}, nil
+}
+
+// Put writes the blob to its file. It's written to a temporary file first and then
+// renamed, so readers never see a partially written blob.
+func (f *Filesystem) Put(_ context.Context, key string, data []byte) error {
+	if err := checkkey(key); err != nil {
+		return err
+	}
+
+	path := f.path(key)
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return errors.Wrapf(err, "failed to store blob %s", key)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), key+".*.tmp")
+	if err != nil {
+		return errors.Wrapf(err, "failed to store blob %s", key)
+	}
+	defer os.Remove(tmp.Name())
+
+	_, err = tmp.Write(data)
+	if err := errors.Join(err, tmp.Close()); err != nil {
+		return errors.Wrapf(err, "failed to store blob %s", key)
+	}
+
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return errors.Wrapf(err, "failed to store blob %s", key)
+	}
+
+	return nil
+}
+
+// Get reads the blob from its file.
+func (f *Filesystem) Get(_ context.Context, key string) ([]byte, error) {
+	if err := checkkey(key); err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(f.path(key))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read blob %s", key)
+	}
+
+	return data, nil
+}
+
+// Delete removes the file of the blob.
+func (f *Filesystem) Delete(_ context.Context, key string) error {
+	if err := checkkey(key); err != nil {
+		return err
+	}
+
+	if err := os.Remove(f.path(key)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return errors.Wrapf(err, "failed to delete blob %s", key)
+	}
+
+	return nil
+}
+
+// path returns the path of the file of the blob, keys being validated beforehand.
+func (f *Filesystem) path(key string) string {
+	return filepath.Join(f.root, key[:min(2, len(key))], key)
+}
@@ -0,0 +1,52 @@
+package blob
+
+import (
+	"context"
+	"slices"
+	"sync"
+)
+
+// Memory keeps blobs in memory. It's meant for tests and local development.
+type Memory struct {
+	mu    sync.RWMutex
+	blobs map[string][]byte
+}
+
+// NewMemory returns an empty memory store.
+func NewMemory() *Memory {
+	return &Memory{blobs: make(map[string][]byte)}
+}
+
+// Put stores a copy of the blob under the key.
+func (m *Memory) Put(_ context.Context, key string, data []byte) error {
+	if err := checkkey(key); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.blobs[key] = slices.Clone(data)
+	return nil
+}
+
+// Get returns a copy of the blob stored under the key.
+func (m *Memory) Get(_ context.Context, key string) ([]byte, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	data, ok := m.blobs[key]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return slices.Clone(data), nil
+}
+
+// Delete removes the blob stored under the key.
+func (m *Memory) Delete(_ context.Context, key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.blobs, key)
+	return nil
+}
@@ -0,0 +1,220 @@
+package blob
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/aexvir/skladka/internal/config"
+	"github.com/aexvir/skladka/internal/errors"
+)
+
+// S3 keeps blobs as objects of a bucket of an s3 compatible service. Objects are addressed
+// by path, endpoint/bucket/key, which every s3 compatible service supports, and requests
+// are authenticated with aws signature version 4.
+type S3 struct {
+	endpoint  *url.URL
+	bucket    string
+	region    string
+	accesskey string
+	secretkey string
+
+	client *http.Client
+}
+
+type S3Option func(*S3)
+
+// WithHTTPClient sends the requests to the s3 service with the given client.
+func WithHTTPClient(client *http.Client) S3Option {
+	return func(s *S3) {
+		s.client = client
+	}
+}
+
+// NewS3 returns a store keeping blobs in the bucket of the s3 compatible service
+// reachable at the endpoint of the config.
+func NewS3(cfg config.Blobs, opts ...S3Option) (*S3, error) {
+	endpoint, err := url.Parse(cfg.Endpoint)
+	if err != nil || endpoint.Scheme == "" || endpoint.Host == "" {
+		return nil, errors.Errorf("invalid s3 endpoint %q", cfg.Endpoint)
+	}
+
+	if cfg.Bucket == "" {
+		return nil, errors.New("the s3 blob store needs a bucket")
+	}
+
+	store := S3{
+		endpoint:  endpoint,
+		bucket:    cfg.Bucket,
+		region:    cfg.Region,
+		accesskey: cfg.AccessKey,
+		secretkey: cfg.SecretKey,
+		client:    http.DefaultClient,
+	}
+
+	for _, opt := range opts {
+		opt(&store)
+	}
+
+	return &store, nil
+}
+
+// Put uploads the blob as the object with the key.
+func (s *S3) Put(ctx context.Context, key string, data []byte) error {
+	res, err := s.do(ctx, http.MethodPut, key, data)
+	if err != nil {
+		return errors.Wrapf(err, "failed to store blob %s", key)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return errors.Wrapf(failure(res), "failed to store blob %s", key)
+	}
+
+	return nil
+}
+
+// Get downloads the object with the key.
+func (s *S3) Get(ctx context.Context, key string) ([]byte, error) {
+	res, err := s.do(ctx, http.MethodGet, key, nil)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read blob %s", key)
+	}
+	defer res.Body.Close()
+
+	switch res.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return nil, ErrNotFound
+	default:
+		return nil, errors.Wrapf(failure(res), "failed to read blob %s", key)
+	}
+
+	data, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read blob %s", key)
+	}
+
+	return data, nil
+}
+
+// Delete removes the object with the key, deleting missing objects succeeds.
+func (s *S3) Delete(ctx context.Context, key string) error {
+	res, err := s.do(ctx, http.MethodDelete, key, nil)
+	if err != nil {
+		return errors.Wrapf(err, "failed to delete blob %s", key)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusNoContent && res.StatusCode != http.StatusOK {
+		return errors.Wrapf(failure(res), "failed to delete blob %s", key)
+	}
+
+	return nil
+}
+
+// do sends a signed request for the object with the key.
+func (s *S3) do(ctx context.Context, method, key string, payload []byte) (*http.Response, error) {
+	if err := checkkey(key); err != nil {
+		return nil, err
+	}
+
+	object := *s.endpoint
+	object.Path = strings.TrimSuffix(object.Path, "/") + "/" + s.bucket + "/" + key
+
+	req, err := http.NewRequestWithContext(ctx, method, object.String(), bytes.NewReader(payload))
+	if err != nil {
+		return nil, err
+	}
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/octet-stream")
+	}
+
+	s.sign(req, payload)
+
+	return s.client.Do(req)
+}
+
+// sign authenticates the request with aws signature version 4, signing the host and
+// every header of the request along with the hash of the payload.
+// See https://docs.aws.amazon.com/AmazonS3/latest/API/sig-v4-header-based-auth.html
+func (s *S3) sign(req *http.Request, payload []byte) {
+	now := time.Now().UTC()
+	timestamp := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+	hash := sha256hex(payload)
+
+	req.Header.Set("X-Amz-Date", timestamp)
+	req.Header.Set("X-Amz-Content-Sha256", hash)
+
+	headers := map[string]string{"host": req.URL.Host}
+	for name, values := range req.Header {
+		headers[strings.ToLower(name)] = strings.TrimSpace(strings.Join(values, ","))
+	}
+
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	var canonical strings.Builder
+	for _, name := range names {
+		fmt.Fprintf(&canonical, "%s:%s\n", name, headers[name])
+	}
+	signed := strings.Join(names, ";")
+
+	request := strings.Join(
+		[]string{
+			req.Method,
+			req.URL.EscapedPath(),
+			req.URL.Query().Encode(),
+			canonical.String(),
+			signed,
+			hash,
+		}, "\n",
+	)
+
+	scope := date + "/" + s.region + "/s3/aws4_request"
+	tosign := strings.Join([]string{"AWS4-HMAC-SHA256", timestamp, scope, sha256hex([]byte(request))}, "\n")
+
+	key := []byte("AWS4" + s.secretkey)
+	for _, part := range []string{date, s.region, "s3", "aws4_request"} {
+		key = hmacsha256(key, part)
+	}
+	signature := hex.EncodeToString(hmacsha256(key, tosign))
+
+	req.Header.Set(
+		"Authorization",
+		fmt.Sprintf(
+			"AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+			s.accesskey, scope, signed, signature,
+		),
+	)
+}
+
+// failure returns the error reported by the s3 service in the response.
+func failure(res *http.Response) error {
+	body, _ := io.ReadAll(io.LimitReader(res.Body, 1024))
+	return errors.Errorf("s3 responded with %s: %s", res.Status, bytes.TrimSpace(body))
+}
+
+func sha256hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacsha256(key []byte, value string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(value))
+	return mac.Sum(nil)
+}
@@ -3,22 +3,82 @@ package storage
 import (
 	"crypto/aes"
 	"crypto/cipher"
+	"crypto/hmac"
 	"crypto/rand"
+	"crypto/sha256"
 	"crypto/subtle"
 	"encoding/base64"
 	"io"
+	"strings"
+	"sync"
+	"time"
 
 	"golang.org/x/crypto/argon2"
+
+	"github.com/aexvir/skladka/internal/config"
+	"github.com/aexvir/skladka/internal/errors"
 )
 
+// envelope prefixes ciphertexts with the version of their format and the id of the key
+// they were encrypted with, so keys can be rotated without losing the data encrypted
+// with the previous ones.
+const envelope = "v1"
+
+// Cipher encrypts data with the current key of its keyring, while still decrypting
+// data encrypted with any of the retired keys.
 type Cipher struct {
+	id  string
 	key []byte
+
+	// keys holds every key of the keyring by its id, current first
+	keys []keyringKey
+
+	// attempts throttles the wrong secrets verified against the same hash
+	attempts *attempts
 }
 
+type keyringKey struct {
+	id  string
+	key []byte
+}
+
+// NewCipher returns a cipher with a single key, identified by the default key id.
 func NewCipher(key, salt string) *Cipher {
-	return &Cipher{
-		key: argon2.IDKey([]byte(key), []byte(salt), 3, 64*1024, 2, 32),
+	return newKeyring([]config.Key{{ID: config.DefaultEncryptionKeyID, Secret: key, Salt: salt}})
+}
+
+// NewKeyring returns a cipher encrypting with the current encryption key of the config,
+// and decrypting with both the current and the retired ones.
+func NewKeyring(cfg config.Config) (*Cipher, error) {
+	keys, err := cfg.Keyring()
+	if err != nil {
+		return nil, err
 	}
+
+	return newKeyring(keys), nil
+}
+
+func newKeyring(keys []config.Key) *Cipher {
+	c := Cipher{id: keys[0].ID, attempts: &attempts{failures: make(map[string]failures)}}
+
+	for _, k := range keys {
+		c.keys = append(
+			c.keys, keyringKey{
+				id:  k.ID,
+				key: argon2.IDKey([]byte(k.Secret), []byte(k.Salt), 3, 64*1024, 2, 32),
+			},
+		)
+	}
+
+	c.key = c.keys[0].key
+
+	return &c
+}
+
+// Envelope returns the prefix of the data encrypted with the current key.
+// Data without it has to be re-encrypted once the key is rotated.
+func (c *Cipher) Envelope() string {
+	return envelope + ":" + c.id + ":"
 }
 
 func (c *Cipher) Hash(value string) string {
@@ -34,62 +94,406 @@ func (c *Cipher) Hash(value string) string {
 	)
 }
 
+// Verify reports whether the password matches the hash. Once a hash was verified against
+// too many wrong passwords in a row, it's refused without hashing the password for a while,
+// so guessing the password of a paste or an account can't keep the server busy.
 func (c *Cipher) Verify(password, encoded string) bool {
+	if c.attempts.throttled(encoded) {
+		return false
+	}
+
 	combined, err := base64.URLEncoding.DecodeString(encoded)
 	if err != nil {
 		panic(err)
 	}
 
 	salt, storedhash := combined[:16], combined[16:]
-
 	computedhash := argon2.IDKey([]byte(password), salt, 3, 64*1024, 2, 32)
 
-	return subtle.ConstantTimeCompare(storedhash, computedhash) == 1
+	if subtle.ConstantTimeCompare(storedhash, computedhash) != 1 {
+		c.attempts.fail(encoded)
+		return false
+	}
+
+	c.attempts.reset(encoded)
+
+	return true
+}
+
+// maxAttempts is how many wrong passwords a hash is verified against in a row before
+// it's throttled, for attemptsWindow since the last of them.
+const (
+	maxAttempts    = 10
+	attemptsWindow = time.Minute
+)
+
+// attempts tracks the wrong passwords verified against each hash.
+type attempts struct {
+	mu       sync.Mutex
+	failures map[string]failures
+}
+
+type failures struct {
+	count int
+	last  time.Time
+}
+
+func (a *attempts) throttled(hash string) bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	f, ok := a.failures[hash]
+	return ok && f.count >= maxAttempts && time.Since(f.last) < attemptsWindow
+}
+
+func (a *attempts) fail(hash string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	now := time.Now()
+
+	// forget the hashes nobody guessed at for a while, so the map doesn't keep growing
+	for h, f := range a.failures {
+		if now.Sub(f.last) >= attemptsWindow {
+			delete(a.failures, h)
+		}
+	}
+
+	f := a.failures[hash]
+	a.failures[hash] = failures{count: f.count + 1, last: now}
+}
+
+func (a *attempts) reset(hash string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	delete(a.failures, hash)
+}
+
+// Digest returns a keyed hash of the value that, unlike Hash, is deterministic.
+// It's meant for high entropy secrets like session tokens, which have to be
+// looked up by their hash and don't need the slow password hashing.
+func (c *Cipher) Digest(value string) string {
+	return digest(c.key, value)
 }
 
+// Digests returns the digests of the value with every key of the keyring, current first.
+// Digests can't be re-encrypted, so lookups by digest have to try each of them.
+func (c *Cipher) Digests(value string) []string {
+	digests := make([]string, len(c.keys))
+	for i, k := range c.keys {
+		digests[i] = digest(k.key, value)
+	}
+	return digests
+}
+
+// Encrypt encrypts the plaintext with the current key, prefixing it with its envelope.
 func (c *Cipher) Encrypt(plaintext string) (string, error) {
-	block, err := aes.NewCipher(c.key)
+	ciphertext, err := seal(c.key, plaintext)
 	if err != nil {
 		return "", err
 	}
 
-	gcm, err := cipher.NewGCM(block)
+	return c.Envelope() + ciphertext, nil
+}
+
+// Decrypt decrypts data encrypted with any key of the keyring.
+// Data encrypted before ciphertexts carried the id of their key is tried with every key.
+func (c *Cipher) Decrypt(encrypted string) (string, error) {
+	if version, rest, ok := strings.Cut(encrypted, ":"); ok && version == envelope {
+		id, ciphertext, _ := strings.Cut(rest, ":")
+
+		for _, k := range c.keys {
+			if k.id == id {
+				return unseal(k.key, ciphertext)
+			}
+		}
+
+		return "", errors.Errorf("unknown encryption key %q", id)
+	}
+
+	var errs []error
+	for _, k := range c.keys {
+		plaintext, err := unseal(k.key, encrypted)
+		if err == nil {
+			return plaintext, nil
+		}
+		errs = append(errs, err)
+	}
+
+	return "", errors.Join(errs...)
+}
+
+// SealBlob encrypts binary data with a new random key, returned encrypted with the current
+// key of the keyring. Rotating the keyring only re-encrypts the key, not the whole blob.
+func (c *Cipher) SealBlob(data []byte) ([]byte, string, error) {
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return nil, "", err
+	}
+
+	sealed, err := sealbytes(key, data)
+	if err != nil {
+		return nil, "", err
+	}
+
+	secret, err := c.Encrypt(base64.StdEncoding.EncodeToString(key))
+	if err != nil {
+		return nil, "", err
+	}
+
+	return sealed, secret, nil
+}
+
+// OpenBlob decrypts binary data encrypted by SealBlob, along with its encrypted key.
+func (c *Cipher) OpenBlob(sealed []byte, secret string) ([]byte, error) {
+	encoded, err := c.Decrypt(secret)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to decrypt blob key")
+	}
+
+	key, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid blob key")
+	}
+
+	return unsealbytes(key, sealed)
+}
+
+func digest(key []byte, value string) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(value))
+
+	return base64.URLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func seal(key []byte, plaintext string) (string, error) {
+	ciphertext, err := sealbytes(key, []byte(plaintext))
 	if err != nil {
 		return "", err
 	}
 
+	return base64.StdEncoding.EncodeToString(ciphertext), nil
+}
+
+func unseal(key []byte, encrypted string) (string, error) {
+	ciphertext, err := base64.StdEncoding.DecodeString(encrypted)
+	if err != nil {
+		return "", err
+	}
+
+	plaintext, err := unsealbytes(key, ciphertext)
+	if err != nil {
+		return "", err
+	}
+
+	return string(plaintext), nil
+}
+
+// sealbytes encrypts the plaintext with aes-gcm, returning the nonce followed by the ciphertext.
+func sealbytes(key, plaintext []byte) ([]byte, error) {
+	gcm, err := aead(key)
+	if err != nil {
+		return nil, err
+	}
+
 	nonce := make([]byte, gcm.NonceSize())
 	if _, err = io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+
+	return gcm.Seal(nonce, nonce, plaintext, nil), nil
+}
+
+// unsealbytes decrypts the output of sealbytes.
+func unsealbytes(key, ciphertext []byte) ([]byte, error) {
+	gcm, err := aead(key)
+	if err != nil {
+		return nil, err
+	}
+
+	noncesz := gcm.NonceSize()
+	if len(ciphertext) < noncesz {
+		return nil, errors.New("ciphertext too short")
+	}
+	nonce, ciphertext := ciphertext[:noncesz], ciphertext[noncesz:]
+
+	return gcm.Open(nil, nonce, ciphertext, nil)
+}
+
+func aead(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
+
+// locked prefixes the content of password protected pastes, which is encrypted with a key
+// of its own instead of the key of the keyring. The content key is stored wrapped with keys
+// derived from each of the secrets that unlock the paste, the password and the owner token,
+// so not even the holder of the encryption keys can read the content without them.
+//
+// It's formatted as locked:<wrapped key>,<wrapped key>:<ciphertext>, where every wrapped key
+// is the salt its key was derived with and the content key encrypted with it.
+const locked = "locked:"
+
+// Lock encrypts the content with a new random key that can only be recovered with one of
+// the secrets. The result still has to be encrypted with Encrypt before storing it.
+func (c *Cipher) Lock(content string, secrets ...string) (string, error) {
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
 		return "", err
 	}
 
-	ciphertext := gcm.Seal(nil, nonce, []byte(plaintext), nil)
-	return base64.StdEncoding.EncodeToString(append(nonce, ciphertext...)), nil
+	wrapped := make([]string, 0, len(secrets))
+	for _, secret := range secrets {
+		salt := make([]byte, 16)
+		if _, err := rand.Read(salt); err != nil {
+			return "", err
+		}
+
+		sealed, err := seal(argon2.IDKey([]byte(secret), salt, 3, 64*1024, 2, 32), string(key))
+		if err != nil {
+			return "", err
+		}
+
+		wrapped = append(wrapped, base64.RawURLEncoding.EncodeToString(salt)+"."+sealed)
+	}
+
+	ciphertext, err := seal(key, content)
+	if err != nil {
+		return "", err
+	}
+
+	return locked + strings.Join(wrapped, ",") + ":" + ciphertext, nil
 }
 
-func (c *Cipher) Decrypt(encrypted string) (string, error) {
-	ciphertext, err := base64.StdEncoding.DecodeString(encrypted)
+// Unlock decrypts content encrypted by Lock with any of the secrets it was locked with.
+// Fails with a forbidden error if the secret doesn't unlock it.
+func (c *Cipher) Unlock(content, secret string) (string, error) {
+	key, ciphertext, err := unwrap(content, secret)
 	if err != nil {
 		return "", err
 	}
 
-	block, err := aes.NewCipher(c.key)
+	return unseal(key, ciphertext)
+}
+
+// Relock replaces the content encrypted by Lock with a new one, unlocked by the same secrets.
+// Any of the secrets is enough to do so.
+func (c *Cipher) Relock(content, secret, replacement string) (string, error) {
+	key, ciphertext, err := unwrap(content, secret)
 	if err != nil {
 		return "", err
 	}
 
-	gcm, err := cipher.NewGCM(block)
+	sealed, err := seal(key, replacement)
 	if err != nil {
 		return "", err
 	}
 
-	noncesz := gcm.NonceSize()
-	nonce, ciphertext := ciphertext[:noncesz], ciphertext[noncesz:]
+	return strings.TrimSuffix(content, ciphertext) + sealed, nil
+}
+
+// unlocker opens content encrypted by Lock with the first of its secrets unlocking it.
+// The content, revisions and files of a paste are all locked with the key of its content,
+// so an unlocker lives as long as a request, deriving the key from the secrets only once.
+type unlocker struct {
+	secrets []string
+
+	// keys holds the content keys by the wrapped keys they were recovered from,
+	// nil when none of the secrets recovers them
+	keys map[string][]byte
+}
+
+// unlocker returns an unlocker trying each of the secrets, in order.
+func (c *Cipher) unlocker(secrets ...string) *unlocker {
+	return &unlocker{secrets: secrets, keys: make(map[string][]byte)}
+}
+
+// open returns the content key of the locked content along with its ciphertext.
+// The key is nil if none of the secrets unlocks the content.
+func (u *unlocker) open(content string) ([]byte, string) {
+	wrapped, ciphertext, _ := strings.Cut(strings.TrimPrefix(content, locked), ":")
+
+	key, ok := u.keys[wrapped]
+	if !ok {
+		for _, secret := range u.secrets {
+			if k, _, err := unwrap(content, secret); err == nil {
+				key = k
+				break
+			}
+		}
+		u.keys[wrapped] = key
+	}
+
+	return key, ciphertext
+}
+
+// reveal unlocks content encrypted by Lock. Locked content none of the secrets opens
+// is withheld, anything else is returned as is.
+func (u *unlocker) reveal(content string) string {
+	if !islocked(content) {
+		return content
+	}
 
-	plaintext, err := gcm.Open(nil, nonce, ciphertext, nil)
+	key, ciphertext := u.open(content)
+	if key == nil {
+		return ""
+	}
+
+	plaintext, err := unseal(key, ciphertext)
+	if err != nil {
+		return ""
+	}
+
+	return plaintext
+}
+
+// relock prepares the replacement of the current content, as it's stored, to be encrypted.
+// If the current content is locked, the replacement is locked the same way; the owner token
+// is enough to do so.
+func (c *Cipher) relock(current, token, replacement string) (string, error) {
+	plaintext, err := c.Decrypt(current)
 	if err != nil {
 		return "", err
 	}
 
-	return string(plaintext), nil
+	if !islocked(plaintext) {
+		return replacement, nil
+	}
+
+	return c.Relock(plaintext, token, replacement)
+}
+
+// islocked reports whether the content was encrypted by Lock.
+func islocked(content string) bool {
+	return strings.HasPrefix(content, locked)
+}
+
+// unwrap returns the content key recovered with the secret and the ciphertext of the content.
+func unwrap(content, secret string) ([]byte, string, error) {
+	wrapped, ciphertext, ok := strings.Cut(strings.TrimPrefix(content, locked), ":")
+	if !islocked(content) || !ok {
+		return nil, "", errors.New("content is not locked")
+	}
+
+	if secret != "" {
+		for _, w := range strings.Split(wrapped, ",") {
+			encoded, sealed, _ := strings.Cut(w, ".")
+
+			salt, err := base64.RawURLEncoding.DecodeString(encoded)
+			if err != nil {
+				return nil, "", errors.Wrap(err, "invalid locked content")
+			}
+
+			if key, err := unseal(argon2.IDKey([]byte(secret), salt, 3, 64*1024, 2, 32), sealed); err == nil {
+				return []byte(key), ciphertext, nil
+			}
+		}
+	}
+
+	return nil, "", errors.Wrap(errors.ErrForbidden, "secret doesn't unlock the content")
 }
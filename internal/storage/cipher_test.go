@@ -1,10 +1,13 @@
 package storage_test
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/require"
 
+	"github.com/aexvir/skladka/internal/config"
+	"github.com/aexvir/skladka/internal/errors"
 	"github.com/aexvir/skladka/internal/storage"
 )
 
@@ -22,6 +25,49 @@ func TestCipherEncryption(t *testing.T) {
 	require.Equal(t, "test", decrypted)
 }
 
+func TestCipherBlobs(t *testing.T) {
+	salt := "6370b25f61f2025a0d4fcbb4aaf8859f"
+
+	old := storage.NewCipher("oldkey", salt)
+	current, err := storage.NewKeyring(
+		config.Config{
+			Core: config.Core{
+				EncryptionKey:         "newkey",
+				EncryptionSalt:        salt,
+				EncryptionKeyID:       "2",
+				RetiredEncryptionKeys: []string{"1:oldkey:" + salt},
+			},
+		},
+	)
+	require.NoError(t, err)
+
+	data := []byte{0x89, 'P', 'N', 'G', 0x00, 0xff}
+
+	sealed, secret, err := old.SealBlob(data)
+	require.NoError(t, err)
+	require.NotContains(t, string(sealed), string(data))
+	require.True(t, strings.HasPrefix(secret, "v1:1:"))
+
+	opened, err := current.OpenBlob(sealed, secret)
+	require.NoError(t, err)
+	require.Equal(t, data, opened)
+
+	// rotating the keyring only re-encrypts the key of the blob
+	plaintext, err := current.Decrypt(secret)
+	require.NoError(t, err)
+	rotated := mustEncrypt(t, current, plaintext)
+
+	opened, err = current.OpenBlob(sealed, rotated)
+	require.NoError(t, err)
+	require.Equal(t, data, opened)
+
+	_, err = old.OpenBlob(sealed, rotated)
+	require.Error(t, err)
+
+	_, err = current.OpenBlob(data, secret)
+	require.Error(t, err)
+}
+
 func TestCipherHashing(t *testing.T) {
 	key := "supersecretkey=="
 	salt := "6370b25f61f2025a0d4fcbb4aaf8859f"
@@ -33,3 +79,117 @@ func TestCipherHashing(t *testing.T) {
 
 	require.True(t, cipher.Verify("muchosecreto", encoded))
 }
+
+func TestCipherHashingThrottle(t *testing.T) {
+	key := "supersecretkey=="
+	salt := "6370b25f61f2025a0d4fcbb4aaf8859f"
+
+	cipher := storage.NewCipher(key, salt)
+
+	encoded := cipher.Hash("muchosecreto")
+	other := cipher.Hash("muchosecreto")
+
+	for range 10 {
+		require.False(t, cipher.Verify("guess", encoded))
+	}
+
+	// too many wrong guesses refuse even the right password for a while
+	require.False(t, cipher.Verify("muchosecreto", encoded))
+	require.True(t, cipher.Verify("muchosecreto", other))
+}
+
+func TestCipherDigest(t *testing.T) {
+	key := "supersecretkey=="
+	salt := "6370b25f61f2025a0d4fcbb4aaf8859f"
+
+	cipher := storage.NewCipher(key, salt)
+
+	require.Equal(t, cipher.Digest("token"), cipher.Digest("token"))
+	require.NotEqual(t, cipher.Digest("token"), cipher.Digest("other"))
+	require.NotEqual(t, cipher.Digest("token"), storage.NewCipher("otherkey", salt).Digest("token"))
+}
+
+func TestCipherKeyring(t *testing.T) {
+	salt := "6370b25f61f2025a0d4fcbb4aaf8859f"
+
+	old, err := storage.NewKeyring(config.Config{Core: config.Core{EncryptionKey: "oldkey", EncryptionSalt: salt}})
+	require.NoError(t, err)
+
+	current, err := storage.NewKeyring(
+		config.Config{
+			Core: config.Core{
+				EncryptionKey:         "newkey",
+				EncryptionSalt:        salt,
+				EncryptionKeyID:       "2",
+				RetiredEncryptionKeys: []string{"1:oldkey:" + salt},
+			},
+		},
+	)
+	require.NoError(t, err)
+
+	encrypted, err := old.Encrypt("test")
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(encrypted, "v1:1:"))
+
+	tests := []struct {
+		name      string
+		encrypted string
+		expected  string
+		err       bool
+	}{
+		{name: "current key", encrypted: mustEncrypt(t, current, "test"), expected: "test"},
+		{name: "retired key", encrypted: encrypted, expected: "test"},
+		{name: "legacy ciphertext", encrypted: strings.TrimPrefix(encrypted, "v1:1:"), expected: "test"},
+		{name: "unknown key", encrypted: strings.Replace(encrypted, "v1:1:", "v1:3:", 1), err: true},
+		{name: "garbage", encrypted: "not encrypted", err: true},
+	}
+
+	for _, test := range tests {
+		t.Run(
+			test.name, func(t *testing.T) {
+				decrypted, err := current.Decrypt(test.encrypted)
+				if test.err {
+					require.Error(t, err)
+					return
+				}
+
+				require.NoError(t, err)
+				require.Equal(t, test.expected, decrypted)
+			},
+		)
+	}
+
+	// digests of the retired key keep sessions created before the rotation valid
+	require.Equal(t, []string{current.Digest("token"), old.Digest("token")}, current.Digests("token"))
+}
+
+func mustEncrypt(t *testing.T, cipher *storage.Cipher, plaintext string) string {
+	encrypted, err := cipher.Encrypt(plaintext)
+	require.NoError(t, err)
+	return encrypted
+}
+
+func TestCipherLock(t *testing.T) {
+	cipher := storage.NewCipher("supersecretkey==", "6370b25f61f2025a0d4fcbb4aaf8859f")
+
+	locked, err := cipher.Lock("confidential", "password", "token")
+	require.NoError(t, err)
+	require.NotContains(t, locked, "confidential")
+
+	for _, secret := range []string{"password", "token"} {
+		content, err := cipher.Unlock(locked, secret)
+		require.NoError(t, err)
+		require.Equal(t, "confidential", content)
+	}
+
+	_, err = cipher.Unlock(locked, "wrong")
+	require.True(t, errors.IsForbidden(err))
+
+	// relocking with either secret keeps both of them working
+	relocked, err := cipher.Relock(locked, "token", "updated")
+	require.NoError(t, err)
+
+	content, err := cipher.Unlock(relocked, "password")
+	require.NoError(t, err)
+	require.Equal(t, "updated", content)
+}
@@ -0,0 +1,219 @@
+package storage_test
+
+import (
+	"context"
+	dbsql "database/sql"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/stretchr/testify/require"
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/modules/postgres"
+	"github.com/testcontainers/testcontainers-go/wait"
+
+	"github.com/aexvir/skladka/internal/config"
+	"github.com/aexvir/skladka/internal/storage"
+	"github.com/aexvir/skladka/internal/storage/storagetest"
+)
+
+// postgresURL points to the database the postgres contract runs against, set up by
+// TestMain; postgresErr holds the reason when it couldn't be set up, and unavailable
+// why the container couldn't be started at all.
+var (
+	postgresURL string
+	postgresErr error
+	unavailable error
+)
+
+// TestMain runs the tests against the database at SKD_TEST_POSTGRES_URL, which has to be
+// migrated already, or otherwise against a throwaway postgres container, which needs
+// docker. The container isn't started in short mode, and without docker the postgres
+// contract is skipped, while the memory and sqlite ones still run.
+func TestMain(m *testing.M) {
+	flag.Parse()
+
+	postgresURL = os.Getenv("SKD_TEST_POSTGRES_URL")
+	if postgresURL != "" || testing.Short() {
+		os.Exit(m.Run())
+	}
+
+	ctx := context.Background()
+
+	db, err := startPostgres(ctx)
+	if err != nil {
+		unavailable = err
+		os.Exit(m.Run())
+	}
+
+	postgresURL, postgresErr = migratePostgres(ctx, db)
+
+	code := m.Run()
+
+	if err := db.Terminate(ctx); err != nil {
+		fmt.Printf("failed to terminate database container: %s\n", err)
+	}
+
+	os.Exit(code)
+}
+
+// startPostgres starts a postgres container, ready to accept connections.
+func startPostgres(ctx context.Context) (db *postgres.PostgresContainer, err error) {
+	// testcontainers panics instead of failing when it can't find docker at all
+	defer func() {
+		if reason := recover(); reason != nil {
+			err = fmt.Errorf("failed to start database container: %v", reason)
+		}
+	}()
+
+	db, err = postgres.Run(ctx,
+		"docker.io/postgres:16-alpine",
+		postgres.WithDatabase("skladka"),
+		postgres.WithUsername("popelar"),
+		postgres.WithPassword("nope"),
+		testcontainers.WithWaitStrategy(
+			// postgres restarts itself after the first startup, so it's only ready once
+			// it logged readiness twice and docker serves the port
+			wait.ForLog("database system is ready to accept connections").WithOccurrence(2),
+			wait.ForListeningPort("5432/tcp"),
+		),
+	)
+	if err != nil {
+		if db != nil {
+			db.Terminate(ctx)
+		}
+		return nil, fmt.Errorf("failed to start database container: %w", err)
+	}
+
+	return db, nil
+}
+
+// migratePostgres applies the migrations to the database in the container,
+// returning its url.
+func migratePostgres(ctx context.Context, db *postgres.PostgresContainer) (string, error) {
+	url, err := db.ConnectionString(ctx, "sslmode=disable")
+	if err != nil {
+		return "", fmt.Errorf("failed to get db conn string: %w", err)
+	}
+
+	cfg := testConfig()
+	cfg.Storage = config.Storage{Driver: "postgres", DSN: url}
+
+	migrator, conn, err := storage.NewMigrator(ctx, cfg)
+	if err != nil {
+		return "", err
+	}
+	defer conn.Close()
+
+	if _, err := migrator.Up(ctx); err != nil {
+		return "", fmt.Errorf("failed to migrate database: %w", err)
+	}
+
+	return url, nil
+}
+
+// testConfig is the config every backend is opened with in tests.
+func testConfig() config.Config {
+	return config.Config{
+		Core: config.Core{
+			EncryptionKey:   "supersecretkey==",
+			EncryptionSalt:  "6370b25f61f2025a0d4fcbb4aaf8859f",
+			SessionDuration: time.Hour,
+		},
+	}
+}
+
+func TestMemoryStorageContract(t *testing.T) {
+	storagetest.Run(
+		t, func(t *testing.T) storagetest.Storage {
+			return storage.NewMemoryStorage(testConfig())
+		},
+	)
+}
+
+func TestSQLiteStorageContract(t *testing.T) {
+	storagetest.Run(
+		t, func(t *testing.T) storagetest.Storage {
+			cfg := testConfig()
+			cfg.Storage = config.Storage{
+				Driver: "sqlite",
+				DSN:    filepath.Join(t.TempDir(), "skladka.db"),
+				Blobs:  config.Blobs{Driver: "filesystem", Path: t.TempDir()},
+			}
+
+			db, err := dbsql.Open("sqlite", cfg.Storage.DSN)
+			require.NoError(t, err)
+			t.Cleanup(func() { db.Close() })
+
+			return sqliteIndexer{SQLiteStorage: newSQLiteStorageWithConfig(t, cfg), db: db}
+		},
+	)
+}
+
+// sqliteIndexer looks into the search index of the sqlite storage for the contract suite.
+type sqliteIndexer struct {
+	*storage.SQLiteStorage
+	db *dbsql.DB
+}
+
+func (s sqliteIndexer) Indexed(ctx context.Context, ref string) (bool, error) {
+	var documents int
+	err := s.db.QueryRowContext(
+		ctx,
+		"select count(*) from paste_search where rowid in (select id from pastes where reference = ?)",
+		ref,
+	).Scan(&documents)
+	return documents > 0, err
+}
+
+// postgresIndexer looks into the search index of the postgres storage for the contract suite.
+type postgresIndexer struct {
+	*storage.PostgresStorage
+	conn *pgxpool.Pool
+}
+
+func (s postgresIndexer) Indexed(ctx context.Context, ref string) (bool, error) {
+	var documents int
+	err := s.conn.QueryRow(
+		ctx,
+		`select count(*)
+		from paste_search
+		join pastes on pastes.id = paste_search.paste_id
+		where pastes.reference = $1`,
+		ref,
+	).Scan(&documents)
+	return documents > 0, err
+}
+
+// TestPostgresStorageContract runs against the database set up by TestMain.
+func TestPostgresStorageContract(t *testing.T) {
+	if testing.Short() && postgresURL == "" {
+		t.Skip("postgres container isn't started in short mode")
+	}
+	if unavailable != nil {
+		t.Skipf("set SKD_TEST_POSTGRES_URL or make docker available: %v", unavailable)
+	}
+	require.NoError(t, postgresErr, "failed to set up the postgres database")
+
+	url := postgresURL
+
+	cfg := testConfig()
+	cfg.Storage = config.Storage{Driver: "postgres", DSN: url, Blobs: config.Blobs{Driver: "filesystem", Path: t.TempDir()}}
+
+	storagetest.Run(
+		t, func(t *testing.T) storagetest.Storage {
+			db, err := storage.NewPostgresStorage(context.Background(), cfg)
+			require.NoError(t, err)
+
+			conn, err := pgxpool.New(context.Background(), url)
+			require.NoError(t, err)
+			t.Cleanup(conn.Close)
+
+			return postgresIndexer{PostgresStorage: db, conn: conn}
+		},
+	)
+}
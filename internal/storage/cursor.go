@@ -0,0 +1,48 @@
+package storage
+
+import (
+	"encoding/base64"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aexvir/skladka/internal/errors"
+)
+
+// cursor points at the last paste of a page, listings continue right after it.
+// Pastes are ordered by creation time and id, so the cursor holds both.
+type cursor struct {
+	created time.Time
+	id      int64
+}
+
+// encode returns the cursor as an opaque url safe string.
+func (c cursor) encode() string {
+	raw := c.created.UTC().Format(time.RFC3339Nano) + "," + strconv.FormatInt(c.id, 10)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// decodeCursor parses a cursor previously returned by encode.
+func decodeCursor(value string) (cursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return cursor{}, errors.Wrap(err, "invalid cursor")
+	}
+
+	created, id, ok := strings.Cut(string(raw), ",")
+	if !ok {
+		return cursor{}, errors.New("invalid cursor")
+	}
+
+	var c cursor
+
+	if c.created, err = time.Parse(time.RFC3339Nano, created); err != nil {
+		return cursor{}, errors.Wrap(err, "invalid cursor")
+	}
+
+	if c.id, err = strconv.ParseInt(id, 10, 64); err != nil {
+		return cursor{}, errors.Wrap(err, "invalid cursor")
+	}
+
+	return c, nil
+}
@@ -2,9 +2,59 @@
 // It implements a PostgreSQL-based storage backend with support for:
 //   - Storing and retrieving pastes with metadata
 //   - Managing paste visibility (public/private)
-//   - Handling paste expiration
-//   - Reference generation and validation
+//   - Handling paste expiration, with a background reaper removing expired pastes
+//   - Unique reference generation of configurable length, retrying on collisions
+//   - Keyset paginated and filtered listings of public pastes
+//   - Full-text search over the titles, tags and contents of public pastes
+//   - Tag usage counts of public pastes, filterable by prefix for autocompletion
+//   - Owner tokens, stored hashed, authorizing edits and deletions of pastes
+//   - Keeping the previous versions of edited pastes as revisions
+//   - Tracking forks of pastes through their parent reference
+//   - User accounts with hashed passwords and cookie sessions owning pastes
+//   - Personal access tokens with scopes, stored as digests
 //   - Content encryption for private pastes
+//   - Per-paste content keys for password protected pastes, only recoverable with the
+//     password or the owner token, so not even the encryption keys reveal their content;
+//     hashes verified against too many wrong passwords in a row are throttled for a while
+//   - Client encrypted pastes, whose content is stored and returned as received and
+//     only their title is encrypted by the server
+//   - Pastes split in several named files, stored apart and only returned when reading
+//     a single paste; the first file is mirrored as the content of the paste, which is
+//     all their revisions keep
+//   - Binary attachments, like screenshots, kept in a blob store apart from the database
+//     under random keys; each blob is encrypted with its own key, which is stored sealed
+//     with the encryption key along with the encrypted name of the attachment
+//
+// Blob stores live in the blob subpackage, attachments are kept on the local filesystem
+// by default or in any s3 compatible bucket, selected with SKD_BLOBS_DRIVER.
+//
+// Ciphertexts carry the id of the key they were encrypted with, so the encryption key
+// can be rotated. After configuring a new SKD_ENCRYPTION_KEY and SKD_ENCRYPTION_KEY_ID,
+// with the previous key listed in SKD_RETIRED_ENCRYPTION_KEYS as id:key:salt, running
+// `skladka admin rotate-keys` re-encrypts the existing pastes in batched transactions.
+// The blobs of attachments are left as they are, only the keys sealing them are re-encrypted.
+// Sessions and api tokens are stored as digests that can't be re-encrypted in bulk,
+// instead each is digested again with the current key the first time it's used after
+// the rotation. Those not used while the key they were created with was still among
+// the retired keys stop working once it's removed.
+//
+// The migrations of both sql backends are embedded in the binary and applied by the
+// migrator in the migrate subpackage, which NewMigrator returns for the configured
+// storage. `skladka migrate up`, `skladka migrate down [steps]` and `skladka migrate
+// status` run it, so deployments don't need atlas to migrate the database.
+//
+// SQLiteStorage implements the same operations on top of a single sqlite file, for
+// self-hosting skladka as a single binary. Its schema lives in the sqlite subpackage
+// and is migrated when the storage is opened.
+//
+// MemoryStorage implements the same operations without a database, keeping everything
+// in memory. It's meant for tests and local development.
+//
+// Every backend is verified by the same contract, the suite in the storagetest
+// subpackage, so new backends only have to plug into it.
+//
+// The backend is selected through the storage section of the config, e.g.
+// SKD_STORAGE_DRIVER=sqlite and SKD_STORAGE_DSN=/var/lib/skladka/skladka.db.
 //
 // The package uses sqlc for type-safe SQL queries and includes metrics
 // for monitoring database operations. It also integrates with the application's
@@ -21,4 +71,8 @@
 //	if err != nil {
 //		return fmt.Errorf("failed to retrieve paste: %w", err)
 //	}
+//
+//	// soft delete expired pastes every minute until stopped
+//	stop := storage.StartReaper(ctx, db, time.Minute)
+//	defer stop()
 package storage
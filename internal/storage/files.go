@@ -0,0 +1,162 @@
+package storage
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"strings"
+
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/aexvir/skladka/internal/errors"
+	"github.com/aexvir/skladka/internal/paste"
+	"github.com/aexvir/skladka/internal/storage/sql"
+)
+
+// amend completes an update of a paste with the files it already has. Updates without
+// files only replace the content and syntax of the first file, so clients unaware of
+// files don't drop the rest of them; an empty list of files does.
+func amend(p *paste.Paste, current paste.Paste) {
+	if p.Files == nil && len(current.Files) > 0 {
+		p.Files = slices.Clone(current.Files)
+		p.Files[0].Content = p.Content
+		p.Files[0].Syntax = p.Syntax
+	}
+
+	p.Flatten()
+}
+
+// searchable returns the content of the paste to index for search, every file of it.
+// The content of protected pastes isn't searchable, nor is the ciphertext of client encrypted ones.
+func searchable(p paste.Paste) string {
+	if p.Password != nil || p.ClientEncrypted {
+		return ""
+	}
+
+	if len(p.Files) == 0 {
+		return p.Content
+	}
+
+	contents := make([]string, len(p.Files))
+	for i, f := range p.Files {
+		contents[i] = f.Content
+	}
+
+	return strings.Join(contents, "\n")
+}
+
+// sealfiles prepares the files of a paste to be stored, encrypting their names and contents.
+// If the content of the paste, as it's stored before being encrypted, is locked, the files
+// are locked with the same key, recovered with the owner token.
+func (c *Cipher) sealfiles(files []paste.File, content, token string, clientEncrypted bool) ([]paste.File, error) {
+	sealed := slices.Clone(files)
+
+	var (
+		key    []byte
+		prefix string
+	)
+	if !clientEncrypted && islocked(content) {
+		k, ciphertext, err := unwrap(content, token)
+		if err != nil {
+			return nil, err
+		}
+		key, prefix = k, strings.TrimSuffix(content, ciphertext)
+	}
+
+	for i := range sealed {
+		f := &sealed[i]
+
+		if key != nil {
+			ciphertext, err := seal(key, f.Content)
+			if err != nil {
+				return nil, errors.Wrapf(err, "failed to lock file %q", f.Name)
+			}
+			f.Content = prefix + ciphertext
+		}
+
+		var errn, errc error
+		f.Name, errn = c.Encrypt(f.Name)
+		if !clientEncrypted {
+			f.Content, errc = c.Encrypt(f.Content)
+		}
+		if err := errors.Join(errn, errc); err != nil {
+			return nil, err
+		}
+	}
+
+	return sealed, nil
+}
+
+// openfiles decrypts the stored files of a paste. Locked contents are only revealed
+// if the unlocker opens them.
+func (c *Cipher) openfiles(files []paste.File, clientEncrypted bool, unlock *unlocker) ([]paste.File, error) {
+	if len(files) == 0 {
+		return nil, nil
+	}
+
+	opened := slices.Clone(files)
+
+	for i := range opened {
+		f := &opened[i]
+		f.Syntax = cmp.Or(f.Syntax, "plaintext")
+
+		var errn, errc error
+		f.Name, errn = c.Decrypt(f.Name)
+		if !clientEncrypted {
+			f.Content, errc = c.Decrypt(f.Content)
+		}
+		if err := errors.Join(errn, errc); err != nil {
+			return nil, err
+		}
+
+		if !clientEncrypted {
+			f.Content = unlock.reveal(f.Content)
+		}
+	}
+
+	return opened, nil
+}
+
+// files loads the stored files of the paste, decrypting them with the unlocker.
+func (s *PostgresStorage) files(ctx context.Context, p *paste.Paste, unlock *unlocker) error {
+	rows, err := s.db.ListPasteFiles(ctx, p.Reference)
+	if err != nil {
+		s.metrics.PasteErrors.Add(ctx, 1)
+		return errors.Wrapf(err, "failed to list files of paste %s", p.Reference)
+	}
+
+	files := make([]paste.File, len(rows))
+	for i, row := range rows {
+		files[i] = row.ToDomain()
+	}
+
+	if p.Files, err = s.cipher.openfiles(files, p.ClientEncrypted, unlock); err != nil {
+		return errors.Wrap(err, "failed to decrypt files")
+	}
+
+	return nil
+}
+
+// storefiles replaces the stored files of the paste with the already encrypted ones.
+func (s *PostgresStorage) storefiles(ctx context.Context, db *sql.Queries, ref string, files []paste.File) error {
+	if err := db.DeletePasteFiles(ctx, ref); err != nil {
+		return errors.Wrapf(err, "failed to store files of paste %s", ref)
+	}
+
+	for position, file := range files {
+		err := db.CreatePasteFile(
+			ctx, sql.CreatePasteFileParams{
+				Reference: ref,
+				Position:  int32(position),
+				Name:      file.Name,
+				Syntax:    pgtype.Text{String: file.Syntax, Valid: file.Syntax != ""},
+				Content:   file.Content,
+			},
+		)
+		if err != nil {
+			return errors.Wrapf(err, "failed to store files of paste %s", ref)
+		}
+	}
+
+	return nil
+}
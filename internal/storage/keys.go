@@ -0,0 +1,232 @@
+package storage
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/aexvir/skladka/internal/errors"
+	"github.com/aexvir/skladka/internal/storage/sql"
+	"github.com/aexvir/skladka/internal/tracing"
+)
+
+// rotation is a row whose encrypted fields have to be re-encrypted with the current key.
+type rotation struct {
+	id      int64
+	title   string
+	content string
+
+	// the content of client encrypted pastes isn't encrypted by the server
+	clientEncrypted bool
+}
+
+// reencrypt decrypts the fields of the row with whichever key they were encrypted with
+// and encrypts them again with the current one.
+func (r *rotation) reencrypt(c *Cipher) error {
+	fields := []*string{&r.title}
+	if !r.clientEncrypted {
+		fields = append(fields, &r.content)
+	}
+
+	for _, field := range fields {
+		plaintext, err := c.Decrypt(*field)
+		if err != nil {
+			return errors.Wrapf(err, "failed to decrypt row %d", r.id)
+		}
+
+		if *field, err = c.Encrypt(plaintext); err != nil {
+			return errors.Wrapf(err, "failed to encrypt row %d", r.id)
+		}
+	}
+
+	return nil
+}
+
+// RotateKeys re-encrypts the pastes, revisions, files and attachments that weren't encrypted with the current key,
+// committing a transaction every batch rows. Returns the number of rows re-encrypted.
+// Sessions and api tokens are only stored as digests, so they're moved to the current key
+// the first time they're used after the rotation instead. Retired keys can be removed from
+// the config once the sessions created before have expired and the tokens have been used,
+// the ones left unused stop working.
+func (s *PostgresStorage) RotateKeys(ctx context.Context, batch int) (int, error) {
+	var err error
+	ctx, finish := tracing.FromContext(ctx, trace.SpanKindInternal, "PostgresStorage.RotateKeys")
+	defer finish(&err)
+
+	if batch <= 0 {
+		return 0, errors.Errorf("invalid batch size %d", batch)
+	}
+
+	pastes, err := s.rotate(
+		ctx, batch,
+		func(db *sql.Queries, after int64) ([]rotation, error) {
+			rows, err := db.ListPastesToRotate(
+				ctx, sql.ListPastesToRotateParams{
+					After:     after,
+					Prefix:    s.cipher.Envelope(),
+					BatchSize: int32(batch),
+				},
+			)
+
+			rotations := make([]rotation, len(rows))
+			for i, row := range rows {
+				rotations[i] = rotation{id: row.ID, title: row.Title, content: row.Content, clientEncrypted: row.ClientEncrypted}
+			}
+
+			return rotations, err
+		},
+		func(db *sql.Queries, row rotation) error {
+			return db.RotatePaste(ctx, sql.RotatePasteParams{ID: row.id, Title: row.title, Content: row.content})
+		},
+	)
+	if err != nil {
+		return pastes, errors.Wrap(err, "failed to rotate pastes")
+	}
+
+	revisions, err := s.rotate(
+		ctx, batch,
+		func(db *sql.Queries, after int64) ([]rotation, error) {
+			rows, err := db.ListRevisionsToRotate(
+				ctx, sql.ListRevisionsToRotateParams{
+					After:     after,
+					Prefix:    s.cipher.Envelope(),
+					BatchSize: int32(batch),
+				},
+			)
+
+			rotations := make([]rotation, len(rows))
+			for i, row := range rows {
+				rotations[i] = rotation{id: row.ID, title: row.Title, content: row.Content, clientEncrypted: row.ClientEncrypted}
+			}
+
+			return rotations, err
+		},
+		func(db *sql.Queries, row rotation) error {
+			return db.RotateRevision(ctx, sql.RotateRevisionParams{ID: row.id, Title: row.title, Content: row.content})
+		},
+	)
+	if err != nil {
+		return pastes + revisions, errors.Wrap(err, "failed to rotate revisions")
+	}
+
+	// the name of a file is encrypted the same way the title of a paste is
+	files, err := s.rotate(
+		ctx, batch,
+		func(db *sql.Queries, after int64) ([]rotation, error) {
+			rows, err := db.ListFilesToRotate(
+				ctx, sql.ListFilesToRotateParams{
+					After:     after,
+					Prefix:    s.cipher.Envelope(),
+					BatchSize: int32(batch),
+				},
+			)
+
+			rotations := make([]rotation, len(rows))
+			for i, row := range rows {
+				rotations[i] = rotation{id: row.ID, title: row.Name, content: row.Content, clientEncrypted: row.ClientEncrypted}
+			}
+
+			return rotations, err
+		},
+		func(db *sql.Queries, row rotation) error {
+			return db.RotateFile(ctx, sql.RotateFileParams{ID: row.id, Name: row.title, Content: row.content})
+		},
+	)
+	if err != nil {
+		return pastes + revisions + files, errors.Wrap(err, "failed to rotate files")
+	}
+
+	// the contents of attachments are encrypted with their own keys, only the sealed
+	// keys have to be re-encrypted, the blobs are left as they are
+	attachments, err := s.rotate(
+		ctx, batch,
+		func(db *sql.Queries, after int64) ([]rotation, error) {
+			rows, err := db.ListAttachmentsToRotate(
+				ctx, sql.ListAttachmentsToRotateParams{
+					After:     after,
+					Prefix:    s.cipher.Envelope(),
+					BatchSize: int32(batch),
+				},
+			)
+
+			rotations := make([]rotation, len(rows))
+			for i, row := range rows {
+				rotations[i] = rotation{id: row.ID, title: row.Name, content: row.Secret}
+			}
+
+			return rotations, err
+		},
+		func(db *sql.Queries, row rotation) error {
+			return db.RotateAttachment(ctx, sql.RotateAttachmentParams{ID: row.id, Name: row.title, Secret: row.content})
+		},
+	)
+	if err != nil {
+		return pastes + revisions + files + attachments, errors.Wrap(err, "failed to rotate attachments")
+	}
+
+	return pastes + revisions + files + attachments, nil
+}
+
+// rotate re-encrypts the rows returned by list in batches, each in its own transaction,
+// until a batch comes back short.
+func (s *PostgresStorage) rotate(
+	ctx context.Context,
+	batch int,
+	list func(db *sql.Queries, after int64) ([]rotation, error),
+	update func(db *sql.Queries, row rotation) error,
+) (int, error) {
+	var (
+		rotated int
+		after   int64
+	)
+
+	for {
+		rows, err := s.rotatebatch(ctx, after, list, update)
+		rotated += len(rows)
+		if err != nil {
+			return rotated, err
+		}
+
+		if len(rows) < batch {
+			return rotated, nil
+		}
+
+		after = rows[len(rows)-1].id
+	}
+}
+
+func (s *PostgresStorage) rotatebatch(
+	ctx context.Context,
+	after int64,
+	list func(db *sql.Queries, after int64) ([]rotation, error),
+	update func(db *sql.Queries, row rotation) error,
+) ([]rotation, error) {
+	tx, err := s.conn.Begin(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to begin transaction")
+	}
+	defer tx.Rollback(ctx)
+
+	db := s.db.WithTx(tx)
+
+	rows, err := list(db, after)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list rows to rotate")
+	}
+
+	for _, row := range rows {
+		if err = row.reencrypt(s.cipher); err != nil {
+			return nil, err
+		}
+
+		if err = update(db, row); err != nil {
+			return nil, errors.Wrapf(err, "failed to update row %d", row.id)
+		}
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return nil, errors.Wrap(err, "failed to commit transaction")
+	}
+
+	return rows, nil
+}
@@ -0,0 +1,904 @@
+package storage
+
+import (
+	"cmp"
+	"context"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/aexvir/skladka/internal/config"
+	"github.com/aexvir/skladka/internal/errors"
+	"github.com/aexvir/skladka/internal/paste"
+	"github.com/aexvir/skladka/internal/storage/blob"
+	"github.com/aexvir/skladka/internal/user"
+)
+
+// MemoryStorage keeps every paste, user and session in memory, behaving the same way
+// the postgres storage does: pastes are encrypted, passwords and tokens are only kept
+// hashed, reads are counted and missing rows are reported as pgx.ErrNoRows.
+// It's meant for tests and local development, everything is lost once the process exits.
+//
+// MemoryStorage is safe for concurrent use.
+type MemoryStorage struct {
+	mu       sync.Mutex
+	cipher   *Cipher
+	blobs    blob.Store
+	sessions time.Duration
+	reflen   int
+	sequence int64
+
+	pastes    map[string]*memoryPaste
+	users     map[int64]*memoryUser
+	logins    map[string]memorySession
+	apitokens map[int64]*memoryToken
+}
+
+// memoryPaste is a paste as stored by the memory storage, encrypted and with
+// only the id of its owner, the same way it would be stored in the database.
+type memoryPaste struct {
+	id        int64
+	paste     paste.Paste
+	token     string
+	updated   *time.Time
+	deleted   *time.Time
+	revisions []paste.Revision
+
+	// files and attachments are kept apart from the paste, only reads of a single paste return them
+	files       []paste.File
+	attachments []storedAttachment
+}
+
+// NewMemoryStorage returns an empty memory storage, encrypting pastes with the key
+// and salt of the config. Attachments are kept in memory too, whatever blob store is configured.
+func NewMemoryStorage(cfg config.Config) *MemoryStorage {
+	return &MemoryStorage{
+		cipher:    NewCipher(cfg.EncryptionKey, cfg.EncryptionSalt),
+		blobs:     blob.NewMemory(),
+		sessions:  cfg.SessionDuration,
+		reflen:    referenceLength(cfg.ReferenceLength),
+		pastes:    make(map[string]*memoryPaste),
+		users:     make(map[int64]*memoryUser),
+		logins:    make(map[string]memorySession),
+		apitokens: make(map[int64]*memoryToken),
+	}
+}
+
+// Close does nothing, the memory storage doesn't hold any connection.
+// It's there so the memory storage can be closed like the other storages.
+func (s *MemoryStorage) Close() error {
+	return nil
+}
+
+// CreatePaste stores a new paste, returning its reference and the secret token
+// that authorizes its owner to edit or delete it.
+func (s *MemoryStorage) CreatePaste(ctx context.Context, p paste.Paste) (string, string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// forks can only be created from pastes that are still around
+	if p.Parent != nil {
+		if _, err := s.lookup(*p.Parent); err != nil {
+			return "", "", errors.Wrapf(errors.ErrBadRequest, "parent paste %s not found", *p.Parent)
+		}
+	}
+
+	// vanity slugs are used as the reference instead of a random one
+	var (
+		ref string
+		err error
+	)
+	if p.Slug != "" {
+		ref, err = s.slug(p.Slug)
+	} else {
+		ref, err = s.ref(10)
+	}
+	if err != nil {
+		return "", "", err
+	}
+
+	token, err := generateToken()
+	if err != nil {
+		return "", "", errors.Wrap(err, "failed to generate token")
+	}
+
+	p.Flatten()
+
+	// the content of protected pastes is only readable with the password or the owner token,
+	// client encrypted ones can't be read by the server in the first place
+	if p.Password != nil {
+		if !p.ClientEncrypted {
+			if p.Content, err = s.cipher.Lock(p.Content, *p.Password, token); err != nil {
+				return "", "", errors.Wrap(err, "failed to lock content")
+			}
+		}
+
+		hash := s.cipher.Hash(*p.Password)
+		p.Password = &hash
+	}
+
+	files, err := s.cipher.sealfiles(p.Files, p.Content, token, p.ClientEncrypted)
+	if err != nil {
+		return "", "", errors.Wrap(err, "failed to encrypt files")
+	}
+	p.Files = nil
+
+	if err := s.encrypt(&p); err != nil {
+		return "", "", errors.Wrap(err, "failed to encrypt data")
+	}
+
+	attachments, err := putattachments(ctx, s.blobs, s.cipher, p.Attachments)
+	if err != nil {
+		return "", "", err
+	}
+	p.Attachments = nil
+
+	p.Reference = ref
+	p.Slug = ""
+	p.Views = 0
+	p.Tags = slices.Clone(p.Tags)
+	p.Creation = time.Now().UTC()
+
+	// only the id of the owner is stored, the username is resolved on reads
+	if p.Owner != nil {
+		p.Owner = &user.User{ID: p.Owner.ID}
+	}
+
+	s.pastes[ref] = &memoryPaste{
+		id:          s.next(),
+		paste:       p,
+		token:       s.cipher.Hash(token),
+		files:       files,
+		attachments: attachments,
+	}
+
+	return ref, token, nil
+}
+
+// GetPaste retrieves a paste by its reference, counting the read.
+// Password protected pastes are returned without counting the read,
+// it's counted once the password is verified via GetPasteWithPassword.
+func (s *MemoryStorage) GetPaste(_ context.Context, ref string) (paste.Paste, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stored, err := s.lookup(ref)
+	if err != nil {
+		return paste.Paste{}, err
+	}
+
+	if stored.paste.Password == nil {
+		s.read(stored)
+	}
+
+	return s.document(stored)
+}
+
+// GetPasteWithPassword retrieves a password protected paste, counting the read.
+// Returns nil if the password doesn't match.
+func (s *MemoryStorage) GetPasteWithPassword(_ context.Context, ref, password string) (*paste.Paste, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stored, err := s.lookup(ref)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get paste")
+	}
+
+	if stored.paste.Password == nil {
+		return nil, errors.Errorf("paste %s doesn't have a password", ref)
+	}
+
+	if !s.cipher.Verify(password, *stored.paste.Password) {
+		return nil, nil
+	}
+
+	s.read(stored)
+
+	p, err := s.document(stored, password)
+	if err != nil {
+		return nil, err
+	}
+
+	return &p, nil
+}
+
+// PeekPaste retrieves a paste without counting the read, so looking at it never burns it.
+// Returns nil if the paste is password protected and the password doesn't match.
+func (s *MemoryStorage) PeekPaste(_ context.Context, ref, password string) (*paste.Paste, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stored, err := s.lookup(ref)
+	if err != nil {
+		return nil, err
+	}
+
+	if stored.paste.Password != nil && !s.cipher.Verify(password, *stored.paste.Password) {
+		return nil, nil
+	}
+
+	p, err := s.document(stored, password)
+	if err != nil {
+		return nil, err
+	}
+
+	return &p, nil
+}
+
+// GetPasteWithToken retrieves a paste on behalf of its owner, without requiring
+// the password and without counting the read.
+func (s *MemoryStorage) GetPasteWithToken(_ context.Context, ref, token string) (paste.Paste, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stored, err := s.authorize(ref, token)
+	if err != nil {
+		return paste.Paste{}, err
+	}
+
+	return s.document(stored, token)
+}
+
+// GetAttachment returns the attachment of the paste with the given name, along with its content.
+// Reading an attachment doesn't count as a read of the paste.
+func (s *MemoryStorage) GetAttachment(ctx context.Context, ref, name string) (paste.Attachment, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stored, err := s.lookup(ref)
+	if err != nil {
+		return paste.Attachment{}, err
+	}
+
+	return readattachment(ctx, s.blobs, s.cipher, stored.attachments, name)
+}
+
+// ListPastes returns a page of at most limit public pastes matching the filter, newest first.
+// Pages are keyset paginated the same way the postgres storage does it, so cursors are
+// interchangeable between both.
+func (s *MemoryStorage) ListPastes(_ context.Context, filter paste.Filter, after string, limit int) (paste.Page, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var page paste.Page
+
+	var from *cursor
+	if after != "" {
+		c, err := decodeCursor(after)
+		if err != nil {
+			return page, errors.NewHTTPError(http.StatusBadRequest, "invalid cursor", err)
+		}
+		from = &c
+	}
+
+	matching := s.collect(
+		func(stored *memoryPaste) bool {
+			p := stored.paste
+			switch {
+			case !p.Public:
+				return false
+			case filter.Tag != "" && !slices.Contains(p.Tags, filter.Tag):
+				return false
+			case filter.Syntax != "" && p.Syntax != filter.Syntax:
+				return false
+			case filter.Since != nil && p.Creation.Before(*filter.Since):
+				return false
+			case filter.Until != nil && !p.Creation.Before(*filter.Until):
+				return false
+			case from != nil && !before(stored, *from):
+				return false
+			}
+			return true
+		},
+	)
+
+	if len(matching) > limit {
+		matching = matching[:limit]
+		last := matching[limit-1]
+		page.Next = cursor{created: last.paste.Creation, id: last.id}.encode()
+	}
+
+	pastes, err := s.domains(matching)
+	if err != nil {
+		return page, err
+	}
+	page.Pastes = pastes
+
+	return page, nil
+}
+
+// ListForks returns the public pastes forked from the paste with the given reference.
+func (s *MemoryStorage) ListForks(_ context.Context, ref string) ([]paste.Paste, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.domains(
+		s.collect(
+			func(stored *memoryPaste) bool {
+				return stored.paste.Public && stored.paste.Parent != nil && *stored.paste.Parent == ref
+			},
+		),
+	)
+}
+
+// ListOwnerPastes returns every paste of the user, newest first, including unlisted,
+// expired and burned ones, while pastes deleted on purpose are left out.
+func (s *MemoryStorage) ListOwnerPastes(_ context.Context, account user.User) ([]paste.Paste, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	owned := make([]*memoryPaste, 0)
+	for _, stored := range s.pastes {
+		p := stored.paste
+		if p.Owner == nil || p.Owner.ID != account.ID {
+			continue
+		}
+
+		reaped := p.Expiration != nil && stored.deleted != nil && !stored.deleted.Before(*p.Expiration)
+		if stored.deleted == nil || reaped || p.Burned() {
+			owned = append(owned, stored)
+		}
+	}
+	sortNewest(owned)
+
+	pastes, err := s.domains(owned)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range pastes {
+		pastes[i].Owner = &account
+	}
+
+	return pastes, nil
+}
+
+// DeleteOwnerPastes soft deletes the pastes of the user with the given references at once.
+// References of pastes owned by someone else are ignored. Returns the number of deleted pastes.
+func (s *MemoryStorage) DeleteOwnerPastes(_ context.Context, account user.User, refs []string) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now().UTC()
+
+	var deleted int64
+	for _, ref := range refs {
+		stored, ok := s.pastes[ref]
+		if !ok || stored.deleted != nil || stored.paste.Owner == nil || stored.paste.Owner.ID != account.ID {
+			continue
+		}
+		stored.deleted = &now
+		deleted++
+	}
+
+	return deleted, nil
+}
+
+// UpdatePaste replaces the title, content, syntax, tags and files of a paste, authorized
+// by the token returned on creation. The previous state is kept as a revision, updates
+// that don't change anything are ignored.
+func (s *MemoryStorage) UpdatePaste(_ context.Context, ref, token string, p paste.Paste) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stored, err := s.authorize(ref, token)
+	if err != nil {
+		return err
+	}
+
+	current, err := s.document(stored, token)
+	if err != nil {
+		return err
+	}
+
+	return s.update(stored, token, current, p)
+}
+
+// UpdateOwnerPaste replaces the title, content, syntax, tags and files of a paste on behalf
+// of the user owning it, without its owner token. Password protected pastes are locked with
+// their owner token, so they can only be edited with it.
+func (s *MemoryStorage) UpdateOwnerPaste(_ context.Context, account user.User, ref string, p paste.Paste) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stored, err := s.lookup(ref)
+	if err != nil {
+		return err
+	}
+
+	current, err := s.document(stored)
+	if err != nil {
+		return err
+	}
+
+	if err = owns(current, account); err != nil {
+		return err
+	}
+
+	return s.update(stored, "", current, p)
+}
+
+// update stores the edit of the current paste, authorized already, keeping the current
+// state as a revision. The token, if any, relocks the content of protected pastes.
+// The lock has to be held by the caller.
+func (s *MemoryStorage) update(stored *memoryPaste, token string, current, p paste.Paste) error {
+	var err error
+
+	amend(&p, current)
+
+	// pastes stay client encrypted, the new content has to be encrypted by the client as well
+	p.ClientEncrypted = current.ClientEncrypted
+	if p.ClientEncrypted {
+		if err = p.Validate(); err != nil {
+			return errors.NewHTTPError(http.StatusBadRequest, err.Error(), err)
+		}
+	}
+
+	if current.Title == p.Title &&
+		current.Content == p.Content &&
+		current.Syntax == p.Syntax &&
+		slices.Equal(current.Tags, p.Tags) &&
+		slices.Equal(current.Files, p.Files) {
+		return nil
+	}
+
+	if !current.ClientEncrypted {
+		if p.Content, err = s.cipher.relock(stored.paste.Content, token, p.Content); err != nil {
+			return errors.Wrap(err, "failed to lock content")
+		}
+	}
+
+	files, err := s.cipher.sealfiles(p.Files, p.Content, token, p.ClientEncrypted)
+	if err != nil {
+		return errors.Wrap(err, "failed to encrypt files")
+	}
+
+	if err := s.encrypt(&p); err != nil {
+		return errors.Wrap(err, "failed to encrypt data")
+	}
+
+	stored.revisions = append(stored.revisions, stored.revision(len(stored.revisions)+1))
+
+	now := time.Now().UTC()
+	stored.paste.Title = p.Title
+	stored.paste.Content = p.Content
+	stored.paste.Syntax = p.Syntax
+	stored.paste.Tags = slices.Clone(p.Tags)
+	stored.files = files
+	stored.updated = &now
+
+	return nil
+}
+
+// DeletePaste soft deletes a paste, authorized by the token returned on creation.
+func (s *MemoryStorage) DeletePaste(_ context.Context, ref, token string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stored, err := s.authorize(ref, token)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now().UTC()
+	stored.deleted = &now
+
+	return nil
+}
+
+// ListRevisions returns every revision of a paste, oldest first.
+// The last revision is always the current state of the paste. The content of password
+// protected pastes is only revealed when the secret is their password or owner token.
+func (s *MemoryStorage) ListRevisions(_ context.Context, ref, secret string) ([]paste.Revision, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stored, err := s.lookup(ref)
+	if err != nil {
+		return nil, err
+	}
+
+	revisions := append(slices.Clone(stored.revisions), stored.revision(len(stored.revisions)+1))
+	// the revisions are locked with the same key, which is only derived once
+	unlock := s.cipher.unlocker(secret)
+	for i := range revisions {
+		if err := s.decryptRevision(&revisions[i], unlock); err != nil {
+			return nil, errors.Wrap(err, "failed to decrypt data")
+		}
+	}
+
+	return revisions, nil
+}
+
+// GetRevision returns a single revision of a paste by its number.
+// The content of password protected pastes is only revealed when the secret is their
+// password or owner token.
+func (s *MemoryStorage) GetRevision(_ context.Context, ref string, number int, secret string) (paste.Revision, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stored, err := s.lookup(ref)
+	if err != nil {
+		return paste.Revision{}, err
+	}
+
+	var revision paste.Revision
+
+	switch {
+	case number == len(stored.revisions)+1:
+		revision = stored.revision(number)
+	case number >= 1 && number <= len(stored.revisions):
+		revision = stored.revisions[number-1]
+	default:
+		return paste.Revision{}, pgx.ErrNoRows
+	}
+
+	if err := s.decryptRevision(&revision, s.cipher.unlocker(secret)); err != nil {
+		return paste.Revision{}, errors.Wrap(err, "failed to decrypt data")
+	}
+
+	return revision, nil
+}
+
+// SearchPastes returns at most limit public pastes containing every word of the query,
+// newest first. Words prefixed with a dash exclude the pastes containing them.
+// It's a rough take on the full-text search of the postgres storage, matching whole
+// words of the titles, tags and contents of every file; only the title and tags of
+// password protected pastes are searched.
+func (s *MemoryStorage) SearchPastes(_ context.Context, query string, limit int) ([]paste.Paste, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var include, exclude []string
+	for _, word := range strings.Fields(strings.ToLower(query)) {
+		negated := strings.HasPrefix(word, "-")
+		for _, term := range words(word) {
+			if negated {
+				exclude = append(exclude, term)
+			} else {
+				include = append(include, term)
+			}
+		}
+	}
+
+	if len(include) == 0 {
+		return []paste.Paste{}, nil
+	}
+
+	candidates := s.collect(func(stored *memoryPaste) bool { return stored.paste.Public })
+
+	results := make([]paste.Paste, 0, limit)
+	for _, stored := range candidates {
+		if len(results) == limit {
+			break
+		}
+
+		p, err := s.document(stored)
+		if err != nil {
+			continue
+		}
+
+		document := p.Title + " " + strings.Join(p.Tags, " ") + " " + searchable(p)
+
+		terms := words(strings.ToLower(document))
+		if containsAll(terms, include) && !containsAny(terms, exclude) {
+			// like any other listing, search results don't carry the files
+			p.Files = nil
+			results = append(results, p)
+		}
+	}
+
+	return results, nil
+}
+
+// ListTags returns at most limit tags used on public pastes starting with the prefix,
+// most used first.
+func (s *MemoryStorage) ListTags(_ context.Context, prefix string, limit int) ([]paste.Tag, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	counts := make(map[string]int)
+	for _, stored := range s.collect(func(stored *memoryPaste) bool { return stored.paste.Public }) {
+		for _, tag := range stored.paste.Tags {
+			if strings.HasPrefix(tag, prefix) {
+				counts[tag]++
+			}
+		}
+	}
+
+	tags := make([]paste.Tag, 0, len(counts))
+	for name, count := range counts {
+		tags = append(tags, paste.Tag{Name: name, Pastes: count})
+	}
+
+	slices.SortFunc(
+		tags, func(a, b paste.Tag) int {
+			return cmp.Or(cmp.Compare(b.Pastes, a.Pastes), cmp.Compare(a.Name, b.Name))
+		},
+	)
+
+	if len(tags) > limit {
+		tags = tags[:limit]
+	}
+
+	return tags, nil
+}
+
+// ReapExpiredPastes soft deletes every expired paste, returning how many were deleted.
+func (s *MemoryStorage) ReapExpiredPastes(_ context.Context) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now().UTC()
+
+	var reaped int64
+	for _, stored := range s.pastes {
+		if stored.deleted == nil && stored.paste.Expiration != nil && !stored.paste.Expiration.After(now) {
+			stored.deleted = &now
+			reaped++
+		}
+	}
+
+	return reaped, nil
+}
+
+// lookup returns the paste with the given reference, as long as it wasn't deleted and
+// didn't expire yet. Must be called with the lock held.
+func (s *MemoryStorage) lookup(ref string) (*memoryPaste, error) {
+	stored, ok := s.pastes[ref]
+	if !ok || !stored.live(time.Now()) {
+		return nil, pgx.ErrNoRows
+	}
+	return stored, nil
+}
+
+// authorize verifies the token against the hash stored for the paste.
+// Must be called with the lock held.
+func (s *MemoryStorage) authorize(ref, token string) (*memoryPaste, error) {
+	stored, err := s.lookup(ref)
+	if err != nil {
+		return nil, err
+	}
+
+	if token == "" || !s.cipher.Verify(token, stored.token) {
+		return nil, errors.Wrapf(errors.ErrForbidden, "invalid token for paste %s", ref)
+	}
+
+	return stored, nil
+}
+
+// read counts a read of the paste, burning it if it reached its read limit.
+func (s *MemoryStorage) read(stored *memoryPaste) {
+	stored.paste.Views++
+
+	if stored.paste.Burned() {
+		now := time.Now().UTC()
+		stored.deleted = &now
+	}
+}
+
+// collect returns the live pastes matching the predicate, newest first.
+// Must be called with the lock held.
+func (s *MemoryStorage) collect(match func(*memoryPaste) bool) []*memoryPaste {
+	now := time.Now()
+
+	matching := make([]*memoryPaste, 0)
+	for _, stored := range s.pastes {
+		if stored.live(now) && match(stored) {
+			matching = append(matching, stored)
+		}
+	}
+	sortNewest(matching)
+
+	return matching
+}
+
+// domain returns a decrypted copy of the stored paste, with the username of its owner.
+// The content of password protected pastes is only revealed if the unlocker opens it.
+// Must be called with the lock held.
+func (s *MemoryStorage) domain(stored *memoryPaste, unlock *unlocker) (paste.Paste, error) {
+	p := stored.paste
+	p.Tags = slices.Clone(p.Tags)
+
+	if p.Syntax == "" {
+		p.Syntax = "plaintext"
+	}
+
+	if err := s.decrypt(&p, unlock); err != nil {
+		return paste.Paste{}, errors.Wrap(err, "failed to decrypt data")
+	}
+
+	if p.Owner != nil {
+		owner := user.User{ID: p.Owner.ID}
+		if account, ok := s.users[owner.ID]; ok {
+			owner.Username = account.user.Username
+		}
+		p.Owner = &owner
+	}
+
+	return p, nil
+}
+
+// document returns a decrypted copy of the stored paste along with its files and attachments.
+// Must be called with the lock held.
+func (s *MemoryStorage) document(stored *memoryPaste, secrets ...string) (paste.Paste, error) {
+	// the key of the content also opens the files, so it's only derived once
+	unlock := s.cipher.unlocker(secrets...)
+	p, err := s.domain(stored, unlock)
+	if err != nil {
+		return paste.Paste{}, err
+	}
+
+	if p.Files, err = s.cipher.openfiles(stored.files, p.ClientEncrypted, unlock); err != nil {
+		return paste.Paste{}, errors.Wrap(err, "failed to decrypt files")
+	}
+
+	if p.Attachments, err = openattachments(s.cipher, stored.attachments); err != nil {
+		return paste.Paste{}, err
+	}
+
+	return p, nil
+}
+
+// domains returns decrypted copies of the stored pastes, skipping the ones that
+// can't be decrypted. Must be called with the lock held.
+func (s *MemoryStorage) domains(stored []*memoryPaste) ([]paste.Paste, error) {
+	pastes := make([]paste.Paste, 0, len(stored))
+	for _, sp := range stored {
+		p, err := s.domain(sp, s.cipher.unlocker())
+		if err != nil {
+			continue
+		}
+		pastes = append(pastes, p)
+	}
+	return pastes, nil
+}
+
+// encrypt encrypts the title and content of the paste, the content of client encrypted
+// pastes is kept as is.
+func (s *MemoryStorage) encrypt(p *paste.Paste) error {
+	var errt, errc error
+	p.Title, errt = s.cipher.Encrypt(p.Title)
+	if !p.ClientEncrypted {
+		p.Content, errc = s.cipher.Encrypt(p.Content)
+	}
+	return errors.Join(errt, errc)
+}
+
+func (s *MemoryStorage) decrypt(p *paste.Paste, unlock *unlocker) error {
+	var errt, errc error
+	p.Title, errt = s.cipher.Decrypt(p.Title)
+	if !p.ClientEncrypted {
+		p.Content, errc = s.cipher.Decrypt(p.Content)
+		p.Content = unlock.reveal(p.Content)
+	}
+	return errors.Join(errt, errc)
+}
+
+func (s *MemoryStorage) decryptRevision(revision *paste.Revision, unlock *unlocker) error {
+	var errt, errc error
+	revision.Title, errt = s.cipher.Decrypt(revision.Title)
+	if !revision.ClientEncrypted {
+		revision.Content, errc = s.cipher.Decrypt(revision.Content)
+		revision.Content = unlock.reveal(revision.Content)
+	}
+	return errors.Join(errt, errc)
+}
+
+// next returns the next id of the storage, shared by everything it stores.
+// Must be called with the lock held.
+func (s *MemoryStorage) next() int64 {
+	s.sequence++
+	return s.sequence
+}
+
+// ref generates a reference not used by any paste yet.
+// Must be called with the lock held.
+func (s *MemoryStorage) ref(attempts int) (string, error) {
+	for range attempts {
+		ref, err := generateReferenceIdentifier(s.reflen)
+		if err != nil {
+			continue
+		}
+
+		if _, taken := s.pastes[ref]; !taken {
+			return ref, nil
+		}
+	}
+
+	return "", errors.Errorf("failed to generate unique ref in %d attempts", attempts)
+}
+
+// slug normalizes the slug requested for a paste, verifying it isn't taken yet.
+// Must be called with the lock held.
+func (s *MemoryStorage) slug(requested string) (string, error) {
+	slug, err := vanity(requested)
+	if err != nil {
+		return "", err
+	}
+
+	if _, taken := s.pastes[slug]; taken {
+		return "", slugTaken(slug, nil)
+	}
+
+	return slug, nil
+}
+
+// live reports whether the paste can still be read.
+func (p *memoryPaste) live(now time.Time) bool {
+	return p.deleted == nil && (p.paste.Expiration == nil || p.paste.Expiration.After(now))
+}
+
+// revision returns the current state of the paste, still encrypted, as a revision.
+func (p *memoryPaste) revision(number int) paste.Revision {
+	creation := p.paste.Creation
+	if p.updated != nil {
+		creation = *p.updated
+	}
+
+	syntax := p.paste.Syntax
+	if syntax == "" {
+		syntax = "plaintext"
+	}
+
+	return paste.Revision{
+		Number:   number,
+		Title:    p.paste.Title,
+		Content:  p.paste.Content,
+		Syntax:   syntax,
+		Tags:     slices.Clone(p.paste.Tags),
+		Creation: creation,
+
+		ClientEncrypted: p.paste.ClientEncrypted,
+	}
+}
+
+// before reports whether the paste is listed after the cursor.
+func before(p *memoryPaste, c cursor) bool {
+	if p.paste.Creation.Equal(c.created) {
+		return p.id < c.id
+	}
+	return p.paste.Creation.Before(c.created)
+}
+
+// sortNewest sorts the pastes by creation time and id, newest first.
+func sortNewest(pastes []*memoryPaste) {
+	slices.SortFunc(
+		pastes, func(a, b *memoryPaste) int {
+			return cmp.Or(b.paste.Creation.Compare(a.paste.Creation), cmp.Compare(b.id, a.id))
+		},
+	)
+}
+
+// words splits the text into its lowercase words, ignoring punctuation.
+func words(text string) []string {
+	return strings.FieldsFunc(
+		text, func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+		},
+	)
+}
+
+func containsAll(terms, wanted []string) bool {
+	for _, w := range wanted {
+		if !slices.Contains(terms, w) {
+			return false
+		}
+	}
+	return true
+}
+
+func containsAny(terms, unwanted []string) bool {
+	for _, u := range unwanted {
+		if slices.Contains(terms, u) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,230 @@
+package storage
+
+import (
+	"cmp"
+	"context"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/aexvir/skladka/internal/errors"
+	"github.com/aexvir/skladka/internal/user"
+)
+
+// memoryUser is a registered user along with the hash of their password.
+type memoryUser struct {
+	user     user.User
+	password string
+}
+
+// memorySession is a session of a user, stored by the digest of its token.
+type memorySession struct {
+	user       int64
+	expiration time.Time
+}
+
+// memoryToken is a personal access token, stored by the digest of its secret.
+type memoryToken struct {
+	user   int64
+	token  user.Token
+	digest string
+}
+
+// CreateUser registers a new user, storing the password hashed.
+// Usernames are unique, registering a taken username fails with a bad request error.
+func (s *MemoryStorage) CreateUser(_ context.Context, username, password string) (user.User, error) {
+	if err := user.ValidateCredentials(username, password); err != nil {
+		return user.User{}, errors.NewHTTPError(http.StatusBadRequest, err.Error(), err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, taken := s.username(username); taken {
+		return user.User{}, errors.NewHTTPError(http.StatusBadRequest, "username is already taken", nil)
+	}
+
+	account := user.User{
+		ID:       s.next(),
+		Username: username,
+		Creation: time.Now().UTC(),
+	}
+
+	s.users[account.ID] = &memoryUser{
+		user:     account,
+		password: s.cipher.Hash(password),
+	}
+
+	return account, nil
+}
+
+// Authenticate verifies the credentials of a user.
+// Returns nil if the user doesn't exist or the password doesn't match.
+func (s *MemoryStorage) Authenticate(_ context.Context, username, password string) (*user.User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stored, ok := s.username(username)
+	if !ok || !s.cipher.Verify(password, stored.password) {
+		return nil, nil
+	}
+
+	account := stored.user
+	return &account, nil
+}
+
+// CreateSession starts a new session for the user, returning the secret session
+// token and when it expires.
+func (s *MemoryStorage) CreateSession(_ context.Context, account user.User) (string, time.Time, error) {
+	token, err := generateToken()
+	if err != nil {
+		return "", time.Time{}, errors.Wrap(err, "failed to generate token")
+	}
+
+	expiration := time.Now().UTC().Add(s.sessions)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.logins[s.cipher.Digest(token)] = memorySession{user: account.ID, expiration: expiration}
+
+	return token, expiration, nil
+}
+
+// GetSessionUser returns the user the session token belongs to.
+// Expired sessions are reported as not found.
+func (s *MemoryStorage) GetSessionUser(_ context.Context, token string) (user.User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	session, ok := s.logins[s.cipher.Digest(token)]
+	if !ok || !session.expiration.After(time.Now()) {
+		return user.User{}, pgx.ErrNoRows
+	}
+
+	stored, ok := s.users[session.user]
+	if !ok {
+		return user.User{}, pgx.ErrNoRows
+	}
+
+	return stored.user, nil
+}
+
+// DeleteSession ends the session with the given token.
+func (s *MemoryStorage) DeleteSession(_ context.Context, token string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.logins, s.cipher.Digest(token))
+
+	return nil
+}
+
+// CreateAPIToken creates a personal access token for the user, limited to the given scopes.
+// Returns the token alongside its secret, which is only known at this point.
+func (s *MemoryStorage) CreateAPIToken(_ context.Context, account user.User, name string, scopes []user.Scope) (user.Token, string, error) {
+	if err := user.ValidateToken(name, scopes); err != nil {
+		return user.Token{}, "", errors.NewHTTPError(http.StatusBadRequest, err.Error(), err)
+	}
+
+	secret, err := generateToken()
+	if err != nil {
+		return user.Token{}, "", errors.Wrap(err, "failed to generate token")
+	}
+	secret = apiTokenPrefix + secret
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	token := user.Token{
+		ID:       s.next(),
+		Name:     name,
+		Scopes:   slices.Clone(scopes),
+		Creation: time.Now().UTC(),
+	}
+
+	s.apitokens[token.ID] = &memoryToken{
+		user:   account.ID,
+		token:  token,
+		digest: s.cipher.Digest(secret),
+	}
+
+	return token, secret, nil
+}
+
+// ListAPITokens returns the personal access tokens of the user, newest first.
+func (s *MemoryStorage) ListAPITokens(_ context.Context, account user.User) ([]user.Token, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tokens := make([]user.Token, 0)
+	for _, stored := range s.apitokens {
+		if stored.user == account.ID {
+			tokens = append(tokens, stored.token)
+		}
+	}
+
+	slices.SortFunc(
+		tokens, func(a, b user.Token) int {
+			return cmp.Or(b.Creation.Compare(a.Creation), cmp.Compare(b.ID, a.ID))
+		},
+	)
+
+	return tokens, nil
+}
+
+// RevokeAPIToken deletes a personal access token of the user.
+// Tokens of other users are reported as not found.
+func (s *MemoryStorage) RevokeAPIToken(_ context.Context, account user.User, id int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stored, ok := s.apitokens[id]
+	if !ok || stored.user != account.ID {
+		return errors.NewHTTPError(http.StatusNotFound, "token not found", nil)
+	}
+
+	delete(s.apitokens, id)
+
+	return nil
+}
+
+// GetTokenUser returns the user a personal access token belongs to and the scopes
+// granted to the token, recording its use.
+func (s *MemoryStorage) GetTokenUser(_ context.Context, secret string) (user.User, []user.Scope, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	digest := s.cipher.Digest(secret)
+
+	for _, stored := range s.apitokens {
+		if stored.digest != digest {
+			continue
+		}
+
+		account, ok := s.users[stored.user]
+		if !ok {
+			break
+		}
+
+		now := time.Now().UTC()
+		stored.token.LastUse = &now
+
+		return account.user, slices.Clone(stored.token.Scopes), nil
+	}
+
+	return user.User{}, nil, pgx.ErrNoRows
+}
+
+// username returns the user registered with the username.
+// Must be called with the lock held.
+func (s *MemoryStorage) username(username string) (*memoryUser, bool) {
+	for _, stored := range s.users {
+		if stored.user.Username == username {
+			return stored, true
+		}
+	}
+	return nil, false
+}
@@ -12,12 +12,36 @@ type Metrics struct {
 	// PasteSize tracks the size of created pastes in bytes
 	PasteSize metric.Int64Histogram `metric:"storage_paste_size_bytes,Size of pastes in bytes"`
 
+	// PasteReferenceCollisions counts the generated references that were already taken
+	PasteReferenceCollisions metric.Int64Counter `metric:"storage_paste_reference_collisions_total,Number of generated paste references that collided with existing ones"`
+
 	// PasteRetrieved counts the number of paste retrievals
 	PasteRetrieved metric.Int64Counter `metric:"storage_paste_retrieved_total,Number of pastes retrieved"`
 
 	// PasteNotFound counts the number of paste retrieval attempts that resulted in not found
 	PasteNotFound metric.Int64Counter `metric:"storage_paste_not_found_total,Number of paste retrieval attempts that resulted in not found"`
 
+	// PasteUpdated counts the number of pastes edited by their owners
+	PasteUpdated metric.Int64Counter `metric:"storage_paste_updated_total,Number of pastes updated"`
+
+	// PasteDeleted counts the number of pastes deleted
+	PasteDeleted metric.Int64Counter `metric:"storage_paste_deleted_total,Number of pastes deleted"`
+
+	// PasteReaped counts the number of expired pastes removed by the reaper
+	PasteReaped metric.Int64Counter `metric:"storage_paste_reaped_total,Number of expired pastes removed by the reaper"`
+
 	// PasteErrors counts the number of errors encountered during paste operations
 	PasteErrors metric.Int64Counter `metric:"storage_paste_errors_total,Number of errors encountered during paste operations"`
+
+	// UserRegistered counts the number of users registered
+	UserRegistered metric.Int64Counter `metric:"storage_user_registered_total,Number of users registered"`
+
+	// SessionCreated counts the number of sessions started by users logging in
+	SessionCreated metric.Int64Counter `metric:"storage_session_created_total,Number of sessions created"`
+
+	// TokenCreated counts the number of personal access tokens created
+	TokenCreated metric.Int64Counter `metric:"storage_token_created_total,Number of api tokens created"`
+
+	// UserErrors counts the number of errors encountered during user and session operations
+	UserErrors metric.Int64Counter `metric:"storage_user_errors_total,Number of errors encountered during user operations"`
 }
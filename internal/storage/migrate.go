@@ -0,0 +1,35 @@
+package storage
+
+import (
+	"context"
+	dbsql "database/sql"
+
+	_ "github.com/jackc/pgx/v5/stdlib"
+
+	"github.com/aexvir/skladka/internal/config"
+	"github.com/aexvir/skladka/internal/errors"
+	"github.com/aexvir/skladka/internal/storage/migrate"
+	"github.com/aexvir/skladka/internal/storage/sql"
+	"github.com/aexvir/skladka/internal/storage/sqlite"
+)
+
+// NewMigrator returns the migrator of the database selected by the config, along with
+// the database, which the caller has to close once done.
+func NewMigrator(ctx context.Context, cfg config.Config) (*migrate.Migrator, *dbsql.DB, error) {
+	switch cfg.Storage.Driver {
+	case "postgres":
+		db, err := dbsql.Open("pgx", postgresDSN(cfg))
+		if err != nil {
+			return nil, nil, errors.Wrap(err, "failed to open database")
+		}
+		return sql.Migrator(db), db, nil
+	case "sqlite":
+		db, err := opensqlite(ctx, cfg)
+		if err != nil {
+			return nil, nil, err
+		}
+		return sqlite.Migrator(db), db, nil
+	default:
+		return nil, nil, errors.Errorf("the %s storage has no schema to migrate", cfg.Storage.Driver)
+	}
+}
@@ -0,0 +1,20 @@
+// Package migrate applies the sql migrations embedded in the skladka binary,
+// backing the `skladka migrate` command.
+//
+// Migrations are files named <version>_<name>.sql, applied in version order, each one
+// in its own transaction along with the row recording it in the schema_migrations
+// table. The ones reverting them have the same name and live in the down directory.
+// Databases migrated by other means before can be adopted through WithBaseline, which
+// records the migrations already applied instead of applying them again.
+//
+// # Example Usage
+//
+//	migrator := migrate.New(db, migrations)
+//
+//	applied, err := migrator.Up(ctx)
+//	if err != nil {
+//		return err
+//	}
+//
+//	reverted, err := migrator.Down(ctx, 1)
+package migrate
@@ -0,0 +1,268 @@
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"io/fs"
+	"path"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/aexvir/skladka/internal/errors"
+)
+
+// Migration is a single migration along with when it was applied.
+type Migration struct {
+	// Version orders the migrations, it's the timestamp prefixing the file name.
+	Version string
+	// Name describes the migration, it's the rest of the file name.
+	Name string
+	// Applied is when the migration was applied, zero while it's pending.
+	Applied time.Time
+}
+
+// Pending reports whether the migration wasn't applied yet.
+func (m Migration) Pending() bool {
+	return m.Applied.IsZero()
+}
+
+// file returns the name of the migration file.
+func (m Migration) file() string {
+	return m.Version + "_" + m.Name + ".sql"
+}
+
+// MigratorOption configures a Migrator.
+type MigratorOption func(*Migrator)
+
+// WithBaseline sets the function listing the migrations applied to the database
+// by other means before the migrator first ran, e.g. by atlas. They are recorded as
+// applied instead of being applied again.
+func WithBaseline(baseline func(ctx context.Context, db *sql.DB) ([]string, error)) MigratorOption {
+	return func(m *Migrator) {
+		m.baseline = baseline
+	}
+}
+
+// Migrator applies the migrations of a directory to a database, keeping track of the
+// applied ones in the schema_migrations table.
+type Migrator struct {
+	db       *sql.DB
+	files    fs.FS
+	baseline func(ctx context.Context, db *sql.DB) ([]string, error)
+}
+
+// New returns a migrator applying the migrations in files to the database.
+// Migrations are named <version>_<name>.sql, the ones reverting them have the same
+// name and are kept in the down directory.
+func New(db *sql.DB, files fs.FS, opts ...MigratorOption) *Migrator {
+	m := &Migrator{db: db, files: files}
+	for _, opt := range opts {
+		opt(m)
+	}
+	return m
+}
+
+// Up applies the migrations that weren't applied to the database yet, in order, each
+// one in its own transaction. Returns the versions of the applied migrations.
+func (m *Migrator) Up(ctx context.Context) ([]string, error) {
+	migrations, err := m.Status(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var migrated []string
+	for _, migration := range migrations {
+		if !migration.Pending() {
+			continue
+		}
+
+		err := m.apply(
+			ctx, migration.file(),
+			"insert into schema_migrations (version, applied_at) values ($1, $2)",
+			migration.Version, time.Now().UTC().Format(time.RFC3339),
+		)
+		if err != nil {
+			return migrated, err
+		}
+		migrated = append(migrated, migration.Version)
+	}
+
+	return migrated, nil
+}
+
+// Down reverts the last steps applied migrations, newest first.
+// Returns the versions of the reverted migrations.
+func (m *Migrator) Down(ctx context.Context, steps int) ([]string, error) {
+	if steps < 1 {
+		return nil, errors.Errorf("invalid number of migrations to revert %d", steps)
+	}
+
+	migrations, err := m.Status(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var reverted []string
+	for _, migration := range slices.Backward(migrations) {
+		if len(reverted) == steps {
+			break
+		}
+
+		if migration.Pending() {
+			continue
+		}
+
+		err := m.apply(
+			ctx, path.Join("down", migration.file()),
+			"delete from schema_migrations where version = $1",
+			migration.Version,
+		)
+		if err != nil {
+			return reverted, err
+		}
+		reverted = append(reverted, migration.Version)
+	}
+
+	return reverted, nil
+}
+
+// Status returns every migration, oldest first, along with when it was applied.
+func (m *Migrator) Status(ctx context.Context) ([]Migration, error) {
+	if err := m.init(ctx); err != nil {
+		return nil, err
+	}
+
+	migrations, err := m.list()
+	if err != nil {
+		return nil, err
+	}
+
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, migration := range migrations {
+		migrations[i].Applied = applied[migration.Version]
+	}
+
+	return migrations, nil
+}
+
+// init creates the migrations table, recording the baseline when it's still empty.
+func (m *Migrator) init(ctx context.Context) error {
+	_, err := m.db.ExecContext(
+		ctx,
+		"create table if not exists schema_migrations (version text not null primary key, applied_at text not null)",
+	)
+	if err != nil {
+		return errors.Wrap(err, "failed to create migrations table")
+	}
+
+	if m.baseline == nil {
+		return nil
+	}
+
+	var count int
+	if err := m.db.QueryRowContext(ctx, "select count(*) from schema_migrations").Scan(&count); err != nil {
+		return errors.Wrap(err, "failed to count applied migrations")
+	}
+	if count > 0 {
+		return nil
+	}
+
+	baseline, err := m.baseline(ctx, m.db)
+	if err != nil {
+		return errors.Wrap(err, "failed to list migrations applied before")
+	}
+
+	migrations, err := m.list()
+	if err != nil {
+		return err
+	}
+
+	now := time.Now().UTC().Format(time.RFC3339)
+	for _, migration := range migrations {
+		if !slices.Contains(baseline, migration.Version) {
+			continue
+		}
+
+		_, err := m.db.ExecContext(
+			ctx, "insert into schema_migrations (version, applied_at) values ($1, $2)", migration.Version, now,
+		)
+		if err != nil {
+			return errors.Wrapf(err, "failed to record migration %s", migration.Version)
+		}
+	}
+
+	return nil
+}
+
+// list returns the migrations found in the directory, oldest first.
+func (m *Migrator) list() ([]Migration, error) {
+	files, err := fs.Glob(m.files, "*.sql")
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list migrations")
+	}
+	slices.Sort(files)
+
+	migrations := make([]Migration, 0, len(files))
+	for _, file := range files {
+		version, name, _ := strings.Cut(strings.TrimSuffix(file, ".sql"), "_")
+		migrations = append(migrations, Migration{Version: version, Name: name})
+	}
+
+	return migrations, nil
+}
+
+// applied returns when each of the applied migrations was applied.
+func (m *Migrator) applied(ctx context.Context) (map[string]time.Time, error) {
+	rows, err := m.db.QueryContext(ctx, "select version, applied_at from schema_migrations")
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list applied migrations")
+	}
+	defer rows.Close()
+
+	applied := make(map[string]time.Time)
+	for rows.Next() {
+		var version, at string
+		if err := rows.Scan(&version, &at); err != nil {
+			return nil, errors.Wrap(err, "failed to list applied migrations")
+		}
+
+		if applied[version], err = time.Parse(time.RFC3339, at); err != nil {
+			return nil, errors.Wrapf(err, "invalid application time of migration %s", version)
+		}
+	}
+
+	return applied, rows.Err()
+}
+
+// apply runs the migration file along with the statement recording it, in a single transaction.
+func (m *Migrator) apply(ctx context.Context, file, record string, args ...any) error {
+	statements, err := fs.ReadFile(m.files, file)
+	if err != nil {
+		return errors.Wrapf(err, "failed to read migration %s", file)
+	}
+
+	tx, err := m.db.BeginTx(ctx, nil)
+	if err != nil {
+		return errors.Wrap(err, "failed to begin transaction")
+	}
+	defer tx.Rollback()
+
+	if _, err = tx.ExecContext(ctx, string(statements)); err != nil {
+		return errors.Wrapf(err, "failed to run migration %s", file)
+	}
+
+	if _, err = tx.ExecContext(ctx, record, args...); err != nil {
+		return errors.Wrapf(err, "failed to record migration %s", file)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return errors.Wrapf(err, "failed to commit migration %s", file)
+	}
+
+	return nil
+}
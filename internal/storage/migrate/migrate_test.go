@@ -0,0 +1,63 @@
+package migrate_test
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	_ "modernc.org/sqlite"
+
+	"github.com/aexvir/skladka/internal/storage/sqlite"
+)
+
+func TestMigrator(t *testing.T) {
+	ctx := context.Background()
+
+	db, err := sql.Open("sqlite", ":memory:")
+	require.NoError(t, err)
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { db.Close() })
+
+	migrator := sqlite.Migrator(db)
+
+	migrations, err := migrator.Status(ctx)
+	require.NoError(t, err)
+	require.NotEmpty(t, migrations)
+	for _, migration := range migrations {
+		require.True(t, migration.Pending(), migration.Version)
+	}
+
+	migrated, err := migrator.Up(ctx)
+	require.NoError(t, err)
+	require.Len(t, migrated, len(migrations))
+
+	migrated, err = migrator.Up(ctx)
+	require.NoError(t, err)
+	require.Empty(t, migrated)
+
+	reverted, err := migrator.Down(ctx, 2)
+	require.NoError(t, err)
+	require.Equal(t, []string{migrations[len(migrations)-1].Version, migrations[len(migrations)-2].Version}, reverted)
+
+	status, err := migrator.Status(ctx)
+	require.NoError(t, err)
+	require.True(t, status[len(status)-1].Pending())
+	require.False(t, status[len(status)-3].Pending())
+
+	// every migration can be reverted and applied again
+	reverted, err = migrator.Down(ctx, len(migrations))
+	require.NoError(t, err)
+	require.Len(t, reverted, len(migrations)-2)
+
+	var tables int
+	require.NoError(t, db.QueryRowContext(ctx, "select count(*) from sqlite_master where name = 'pastes'").Scan(&tables))
+	require.Zero(t, tables)
+
+	migrated, err = migrator.Up(ctx)
+	require.NoError(t, err)
+	require.Len(t, migrated, len(migrations))
+
+	_, err = migrator.Down(ctx, 0)
+	require.Error(t, err)
+}
@@ -1,10 +1,16 @@
 package storage
 
 import (
+	"cmp"
 	"context"
 	"fmt"
+	"net/http"
+	"slices"
+	"time"
 
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"go.opentelemetry.io/otel/trace"
 
@@ -13,15 +19,23 @@ import (
 	"github.com/aexvir/skladka/internal/logging"
 	"github.com/aexvir/skladka/internal/metrics"
 	"github.com/aexvir/skladka/internal/paste"
+	"github.com/aexvir/skladka/internal/storage/blob"
 	"github.com/aexvir/skladka/internal/storage/sql"
 	"github.com/aexvir/skladka/internal/tracing"
+	"github.com/aexvir/skladka/internal/user"
 )
 
+// uniqueViolation is the postgres error code for unique constraint violations.
+const uniqueViolation = "23505"
+
 type PostgresStorage struct {
-	conn    *pgxpool.Pool
-	db      *sql.Queries
-	cipher  *Cipher
-	metrics *Metrics
+	conn     *pgxpool.Pool
+	db       *sql.Queries
+	cipher   *Cipher
+	blobs    blob.Store
+	metrics  *Metrics
+	sessions time.Duration
+	reflen   int
 }
 
 type PostgresStorageOption func(*PostgresStorage)
@@ -35,32 +49,37 @@ func NewPostgresStorage(ctx context.Context, cfg config.Config, opts ...Postgres
 		FromContext(ctx).
 		Info("storage.postgres", "initializing postgres storage", "url", cfg.Postgres.URL)
 
-	connstr := cfg.Postgres.URL
+	conn, err := pgxpool.New(ctx, postgresDSN(cfg))
+	if err != nil {
+		return nil, err
+	}
 
-	if cfg.Postgres.URL == "" {
-		connstr = fmt.Sprintf(
-			"postgres://%s:%s@%s:%d/%s",
-			cfg.Postgres.User, cfg.Postgres.Password,
-			cfg.Postgres.Host, cfg.Postgres.Port,
-			cfg.Postgres.Database,
-		)
+	keyring, err := NewKeyring(cfg)
+	if err != nil {
+		conn.Close()
+		return nil, errors.Wrap(err, "invalid encryption keys")
 	}
 
-	conn, err := pgxpool.New(ctx, connstr)
+	blobs, err := blob.Open(cfg.Storage.Blobs)
 	if err != nil {
-		return nil, err
+		conn.Close()
+		return nil, errors.Wrap(err, "failed to open blob store")
 	}
 
 	met := new(Metrics)
 	if err := metrics.FromContext(ctx).Register(met); err != nil {
+		conn.Close()
 		return nil, errors.Wrap(err, "registering metrics")
 	}
 
 	store := PostgresStorage{
-		conn:    conn,
-		db:      sql.New(conn),
-		metrics: met,
-		cipher:  NewCipher(cfg.EncryptionKey, cfg.EncryptionSalt),
+		conn:     conn,
+		db:       sql.New(conn),
+		metrics:  met,
+		cipher:   keyring,
+		blobs:    blobs,
+		sessions: cfg.SessionDuration,
+		reflen:   referenceLength(cfg.ReferenceLength),
 	}
 
 	for _, opt := range opts {
@@ -70,31 +89,113 @@ func NewPostgresStorage(ctx context.Context, cfg config.Config, opts ...Postgres
 	return &store, nil
 }
 
-func (s *PostgresStorage) CreatePaste(ctx context.Context, paste paste.Paste) (string, error) {
+// Close closes every connection of the pool, waiting for the ones in use to be released.
+func (s *PostgresStorage) Close() error {
+	s.conn.Close()
+	return nil
+}
+
+// postgresDSN returns the url of the postgres database, built from the postgres
+// section of the config unless the storage dsn or the full url are set.
+func postgresDSN(cfg config.Config) string {
+	if dsn := cmp.Or(cfg.Storage.DSN, cfg.Postgres.URL); dsn != "" {
+		return dsn
+	}
+
+	return fmt.Sprintf(
+		"postgres://%s:%s@%s:%d/%s",
+		cfg.Postgres.User, cfg.Postgres.Password,
+		cfg.Postgres.Host, cfg.Postgres.Port,
+		cfg.Postgres.Database,
+	)
+}
+
+// CreatePaste stores a new paste, returning its reference and the secret token
+// that authorizes its owner to edit or delete it. Only a hash of the token is
+// stored, so it can't be recovered later on.
+func (s *PostgresStorage) CreatePaste(ctx context.Context, paste paste.Paste) (string, string, error) {
 	var err error
 	ctx, finish := tracing.FromContext(ctx, trace.SpanKindInternal, "PostgresStorage.CreatePaste")
 	defer finish(&err)
 
-	ref, err := s.ref(10)
+	// vanity slugs are used as the reference instead of a random one
+	var slug string
+	if paste.Slug != "" {
+		if slug, err = vanity(paste.Slug); err != nil {
+			return "", "", err
+		}
+	}
+
+	token, err := generateToken()
 	if err != nil {
 		s.metrics.PasteErrors.Add(ctx, 1)
-		return "", err
+		return "", "", errors.Wrap(err, "failed to generate token")
+	}
+
+	// forks can only be created from pastes that are still around
+	if paste.Parent != nil {
+		if _, err = s.db.PeekPasteByReference(ctx, *paste.Parent); err != nil {
+			s.failed(ctx, err)
+			if errors.Is(err, pgx.ErrNoRows) {
+				return "", "", errors.Wrapf(errors.ErrBadRequest, "parent paste %s not found", *paste.Parent)
+			}
+			return "", "", errors.Wrap(err, "failed to fetch parent paste")
+		}
 	}
 
+	paste.Flatten()
+
+	// the content of protected pastes is only readable with the password or the owner token,
+	// client encrypted ones can't be read by the server in the first place
 	if paste.Password != nil {
+		if !paste.ClientEncrypted {
+			if paste.Content, err = s.cipher.Lock(paste.Content, *paste.Password, token); err != nil {
+				return "", "", errors.Wrap(err, "failed to lock content")
+			}
+		}
+
 		hash := s.cipher.Hash(*paste.Password)
 		paste.Password = &hash
 	}
 
+	// the search index is built from the plaintext paste
+	plain := paste
+
+	files, err := s.cipher.sealfiles(paste.Files, paste.Content, token, paste.ClientEncrypted)
+	if err != nil {
+		return "", "", errors.Wrap(err, "failed to encrypt files")
+	}
+
 	if err := s.EncryptPaste(&paste); err != nil {
-		return "", errors.Wrap(err, "failed to encrypt data")
+		return "", "", errors.Wrap(err, "failed to encrypt data")
 	}
 
 	row := new(sql.Paste).FromDomain(paste)
 
-	_, err = s.db.CreatePaste(
-		ctx, sql.CreatePasteParams{
-			Reference:  ref,
+	// the contents of attachments go to the blob store before the paste is stored,
+	// they're removed again if storing it fails
+	attachments, err := putattachments(ctx, s.blobs, s.cipher, paste.Attachments)
+	if err != nil {
+		s.metrics.PasteErrors.Add(ctx, 1)
+		return "", "", err
+	}
+	defer func() {
+		if err != nil {
+			dropattachments(ctx, s.blobs, attachments)
+		}
+	}()
+
+	tx, err := s.conn.Begin(ctx)
+	if err != nil {
+		s.metrics.PasteErrors.Add(ctx, 1)
+		return "", "", errors.Wrap(err, "failed to begin transaction")
+	}
+	defer tx.Rollback(ctx)
+
+	db := s.db.WithTx(tx)
+
+	ref, err := s.insert(
+		ctx, tx, sql.CreatePasteParams{
 			Title:      row.Title,
 			Content:    row.Content,
 			Syntax:     row.Syntax,
@@ -102,18 +203,47 @@ func (s *PostgresStorage) CreatePaste(ctx context.Context, paste paste.Paste) (s
 			Expiration: row.Expiration,
 			Public:     row.Public,
 			Password:   row.Password,
+			BurnAfter:  row.BurnAfter,
+			Token:      pgtype.Text{String: s.cipher.Hash(token), Valid: true},
+			Parent:     row.Parent,
+			OwnerID:    row.OwnerID,
+
+			ClientEncrypted: row.ClientEncrypted,
 		},
+		slug,
+		10,
 	)
-
 	if err != nil {
 		s.metrics.PasteErrors.Add(ctx, 1)
-		return "", err
+		return "", "", err
+	}
+
+	plain.Reference = ref
+
+	if err = s.storefiles(ctx, db, ref, files); err != nil {
+		s.metrics.PasteErrors.Add(ctx, 1)
+		return "", "", err
+	}
+
+	if err = s.storeattachments(ctx, db, ref, attachments); err != nil {
+		s.metrics.PasteErrors.Add(ctx, 1)
+		return "", "", err
+	}
+
+	if err = s.index(ctx, db, plain); err != nil {
+		s.metrics.PasteErrors.Add(ctx, 1)
+		return "", "", err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		s.metrics.PasteErrors.Add(ctx, 1)
+		return "", "", errors.Wrap(err, "failed to commit transaction")
 	}
 
 	s.metrics.PasteCreated.Add(ctx, 1)
 	s.metrics.PasteSize.Record(ctx, int64(len(row.Content)))
 
-	return ref, nil
+	return ref, token, nil
 }
 
 func (s *PostgresStorage) GetPaste(ctx context.Context, ref string) (paste.Paste, error) {
@@ -123,44 +253,204 @@ func (s *PostgresStorage) GetPaste(ctx context.Context, ref string) (paste.Paste
 
 	var empty paste.Paste
 
-	row, err := s.db.GetPasteByReference(ctx, ref)
+	// password protected pastes are only peeked at, the read is counted
+	// once the password has been verified via GetPasteWithPassword
+	row, err := s.db.PeekPasteByReference(ctx, ref)
+	if err == nil && !row.Password.Valid {
+		row, err = s.read(ctx, ref)
+	}
+
 	if err != nil {
-		if errors.Is(err, pgx.ErrNoRows) {
-			s.metrics.PasteNotFound.Add(ctx, 1)
-		} else {
-			s.metrics.PasteErrors.Add(ctx, 1)
-		}
+		s.failed(ctx, err)
 		return empty, err
 	}
 
 	paste := row.ToDomain()
-	if err := s.DecryptPaste(&paste); err != nil {
+	unlock := s.cipher.unlocker()
+	if err := s.decrypt(&paste, unlock); err != nil {
 		return empty, errors.Wrap(err, "failed to decrypt data")
 	}
 
+	if err := s.files(ctx, &paste, unlock); err != nil {
+		return empty, err
+	}
+
+	if err := s.attach(ctx, &paste); err != nil {
+		return empty, err
+	}
+
+	if err := s.owners(ctx, &paste); err != nil {
+		return empty, err
+	}
+
 	s.metrics.PasteRetrieved.Add(ctx, 1)
 
 	return paste, nil
 }
 
 func (s *PostgresStorage) GetPasteWithPassword(ctx context.Context, ref, password string) (*paste.Paste, error) {
-	paste, err := s.GetPaste(ctx, ref)
+	var err error
+	ctx, finish := tracing.FromContext(ctx, trace.SpanKindInternal, "PostgresStorage.GetPasteWithPassword")
+	defer finish(&err)
+
+	row, err := s.db.PeekPasteByReference(ctx, ref)
 	if err != nil {
+		s.failed(ctx, err)
 		return nil, errors.Wrap(err, "failed to get paste")
 	}
 
-	if paste.Password == nil {
+	if !row.Password.Valid {
 		return nil, errors.Errorf("paste %s doesn't have a password", ref)
 	}
 
-	if !s.cipher.Verify(password, *paste.Password) {
+	if !s.cipher.Verify(password, row.Password.String) {
 		return nil, nil
 	}
 
+	// count the read, burning the paste if it reached its limit
+	row, err = s.read(ctx, ref)
+	if err != nil {
+		s.failed(ctx, err)
+		return nil, errors.Wrap(err, "failed to get paste")
+	}
+
+	paste := row.ToDomain()
+	unlock := s.cipher.unlocker(password)
+	if err := s.decrypt(&paste, unlock); err != nil {
+		return nil, errors.Wrap(err, "failed to decrypt data")
+	}
+
+	if err := s.files(ctx, &paste, unlock); err != nil {
+		return nil, err
+	}
+
+	if err := s.attach(ctx, &paste); err != nil {
+		return nil, err
+	}
+
+	if err := s.owners(ctx, &paste); err != nil {
+		return nil, err
+	}
+
+	s.metrics.PasteRetrieved.Add(ctx, 1)
+
+	return &paste, nil
+}
+
+// PeekPaste retrieves a paste without counting the read, so looking at it never burns it,
+// e.g. to authorize access to its revisions. Returns nil if the paste is password protected
+// and the password doesn't match.
+func (s *PostgresStorage) PeekPaste(ctx context.Context, ref, password string) (*paste.Paste, error) {
+	var err error
+	ctx, finish := tracing.FromContext(ctx, trace.SpanKindInternal, "PostgresStorage.PeekPaste")
+	defer finish(&err)
+
+	row, err := s.db.PeekPasteByReference(ctx, ref)
+	if err != nil {
+		s.failed(ctx, err)
+		return nil, err
+	}
+
+	if row.Password.Valid && !s.cipher.Verify(password, row.Password.String) {
+		return nil, nil
+	}
+
+	paste := row.ToDomain()
+	unlock := s.cipher.unlocker(password)
+	if err := s.decrypt(&paste, unlock); err != nil {
+		return nil, errors.Wrap(err, "failed to decrypt data")
+	}
+
+	if err := s.files(ctx, &paste, unlock); err != nil {
+		return nil, err
+	}
+
+	if err := s.attach(ctx, &paste); err != nil {
+		return nil, err
+	}
+
+	if err := s.owners(ctx, &paste); err != nil {
+		return nil, err
+	}
+
 	return &paste, nil
 }
 
-func (s *PostgresStorage) ListPastes(ctx context.Context) ([]paste.Paste, error) {
+// ListForks returns the public pastes forked from the paste with the given reference.
+func (s *PostgresStorage) ListForks(ctx context.Context, ref string) ([]paste.Paste, error) {
+	var err error
+	ctx, finish := tracing.FromContext(ctx, trace.SpanKindInternal, "PostgresStorage.ListForks")
+	defer finish(&err)
+
+	rows, err := s.db.ListPublicForks(ctx, pgtype.Text{String: ref, Valid: true})
+	if err != nil {
+		s.metrics.PasteErrors.Add(ctx, 1)
+		return nil, errors.Wrap(err, "failed to list forks")
+	}
+
+	forks := make([]paste.Paste, 0, len(rows))
+	for _, row := range rows {
+		fork := row.ToDomain()
+
+		if err := s.DecryptPaste(&fork); err != nil {
+			continue
+		}
+
+		forks = append(forks, fork)
+	}
+
+	if err = s.owners(ctx, pointers(forks)...); err != nil {
+		return nil, err
+	}
+
+	return forks, nil
+}
+
+// GetPasteWithToken retrieves a paste on behalf of its owner.
+// The password is not required and the read is not counted, so owners
+// can review their pastes without burning them.
+func (s *PostgresStorage) GetPasteWithToken(ctx context.Context, ref, token string) (paste.Paste, error) {
+	var err error
+	ctx, finish := tracing.FromContext(ctx, trace.SpanKindInternal, "PostgresStorage.GetPasteWithToken")
+	defer finish(&err)
+
+	var empty paste.Paste
+
+	if err = s.authorize(ctx, ref, token); err != nil {
+		return empty, err
+	}
+
+	row, err := s.db.PeekPasteByReference(ctx, ref)
+	if err != nil {
+		s.failed(ctx, err)
+		return empty, err
+	}
+
+	paste := row.ToDomain()
+	unlock := s.cipher.unlocker(token)
+	if err := s.decrypt(&paste, unlock); err != nil {
+		return empty, errors.Wrap(err, "failed to decrypt data")
+	}
+
+	if err := s.files(ctx, &paste, unlock); err != nil {
+		return empty, err
+	}
+
+	if err := s.attach(ctx, &paste); err != nil {
+		return empty, err
+	}
+
+	if err := s.owners(ctx, &paste); err != nil {
+		return empty, err
+	}
+
+	return paste, nil
+}
+
+// ListPastes returns a page of at most limit public pastes matching the filter, newest first.
+// Pages are keyset paginated on the creation time and id of the pastes, the cursor of the
+// next page is returned along with the pastes and has to be passed back as after.
+func (s *PostgresStorage) ListPastes(ctx context.Context, filter paste.Filter, after string, limit int) (paste.Page, error) {
 	var err error
 	ctx, finish := tracing.FromContext(ctx, trace.SpanKindInternal, "storage.ListPastes")
 	defer finish(&err)
@@ -168,29 +458,495 @@ func (s *PostgresStorage) ListPastes(ctx context.Context) ([]paste.Paste, error)
 	logger := logging.FromContext(ctx)
 	logger.Info("storage.postgres", "listing public pastes")
 
-	dbPastes, err := s.db.ListPublicPastes(ctx)
+	var page paste.Page
+
+	params := sql.ListPublicPastesParams{
+		Tag:    pgtype.Text{String: filter.Tag, Valid: filter.Tag != ""},
+		Syntax: pgtype.Text{String: filter.Syntax, Valid: filter.Syntax != ""},
+		// fetch one extra paste to know whether there's a next page
+		PageSize: int32(limit + 1),
+	}
+
+	if filter.Since != nil {
+		params.Since = pgtype.Timestamp{Time: *filter.Since, Valid: true}
+	}
+
+	if filter.Until != nil {
+		params.Until = pgtype.Timestamp{Time: *filter.Until, Valid: true}
+	}
+
+	if after != "" {
+		c, err := decodeCursor(after)
+		if err != nil {
+			return page, errors.NewHTTPError(http.StatusBadRequest, "invalid cursor", err)
+		}
+		params.CursorCreatedAt = pgtype.Timestamp{Time: c.created, Valid: true}
+		params.CursorID = pgtype.Int8{Int64: c.id, Valid: true}
+	}
+
+	dbPastes, err := s.db.ListPublicPastes(ctx, params)
 	if err != nil {
-		return nil, errors.Wrap(err, "failed to list public pastes")
+		return page, errors.Wrap(err, "failed to list public pastes")
+	}
+
+	if len(dbPastes) > limit {
+		dbPastes = dbPastes[:limit]
+		last := dbPastes[limit-1]
+		page.Next = cursor{created: last.CreatedAt.Time, id: last.ID}.encode()
+	}
+
+	page.Pastes = make([]paste.Paste, 0, len(dbPastes))
+	for _, row := range dbPastes {
+		paste := row.ToDomain()
+
+		if err := s.DecryptPaste(&paste); err != nil {
+			continue
+		}
+
+		page.Pastes = append(page.Pastes, paste)
+	}
+
+	if err = s.owners(ctx, pointers(page.Pastes)...); err != nil {
+		return page, err
+	}
+
+	return page, nil
+}
+
+// ListOwnerPastes returns every paste of the user, newest first, including unlisted and
+// password protected ones. Pastes that expired or were burned are listed as well, so users
+// can keep track of them, while pastes deleted on purpose are left out.
+func (s *PostgresStorage) ListOwnerPastes(ctx context.Context, account user.User) ([]paste.Paste, error) {
+	var err error
+	ctx, finish := tracing.FromContext(ctx, trace.SpanKindInternal, "PostgresStorage.ListOwnerPastes")
+	defer finish(&err)
+
+	rows, err := s.db.ListOwnerPastes(ctx, pgtype.Int8{Int64: account.ID, Valid: true})
+	if err != nil {
+		s.metrics.PasteErrors.Add(ctx, 1)
+		return nil, errors.Wrap(err, "failed to list owner pastes")
 	}
 
-	pastes := make([]paste.Paste, len(dbPastes))
-	for i, row := range dbPastes {
+	pastes := make([]paste.Paste, 0, len(rows))
+	for _, row := range rows {
 		paste := row.ToDomain()
 
 		if err := s.DecryptPaste(&paste); err != nil {
 			continue
 		}
 
-		pastes[i] = paste
+		paste.Owner = &account
+		pastes = append(pastes, paste)
 	}
 
 	return pastes, nil
 }
 
+// DeleteOwnerPastes soft deletes the pastes of the user with the given references at once.
+// References of pastes owned by someone else are ignored. Returns the number of deleted pastes.
+func (s *PostgresStorage) DeleteOwnerPastes(ctx context.Context, account user.User, refs []string) (int64, error) {
+	var err error
+	ctx, finish := tracing.FromContext(ctx, trace.SpanKindInternal, "PostgresStorage.DeleteOwnerPastes")
+	defer finish(&err)
+
+	tx, err := s.conn.Begin(ctx)
+	if err != nil {
+		s.metrics.PasteErrors.Add(ctx, 1)
+		return 0, errors.Wrap(err, "failed to begin transaction")
+	}
+	defer tx.Rollback(ctx)
+
+	db := s.db.WithTx(tx)
+
+	deleted, err := db.DeleteOwnerPastes(
+		ctx, sql.DeleteOwnerPastesParams{
+			OwnerID: pgtype.Int8{Int64: account.ID, Valid: true},
+			Refs:    refs,
+		},
+	)
+	if err != nil {
+		s.metrics.PasteErrors.Add(ctx, 1)
+		return 0, errors.Wrap(err, "failed to delete owner pastes")
+	}
+
+	// deleted pastes don't stay searchable, nor readable in the plaintext index
+	if err = db.DeleteSearchDocuments(ctx); err != nil {
+		s.metrics.PasteErrors.Add(ctx, 1)
+		return 0, errors.Wrap(err, "failed to drop deleted pastes from the search index")
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		s.metrics.PasteErrors.Add(ctx, 1)
+		return 0, errors.Wrap(err, "failed to commit transaction")
+	}
+
+	s.metrics.PasteDeleted.Add(ctx, deleted)
+
+	return deleted, nil
+}
+
+// UpdatePaste replaces the title, content, syntax, tags and files of a paste.
+// The token returned on creation is required to authorize the change.
+// The previous state of the paste is kept as a revision, updates that
+// don't change anything are ignored.
+func (s *PostgresStorage) UpdatePaste(ctx context.Context, ref, token string, paste paste.Paste) error {
+	var err error
+	ctx, finish := tracing.FromContext(ctx, trace.SpanKindInternal, "PostgresStorage.UpdatePaste")
+	defer finish(&err)
+
+	current, err := s.GetPasteWithToken(ctx, ref, token)
+	if err != nil {
+		return err
+	}
+
+	return s.update(ctx, ref, token, current, paste)
+}
+
+// UpdateOwnerPaste replaces the title, content, syntax, tags and files of a paste on behalf
+// of the user owning it, without its owner token. Password protected pastes are locked with
+// their owner token, so they can only be edited with it.
+func (s *PostgresStorage) UpdateOwnerPaste(ctx context.Context, account user.User, ref string, paste paste.Paste) error {
+	var err error
+	ctx, finish := tracing.FromContext(ctx, trace.SpanKindInternal, "PostgresStorage.UpdateOwnerPaste")
+	defer finish(&err)
+
+	row, err := s.db.PeekPasteByReference(ctx, ref)
+	if err != nil {
+		s.failed(ctx, err)
+		return err
+	}
+
+	current := row.ToDomain()
+	if err = owns(current, account); err != nil {
+		return err
+	}
+
+	unlock := s.cipher.unlocker()
+	if err = s.decrypt(&current, unlock); err != nil {
+		return errors.Wrap(err, "failed to decrypt data")
+	}
+
+	if err = s.files(ctx, &current, unlock); err != nil {
+		return err
+	}
+
+	return s.update(ctx, ref, "", current, paste)
+}
+
+// update stores the edit of the current paste, authorized already, keeping the current
+// state as a revision. The token, if any, relocks the content of protected pastes.
+func (s *PostgresStorage) update(ctx context.Context, ref, token string, current, paste paste.Paste) error {
+	var err error
+
+	amend(&paste, current)
+
+	// pastes stay client encrypted, the new content has to be encrypted by the client as well
+	paste.ClientEncrypted = current.ClientEncrypted
+	if paste.ClientEncrypted {
+		if err = paste.Validate(); err != nil {
+			return errors.NewHTTPError(http.StatusBadRequest, err.Error(), err)
+		}
+	}
+
+	if current.Title == paste.Title &&
+		current.Content == paste.Content &&
+		current.Syntax == paste.Syntax &&
+		slices.Equal(current.Tags, paste.Tags) &&
+		slices.Equal(current.Files, paste.Files) {
+		return nil
+	}
+
+	// the search index is built from the plaintext paste, keeping the visibility of the current one
+	plain := paste
+	plain.Reference, plain.Public, plain.Password = ref, current.Public, current.Password
+
+	// protected pastes stay locked with their password and owner token
+	if current.Password != nil && !current.ClientEncrypted {
+		stored, err := s.db.PeekPasteByReference(ctx, ref)
+		if err != nil {
+			s.failed(ctx, err)
+			return err
+		}
+
+		if paste.Content, err = s.cipher.relock(stored.Content, token, paste.Content); err != nil {
+			return errors.Wrap(err, "failed to lock content")
+		}
+	}
+
+	files, err := s.cipher.sealfiles(paste.Files, paste.Content, token, paste.ClientEncrypted)
+	if err != nil {
+		return errors.Wrap(err, "failed to encrypt files")
+	}
+
+	if err = s.EncryptPaste(&paste); err != nil {
+		return errors.Wrap(err, "failed to encrypt data")
+	}
+
+	row := new(sql.Paste).FromDomain(paste)
+
+	tx, err := s.conn.Begin(ctx)
+	if err != nil {
+		s.metrics.PasteErrors.Add(ctx, 1)
+		return errors.Wrap(err, "failed to begin transaction")
+	}
+	defer tx.Rollback(ctx)
+
+	db := s.db.WithTx(tx)
+
+	if err = db.CreatePasteRevision(ctx, ref); err != nil {
+		s.metrics.PasteErrors.Add(ctx, 1)
+		return errors.Wrap(err, "failed to store revision")
+	}
+
+	updated, err := db.UpdatePaste(
+		ctx, sql.UpdatePasteParams{
+			Reference: ref,
+			Title:     row.Title,
+			Content:   row.Content,
+			Syntax:    row.Syntax,
+			Tags:      row.Tags,
+		},
+	)
+	if err != nil {
+		s.metrics.PasteErrors.Add(ctx, 1)
+		return errors.Wrap(err, "failed to update paste")
+	}
+
+	if updated == 0 {
+		s.failed(ctx, pgx.ErrNoRows)
+		return pgx.ErrNoRows
+	}
+
+	if err = s.storefiles(ctx, db, ref, files); err != nil {
+		s.metrics.PasteErrors.Add(ctx, 1)
+		return err
+	}
+
+	if err = s.index(ctx, db, plain); err != nil {
+		s.metrics.PasteErrors.Add(ctx, 1)
+		return err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		s.metrics.PasteErrors.Add(ctx, 1)
+		return errors.Wrap(err, "failed to commit transaction")
+	}
+
+	s.metrics.PasteUpdated.Add(ctx, 1)
+	s.metrics.PasteSize.Record(ctx, int64(len(row.Content)))
+
+	return nil
+}
+
+// ListRevisions returns every revision of a paste, oldest first.
+// The last revision is always the current state of the paste.
+// Access to the paste has to be checked by the caller, reads are not counted. The content
+// of password protected pastes is only revealed when the secret is their password or owner token.
+func (s *PostgresStorage) ListRevisions(ctx context.Context, ref, secret string) ([]paste.Revision, error) {
+	var err error
+	ctx, finish := tracing.FromContext(ctx, trace.SpanKindInternal, "PostgresStorage.ListRevisions")
+	defer finish(&err)
+
+	row, err := s.db.PeekPasteByReference(ctx, ref)
+	if err != nil {
+		s.failed(ctx, err)
+		return nil, err
+	}
+
+	rows, err := s.db.ListPasteRevisions(ctx, row.ID)
+	if err != nil {
+		s.metrics.PasteErrors.Add(ctx, 1)
+		return nil, errors.Wrap(err, "failed to list revisions")
+	}
+
+	revisions := make([]paste.Revision, 0, len(rows)+1)
+	for _, rev := range rows {
+		revisions = append(revisions, rev.ToDomain())
+	}
+	revisions = append(revisions, row.ToRevision(len(rows)+1))
+
+	// the revisions are locked with the same key, which is only derived once
+	unlock := s.cipher.unlocker(secret)
+	for i := range revisions {
+		revisions[i].ClientEncrypted = row.ClientEncrypted
+		if err = s.decryptrevision(&revisions[i], unlock); err != nil {
+			return nil, errors.Wrap(err, "failed to decrypt data")
+		}
+	}
+
+	return revisions, nil
+}
+
+// GetRevision returns a single revision of a paste by its number.
+// Access to the paste has to be checked by the caller, reads are not counted. The content
+// of password protected pastes is only revealed when the secret is their password or owner token.
+func (s *PostgresStorage) GetRevision(ctx context.Context, ref string, number int, secret string) (paste.Revision, error) {
+	var err error
+	ctx, finish := tracing.FromContext(ctx, trace.SpanKindInternal, "PostgresStorage.GetRevision")
+	defer finish(&err)
+
+	var empty paste.Revision
+
+	row, err := s.db.PeekPasteByReference(ctx, ref)
+	if err != nil {
+		s.failed(ctx, err)
+		return empty, err
+	}
+
+	count, err := s.db.CountPasteRevisions(ctx, row.ID)
+	if err != nil {
+		s.metrics.PasteErrors.Add(ctx, 1)
+		return empty, errors.Wrap(err, "failed to count revisions")
+	}
+
+	var revision paste.Revision
+
+	switch {
+	case number == int(count)+1:
+		revision = row.ToRevision(number)
+	case number >= 1 && number <= int(count):
+		rev, err := s.db.GetPasteRevision(
+			ctx, sql.GetPasteRevisionParams{
+				PasteID:  row.ID,
+				Revision: int32(number),
+			},
+		)
+		if err != nil {
+			s.failed(ctx, err)
+			return empty, err
+		}
+		revision = rev.ToDomain()
+		revision.ClientEncrypted = row.ClientEncrypted
+	default:
+		s.failed(ctx, pgx.ErrNoRows)
+		return empty, pgx.ErrNoRows
+	}
+
+	if err = s.DecryptRevision(&revision, secret); err != nil {
+		return empty, errors.Wrap(err, "failed to decrypt data")
+	}
+
+	return revision, nil
+}
+
+// DeletePaste soft deletes a paste.
+// The token returned on creation is required to authorize the deletion.
+func (s *PostgresStorage) DeletePaste(ctx context.Context, ref, token string) error {
+	var err error
+	ctx, finish := tracing.FromContext(ctx, trace.SpanKindInternal, "PostgresStorage.DeletePaste")
+	defer finish(&err)
+
+	if err = s.authorize(ctx, ref, token); err != nil {
+		return err
+	}
+
+	tx, err := s.conn.Begin(ctx)
+	if err != nil {
+		s.metrics.PasteErrors.Add(ctx, 1)
+		return errors.Wrap(err, "failed to begin transaction")
+	}
+	defer tx.Rollback(ctx)
+
+	db := s.db.WithTx(tx)
+
+	deleted, err := db.DeletePaste(ctx, ref)
+	if err != nil {
+		s.metrics.PasteErrors.Add(ctx, 1)
+		return errors.Wrap(err, "failed to delete paste")
+	}
+
+	if deleted == 0 {
+		s.failed(ctx, pgx.ErrNoRows)
+		return pgx.ErrNoRows
+	}
+
+	if err = db.DeleteSearchDocument(ctx, ref); err != nil {
+		s.metrics.PasteErrors.Add(ctx, 1)
+		return errors.Wrapf(err, "failed to drop paste %s from the search index", ref)
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		s.metrics.PasteErrors.Add(ctx, 1)
+		return errors.Wrap(err, "failed to commit transaction")
+	}
+
+	s.metrics.PasteDeleted.Add(ctx, 1)
+
+	return nil
+}
+
+func (s *PostgresStorage) ReapExpiredPastes(ctx context.Context) (int64, error) {
+	var err error
+	ctx, finish := tracing.FromContext(ctx, trace.SpanKindInternal, "PostgresStorage.ReapExpiredPastes")
+	defer finish(&err)
+
+	tx, err := s.conn.Begin(ctx)
+	if err != nil {
+		s.metrics.PasteErrors.Add(ctx, 1)
+		return 0, errors.Wrap(err, "failed to begin transaction")
+	}
+	defer tx.Rollback(ctx)
+
+	db := s.db.WithTx(tx)
+
+	reaped, err := db.ReapExpiredPastes(ctx)
+	if err != nil {
+		s.metrics.PasteErrors.Add(ctx, 1)
+		return 0, errors.Wrap(err, "failed to reap expired pastes")
+	}
+
+	if err = db.DeleteSearchDocuments(ctx); err != nil {
+		s.metrics.PasteErrors.Add(ctx, 1)
+		return 0, errors.Wrap(err, "failed to drop expired pastes from the search index")
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		s.metrics.PasteErrors.Add(ctx, 1)
+		return 0, errors.Wrap(err, "failed to commit transaction")
+	}
+
+	s.metrics.PasteReaped.Add(ctx, reaped)
+
+	return reaped, nil
+}
+
+// read counts a read of the paste, burning it if it reached its read limit.
+// Burned pastes are dropped from the search index along with the read.
+func (s *PostgresStorage) read(ctx context.Context, ref string) (sql.Paste, error) {
+	tx, err := s.conn.Begin(ctx)
+	if err != nil {
+		return sql.Paste{}, errors.Wrap(err, "failed to begin transaction")
+	}
+	defer tx.Rollback(ctx)
+
+	db := s.db.WithTx(tx)
+
+	row, err := db.GetPasteByReference(ctx, ref)
+	if err != nil {
+		return row, err
+	}
+
+	if row.DeletedAt.Valid {
+		if err = db.DeleteSearchDocument(ctx, ref); err != nil {
+			return row, errors.Wrapf(err, "failed to drop paste %s from the search index", ref)
+		}
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return row, errors.Wrap(err, "failed to commit transaction")
+	}
+
+	return row, nil
+}
+
+// EncryptPaste encrypts the title and content of the paste.
+// The content of client encrypted pastes is kept as is, it's encrypted already.
 func (s *PostgresStorage) EncryptPaste(paste *paste.Paste) error {
 	var errt, errc error
 	paste.Title, errt = s.cipher.Encrypt(paste.Title)
-	paste.Content, errc = s.cipher.Encrypt(paste.Content)
+	if !paste.ClientEncrypted {
+		paste.Content, errc = s.cipher.Encrypt(paste.Content)
+	}
 
 	if errt != nil || errc != nil {
 		return errors.Join(errt, errc)
@@ -199,10 +955,22 @@ func (s *PostgresStorage) EncryptPaste(paste *paste.Paste) error {
 	return nil
 }
 
-func (s *PostgresStorage) DecryptPaste(paste *paste.Paste) error {
+// DecryptPaste decrypts the title and content of the paste. The content of password
+// protected pastes is only revealed if one of the secrets unlocks it, the content of client
+// encrypted pastes is left for the client to decrypt.
+func (s *PostgresStorage) DecryptPaste(paste *paste.Paste, secrets ...string) error {
+	return s.decrypt(paste, s.cipher.unlocker(secrets...))
+}
+
+// decrypt decrypts the title and content of the paste, revealing protected content with
+// the unlocker, which is shared with the files and revisions of the paste.
+func (s *PostgresStorage) decrypt(paste *paste.Paste, unlock *unlocker) error {
 	var errt, errc error
 	paste.Title, errt = s.cipher.Decrypt(paste.Title)
-	paste.Content, errc = s.cipher.Decrypt(paste.Content)
+	if !paste.ClientEncrypted {
+		paste.Content, errc = s.cipher.Decrypt(paste.Content)
+		paste.Content = unlock.reveal(paste.Content)
+	}
 
 	if errt != nil || errc != nil {
 		return errors.Join(errt, errc)
@@ -211,19 +979,113 @@ func (s *PostgresStorage) DecryptPaste(paste *paste.Paste) error {
 	return nil
 }
 
-func (s *PostgresStorage) ref(attempts int) (string, error) {
-	attempt := 0
+// authorize verifies the token against the hash stored for the paste.
+// Pastes created before tokens were introduced can't be modified by anyone.
+func (s *PostgresStorage) authorize(ctx context.Context, ref, token string) error {
+	hash, err := s.db.GetPasteToken(ctx, ref)
+	if err != nil {
+		s.failed(ctx, err)
+		return err
+	}
+
+	if !hash.Valid || token == "" || !s.cipher.Verify(token, hash.String) {
+		return errors.Wrapf(errors.ErrForbidden, "invalid token for paste %s", ref)
+	}
+
+	return nil
+}
+
+func (s *PostgresStorage) DecryptRevision(revision *paste.Revision, secrets ...string) error {
+	return s.decryptrevision(revision, s.cipher.unlocker(secrets...))
+}
+
+// decryptrevision decrypts the title and content of the revision, revealing protected
+// content with the unlocker, which is shared with the other revisions of the paste.
+func (s *PostgresStorage) decryptrevision(revision *paste.Revision, unlock *unlocker) error {
+	var errt, errc error
+	revision.Title, errt = s.cipher.Decrypt(revision.Title)
+	if !revision.ClientEncrypted {
+		revision.Content, errc = s.cipher.Decrypt(revision.Content)
+		revision.Content = unlock.reveal(revision.Content)
+	}
 
-	for {
-		if attempt >= attempts {
-			return "", errors.Errorf("failed to generate unique ref in %d attempts", attempt)
+	if errt != nil || errc != nil {
+		return errors.Join(errt, errc)
+	}
+
+	return nil
+}
+
+// failed records the error metric matching the error returned by a query.
+func (s *PostgresStorage) failed(ctx context.Context, err error) {
+	if errors.Is(err, pgx.ErrNoRows) {
+		s.metrics.PasteNotFound.Add(ctx, 1)
+		return
+	}
+	s.metrics.PasteErrors.Add(ctx, 1)
+}
+
+// duplicate reports whether the error is a unique constraint violation.
+func duplicate(err error) bool {
+	var pgerr *pgconn.PgError
+	return errors.As(err, &pgerr) && pgerr.Code == uniqueViolation
+}
+
+// pointers returns pointers to every paste of the slice, so they can be modified in place.
+func pointers(pastes []paste.Paste) []*paste.Paste {
+	ptrs := make([]*paste.Paste, len(pastes))
+	for i := range pastes {
+		ptrs[i] = &pastes[i]
+	}
+	return ptrs
+}
+
+// insert stores the paste under its slug, or under a newly generated reference when it
+// doesn't have one. References are random, so whenever one is already taken a new one is
+// generated, up to attempts times. Every attempt runs in its own savepoint, as a failed
+// insert aborts the transaction.
+func (s *PostgresStorage) insert(ctx context.Context, tx pgx.Tx, params sql.CreatePasteParams, slug string, attempts int) (string, error) {
+	if slug != "" {
+		params.Reference = slug
+		if _, err := s.db.WithTx(tx).CreatePaste(ctx, params); err != nil {
+			if duplicate(err) {
+				return "", slugTaken(slug, err)
+			}
+			return "", err
 		}
+		return slug, nil
+	}
 
-		attempt++
+	for range attempts {
+		ref, err := generateReferenceIdentifier(s.reflen)
+		if err != nil {
+			continue
+		}
+		params.Reference = ref
 
-		ref, err := generateReferenceIdentifier()
-		if err == nil {
-			return ref, nil
+		savepoint, err := tx.Begin(ctx)
+		if err != nil {
+			return "", errors.Wrap(err, "failed to begin savepoint")
 		}
+
+		_, err = s.db.WithTx(savepoint).CreatePaste(ctx, params)
+		if err != nil {
+			savepoint.Rollback(ctx)
+
+			if duplicate(err) {
+				s.metrics.PasteReferenceCollisions.Add(ctx, 1)
+				continue
+			}
+
+			return "", err
+		}
+
+		if err = savepoint.Commit(ctx); err != nil {
+			return "", errors.Wrap(err, "failed to release savepoint")
+		}
+
+		return ref, nil
 	}
+
+	return "", errors.Errorf("failed to generate unique ref in %d attempts", attempts)
 }
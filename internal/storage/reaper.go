@@ -0,0 +1,59 @@
+package storage
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/aexvir/skladka/internal/logging"
+)
+
+// Reaper is implemented by storage backends capable of removing expired pastes.
+type Reaper interface {
+	// ReapExpiredPastes removes all expired pastes and returns how many were removed.
+	ReapExpiredPastes(context.Context) (int64, error)
+}
+
+// StartReaper runs the reaper in a background goroutine every interval until the
+// context is cancelled or the returned stop function is called.
+// The stop function blocks until the goroutine has exited, making it safe to close
+// the underlying storage afterwards.
+func StartReaper(ctx context.Context, reaper Reaper, interval time.Duration) (stop func()) {
+	ctx, cancel := context.WithCancel(ctx)
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+
+	go func() {
+		defer wg.Done()
+
+		logger := logging.FromContext(ctx)
+		logger.Info("storage.reaper", "starting expired paste reaper", "interval", interval.String())
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				logger.Info("storage.reaper", "stopping expired paste reaper")
+				return
+			case <-ticker.C:
+				reaped, err := reaper.ReapExpiredPastes(ctx)
+				if err != nil {
+					logger.Error(err, "storage.reaper", "failed to reap expired pastes")
+					continue
+				}
+
+				if reaped > 0 {
+					logger.Info("storage.reaper", "reaped expired pastes", "count", reaped)
+				}
+			}
+		}
+	}()
+
+	return func() {
+		cancel()
+		wg.Wait()
+	}
+}
@@ -6,18 +6,19 @@ import (
 )
 
 const (
-	refIdentifierLength = 8
-	characters          = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+	// defaultReferenceLength is used when the config doesn't set the length of references.
+	defaultReferenceLength = 8
+	characters             = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
 )
 
-func generateReferenceIdentifier() (string, error) {
-	buf := make([]byte, refIdentifierLength)
+func generateReferenceIdentifier(length int) (string, error) {
+	buf := make([]byte, length)
 	if _, err := rand.Read(buf); err != nil {
 		return "", err
 	}
 
 	var ref strings.Builder
-	ref.Grow(refIdentifierLength)
+	ref.Grow(length)
 
 	for i := range buf {
 		ref.WriteByte(characters[int(buf[i])%len(characters)])
@@ -25,3 +26,11 @@ func generateReferenceIdentifier() (string, error) {
 
 	return ref.String(), nil
 }
+
+// referenceLength returns the configured length of references, or the default one.
+func referenceLength(configured int) int {
+	if configured <= 0 {
+		return defaultReferenceLength
+	}
+	return configured
+}
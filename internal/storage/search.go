@@ -0,0 +1,115 @@
+package storage
+
+import (
+	"context"
+	"strings"
+
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/aexvir/skladka/internal/errors"
+	"github.com/aexvir/skladka/internal/logging"
+	"github.com/aexvir/skladka/internal/paste"
+	"github.com/aexvir/skladka/internal/storage/sql"
+	"github.com/aexvir/skladka/internal/tracing"
+)
+
+// SearchPastes runs a full-text search over the public pastes, returning at most limit
+// of them, best matches first. The query supports the web search syntax, e.g. quoted
+// phrases, or and negations with a leading dash.
+//
+// Pastes are encrypted at rest, so the search runs on a separate index that is only
+// kept for public pastes, dropping them as soon as they're deleted, burned or reaped.
+// Password protected pastes only have their title and tags indexed.
+func (s *PostgresStorage) SearchPastes(ctx context.Context, query string, limit int) ([]paste.Paste, error) {
+	var err error
+	ctx, finish := tracing.FromContext(ctx, trace.SpanKindInternal, "PostgresStorage.SearchPastes")
+	defer finish(&err)
+
+	rows, err := s.db.SearchPublicPastes(
+		ctx, sql.SearchPublicPastesParams{
+			Query:    query,
+			PageSize: int32(limit),
+		},
+	)
+	if err != nil {
+		s.metrics.PasteErrors.Add(ctx, 1)
+		return nil, errors.Wrap(err, "failed to search pastes")
+	}
+
+	pastes := make([]paste.Paste, 0, len(rows))
+	for _, row := range rows {
+		paste := row.ToDomain()
+
+		if err := s.DecryptPaste(&paste); err != nil {
+			continue
+		}
+
+		pastes = append(pastes, paste)
+	}
+
+	if err = s.owners(ctx, pointers(pastes)...); err != nil {
+		return nil, err
+	}
+
+	return pastes, nil
+}
+
+// ReindexPastes adds the public pastes missing from the search index, e.g. the ones
+// created before search was available, and drops the documents of deleted pastes left
+// behind by older versions. Pastes already indexed are left untouched, so it's cheap
+// to run on every start. Returns the number of indexed pastes.
+func (s *PostgresStorage) ReindexPastes(ctx context.Context) (int, error) {
+	var err error
+	ctx, finish := tracing.FromContext(ctx, trace.SpanKindInternal, "PostgresStorage.ReindexPastes")
+	defer finish(&err)
+
+	if err = s.db.DeleteSearchDocuments(ctx); err != nil {
+		return 0, errors.Wrap(err, "failed to drop deleted pastes from the search index")
+	}
+
+	rows, err := s.db.ListUnindexedPastes(ctx)
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to list unindexed pastes")
+	}
+
+	indexed := 0
+	for _, row := range rows {
+		paste := row.ToDomain()
+
+		if err := s.DecryptPaste(&paste); err != nil {
+			logging.
+				FromContext(ctx).
+				Error(err, "storage.search", "failed to decrypt paste", "ref", paste.Reference)
+			continue
+		}
+
+		if err = s.index(ctx, s.db, paste); err != nil {
+			return indexed, err
+		}
+		indexed++
+	}
+
+	return indexed, nil
+}
+
+// index stores the plaintext paste in the search index.
+// Private pastes are skipped, only their encrypted form is ever stored.
+func (s *PostgresStorage) index(ctx context.Context, db *sql.Queries, p paste.Paste) error {
+	if !p.Public {
+		return nil
+	}
+
+	err := db.IndexPaste(
+		ctx, sql.IndexPasteParams{
+			Title:     p.Title,
+			Tags:      strings.Join(p.Tags, " "),
+			Content:   searchable(p),
+			Reference: p.Reference,
+		},
+	)
+	if err != nil {
+		return errors.Wrapf(err, "failed to index paste %s", p.Reference)
+	}
+
+	return nil
+}
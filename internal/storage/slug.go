@@ -1,12 +1,58 @@
 package storage
 
 import (
+	"fmt"
+	"net/http"
 	"regexp"
+	"slices"
 	"strings"
+
+	"github.com/aexvir/skladka/internal/config"
+	"github.com/aexvir/skladka/internal/errors"
 )
 
+// minSlugLength keeps slugs from taking up the short, memorable references.
+const minSlugLength = 3
+
+// reservedSlugs are the top level routes of the server, pastes can't be served from them.
+var reservedSlugs = []string{
+	"api", "archive", "health", "login", "logout", "me", "metrics",
+	"register", "search", "settings", "static", "tags",
+}
+
+var nonslug = regexp.MustCompile(`[^a-z0-9]+`)
+
 func slugify(value string) string {
-	return strings.ToLower(
-		regexp.MustCompile(`[^a-z0-9]+`).ReplaceAllString(value, "-"),
+	return strings.Trim(
+		nonslug.ReplaceAllString(strings.ToLower(value), "-"),
+		"-",
 	)
 }
+
+// vanity normalizes the slug requested for a paste, verifying it can be used as its reference.
+// Slugs share the namespace of the random references, so they are unique alongside them.
+func vanity(slug string) (string, error) {
+	normalized := slugify(slug)
+
+	switch {
+	case len(normalized) < minSlugLength || len(normalized) > config.MaxReferenceLength:
+		return "", errors.NewHTTPError(
+			http.StatusBadRequest,
+			fmt.Sprintf("slugs must be between %d and %d characters long", minSlugLength, config.MaxReferenceLength),
+			errors.Errorf("invalid slug %q", slug),
+		)
+	case slices.Contains(reservedSlugs, normalized):
+		return "", errors.NewHTTPError(
+			http.StatusBadRequest,
+			"slug "+normalized+" is reserved",
+			errors.Errorf("reserved slug %q", slug),
+		)
+	}
+
+	return normalized, nil
+}
+
+// slugTaken is the error returned when the slug requested for a paste is already in use.
+func slugTaken(slug string, err error) error {
+	return errors.NewHTTPError(http.StatusBadRequest, "slug "+slug+" is already taken", err)
+}
@@ -0,0 +1,55 @@
+package sql
+
+import (
+	"context"
+	dbsql "database/sql"
+	"embed"
+	"io/fs"
+
+	"github.com/aexvir/skladka/internal/errors"
+	"github.com/aexvir/skladka/internal/storage/migrate"
+)
+
+// The migrations are generated by atlas from schema.sql, the ones reverting them
+// are written by hand and live in migrations/down, where atlas doesn't look.
+//
+//go:embed migrations/*.sql migrations/down/*.sql
+var migrations embed.FS
+
+// Migrator returns the migrator of the postgres schema. Databases migrated by atlas
+// before the skladka binary could migrate them carry on from the last migration
+// atlas applied.
+func Migrator(db *dbsql.DB) *migrate.Migrator {
+	files, _ := fs.Sub(migrations, "migrations")
+	return migrate.New(db, files, migrate.WithBaseline(atlas))
+}
+
+// atlas returns the versions of the migrations fully applied by atlas, if any.
+func atlas(ctx context.Context, db *dbsql.DB) ([]string, error) {
+	var exists bool
+	err := db.QueryRowContext(
+		ctx, "select to_regclass('atlas_schema_revisions.atlas_schema_revisions') is not null",
+	).Scan(&exists)
+	if err != nil || !exists {
+		return nil, err
+	}
+
+	rows, err := db.QueryContext(
+		ctx, "select version from atlas_schema_revisions.atlas_schema_revisions where applied = total",
+	)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list atlas revisions")
+	}
+	defer rows.Close()
+
+	var versions []string
+	for rows.Next() {
+		var version string
+		if err := rows.Scan(&version); err != nil {
+			return nil, errors.Wrap(err, "failed to list atlas revisions")
+		}
+		versions = append(versions, version)
+	}
+
+	return versions, rows.Err()
+}
@@ -0,0 +1,2 @@
+-- Modify "pastes" table
+ALTER TABLE "public"."pastes" ADD COLUMN "burn_after" integer NULL;
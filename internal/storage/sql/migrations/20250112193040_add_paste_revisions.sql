@@ -0,0 +1,4 @@
+-- Create "paste_revisions" table
+CREATE TABLE "public"."paste_revisions" ("id" bigserial NOT NULL, "paste_id" bigint NOT NULL, "revision" integer NOT NULL, "title" character varying(255) NOT NULL, "content" text NOT NULL, "syntax" character varying(50) NULL, "tags" text[] NULL, "created_at" timestamp NOT NULL DEFAULT now(), PRIMARY KEY ("id"), CONSTRAINT "paste_revisions_paste_id_fkey" FOREIGN KEY ("paste_id") REFERENCES "public"."pastes" ("id") ON UPDATE NO ACTION ON DELETE CASCADE);
+-- Create index "paste_revisions_paste_id_revision_idx" to table: "paste_revisions"
+CREATE UNIQUE INDEX "paste_revisions_paste_id_revision_idx" ON "public"."paste_revisions" ("paste_id", "revision");
@@ -0,0 +1,4 @@
+-- Modify "pastes" table
+ALTER TABLE "public"."pastes" ADD COLUMN "parent" character varying(8) NULL;
+-- Create index "pastes_parent_idx" to table: "pastes"
+CREATE INDEX "pastes_parent_idx" ON "public"."pastes" ("parent") WHERE (deleted_at IS NULL);
@@ -0,0 +1,12 @@
+-- Create "users" table
+CREATE TABLE "public"."users" ("id" bigserial NOT NULL, "username" character varying(32) NOT NULL, "password" text NOT NULL, "created_at" timestamp NOT NULL DEFAULT now(), "deleted_at" timestamp NULL, PRIMARY KEY ("id"));
+-- Create index "users_username_idx" to table: "users"
+CREATE UNIQUE INDEX "users_username_idx" ON "public"."users" ("username");
+-- Modify "pastes" table
+ALTER TABLE "public"."pastes" ADD COLUMN "owner_id" bigint NULL, ADD CONSTRAINT "pastes_owner_id_fkey" FOREIGN KEY ("owner_id") REFERENCES "public"."users" ("id") ON UPDATE NO ACTION ON DELETE SET NULL;
+-- Create index "pastes_owner_id_idx" to table: "pastes"
+CREATE INDEX "pastes_owner_id_idx" ON "public"."pastes" ("owner_id") WHERE (deleted_at IS NULL);
+-- Create "sessions" table
+CREATE TABLE "public"."sessions" ("id" bigserial NOT NULL, "user_id" bigint NOT NULL, "token" text NOT NULL, "created_at" timestamp NOT NULL DEFAULT now(), "expires_at" timestamp NOT NULL, PRIMARY KEY ("id"), CONSTRAINT "sessions_user_id_fkey" FOREIGN KEY ("user_id") REFERENCES "public"."users" ("id") ON UPDATE NO ACTION ON DELETE CASCADE);
+-- Create index "sessions_token_idx" to table: "sessions"
+CREATE UNIQUE INDEX "sessions_token_idx" ON "public"."sessions" ("token");
@@ -0,0 +1,6 @@
+-- Create "api_tokens" table
+CREATE TABLE "public"."api_tokens" ("id" bigserial NOT NULL, "user_id" bigint NOT NULL, "name" character varying(64) NOT NULL, "token" text NOT NULL, "scopes" text[] NOT NULL, "created_at" timestamp NOT NULL DEFAULT now(), "used_at" timestamp NULL, PRIMARY KEY ("id"), CONSTRAINT "api_tokens_user_id_fkey" FOREIGN KEY ("user_id") REFERENCES "public"."users" ("id") ON UPDATE NO ACTION ON DELETE CASCADE);
+-- Create index "api_tokens_token_idx" to table: "api_tokens"
+CREATE UNIQUE INDEX "api_tokens_token_idx" ON "public"."api_tokens" ("token");
+-- Create index "api_tokens_user_id_idx" to table: "api_tokens"
+CREATE INDEX "api_tokens_user_id_idx" ON "public"."api_tokens" ("user_id");
@@ -0,0 +1,4 @@
+-- Create index "pastes_listing_idx" to table: "pastes"
+CREATE INDEX "pastes_listing_idx" ON "public"."pastes" ("created_at" DESC, "id" DESC) WHERE ((public = true) AND (deleted_at IS NULL));
+-- Create index "pastes_tags_idx" to table: "pastes"
+CREATE INDEX "pastes_tags_idx" ON "public"."pastes" USING gin ("tags");
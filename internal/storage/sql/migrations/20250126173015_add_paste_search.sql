@@ -0,0 +1,4 @@
+-- Create "paste_search" table
+CREATE TABLE "public"."paste_search" ("paste_id" bigint NOT NULL, "document" tsvector NOT NULL, PRIMARY KEY ("paste_id"), CONSTRAINT "paste_search_paste_id_fkey" FOREIGN KEY ("paste_id") REFERENCES "public"."pastes" ("id") ON UPDATE NO ACTION ON DELETE CASCADE);
+-- Create index "paste_search_document_idx" to table: "paste_search"
+CREATE INDEX "paste_search_document_idx" ON "public"."paste_search" USING gin ("document");
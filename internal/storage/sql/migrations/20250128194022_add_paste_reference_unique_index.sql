@@ -0,0 +1,4 @@
+-- Modify "pastes" table
+ALTER TABLE "public"."pastes" ALTER COLUMN "reference" TYPE character varying(32), ALTER COLUMN "parent" TYPE character varying(32);
+-- Create index "pastes_reference_idx" to table: "pastes"
+CREATE UNIQUE INDEX "pastes_reference_idx" ON "public"."pastes" ("reference");
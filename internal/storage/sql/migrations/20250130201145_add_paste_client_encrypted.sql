@@ -0,0 +1,2 @@
+-- Modify "pastes" table
+ALTER TABLE "public"."pastes" ADD COLUMN "client_encrypted" boolean NOT NULL DEFAULT false;
@@ -0,0 +1,4 @@
+-- Create "paste_files" table
+CREATE TABLE "public"."paste_files" ("id" bigserial NOT NULL, "paste_id" bigint NOT NULL, "position" integer NOT NULL, "name" text NOT NULL, "syntax" character varying(50) NULL, "content" text NOT NULL, PRIMARY KEY ("id"), CONSTRAINT "paste_files_paste_id_fkey" FOREIGN KEY ("paste_id") REFERENCES "public"."pastes" ("id") ON UPDATE NO ACTION ON DELETE CASCADE);
+-- Create index "paste_files_paste_id_position_idx" to table: "paste_files"
+CREATE UNIQUE INDEX "paste_files_paste_id_position_idx" ON "public"."paste_files" ("paste_id", "position");
@@ -0,0 +1,4 @@
+-- Create "paste_attachments" table
+CREATE TABLE "public"."paste_attachments" ("id" bigserial NOT NULL, "paste_id" bigint NOT NULL, "position" integer NOT NULL, "name" text NOT NULL, "content_type" character varying(255) NOT NULL, "size" bigint NOT NULL, "blob" character varying(64) NOT NULL, "secret" text NOT NULL, PRIMARY KEY ("id"), CONSTRAINT "paste_attachments_paste_id_fkey" FOREIGN KEY ("paste_id") REFERENCES "public"."pastes" ("id") ON UPDATE NO ACTION ON DELETE CASCADE);
+-- Create index "paste_attachments_paste_id_position_idx" to table: "paste_attachments"
+CREATE UNIQUE INDEX "paste_attachments_paste_id_position_idx" ON "public"."paste_attachments" ("paste_id", "position");
@@ -0,0 +1,4 @@
+-- Modify "pastes" table, encrypted titles outgrow 255 characters
+ALTER TABLE "public"."pastes" ALTER COLUMN "title" TYPE text;
+-- Modify "paste_revisions" table
+ALTER TABLE "public"."paste_revisions" ALTER COLUMN "title" TYPE text;
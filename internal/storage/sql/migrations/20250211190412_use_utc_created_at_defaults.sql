@@ -0,0 +1,10 @@
+-- Modify "api_tokens" table
+ALTER TABLE "public"."api_tokens" ALTER COLUMN "created_at" SET DEFAULT timezone('utc'::text, now());
+-- Modify "paste_revisions" table
+ALTER TABLE "public"."paste_revisions" ALTER COLUMN "created_at" SET DEFAULT timezone('utc'::text, now());
+-- Modify "pastes" table
+ALTER TABLE "public"."pastes" ALTER COLUMN "created_at" SET DEFAULT timezone('utc'::text, now());
+-- Modify "sessions" table
+ALTER TABLE "public"."sessions" ALTER COLUMN "created_at" SET DEFAULT timezone('utc'::text, now());
+-- Modify "users" table
+ALTER TABLE "public"."users" ALTER COLUMN "created_at" SET DEFAULT timezone('utc'::text, now());
@@ -0,0 +1,4 @@
+-- Drop index "pastes_owner_id_idx" from table: "pastes", the dashboard lists expired and burnt pastes too
+DROP INDEX "public"."pastes_owner_id_idx";
+-- Create index "pastes_owner_id_idx" to table: "pastes"
+CREATE INDEX "pastes_owner_id_idx" ON "public"."pastes" ("owner_id");
@@ -0,0 +1,2 @@
+-- Modify "pastes" table
+ALTER TABLE "public"."pastes" DROP COLUMN "password";
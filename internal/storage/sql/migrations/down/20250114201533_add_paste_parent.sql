@@ -0,0 +1,4 @@
+-- Drop index "pastes_parent_idx" from table: "pastes"
+DROP INDEX "public"."pastes_parent_idx";
+-- Modify "pastes" table
+ALTER TABLE "public"."pastes" DROP COLUMN "parent";
@@ -0,0 +1,8 @@
+-- Drop "sessions" table
+DROP TABLE "public"."sessions";
+-- Drop index "pastes_owner_id_idx" from table: "pastes"
+DROP INDEX "public"."pastes_owner_id_idx";
+-- Modify "pastes" table
+ALTER TABLE "public"."pastes" DROP COLUMN "owner_id";
+-- Drop "users" table
+DROP TABLE "public"."users";
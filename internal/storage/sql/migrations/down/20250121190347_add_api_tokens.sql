@@ -0,0 +1,2 @@
+-- Drop "api_tokens" table
+DROP TABLE "public"."api_tokens";
@@ -0,0 +1,4 @@
+-- Drop index "pastes_tags_idx" from table: "pastes"
+DROP INDEX "public"."pastes_tags_idx";
+-- Drop index "pastes_listing_idx" from table: "pastes"
+DROP INDEX "public"."pastes_listing_idx";
@@ -0,0 +1,2 @@
+-- Drop "paste_search" table
+DROP TABLE "public"."paste_search";
@@ -0,0 +1,4 @@
+-- Drop index "pastes_reference_idx" from table: "pastes"
+DROP INDEX "public"."pastes_reference_idx";
+-- Modify "pastes" table, references longer than 8 characters have to be shortened first
+ALTER TABLE "public"."pastes" ALTER COLUMN "reference" TYPE character varying(8), ALTER COLUMN "parent" TYPE character varying(8);
@@ -0,0 +1,2 @@
+-- Drop "paste_files" table
+DROP TABLE "public"."paste_files";
@@ -0,0 +1,2 @@
+-- Drop "paste_attachments" table
+DROP TABLE "public"."paste_attachments";
@@ -0,0 +1,4 @@
+-- Modify "paste_revisions" table, titles longer than 255 characters have to be shortened first
+ALTER TABLE "public"."paste_revisions" ALTER COLUMN "title" TYPE character varying(255);
+-- Modify "pastes" table
+ALTER TABLE "public"."pastes" ALTER COLUMN "title" TYPE character varying(255);
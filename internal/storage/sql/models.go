@@ -8,18 +8,85 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+type ApiToken struct {
+	ID        int64            `db:"id" json:"id"`
+	UserID    int64            `db:"user_id" json:"user_id"`
+	Name      string           `db:"name" json:"name"`
+	Token     string           `db:"token" json:"token"`
+	Scopes    []string         `db:"scopes" json:"scopes"`
+	CreatedAt pgtype.Timestamp `db:"created_at" json:"created_at"`
+	UsedAt    pgtype.Timestamp `db:"used_at" json:"used_at"`
+}
+
 type Paste struct {
-	ID         int64            `db:"id" json:"id"`
-	Reference  string           `db:"reference" json:"reference"`
-	Title      string           `db:"title" json:"title"`
-	Content    string           `db:"content" json:"content"`
-	Syntax     pgtype.Text      `db:"syntax" json:"syntax"`
-	Tags       []string         `db:"tags" json:"tags"`
-	Expiration pgtype.Timestamp `db:"expiration" json:"expiration"`
-	Public     bool             `db:"public" json:"public"`
-	CreatedAt  pgtype.Timestamp `db:"created_at" json:"created_at"`
-	UpdatedAt  pgtype.Timestamp `db:"updated_at" json:"updated_at"`
-	DeletedAt  pgtype.Timestamp `db:"deleted_at" json:"deleted_at"`
-	Views      pgtype.Int4      `db:"views" json:"views"`
-	Password   pgtype.Text      `db:"password" json:"password"`
+	ID              int64            `db:"id" json:"id"`
+	Reference       string           `db:"reference" json:"reference"`
+	Title           string           `db:"title" json:"title"`
+	Content         string           `db:"content" json:"content"`
+	Syntax          pgtype.Text      `db:"syntax" json:"syntax"`
+	Tags            []string         `db:"tags" json:"tags"`
+	Expiration      pgtype.Timestamp `db:"expiration" json:"expiration"`
+	Public          bool             `db:"public" json:"public"`
+	CreatedAt       pgtype.Timestamp `db:"created_at" json:"created_at"`
+	UpdatedAt       pgtype.Timestamp `db:"updated_at" json:"updated_at"`
+	DeletedAt       pgtype.Timestamp `db:"deleted_at" json:"deleted_at"`
+	Views           pgtype.Int4      `db:"views" json:"views"`
+	Password        pgtype.Text      `db:"password" json:"password"`
+	BurnAfter       pgtype.Int4      `db:"burn_after" json:"burn_after"`
+	Token           pgtype.Text      `db:"token" json:"token"`
+	Parent          pgtype.Text      `db:"parent" json:"parent"`
+	OwnerID         pgtype.Int8      `db:"owner_id" json:"owner_id"`
+	ClientEncrypted bool             `db:"client_encrypted" json:"client_encrypted"`
+}
+
+type PasteAttachment struct {
+	ID          int64  `db:"id" json:"id"`
+	PasteID     int64  `db:"paste_id" json:"paste_id"`
+	Position    int32  `db:"position" json:"position"`
+	Name        string `db:"name" json:"name"`
+	ContentType string `db:"content_type" json:"content_type"`
+	Size        int64  `db:"size" json:"size"`
+	Blob        string `db:"blob" json:"blob"`
+	Secret      string `db:"secret" json:"secret"`
+}
+
+type PasteFile struct {
+	ID       int64       `db:"id" json:"id"`
+	PasteID  int64       `db:"paste_id" json:"paste_id"`
+	Position int32       `db:"position" json:"position"`
+	Name     string      `db:"name" json:"name"`
+	Syntax   pgtype.Text `db:"syntax" json:"syntax"`
+	Content  string      `db:"content" json:"content"`
+}
+
+type PasteRevision struct {
+	ID        int64            `db:"id" json:"id"`
+	PasteID   int64            `db:"paste_id" json:"paste_id"`
+	Revision  int32            `db:"revision" json:"revision"`
+	Title     string           `db:"title" json:"title"`
+	Content   string           `db:"content" json:"content"`
+	Syntax    pgtype.Text      `db:"syntax" json:"syntax"`
+	Tags      []string         `db:"tags" json:"tags"`
+	CreatedAt pgtype.Timestamp `db:"created_at" json:"created_at"`
+}
+
+type PasteSearch struct {
+	PasteID  int64       `db:"paste_id" json:"paste_id"`
+	Document interface{} `db:"document" json:"document"`
+}
+
+type Session struct {
+	ID        int64            `db:"id" json:"id"`
+	UserID    int64            `db:"user_id" json:"user_id"`
+	Token     string           `db:"token" json:"token"`
+	CreatedAt pgtype.Timestamp `db:"created_at" json:"created_at"`
+	ExpiresAt pgtype.Timestamp `db:"expires_at" json:"expires_at"`
+}
+
+type User struct {
+	ID        int64            `db:"id" json:"id"`
+	Username  string           `db:"username" json:"username"`
+	Password  string           `db:"password" json:"password"`
+	CreatedAt pgtype.Timestamp `db:"created_at" json:"created_at"`
+	DeletedAt pgtype.Timestamp `db:"deleted_at" json:"deleted_at"`
 }
@@ -13,27 +13,32 @@ import (
 
 const createPaste = `-- name: CreatePaste :one
 insert into pastes
-(reference, title, content, syntax, tags, expiration, public, password)
-values ($1, $2, $3, $4, $5, $6, $7, $8)
+(reference, title, content, syntax, tags, expiration, public, password, burn_after, token, parent, owner_id, client_encrypted)
+values ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
 returning id
 `
 
 type CreatePasteParams struct {
-	Reference  string           `db:"reference" json:"reference"`
-	Title      string           `db:"title" json:"title"`
-	Content    string           `db:"content" json:"content"`
-	Syntax     pgtype.Text      `db:"syntax" json:"syntax"`
-	Tags       []string         `db:"tags" json:"tags"`
-	Expiration pgtype.Timestamp `db:"expiration" json:"expiration"`
-	Public     bool             `db:"public" json:"public"`
-	Password   pgtype.Text      `db:"password" json:"password"`
+	Reference       string           `db:"reference" json:"reference"`
+	Title           string           `db:"title" json:"title"`
+	Content         string           `db:"content" json:"content"`
+	Syntax          pgtype.Text      `db:"syntax" json:"syntax"`
+	Tags            []string         `db:"tags" json:"tags"`
+	Expiration      pgtype.Timestamp `db:"expiration" json:"expiration"`
+	Public          bool             `db:"public" json:"public"`
+	Password        pgtype.Text      `db:"password" json:"password"`
+	BurnAfter       pgtype.Int4      `db:"burn_after" json:"burn_after"`
+	Token           pgtype.Text      `db:"token" json:"token"`
+	Parent          pgtype.Text      `db:"parent" json:"parent"`
+	OwnerID         pgtype.Int8      `db:"owner_id" json:"owner_id"`
+	ClientEncrypted bool             `db:"client_encrypted" json:"client_encrypted"`
 }
 
 // CreatePaste
 //
 //	insert into pastes
-//	(reference, title, content, syntax, tags, expiration, public, password)
-//	values ($1, $2, $3, $4, $5, $6, $7, $8)
+//	(reference, title, content, syntax, tags, expiration, public, password, burn_after, token, parent, owner_id, client_encrypted)
+//	values ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
 //	returning id
 func (q *Queries) CreatePaste(ctx context.Context, arg CreatePasteParams) (int64, error) {
 	row := q.db.QueryRow(ctx, createPaste,
@@ -45,25 +50,83 @@ func (q *Queries) CreatePaste(ctx context.Context, arg CreatePasteParams) (int64
 		arg.Expiration,
 		arg.Public,
 		arg.Password,
+		arg.BurnAfter,
+		arg.Token,
+		arg.Parent,
+		arg.OwnerID,
+		arg.ClientEncrypted,
 	)
 	var id int64
 	err := row.Scan(&id)
 	return id, err
 }
 
+const deleteOwnerPastes = `-- name: DeleteOwnerPastes :execrows
+update pastes
+set deleted_at = timezone('utc', now())
+where owner_id = $1
+    and reference = any($2::text[])
+    and deleted_at is null
+`
+
+type DeleteOwnerPastesParams struct {
+	OwnerID pgtype.Int8 `db:"owner_id" json:"owner_id"`
+	Refs    []string    `db:"refs" json:"refs"`
+}
+
+// DeleteOwnerPastes
+//
+//	update pastes
+//	set deleted_at = timezone('utc', now())
+//	where owner_id = $1
+//	    and reference = any($2::text[])
+//	    and deleted_at is null
+func (q *Queries) DeleteOwnerPastes(ctx context.Context, arg DeleteOwnerPastesParams) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteOwnerPastes, arg.OwnerID, arg.Refs)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const deletePaste = `-- name: DeletePaste :execrows
+update pastes
+set deleted_at = timezone('utc', now())
+where reference = $1
+    and deleted_at is null
+    and (expiration is null or expiration > timezone('utc', now()))
+`
+
+// DeletePaste
+//
+//	update pastes
+//	set deleted_at = timezone('utc', now())
+//	where reference = $1
+//	    and deleted_at is null
+//	    and (expiration is null or expiration > timezone('utc', now()))
+func (q *Queries) DeletePaste(ctx context.Context, reference string) (int64, error) {
+	result, err := q.db.Exec(ctx, deletePaste, reference)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
 const getPasteByID = `-- name: GetPasteByID :one
-select id, reference, title, content, syntax, tags, expiration, public, created_at, updated_at, deleted_at, views, password
+select id, reference, title, content, syntax, tags, expiration, public, created_at, updated_at, deleted_at, views, password, burn_after, token, parent, owner_id, client_encrypted
 from pastes
 where id = $1
     and deleted_at is null
+    and (expiration is null or expiration > timezone('utc', now()))
 `
 
 // GetPasteByID
 //
-//	select id, reference, title, content, syntax, tags, expiration, public, created_at, updated_at, deleted_at, views, password
+//	select id, reference, title, content, syntax, tags, expiration, public, created_at, updated_at, deleted_at, views, password, burn_after, token, parent, owner_id, client_encrypted
 //	from pastes
 //	where id = $1
 //	    and deleted_at is null
+//	    and (expiration is null or expiration > timezone('utc', now()))
 func (q *Queries) GetPasteByID(ctx context.Context, id int64) (Paste, error) {
 	row := q.db.QueryRow(ctx, getPasteByID, id)
 	var i Paste
@@ -81,25 +144,42 @@ func (q *Queries) GetPasteByID(ctx context.Context, id int64) (Paste, error) {
 		&i.DeletedAt,
 		&i.Views,
 		&i.Password,
+		&i.BurnAfter,
+		&i.Token,
+		&i.Parent,
+		&i.OwnerID,
+		&i.ClientEncrypted,
 	)
 	return i, err
 }
 
 const getPasteByReference = `-- name: GetPasteByReference :one
 update pastes
-set views = views + 1
+set views = views + 1,
+    deleted_at = case
+        when burn_after is not null and coalesce(views, 0) + 1 >= burn_after then timezone('utc', now())
+        else deleted_at
+    end
 where reference = $1
     and deleted_at is null
-returning id, reference, title, content, syntax, tags, expiration, public, created_at, updated_at, deleted_at, views, password
+    and (expiration is null or expiration > timezone('utc', now()))
+    and (burn_after is null or coalesce(views, 0) < burn_after)
+returning id, reference, title, content, syntax, tags, expiration, public, created_at, updated_at, deleted_at, views, password, burn_after, token, parent, owner_id, client_encrypted
 `
 
 // GetPasteByReference
 //
 //	update pastes
-//	set views = views + 1
+//	set views = views + 1,
+//	    deleted_at = case
+//	        when burn_after is not null and coalesce(views, 0) + 1 >= burn_after then timezone('utc', now())
+//	        else deleted_at
+//	    end
 //	where reference = $1
 //	    and deleted_at is null
-//	returning id, reference, title, content, syntax, tags, expiration, public, created_at, updated_at, deleted_at, views, password
+//	    and (expiration is null or expiration > timezone('utc', now()))
+//	    and (burn_after is null or coalesce(views, 0) < burn_after)
+//	returning id, reference, title, content, syntax, tags, expiration, public, created_at, updated_at, deleted_at, views, password, burn_after, token, parent, owner_id, client_encrypted
 func (q *Queries) GetPasteByReference(ctx context.Context, reference string) (Paste, error) {
 	row := q.db.QueryRow(ctx, getPasteByReference, reference)
 	var i Paste
@@ -117,27 +197,212 @@ func (q *Queries) GetPasteByReference(ctx context.Context, reference string) (Pa
 		&i.DeletedAt,
 		&i.Views,
 		&i.Password,
+		&i.BurnAfter,
+		&i.Token,
+		&i.Parent,
+		&i.OwnerID,
+		&i.ClientEncrypted,
 	)
 	return i, err
 }
 
+const getPasteToken = `-- name: GetPasteToken :one
+select token
+from pastes
+where reference = $1
+    and deleted_at is null
+    and (expiration is null or expiration > timezone('utc', now()))
+`
+
+// GetPasteToken
+//
+//	select token
+//	from pastes
+//	where reference = $1
+//	    and deleted_at is null
+//	    and (expiration is null or expiration > timezone('utc', now()))
+func (q *Queries) GetPasteToken(ctx context.Context, reference string) (pgtype.Text, error) {
+	row := q.db.QueryRow(ctx, getPasteToken, reference)
+	var token pgtype.Text
+	err := row.Scan(&token)
+	return token, err
+}
+
+const listOwnerPastes = `-- name: ListOwnerPastes :many
+select id, reference, title, content, syntax, tags, expiration, public, created_at, updated_at, deleted_at, views, password, burn_after, token, parent, owner_id, client_encrypted
+from pastes
+where owner_id = $1
+    and (
+        deleted_at is null
+        or (expiration is not null and deleted_at >= expiration)
+        or (burn_after is not null and coalesce(views, 0) >= burn_after)
+    )
+order by created_at desc
+`
+
+// ListOwnerPastes
+//
+//	select id, reference, title, content, syntax, tags, expiration, public, created_at, updated_at, deleted_at, views, password, burn_after, token, parent, owner_id, client_encrypted
+//	from pastes
+//	where owner_id = $1
+//	    and (
+//	        deleted_at is null
+//	        or (expiration is not null and deleted_at >= expiration)
+//	        or (burn_after is not null and coalesce(views, 0) >= burn_after)
+//	    )
+//	order by created_at desc
+func (q *Queries) ListOwnerPastes(ctx context.Context, ownerID pgtype.Int8) ([]Paste, error) {
+	rows, err := q.db.Query(ctx, listOwnerPastes, ownerID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Paste
+	for rows.Next() {
+		var i Paste
+		if err := rows.Scan(
+			&i.ID,
+			&i.Reference,
+			&i.Title,
+			&i.Content,
+			&i.Syntax,
+			&i.Tags,
+			&i.Expiration,
+			&i.Public,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.DeletedAt,
+			&i.Views,
+			&i.Password,
+			&i.BurnAfter,
+			&i.Token,
+			&i.Parent,
+			&i.OwnerID,
+			&i.ClientEncrypted,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listPublicForks = `-- name: ListPublicForks :many
+select id, reference, title, content, syntax, tags, expiration, public, created_at, updated_at, deleted_at, views, password, burn_after, token, parent, owner_id, client_encrypted
+from pastes
+where parent = $1
+    and public = true
+    and deleted_at is null
+    and (expiration is null or expiration > timezone('utc', now()))
+order by created_at desc
+`
+
+// ListPublicForks
+//
+//	select id, reference, title, content, syntax, tags, expiration, public, created_at, updated_at, deleted_at, views, password, burn_after, token, parent, owner_id, client_encrypted
+//	from pastes
+//	where parent = $1
+//	    and public = true
+//	    and deleted_at is null
+//	    and (expiration is null or expiration > timezone('utc', now()))
+//	order by created_at desc
+func (q *Queries) ListPublicForks(ctx context.Context, parent pgtype.Text) ([]Paste, error) {
+	rows, err := q.db.Query(ctx, listPublicForks, parent)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Paste
+	for rows.Next() {
+		var i Paste
+		if err := rows.Scan(
+			&i.ID,
+			&i.Reference,
+			&i.Title,
+			&i.Content,
+			&i.Syntax,
+			&i.Tags,
+			&i.Expiration,
+			&i.Public,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.DeletedAt,
+			&i.Views,
+			&i.Password,
+			&i.BurnAfter,
+			&i.Token,
+			&i.Parent,
+			&i.OwnerID,
+			&i.ClientEncrypted,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const listPublicPastes = `-- name: ListPublicPastes :many
-select id, reference, title, content, syntax, tags, expiration, public, created_at, updated_at, deleted_at, views, password
+select id, reference, title, content, syntax, tags, expiration, public, created_at, updated_at, deleted_at, views, password, burn_after, token, parent, owner_id, client_encrypted
 from pastes
 where public = true
     and deleted_at is null
-order by created_at desc
+    and (expiration is null or expiration > timezone('utc', now()))
+    and ($1::text is null or tags @> array[$1::text])
+    and ($2::text is null or syntax = $2::text)
+    and ($3::timestamp is null or created_at >= $3::timestamp)
+    and ($4::timestamp is null or created_at < $4::timestamp)
+    and (
+        $5::timestamp is null
+        or (created_at, id) < ($5::timestamp, $6::bigint)
+    )
+order by created_at desc, id desc
+limit $7
 `
 
+type ListPublicPastesParams struct {
+	Tag             pgtype.Text      `db:"tag" json:"tag"`
+	Syntax          pgtype.Text      `db:"syntax" json:"syntax"`
+	Since           pgtype.Timestamp `db:"since" json:"since"`
+	Until           pgtype.Timestamp `db:"until" json:"until"`
+	CursorCreatedAt pgtype.Timestamp `db:"cursor_created_at" json:"cursor_created_at"`
+	CursorID        pgtype.Int8      `db:"cursor_id" json:"cursor_id"`
+	PageSize        int32            `db:"page_size" json:"page_size"`
+}
+
 // ListPublicPastes
 //
-//	select id, reference, title, content, syntax, tags, expiration, public, created_at, updated_at, deleted_at, views, password
+//	select id, reference, title, content, syntax, tags, expiration, public, created_at, updated_at, deleted_at, views, password, burn_after, token, parent, owner_id, client_encrypted
 //	from pastes
 //	where public = true
 //	    and deleted_at is null
-//	order by created_at desc
-func (q *Queries) ListPublicPastes(ctx context.Context) ([]Paste, error) {
-	rows, err := q.db.Query(ctx, listPublicPastes)
+//	    and (expiration is null or expiration > timezone('utc', now()))
+//	    and ($1::text is null or tags @> array[$1::text])
+//	    and ($2::text is null or syntax = $2::text)
+//	    and ($3::timestamp is null or created_at >= $3::timestamp)
+//	    and ($4::timestamp is null or created_at < $4::timestamp)
+//	    and (
+//	        $5::timestamp is null
+//	        or (created_at, id) < ($5::timestamp, $6::bigint)
+//	    )
+//	order by created_at desc, id desc
+//	limit $7
+func (q *Queries) ListPublicPastes(ctx context.Context, arg ListPublicPastesParams) ([]Paste, error) {
+	rows, err := q.db.Query(ctx, listPublicPastes,
+		arg.Tag,
+		arg.Syntax,
+		arg.Since,
+		arg.Until,
+		arg.CursorCreatedAt,
+		arg.CursorID,
+		arg.PageSize,
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -159,6 +424,11 @@ func (q *Queries) ListPublicPastes(ctx context.Context) ([]Paste, error) {
 			&i.DeletedAt,
 			&i.Views,
 			&i.Password,
+			&i.BurnAfter,
+			&i.Token,
+			&i.Parent,
+			&i.OwnerID,
+			&i.ClientEncrypted,
 		); err != nil {
 			return nil, err
 		}
@@ -169,3 +439,166 @@ func (q *Queries) ListPublicPastes(ctx context.Context) ([]Paste, error) {
 	}
 	return items, nil
 }
+
+const listPublicTags = `-- name: ListPublicTags :many
+select tag::text as tag, count(*) as pastes
+from pastes, unnest(tags) as tag
+where public = true
+    and deleted_at is null
+    and (expiration is null or expiration > timezone('utc', now()))
+    and ($1::text is null or starts_with(tag, $1::text))
+group by tag
+order by pastes desc, tag
+limit $2
+`
+
+type ListPublicTagsParams struct {
+	Prefix   pgtype.Text `db:"prefix" json:"prefix"`
+	PageSize int32       `db:"page_size" json:"page_size"`
+}
+
+type ListPublicTagsRow struct {
+	Tag    string `db:"tag" json:"tag"`
+	Pastes int64  `db:"pastes" json:"pastes"`
+}
+
+// ListPublicTags
+//
+//	select tag::text as tag, count(*) as pastes
+//	from pastes, unnest(tags) as tag
+//	where public = true
+//	    and deleted_at is null
+//	    and (expiration is null or expiration > timezone('utc', now()))
+//	    and ($1::text is null or starts_with(tag, $1::text))
+//	group by tag
+//	order by pastes desc, tag
+//	limit $2
+func (q *Queries) ListPublicTags(ctx context.Context, arg ListPublicTagsParams) ([]ListPublicTagsRow, error) {
+	rows, err := q.db.Query(ctx, listPublicTags, arg.Prefix, arg.PageSize)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListPublicTagsRow
+	for rows.Next() {
+		var i ListPublicTagsRow
+		if err := rows.Scan(
+			&i.Tag,
+			&i.Pastes,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const peekPasteByReference = `-- name: PeekPasteByReference :one
+select id, reference, title, content, syntax, tags, expiration, public, created_at, updated_at, deleted_at, views, password, burn_after, token, parent, owner_id, client_encrypted
+from pastes
+where reference = $1
+    and deleted_at is null
+    and (expiration is null or expiration > timezone('utc', now()))
+`
+
+// PeekPasteByReference
+//
+//	select id, reference, title, content, syntax, tags, expiration, public, created_at, updated_at, deleted_at, views, password, burn_after, token, parent, owner_id, client_encrypted
+//	from pastes
+//	where reference = $1
+//	    and deleted_at is null
+//	    and (expiration is null or expiration > timezone('utc', now()))
+func (q *Queries) PeekPasteByReference(ctx context.Context, reference string) (Paste, error) {
+	row := q.db.QueryRow(ctx, peekPasteByReference, reference)
+	var i Paste
+	err := row.Scan(
+		&i.ID,
+		&i.Reference,
+		&i.Title,
+		&i.Content,
+		&i.Syntax,
+		&i.Tags,
+		&i.Expiration,
+		&i.Public,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DeletedAt,
+		&i.Views,
+		&i.Password,
+		&i.BurnAfter,
+		&i.Token,
+		&i.Parent,
+		&i.OwnerID,
+		&i.ClientEncrypted,
+	)
+	return i, err
+}
+
+const reapExpiredPastes = `-- name: ReapExpiredPastes :execrows
+update pastes
+set deleted_at = timezone('utc', now())
+where expiration <= timezone('utc', now())
+    and deleted_at is null
+`
+
+// ReapExpiredPastes
+//
+//	update pastes
+//	set deleted_at = timezone('utc', now())
+//	where expiration <= timezone('utc', now())
+//	    and deleted_at is null
+func (q *Queries) ReapExpiredPastes(ctx context.Context) (int64, error) {
+	result, err := q.db.Exec(ctx, reapExpiredPastes)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const updatePaste = `-- name: UpdatePaste :execrows
+update pastes
+set title = $2,
+    content = $3,
+    syntax = $4,
+    tags = $5,
+    updated_at = timezone('utc', now())
+where reference = $1
+    and deleted_at is null
+    and (expiration is null or expiration > timezone('utc', now()))
+`
+
+type UpdatePasteParams struct {
+	Reference string      `db:"reference" json:"reference"`
+	Title     string      `db:"title" json:"title"`
+	Content   string      `db:"content" json:"content"`
+	Syntax    pgtype.Text `db:"syntax" json:"syntax"`
+	Tags      []string    `db:"tags" json:"tags"`
+}
+
+// UpdatePaste
+//
+//	update pastes
+//	set title = $2,
+//	    content = $3,
+//	    syntax = $4,
+//	    tags = $5,
+//	    updated_at = timezone('utc', now())
+//	where reference = $1
+//	    and deleted_at is null
+//	    and (expiration is null or expiration > timezone('utc', now()))
+func (q *Queries) UpdatePaste(ctx context.Context, arg UpdatePasteParams) (int64, error) {
+	result, err := q.db.Exec(ctx, updatePaste,
+		arg.Reference,
+		arg.Title,
+		arg.Content,
+		arg.Syntax,
+		arg.Tags,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
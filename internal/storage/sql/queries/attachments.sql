@@ -0,0 +1,12 @@
+-- name: CreatePasteAttachment :exec
+insert into paste_attachments (paste_id, position, name, content_type, size, blob, secret)
+select id, $2, $3, $4, $5, $6, $7
+from pastes
+where reference = $1;
+
+-- name: ListPasteAttachments :many
+select paste_attachments.*
+from paste_attachments
+    join pastes on pastes.id = paste_attachments.paste_id
+where pastes.reference = $1
+order by paste_attachments.position;
@@ -0,0 +1,16 @@
+-- name: CreatePasteFile :exec
+insert into paste_files (paste_id, position, name, syntax, content)
+select id, $2, $3, $4, $5
+from pastes
+where reference = $1;
+
+-- name: ListPasteFiles :many
+select paste_files.*
+from paste_files
+    join pastes on pastes.id = paste_files.paste_id
+where pastes.reference = $1
+order by paste_files.position;
+
+-- name: DeletePasteFiles :exec
+delete from paste_files
+where paste_id = (select id from pastes where reference = $1);
@@ -0,0 +1,83 @@
+-- name: ListPastesToRotate :many
+select id, title, content, client_encrypted
+from pastes
+where id > sqlc.arg(after)
+    and (
+        not starts_with(title, sqlc.arg(prefix)::text)
+        or (not client_encrypted and not starts_with(content, sqlc.arg(prefix)::text))
+    )
+order by id
+limit sqlc.arg(batch_size)
+for update;
+
+-- name: RotatePaste :exec
+update pastes
+set title = $2,
+    content = $3
+where id = $1;
+
+-- name: ListRevisionsToRotate :many
+select paste_revisions.id, paste_revisions.title, paste_revisions.content, pastes.client_encrypted
+from paste_revisions
+    join pastes on pastes.id = paste_revisions.paste_id
+where paste_revisions.id > sqlc.arg(after)
+    and (
+        not starts_with(paste_revisions.title, sqlc.arg(prefix)::text)
+        or (not pastes.client_encrypted and not starts_with(paste_revisions.content, sqlc.arg(prefix)::text))
+    )
+order by paste_revisions.id
+limit sqlc.arg(batch_size)
+for update of paste_revisions;
+
+-- name: RotateRevision :exec
+update paste_revisions
+set title = $2,
+    content = $3
+where id = $1;
+
+-- name: ListFilesToRotate :many
+select paste_files.id, paste_files.name, paste_files.content, pastes.client_encrypted
+from paste_files
+    join pastes on pastes.id = paste_files.paste_id
+where paste_files.id > sqlc.arg(after)
+    and (
+        not starts_with(paste_files.name, sqlc.arg(prefix)::text)
+        or (not pastes.client_encrypted and not starts_with(paste_files.content, sqlc.arg(prefix)::text))
+    )
+order by paste_files.id
+limit sqlc.arg(batch_size)
+for update of paste_files;
+
+-- name: RotateFile :exec
+update paste_files
+set name = $2,
+    content = $3
+where id = $1;
+
+-- name: ListAttachmentsToRotate :many
+select id, name, secret
+from paste_attachments
+where id > sqlc.arg(after)
+    and (
+        not starts_with(name, sqlc.arg(prefix)::text)
+        or not starts_with(secret, sqlc.arg(prefix)::text)
+    )
+order by id
+limit sqlc.arg(batch_size)
+for update;
+
+-- name: RotateAttachment :exec
+update paste_attachments
+set name = $2,
+    secret = $3
+where id = $1;
+
+-- name: RotateSessionDigest :exec
+update sessions
+set token = sqlc.arg(digest)
+where token = sqlc.arg(retired);
+
+-- name: RotateAPITokenDigest :exec
+update api_tokens
+set token = sqlc.arg(digest)
+where token = sqlc.arg(retired);
@@ -2,19 +2,33 @@
 select *
 from pastes
 where id = $1
-    and deleted_at is null;
+    and deleted_at is null
+    and (expiration is null or expiration > timezone('utc', now()));
 
 -- name: GetPasteByReference :one
 update pastes
-set views = views + 1
+set views = views + 1,
+    deleted_at = case
+        when burn_after is not null and coalesce(views, 0) + 1 >= burn_after then timezone('utc', now())
+        else deleted_at
+    end
 where reference = $1
     and deleted_at is null
+    and (expiration is null or expiration > timezone('utc', now()))
+    and (burn_after is null or coalesce(views, 0) < burn_after)
 returning *;
 
+-- name: PeekPasteByReference :one
+select *
+from pastes
+where reference = $1
+    and deleted_at is null
+    and (expiration is null or expiration > timezone('utc', now()));
+
 -- name: CreatePaste :one
 insert into pastes
-(reference, title, content, syntax, tags, expiration, public, password)
-values ($1, $2, $3, $4, $5, $6, $7, $8)
+(reference, title, content, syntax, tags, expiration, public, password, burn_after, token, parent, owner_id, client_encrypted)
+values ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
 returning id;
 
 -- name: ListPublicPastes :many
@@ -22,4 +36,83 @@ select *
 from pastes
 where public = true
     and deleted_at is null
+    and (expiration is null or expiration > timezone('utc', now()))
+    and (sqlc.narg(tag)::text is null or tags @> array[sqlc.narg(tag)::text])
+    and (sqlc.narg(syntax)::text is null or syntax = sqlc.narg(syntax)::text)
+    and (sqlc.narg(since)::timestamp is null or created_at >= sqlc.narg(since)::timestamp)
+    and (sqlc.narg(until)::timestamp is null or created_at < sqlc.narg(until)::timestamp)
+    and (
+        sqlc.narg(cursor_created_at)::timestamp is null
+        or (created_at, id) < (sqlc.narg(cursor_created_at)::timestamp, sqlc.narg(cursor_id)::bigint)
+    )
+order by created_at desc, id desc
+limit sqlc.arg(page_size);
+
+-- name: ListPublicForks :many
+select *
+from pastes
+where parent = $1
+    and public = true
+    and deleted_at is null
+    and (expiration is null or expiration > timezone('utc', now()))
+order by created_at desc;
+
+-- name: GetPasteToken :one
+select token
+from pastes
+where reference = $1
+    and deleted_at is null
+    and (expiration is null or expiration > timezone('utc', now()));
+
+-- name: UpdatePaste :execrows
+update pastes
+set title = $2,
+    content = $3,
+    syntax = $4,
+    tags = $5,
+    updated_at = timezone('utc', now())
+where reference = $1
+    and deleted_at is null
+    and (expiration is null or expiration > timezone('utc', now()));
+
+-- name: DeletePaste :execrows
+update pastes
+set deleted_at = timezone('utc', now())
+where reference = $1
+    and deleted_at is null
+    and (expiration is null or expiration > timezone('utc', now()));
+
+-- name: ReapExpiredPastes :execrows
+update pastes
+set deleted_at = timezone('utc', now())
+where expiration <= timezone('utc', now())
+    and deleted_at is null;
+
+-- name: ListOwnerPastes :many
+select *
+from pastes
+where owner_id = $1
+    and (
+        deleted_at is null
+        or (expiration is not null and deleted_at >= expiration)
+        or (burn_after is not null and coalesce(views, 0) >= burn_after)
+    )
 order by created_at desc;
+
+-- name: DeleteOwnerPastes :execrows
+update pastes
+set deleted_at = timezone('utc', now())
+where owner_id = $1
+    and reference = any(sqlc.arg(refs)::text[])
+    and deleted_at is null;
+
+-- name: ListPublicTags :many
+select tag::text as tag, count(*) as pastes
+from pastes, unnest(tags) as tag
+where public = true
+    and deleted_at is null
+    and (expiration is null or expiration > timezone('utc', now()))
+    and (sqlc.narg(prefix)::text is null or starts_with(tag, sqlc.narg(prefix)::text))
+group by tag
+order by pastes desc, tag
+limit sqlc.arg(page_size);
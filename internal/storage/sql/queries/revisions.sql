@@ -0,0 +1,29 @@
+-- name: CreatePasteRevision :exec
+insert into paste_revisions (paste_id, revision, title, content, syntax, tags, created_at)
+select id,
+    (select coalesce(max(revision), 0) + 1 from paste_revisions where paste_id = pastes.id),
+    title,
+    content,
+    syntax,
+    tags,
+    coalesce(updated_at, created_at)
+from pastes
+where reference = $1
+    and deleted_at is null;
+
+-- name: ListPasteRevisions :many
+select *
+from paste_revisions
+where paste_id = $1
+order by revision;
+
+-- name: GetPasteRevision :one
+select *
+from paste_revisions
+where paste_id = $1
+    and revision = $2;
+
+-- name: CountPasteRevisions :one
+select count(*)
+from paste_revisions
+where paste_id = $1;
@@ -0,0 +1,44 @@
+-- name: IndexPaste :exec
+insert into paste_search (paste_id, document)
+select id,
+    setweight(to_tsvector('simple', sqlc.arg(title)::text), 'A')
+    || setweight(to_tsvector('simple', sqlc.arg(tags)::text), 'B')
+    || setweight(to_tsvector('simple', sqlc.arg(content)::text), 'C')
+from pastes
+where reference = sqlc.arg(reference)
+    and public = true
+on conflict (paste_id) do update
+set document = excluded.document;
+
+-- name: SearchPublicPastes :many
+select pastes.*
+from paste_search
+join pastes on pastes.id = paste_search.paste_id
+where paste_search.document @@ websearch_to_tsquery('simple', sqlc.arg(query))
+    and pastes.public = true
+    and pastes.deleted_at is null
+    and (pastes.expiration is null or pastes.expiration > timezone('utc', now()))
+order by ts_rank(paste_search.document, websearch_to_tsquery('simple', sqlc.arg(query))) desc,
+    pastes.created_at desc
+limit sqlc.arg(page_size);
+
+-- name: ListUnindexedPastes :many
+select pastes.*
+from pastes
+left join paste_search on paste_search.paste_id = pastes.id
+where pastes.public = true
+    and pastes.deleted_at is null
+    and paste_search.paste_id is null;
+
+-- name: DeleteSearchDocument :exec
+delete from paste_search
+using pastes
+where paste_search.paste_id = pastes.id
+    and pastes.reference = $1
+    and pastes.deleted_at is not null;
+
+-- name: DeleteSearchDocuments :exec
+delete from paste_search
+using pastes
+where paste_search.paste_id = pastes.id
+    and pastes.deleted_at is not null;
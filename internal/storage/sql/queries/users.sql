@@ -0,0 +1,56 @@
+-- name: CreateUser :one
+insert into users (username, password)
+values ($1, $2)
+returning *;
+
+-- name: GetUserByUsername :one
+select *
+from users
+where username = $1
+    and deleted_at is null;
+
+-- name: ListUsernames :many
+select id, username
+from users
+where id = any(sqlc.arg(ids)::bigint[]);
+
+-- name: CreateSession :exec
+insert into sessions (user_id, token, expires_at)
+values ($1, $2, $3);
+
+-- name: GetSessionUser :one
+select users.*
+from sessions
+join users on users.id = sessions.user_id
+where sessions.token = $1
+    and sessions.expires_at > timezone('utc', now())
+    and users.deleted_at is null;
+
+-- name: DeleteSession :exec
+delete from sessions
+where token = $1;
+
+-- name: CreateAPIToken :one
+insert into api_tokens (user_id, name, token, scopes)
+values ($1, $2, $3, $4)
+returning *;
+
+-- name: ListAPITokens :many
+select *
+from api_tokens
+where user_id = $1
+order by created_at desc;
+
+-- name: DeleteAPIToken :execrows
+delete from api_tokens
+where id = $1
+    and user_id = $2;
+
+-- name: UseAPIToken :one
+update api_tokens
+set used_at = timezone('utc', now())
+from users
+where api_tokens.token = $1
+    and users.id = api_tokens.user_id
+    and users.deleted_at is null
+returning users.*, api_tokens.scopes;
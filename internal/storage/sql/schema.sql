@@ -1,8 +1,47 @@
+create table users (
+    id bigserial primary key,
+
+    username varchar(32) not null,
+    password text not null,
+
+    created_at timestamp not null default timezone('utc', now()),
+    deleted_at timestamp null
+);
+
+create unique index users_username_idx on users (username);
+
+create table sessions (
+    id bigserial primary key,
+
+    user_id bigint not null references users (id) on delete cascade,
+    token text not null,
+
+    created_at timestamp not null default timezone('utc', now()),
+    expires_at timestamp not null
+);
+
+create unique index sessions_token_idx on sessions (token);
+
+create table api_tokens (
+    id bigserial primary key,
+
+    user_id bigint not null references users (id) on delete cascade,
+    name varchar(64) not null,
+    token text not null,
+    scopes text[] not null,
+
+    created_at timestamp not null default timezone('utc', now()),
+    used_at timestamp null
+);
+
+create unique index api_tokens_token_idx on api_tokens (token);
+create index api_tokens_user_id_idx on api_tokens (user_id);
+
 create table pastes (
     id bigserial primary key,
 
-    reference varchar(8) not null,
-    title varchar(255) not null,
+    reference varchar(32) not null,
+    title text not null,
 
     content text not null,
 
@@ -12,8 +51,72 @@ create table pastes (
     public boolean not null default true,
     views integer default 0,
     password text null,
+    burn_after integer null,
+    token text null,
+    parent varchar(32) null,
+    owner_id bigint null references users (id) on delete set null,
+    client_encrypted boolean not null default false,
 
-    created_at timestamp not null default now(),
+    created_at timestamp not null default timezone('utc', now()),
     updated_at timestamp null,
     deleted_at timestamp null
 );
+
+create unique index pastes_reference_idx on pastes (reference);
+create index pastes_expiration_idx on pastes (expiration) where deleted_at is null;
+create index pastes_parent_idx on pastes (parent) where deleted_at is null;
+create index pastes_owner_id_idx on pastes (owner_id);
+create index pastes_listing_idx on pastes (created_at desc, id desc) where public = true and deleted_at is null;
+create index pastes_tags_idx on pastes using gin (tags);
+
+create table paste_revisions (
+    id bigserial primary key,
+
+    paste_id bigint not null references pastes (id) on delete cascade,
+    revision integer not null,
+
+    title text not null,
+    content text not null,
+    syntax varchar(50) null,
+    tags text[],
+
+    created_at timestamp not null default timezone('utc', now())
+);
+
+create unique index paste_revisions_paste_id_revision_idx on paste_revisions (paste_id, revision);
+
+create table paste_files (
+    id bigserial primary key,
+
+    paste_id bigint not null references pastes (id) on delete cascade,
+    position integer not null,
+
+    name text not null,
+    syntax varchar(50) null,
+    content text not null
+);
+
+create unique index paste_files_paste_id_position_idx on paste_files (paste_id, position);
+
+create table paste_attachments (
+    id bigserial primary key,
+
+    paste_id bigint not null references pastes (id) on delete cascade,
+    position integer not null,
+
+    name text not null,
+    content_type varchar(255) not null,
+    size bigint not null,
+    blob varchar(64) not null,
+    secret text not null
+);
+
+create unique index paste_attachments_paste_id_position_idx on paste_attachments (paste_id, position);
+
+create table paste_search (
+    paste_id bigint primary key references pastes (id) on delete cascade,
+
+    document tsvector not null
+);
+
+create index paste_search_document_idx on paste_search using gin (document);
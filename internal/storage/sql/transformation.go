@@ -6,6 +6,7 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 
 	"github.com/aexvir/skladka/internal/paste"
+	"github.com/aexvir/skladka/internal/user"
 )
 
 func (db Paste) ToDomain() paste.Paste {
@@ -24,6 +25,23 @@ func (db Paste) ToDomain() paste.Paste {
 		password = &db.Password.String
 	}
 
+	var burnafter *int
+	if db.BurnAfter.Valid {
+		reads := int(db.BurnAfter.Int32)
+		burnafter = &reads
+	}
+
+	var parent *string
+	if db.Parent.Valid {
+		parent = &db.Parent.String
+	}
+
+	// only the id of the owner is known here, the username is resolved by the storage
+	var owner *user.User
+	if db.OwnerID.Valid {
+		owner = &user.User{ID: db.OwnerID.Int64}
+	}
+
 	return paste.Paste{
 		Reference:  db.Reference,
 		Title:      db.Title,
@@ -35,6 +53,11 @@ func (db Paste) ToDomain() paste.Paste {
 		Public:     db.Public,
 		Views:      int(db.Views.Int32),
 		Password:   password,
+		BurnAfter:  burnafter,
+		Parent:     parent,
+		Owner:      owner,
+
+		ClientEncrypted: db.ClientEncrypted,
 	}
 }
 
@@ -49,8 +72,9 @@ func (Paste) FromDomain(domain paste.Paste) *Paste {
 
 	var expiration pgtype.Timestamp
 	if domain.Expiration != nil {
+		// expiration is stored without time zone, always in utc
 		expiration = pgtype.Timestamp{
-			Time:  *domain.Expiration,
+			Time:  domain.Expiration.UTC(),
 			Valid: true,
 		}
 	}
@@ -63,6 +87,30 @@ func (Paste) FromDomain(domain paste.Paste) *Paste {
 		}
 	}
 
+	var burnafter pgtype.Int4
+	if domain.BurnAfter != nil {
+		burnafter = pgtype.Int4{
+			Int32: int32(*domain.BurnAfter),
+			Valid: true,
+		}
+	}
+
+	var parent pgtype.Text
+	if domain.Parent != nil {
+		parent = pgtype.Text{
+			String: *domain.Parent,
+			Valid:  true,
+		}
+	}
+
+	var owner pgtype.Int8
+	if domain.Owner != nil {
+		owner = pgtype.Int8{
+			Int64: domain.Owner.ID,
+			Valid: true,
+		}
+	}
+
 	return &Paste{
 		Reference:  domain.Reference,
 		Title:      domain.Title,
@@ -72,5 +120,95 @@ func (Paste) FromDomain(domain paste.Paste) *Paste {
 		Expiration: expiration,
 		Public:     domain.Public,
 		Password:   password,
+		BurnAfter:  burnafter,
+		Parent:     parent,
+		OwnerID:    owner,
+
+		ClientEncrypted: domain.ClientEncrypted,
+	}
+}
+
+// ToRevision returns the current state of the paste as its latest revision.
+func (db Paste) ToRevision(number int) paste.Revision {
+	creation := db.CreatedAt.Time
+	if db.UpdatedAt.Valid {
+		creation = db.UpdatedAt.Time
+	}
+
+	domain := db.ToDomain()
+
+	return paste.Revision{
+		Number:   number,
+		Title:    domain.Title,
+		Content:  domain.Content,
+		Syntax:   domain.Syntax,
+		Tags:     domain.Tags,
+		Creation: creation,
+
+		ClientEncrypted: domain.ClientEncrypted,
+	}
+}
+
+func (db PasteRevision) ToDomain() paste.Revision {
+	syntax := "plaintext"
+	if db.Syntax.Valid {
+		syntax = db.Syntax.String
+	}
+
+	return paste.Revision{
+		Number:   int(db.Revision),
+		Title:    db.Title,
+		Content:  db.Content,
+		Syntax:   syntax,
+		Tags:     db.Tags,
+		Creation: db.CreatedAt.Time,
+	}
+}
+
+func (db PasteFile) ToDomain() paste.File {
+	syntax := "plaintext"
+	if db.Syntax.Valid {
+		syntax = db.Syntax.String
+	}
+
+	return paste.File{
+		Name:    db.Name,
+		Syntax:  syntax,
+		Content: db.Content,
+	}
+}
+
+func (db User) ToDomain() user.User {
+	return user.User{
+		ID:       db.ID,
+		Username: db.Username,
+		Creation: db.CreatedAt.Time,
+	}
+}
+
+func (db ApiToken) ToDomain() user.Token {
+	scopes := make([]user.Scope, len(db.Scopes))
+	for i, scope := range db.Scopes {
+		scopes[i] = user.Scope(scope)
+	}
+
+	var lastuse *time.Time
+	if db.UsedAt.Valid {
+		lastuse = &db.UsedAt.Time
+	}
+
+	return user.Token{
+		ID:       db.ID,
+		Name:     db.Name,
+		Scopes:   scopes,
+		Creation: db.CreatedAt.Time,
+		LastUse:  lastuse,
+	}
+}
+
+func (db ListPublicTagsRow) ToDomain() paste.Tag {
+	return paste.Tag{
+		Name:   db.Tag,
+		Pastes: int(db.Pastes),
 	}
 }
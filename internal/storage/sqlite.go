@@ -0,0 +1,1526 @@
+package storage
+
+import (
+	"context"
+	dbsql "database/sql"
+	"encoding/json"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"go.opentelemetry.io/otel/trace"
+	sqlitedriver "modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
+
+	"github.com/aexvir/skladka/internal/config"
+	"github.com/aexvir/skladka/internal/errors"
+	"github.com/aexvir/skladka/internal/logging"
+	"github.com/aexvir/skladka/internal/metrics"
+	"github.com/aexvir/skladka/internal/paste"
+	"github.com/aexvir/skladka/internal/storage/blob"
+	"github.com/aexvir/skladka/internal/storage/sqlite"
+	"github.com/aexvir/skladka/internal/tracing"
+	"github.com/aexvir/skladka/internal/user"
+)
+
+// sqliteTimeLayout is how timestamps are stored by the sqlite storage.
+// It's fixed width and always in utc, so timestamps can be compared as text.
+const sqliteTimeLayout = "2006-01-02 15:04:05.000000"
+
+const (
+	// sqlitePasteColumns are the columns scanned by scanPaste, the username of the
+	// owner is joined right away as sqlite doesn't mind the extra join.
+	sqlitePasteColumns = `pastes.id, pastes.reference, pastes.title, pastes.content, pastes.syntax, pastes.tags,
+		pastes.expiration, pastes.public, pastes.created_at, pastes.updated_at, pastes.deleted_at, pastes.views,
+		pastes.password, pastes.burn_after, pastes.token, pastes.parent, pastes.owner_id, users.username,
+		pastes.client_encrypted`
+
+	// sqlitePastes is the from clause matching sqlitePasteColumns.
+	sqlitePastes = `pastes left join users on users.id = pastes.owner_id`
+
+	// sqliteLive filters out deleted and expired pastes.
+	sqliteLive = `pastes.deleted_at is null and (pastes.expiration is null or pastes.expiration > @now)`
+)
+
+// SQLiteStorage stores pastes in a sqlite database, for self-hosting skladka as a single
+// binary without running postgres. It behaves the same way the postgres storage does,
+// pastes are encrypted, passwords and tokens are only stored hashed and missing rows are
+// reported as pgx.ErrNoRows, so callers can't tell both apart.
+//
+// The schema is migrated when the storage is opened.
+type SQLiteStorage struct {
+	db       *dbsql.DB
+	cipher   *Cipher
+	blobs    blob.Store
+	metrics  *Metrics
+	sessions time.Duration
+	reflen   int
+}
+
+// NewSQLiteStorage opens the sqlite database at the dsn of the storage config, creating
+// it if it doesn't exist yet, and applies the pending migrations.
+func NewSQLiteStorage(ctx context.Context, cfg config.Config) (*SQLiteStorage, error) {
+	var err error
+	ctx, finish := tracing.FromContext(ctx, trace.SpanKindInternal, "storage.NewSQLiteStorage")
+	defer finish(&err)
+
+	logger := logging.FromContext(ctx)
+	logger.Info("storage.sqlite", "initializing sqlite storage", "dsn", cfg.Storage.DSN)
+
+	db, err := opensqlite(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	migrated, err := sqlite.Migrate(ctx, db)
+	if err != nil {
+		db.Close()
+		return nil, errors.Wrap(err, "failed to migrate database")
+	}
+
+	if len(migrated) > 0 {
+		logger.Info("storage.sqlite", "applied migrations", "versions", migrated)
+	}
+
+	keyring, err := NewKeyring(cfg)
+	if err != nil {
+		db.Close()
+		return nil, errors.Wrap(err, "invalid encryption keys")
+	}
+
+	blobs, err := blob.Open(cfg.Storage.Blobs)
+	if err != nil {
+		db.Close()
+		return nil, errors.Wrap(err, "failed to open blob store")
+	}
+
+	met := new(Metrics)
+	if err = metrics.FromContext(ctx).Register(met); err != nil {
+		db.Close()
+		return nil, errors.Wrap(err, "registering metrics")
+	}
+
+	return &SQLiteStorage{
+		db:       db,
+		cipher:   keyring,
+		blobs:    blobs,
+		metrics:  met,
+		sessions: cfg.SessionDuration,
+		reflen:   referenceLength(cfg.ReferenceLength),
+	}, nil
+}
+
+// opensqlite opens the sqlite database of the config, creating it if it doesn't exist yet.
+func opensqlite(ctx context.Context, cfg config.Config) (*dbsql.DB, error) {
+	if cfg.Storage.DSN == "" {
+		return nil, errors.New("the sqlite storage requires a dsn")
+	}
+
+	db, err := dbsql.Open("sqlite", cfg.Storage.DSN)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to open database")
+	}
+
+	// sqlite handles a single writer at a time, sharing one connection avoids lock
+	// errors and keeps in memory databases around for as long as the storage is open
+	db.SetMaxOpenConns(1)
+	db.SetConnMaxLifetime(0)
+	db.SetConnMaxIdleTime(0)
+
+	if _, err = db.ExecContext(ctx, "pragma foreign_keys = on; pragma journal_mode = wal; pragma busy_timeout = 5000"); err != nil {
+		db.Close()
+		return nil, errors.Wrap(err, "failed to configure database")
+	}
+
+	return db, nil
+}
+
+// Close closes the underlying database.
+func (s *SQLiteStorage) Close() error {
+	return s.db.Close()
+}
+
+// CreatePaste stores a new paste, returning its reference and the secret token
+// that authorizes its owner to edit or delete it.
+func (s *SQLiteStorage) CreatePaste(ctx context.Context, p paste.Paste) (string, string, error) {
+	var err error
+	ctx, finish := tracing.FromContext(ctx, trace.SpanKindInternal, "SQLiteStorage.CreatePaste")
+	defer finish(&err)
+
+	// vanity slugs are used as the reference instead of a random one
+	var slug string
+	if p.Slug != "" {
+		if slug, err = vanity(p.Slug); err != nil {
+			return "", "", err
+		}
+	}
+
+	token, err := generateToken()
+	if err != nil {
+		s.metrics.PasteErrors.Add(ctx, 1)
+		return "", "", errors.Wrap(err, "failed to generate token")
+	}
+
+	// the contents of attachments go to the blob store before the paste is stored,
+	// they're removed again if storing it fails
+	attachments, err := putattachments(ctx, s.blobs, s.cipher, p.Attachments)
+	if err != nil {
+		s.metrics.PasteErrors.Add(ctx, 1)
+		return "", "", err
+	}
+	defer func() {
+		if err != nil {
+			dropattachments(ctx, s.blobs, attachments)
+		}
+	}()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		s.metrics.PasteErrors.Add(ctx, 1)
+		return "", "", errors.Wrap(err, "failed to begin transaction")
+	}
+	defer tx.Rollback()
+
+	// forks can only be created from pastes that are still around
+	if p.Parent != nil {
+		if _, err = s.peek(ctx, tx, *p.Parent); err != nil {
+			s.failed(ctx, err)
+			if errors.Is(err, pgx.ErrNoRows) {
+				return "", "", errors.Wrapf(errors.ErrBadRequest, "parent paste %s not found", *p.Parent)
+			}
+			return "", "", errors.Wrap(err, "failed to fetch parent paste")
+		}
+	}
+
+	p.Flatten()
+
+	// the search index is built from the plaintext paste
+	plain := p
+
+	// the content of protected pastes is only readable with the password or the owner token,
+	// client encrypted ones can't be read by the server in the first place
+	if p.Password != nil {
+		if !p.ClientEncrypted {
+			if p.Content, err = s.cipher.Lock(p.Content, *p.Password, token); err != nil {
+				return "", "", errors.Wrap(err, "failed to lock content")
+			}
+		}
+
+		hash := s.cipher.Hash(*p.Password)
+		p.Password = &hash
+	}
+
+	files, err := s.cipher.sealfiles(p.Files, p.Content, token, p.ClientEncrypted)
+	if err != nil {
+		return "", "", errors.Wrap(err, "failed to encrypt files")
+	}
+
+	if err = s.encrypt(&p); err != nil {
+		return "", "", errors.Wrap(err, "failed to encrypt data")
+	}
+
+	var owner *int64
+	if p.Owner != nil {
+		owner = &p.Owner.ID
+	}
+
+	id, ref, err := s.insert(ctx, tx, p, owner, s.cipher.Hash(token), slug, 10)
+	if err != nil {
+		s.metrics.PasteErrors.Add(ctx, 1)
+		return "", "", err
+	}
+
+	if err = s.storefiles(ctx, tx, id, files); err != nil {
+		s.metrics.PasteErrors.Add(ctx, 1)
+		return "", "", err
+	}
+
+	if err = s.storeattachments(ctx, tx, id, attachments); err != nil {
+		s.metrics.PasteErrors.Add(ctx, 1)
+		return "", "", err
+	}
+
+	if err = s.index(ctx, tx, id, plain); err != nil {
+		s.metrics.PasteErrors.Add(ctx, 1)
+		return "", "", err
+	}
+
+	if err = tx.Commit(); err != nil {
+		s.metrics.PasteErrors.Add(ctx, 1)
+		return "", "", errors.Wrap(err, "failed to commit transaction")
+	}
+
+	s.metrics.PasteCreated.Add(ctx, 1)
+	s.metrics.PasteSize.Record(ctx, int64(len(p.Content)))
+
+	return ref, token, nil
+}
+
+// GetPaste retrieves a paste by its reference, counting the read.
+// Password protected pastes are only peeked at, the read is counted once the
+// password has been verified via GetPasteWithPassword.
+func (s *SQLiteStorage) GetPaste(ctx context.Context, ref string) (paste.Paste, error) {
+	var err error
+	ctx, finish := tracing.FromContext(ctx, trace.SpanKindInternal, "SQLiteStorage.GetPaste")
+	defer finish(&err)
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return paste.Paste{}, errors.Wrap(err, "failed to begin transaction")
+	}
+	defer tx.Rollback()
+
+	row, err := s.peek(ctx, tx, ref)
+	if err == nil && !row.password.Valid {
+		row, err = s.read(ctx, tx, ref)
+	}
+
+	if err != nil {
+		s.failed(ctx, err)
+		return paste.Paste{}, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		s.metrics.PasteErrors.Add(ctx, 1)
+		return paste.Paste{}, errors.Wrap(err, "failed to commit transaction")
+	}
+
+	p, err := s.document(ctx, s.db, row)
+	if err != nil {
+		return paste.Paste{}, err
+	}
+
+	s.metrics.PasteRetrieved.Add(ctx, 1)
+
+	return p, nil
+}
+
+// GetPasteWithPassword retrieves a password protected paste, counting the read.
+// Returns nil if the password doesn't match.
+func (s *SQLiteStorage) GetPasteWithPassword(ctx context.Context, ref, password string) (*paste.Paste, error) {
+	var err error
+	ctx, finish := tracing.FromContext(ctx, trace.SpanKindInternal, "SQLiteStorage.GetPasteWithPassword")
+	defer finish(&err)
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to begin transaction")
+	}
+	defer tx.Rollback()
+
+	row, err := s.peek(ctx, tx, ref)
+	if err != nil {
+		s.failed(ctx, err)
+		return nil, errors.Wrap(err, "failed to get paste")
+	}
+
+	if !row.password.Valid {
+		return nil, errors.Errorf("paste %s doesn't have a password", ref)
+	}
+
+	if !s.cipher.Verify(password, row.password.String) {
+		return nil, nil
+	}
+
+	// count the read, burning the paste if it reached its limit
+	row, err = s.read(ctx, tx, ref)
+	if err != nil {
+		s.failed(ctx, err)
+		return nil, errors.Wrap(err, "failed to get paste")
+	}
+
+	if err = tx.Commit(); err != nil {
+		s.metrics.PasteErrors.Add(ctx, 1)
+		return nil, errors.Wrap(err, "failed to commit transaction")
+	}
+
+	p, err := s.document(ctx, s.db, row, password)
+	if err != nil {
+		return nil, err
+	}
+
+	s.metrics.PasteRetrieved.Add(ctx, 1)
+
+	return &p, nil
+}
+
+// PeekPaste retrieves a paste without counting the read, so looking at it never burns it.
+// Returns nil if the paste is password protected and the password doesn't match.
+func (s *SQLiteStorage) PeekPaste(ctx context.Context, ref, password string) (*paste.Paste, error) {
+	var err error
+	ctx, finish := tracing.FromContext(ctx, trace.SpanKindInternal, "SQLiteStorage.PeekPaste")
+	defer finish(&err)
+
+	row, err := s.peek(ctx, s.db, ref)
+	if err != nil {
+		s.failed(ctx, err)
+		return nil, err
+	}
+
+	if row.password.Valid && !s.cipher.Verify(password, row.password.String) {
+		return nil, nil
+	}
+
+	p, err := s.document(ctx, s.db, row, password)
+	if err != nil {
+		return nil, err
+	}
+
+	return &p, nil
+}
+
+// GetPasteWithToken retrieves a paste on behalf of its owner.
+// The password is not required and the read is not counted.
+func (s *SQLiteStorage) GetPasteWithToken(ctx context.Context, ref, token string) (paste.Paste, error) {
+	var err error
+	ctx, finish := tracing.FromContext(ctx, trace.SpanKindInternal, "SQLiteStorage.GetPasteWithToken")
+	defer finish(&err)
+
+	row, err := s.authorize(ctx, s.db, ref, token)
+	if err != nil {
+		return paste.Paste{}, err
+	}
+
+	return s.document(ctx, s.db, row, token)
+}
+
+// ListPastes returns a page of at most limit public pastes matching the filter, newest first.
+// Pages are keyset paginated on the creation time and id of the pastes, the same way the
+// postgres storage does it.
+func (s *SQLiteStorage) ListPastes(ctx context.Context, filter paste.Filter, after string, limit int) (paste.Page, error) {
+	var err error
+	ctx, finish := tracing.FromContext(ctx, trace.SpanKindInternal, "SQLiteStorage.ListPastes")
+	defer finish(&err)
+
+	var page paste.Page
+
+	var createdafter, idafter any
+	if after != "" {
+		c, err := decodeCursor(after)
+		if err != nil {
+			return page, errors.NewHTTPError(http.StatusBadRequest, "invalid cursor", err)
+		}
+		createdafter, idafter = sqlitetime(&c.created), c.id
+	}
+
+	rows, err := s.pastes(
+		ctx,
+		`where pastes.public = true and `+sqliteLive+`
+			and (@tag is null or exists (select 1 from json_each(pastes.tags) where json_each.value = @tag))
+			and (@syntax is null or pastes.syntax = @syntax)
+			and (@since is null or pastes.created_at >= @since)
+			and (@until is null or pastes.created_at < @until)
+			and (@cursor_created_at is null or (pastes.created_at, pastes.id) < (@cursor_created_at, @cursor_id))
+		order by pastes.created_at desc, pastes.id desc
+		limit @page_size`,
+		dbsql.Named("now", sqlitenow()),
+		dbsql.Named("tag", nullable(filter.Tag)),
+		dbsql.Named("syntax", nullable(filter.Syntax)),
+		dbsql.Named("since", sqlitetime(filter.Since)),
+		dbsql.Named("until", sqlitetime(filter.Until)),
+		dbsql.Named("cursor_created_at", createdafter),
+		dbsql.Named("cursor_id", idafter),
+		// fetch one extra paste to know whether there's a next page
+		dbsql.Named("page_size", limit+1),
+	)
+	if err != nil {
+		return page, errors.Wrap(err, "failed to list public pastes")
+	}
+
+	if len(rows) > limit {
+		rows = rows[:limit]
+		last := rows[limit-1]
+		page.Next = cursor{created: last.created, id: last.id}.encode()
+	}
+
+	page.Pastes = s.domains(rows)
+
+	return page, nil
+}
+
+// ListForks returns the public pastes forked from the paste with the given reference.
+func (s *SQLiteStorage) ListForks(ctx context.Context, ref string) ([]paste.Paste, error) {
+	var err error
+	ctx, finish := tracing.FromContext(ctx, trace.SpanKindInternal, "SQLiteStorage.ListForks")
+	defer finish(&err)
+
+	rows, err := s.pastes(
+		ctx,
+		`where pastes.parent = @parent and pastes.public = true and `+sqliteLive+`
+		order by pastes.created_at desc`,
+		dbsql.Named("parent", ref),
+		dbsql.Named("now", sqlitenow()),
+	)
+	if err != nil {
+		s.metrics.PasteErrors.Add(ctx, 1)
+		return nil, errors.Wrap(err, "failed to list forks")
+	}
+
+	return s.domains(rows), nil
+}
+
+// ListOwnerPastes returns every paste of the user, newest first, including unlisted,
+// expired and burned ones, while pastes deleted on purpose are left out.
+func (s *SQLiteStorage) ListOwnerPastes(ctx context.Context, account user.User) ([]paste.Paste, error) {
+	var err error
+	ctx, finish := tracing.FromContext(ctx, trace.SpanKindInternal, "SQLiteStorage.ListOwnerPastes")
+	defer finish(&err)
+
+	rows, err := s.pastes(
+		ctx,
+		`where pastes.owner_id = @owner_id
+			and (
+				pastes.deleted_at is null
+				or (pastes.expiration is not null and pastes.deleted_at >= pastes.expiration)
+				or (pastes.burn_after is not null and coalesce(pastes.views, 0) >= pastes.burn_after)
+			)
+		order by pastes.created_at desc`,
+		dbsql.Named("owner_id", account.ID),
+	)
+	if err != nil {
+		s.metrics.PasteErrors.Add(ctx, 1)
+		return nil, errors.Wrap(err, "failed to list owner pastes")
+	}
+
+	pastes := s.domains(rows)
+	for i := range pastes {
+		pastes[i].Owner = &account
+	}
+
+	return pastes, nil
+}
+
+// DeleteOwnerPastes soft deletes the pastes of the user with the given references at once.
+// References of pastes owned by someone else are ignored. Returns the number of deleted pastes.
+func (s *SQLiteStorage) DeleteOwnerPastes(ctx context.Context, account user.User, refs []string) (int64, error) {
+	var err error
+	ctx, finish := tracing.FromContext(ctx, trace.SpanKindInternal, "SQLiteStorage.DeleteOwnerPastes")
+	defer finish(&err)
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		s.metrics.PasteErrors.Add(ctx, 1)
+		return 0, errors.Wrap(err, "failed to begin transaction")
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(
+		ctx,
+		`update pastes
+		set deleted_at = @now
+		where owner_id = @owner_id
+			and reference in (select value from json_each(@refs))
+			and deleted_at is null`,
+		dbsql.Named("now", sqlitenow()),
+		dbsql.Named("owner_id", account.ID),
+		dbsql.Named("refs", jsonarray(refs)),
+	)
+	if err != nil {
+		s.metrics.PasteErrors.Add(ctx, 1)
+		return 0, errors.Wrap(err, "failed to delete owner pastes")
+	}
+
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to delete owner pastes")
+	}
+
+	// deleted pastes don't stay searchable, nor readable in the plaintext index
+	if err = s.unindexdeleted(ctx, tx); err != nil {
+		s.metrics.PasteErrors.Add(ctx, 1)
+		return 0, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		s.metrics.PasteErrors.Add(ctx, 1)
+		return 0, errors.Wrap(err, "failed to commit transaction")
+	}
+
+	s.metrics.PasteDeleted.Add(ctx, deleted)
+
+	return deleted, nil
+}
+
+// UpdatePaste replaces the title, content, syntax, tags and files of a paste, authorized
+// by the token returned on creation. The previous state is kept as a revision, updates
+// that don't change anything are ignored.
+func (s *SQLiteStorage) UpdatePaste(ctx context.Context, ref, token string, p paste.Paste) error {
+	var err error
+	ctx, finish := tracing.FromContext(ctx, trace.SpanKindInternal, "SQLiteStorage.UpdatePaste")
+	defer finish(&err)
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		s.metrics.PasteErrors.Add(ctx, 1)
+		return errors.Wrap(err, "failed to begin transaction")
+	}
+	defer tx.Rollback()
+
+	row, err := s.authorize(ctx, tx, ref, token)
+	if err != nil {
+		return err
+	}
+
+	current, err := s.document(ctx, tx, row, token)
+	if err != nil {
+		return err
+	}
+
+	return s.update(ctx, tx, row, token, current, p)
+}
+
+// UpdateOwnerPaste replaces the title, content, syntax, tags and files of a paste on behalf
+// of the user owning it, without its owner token. Password protected pastes are locked with
+// their owner token, so they can only be edited with it.
+func (s *SQLiteStorage) UpdateOwnerPaste(ctx context.Context, account user.User, ref string, p paste.Paste) error {
+	var err error
+	ctx, finish := tracing.FromContext(ctx, trace.SpanKindInternal, "SQLiteStorage.UpdateOwnerPaste")
+	defer finish(&err)
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		s.metrics.PasteErrors.Add(ctx, 1)
+		return errors.Wrap(err, "failed to begin transaction")
+	}
+	defer tx.Rollback()
+
+	row, err := s.peek(ctx, tx, ref)
+	if err != nil {
+		s.failed(ctx, err)
+		return err
+	}
+
+	current, err := s.document(ctx, tx, row)
+	if err != nil {
+		return err
+	}
+
+	if err = owns(current, account); err != nil {
+		return err
+	}
+
+	return s.update(ctx, tx, row, "", current, p)
+}
+
+// update stores the edit of the current paste, authorized already, keeping the current
+// state as a revision, and commits the transaction. The token, if any, relocks the
+// content of protected pastes.
+func (s *SQLiteStorage) update(ctx context.Context, tx *dbsql.Tx, row sqlitePaste, token string, current, p paste.Paste) error {
+	var err error
+
+	amend(&p, current)
+
+	// pastes stay client encrypted, the new content has to be encrypted by the client as well
+	p.ClientEncrypted = current.ClientEncrypted
+	if p.ClientEncrypted {
+		if err = p.Validate(); err != nil {
+			return errors.NewHTTPError(http.StatusBadRequest, err.Error(), err)
+		}
+	}
+
+	if current.Title == p.Title &&
+		current.Content == p.Content &&
+		current.Syntax == p.Syntax &&
+		slices.Equal(current.Tags, p.Tags) &&
+		slices.Equal(current.Files, p.Files) {
+		return nil
+	}
+
+	// the search index is built from the plaintext paste, keeping the visibility of the current one
+	plain := p
+	plain.Public, plain.Password = current.Public, current.Password
+
+	// protected pastes stay locked with their password and owner token
+	if !current.ClientEncrypted {
+		if p.Content, err = s.cipher.relock(row.content, token, p.Content); err != nil {
+			return errors.Wrap(err, "failed to lock content")
+		}
+	}
+
+	files, err := s.cipher.sealfiles(p.Files, p.Content, token, p.ClientEncrypted)
+	if err != nil {
+		return errors.Wrap(err, "failed to encrypt files")
+	}
+
+	if err = s.encrypt(&p); err != nil {
+		return errors.Wrap(err, "failed to encrypt data")
+	}
+
+	_, err = tx.ExecContext(
+		ctx,
+		`insert into paste_revisions (paste_id, revision, title, content, syntax, tags, created_at)
+		select id,
+			(select coalesce(max(revision), 0) + 1 from paste_revisions where paste_id = pastes.id),
+			title, content, syntax, tags, coalesce(updated_at, created_at)
+		from pastes
+		where id = @id`,
+		dbsql.Named("id", row.id),
+	)
+	if err != nil {
+		s.metrics.PasteErrors.Add(ctx, 1)
+		return errors.Wrap(err, "failed to store revision")
+	}
+
+	_, err = tx.ExecContext(
+		ctx,
+		`update pastes
+		set title = @title, content = @content, syntax = @syntax, tags = @tags, updated_at = @now
+		where id = @id`,
+		dbsql.Named("title", p.Title),
+		dbsql.Named("content", p.Content),
+		dbsql.Named("syntax", nullable(p.Syntax)),
+		dbsql.Named("tags", jsonarray(p.Tags)),
+		dbsql.Named("now", sqlitenow()),
+		dbsql.Named("id", row.id),
+	)
+	if err != nil {
+		s.metrics.PasteErrors.Add(ctx, 1)
+		return errors.Wrap(err, "failed to update paste")
+	}
+
+	if err = s.storefiles(ctx, tx, row.id, files); err != nil {
+		s.metrics.PasteErrors.Add(ctx, 1)
+		return err
+	}
+
+	if err = s.index(ctx, tx, row.id, plain); err != nil {
+		s.metrics.PasteErrors.Add(ctx, 1)
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
+		s.metrics.PasteErrors.Add(ctx, 1)
+		return errors.Wrap(err, "failed to commit transaction")
+	}
+
+	s.metrics.PasteUpdated.Add(ctx, 1)
+	s.metrics.PasteSize.Record(ctx, int64(len(p.Content)))
+
+	return nil
+}
+
+// DeletePaste soft deletes a paste, authorized by the token returned on creation.
+func (s *SQLiteStorage) DeletePaste(ctx context.Context, ref, token string) error {
+	var err error
+	ctx, finish := tracing.FromContext(ctx, trace.SpanKindInternal, "SQLiteStorage.DeletePaste")
+	defer finish(&err)
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		s.metrics.PasteErrors.Add(ctx, 1)
+		return errors.Wrap(err, "failed to begin transaction")
+	}
+	defer tx.Rollback()
+
+	row, err := s.authorize(ctx, tx, ref, token)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(
+		ctx,
+		"update pastes set deleted_at = @now where id = @id",
+		dbsql.Named("now", sqlitenow()),
+		dbsql.Named("id", row.id),
+	)
+	if err != nil {
+		s.metrics.PasteErrors.Add(ctx, 1)
+		return errors.Wrap(err, "failed to delete paste")
+	}
+
+	if err = s.unindex(ctx, tx, row.id); err != nil {
+		s.metrics.PasteErrors.Add(ctx, 1)
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
+		s.metrics.PasteErrors.Add(ctx, 1)
+		return errors.Wrap(err, "failed to commit transaction")
+	}
+
+	s.metrics.PasteDeleted.Add(ctx, 1)
+
+	return nil
+}
+
+// ListRevisions returns every revision of a paste, oldest first.
+// The last revision is always the current state of the paste. The content of password
+// protected pastes is only revealed when the secret is their password or owner token.
+func (s *SQLiteStorage) ListRevisions(ctx context.Context, ref, secret string) ([]paste.Revision, error) {
+	var err error
+	ctx, finish := tracing.FromContext(ctx, trace.SpanKindInternal, "SQLiteStorage.ListRevisions")
+	defer finish(&err)
+
+	row, err := s.peek(ctx, s.db, ref)
+	if err != nil {
+		s.failed(ctx, err)
+		return nil, err
+	}
+
+	revisions, err := s.revisions(ctx, "where paste_id = @paste_id order by revision", dbsql.Named("paste_id", row.id))
+	if err != nil {
+		s.metrics.PasteErrors.Add(ctx, 1)
+		return nil, errors.Wrap(err, "failed to list revisions")
+	}
+	revisions = append(revisions, row.revision(len(revisions)+1))
+
+	// the revisions are locked with the same key, which is only derived once
+	unlock := s.cipher.unlocker(secret)
+	for i := range revisions {
+		revisions[i].ClientEncrypted = row.encrypted
+		if err = s.decryptRevision(&revisions[i], unlock); err != nil {
+			return nil, errors.Wrap(err, "failed to decrypt data")
+		}
+	}
+
+	return revisions, nil
+}
+
+// GetRevision returns a single revision of a paste by its number.
+// The content of password protected pastes is only revealed when the secret is their
+// password or owner token.
+func (s *SQLiteStorage) GetRevision(ctx context.Context, ref string, number int, secret string) (paste.Revision, error) {
+	var err error
+	ctx, finish := tracing.FromContext(ctx, trace.SpanKindInternal, "SQLiteStorage.GetRevision")
+	defer finish(&err)
+
+	row, err := s.peek(ctx, s.db, ref)
+	if err != nil {
+		s.failed(ctx, err)
+		return paste.Revision{}, err
+	}
+
+	var count int
+	err = s.db.QueryRowContext(ctx, "select count(*) from paste_revisions where paste_id = ?", row.id).Scan(&count)
+	if err != nil {
+		s.metrics.PasteErrors.Add(ctx, 1)
+		return paste.Revision{}, errors.Wrap(err, "failed to count revisions")
+	}
+
+	var revision paste.Revision
+
+	switch {
+	case number == count+1:
+		revision = row.revision(number)
+	case number >= 1 && number <= count:
+		revisions, err := s.revisions(
+			ctx, "where paste_id = @paste_id and revision = @revision",
+			dbsql.Named("paste_id", row.id),
+			dbsql.Named("revision", number),
+		)
+		if err != nil {
+			s.metrics.PasteErrors.Add(ctx, 1)
+			return paste.Revision{}, errors.Wrap(err, "failed to get revision")
+		}
+		if len(revisions) == 0 {
+			s.failed(ctx, pgx.ErrNoRows)
+			return paste.Revision{}, pgx.ErrNoRows
+		}
+		revision = revisions[0]
+		revision.ClientEncrypted = row.encrypted
+	default:
+		s.failed(ctx, pgx.ErrNoRows)
+		return paste.Revision{}, pgx.ErrNoRows
+	}
+
+	if err = s.decryptRevision(&revision, s.cipher.unlocker(secret)); err != nil {
+		return paste.Revision{}, errors.Wrap(err, "failed to decrypt data")
+	}
+
+	return revision, nil
+}
+
+// SearchPastes runs a full-text search over the public pastes, returning at most limit
+// of them, newest first. Every word of the query has to match, words prefixed with a
+// dash exclude the pastes containing them and quoted phrases have to match as a whole.
+// Password protected pastes only have their title and tags indexed.
+func (s *SQLiteStorage) SearchPastes(ctx context.Context, query string, limit int) ([]paste.Paste, error) {
+	var err error
+	ctx, finish := tracing.FromContext(ctx, trace.SpanKindInternal, "SQLiteStorage.SearchPastes")
+	defer finish(&err)
+
+	match := ftsquery(query)
+	if match == "" {
+		return []paste.Paste{}, nil
+	}
+
+	rows, err := s.pastes(
+		ctx,
+		`where pastes.id in (select rowid from paste_search where paste_search match @query)
+			and pastes.public = true and `+sqliteLive+`
+		order by pastes.created_at desc
+		limit @page_size`,
+		dbsql.Named("query", match),
+		dbsql.Named("now", sqlitenow()),
+		dbsql.Named("page_size", limit),
+	)
+	if err != nil {
+		s.metrics.PasteErrors.Add(ctx, 1)
+		return nil, errors.Wrap(err, "failed to search pastes")
+	}
+
+	return s.domains(rows), nil
+}
+
+// ReindexPastes adds the public pastes missing from the search index and drops the
+// documents of deleted pastes left behind by older versions.
+// Returns the number of indexed pastes.
+func (s *SQLiteStorage) ReindexPastes(ctx context.Context) (int, error) {
+	var err error
+	ctx, finish := tracing.FromContext(ctx, trace.SpanKindInternal, "SQLiteStorage.ReindexPastes")
+	defer finish(&err)
+
+	if err = s.unindexdeleted(ctx, s.db); err != nil {
+		return 0, err
+	}
+
+	rows, err := s.pastes(
+		ctx,
+		`where pastes.public = true and pastes.deleted_at is null
+			and pastes.id not in (select rowid from paste_search)`,
+	)
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to list unindexed pastes")
+	}
+
+	indexed := 0
+	for _, row := range rows {
+		p, err := s.domain(row, s.cipher.unlocker())
+		if err != nil {
+			logging.
+				FromContext(ctx).
+				Error(err, "storage.search", "failed to decrypt paste", "ref", row.reference)
+			continue
+		}
+
+		if err = s.index(ctx, s.db, row.id, p); err != nil {
+			return indexed, err
+		}
+		indexed++
+	}
+
+	return indexed, nil
+}
+
+// ListTags returns at most limit tags used on public pastes starting with the prefix,
+// most used first.
+func (s *SQLiteStorage) ListTags(ctx context.Context, prefix string, limit int) ([]paste.Tag, error) {
+	var err error
+	ctx, finish := tracing.FromContext(ctx, trace.SpanKindInternal, "SQLiteStorage.ListTags")
+	defer finish(&err)
+
+	rows, err := s.db.QueryContext(
+		ctx,
+		`select tag.value, count(*) as pastes
+		from pastes, json_each(pastes.tags) as tag
+		where pastes.public = true and `+sqliteLive+`
+			and (@prefix is null or substr(tag.value, 1, length(@prefix)) = @prefix)
+		group by tag.value
+		order by pastes desc, tag.value
+		limit @page_size`,
+		dbsql.Named("now", sqlitenow()),
+		dbsql.Named("prefix", nullable(prefix)),
+		dbsql.Named("page_size", limit),
+	)
+	if err != nil {
+		s.metrics.PasteErrors.Add(ctx, 1)
+		return nil, errors.Wrap(err, "failed to list tags")
+	}
+	defer rows.Close()
+
+	tags := make([]paste.Tag, 0)
+	for rows.Next() {
+		var tag paste.Tag
+		if err = rows.Scan(&tag.Name, &tag.Pastes); err != nil {
+			return nil, errors.Wrap(err, "failed to list tags")
+		}
+		tags = append(tags, tag)
+	}
+
+	return tags, rows.Err()
+}
+
+// ReapExpiredPastes soft deletes every expired paste, returning how many were deleted.
+func (s *SQLiteStorage) ReapExpiredPastes(ctx context.Context) (int64, error) {
+	var err error
+	ctx, finish := tracing.FromContext(ctx, trace.SpanKindInternal, "SQLiteStorage.ReapExpiredPastes")
+	defer finish(&err)
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		s.metrics.PasteErrors.Add(ctx, 1)
+		return 0, errors.Wrap(err, "failed to begin transaction")
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(
+		ctx,
+		"update pastes set deleted_at = @now where expiration <= @now and deleted_at is null",
+		dbsql.Named("now", sqlitenow()),
+	)
+	if err != nil {
+		s.metrics.PasteErrors.Add(ctx, 1)
+		return 0, errors.Wrap(err, "failed to reap expired pastes")
+	}
+
+	reaped, err := result.RowsAffected()
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to reap expired pastes")
+	}
+
+	if err = s.unindexdeleted(ctx, tx); err != nil {
+		s.metrics.PasteErrors.Add(ctx, 1)
+		return 0, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		s.metrics.PasteErrors.Add(ctx, 1)
+		return 0, errors.Wrap(err, "failed to commit transaction")
+	}
+
+	s.metrics.PasteReaped.Add(ctx, reaped)
+
+	return reaped, nil
+}
+
+// sqliteQuerier is implemented by both the database and its transactions.
+type sqliteQuerier interface {
+	ExecContext(context.Context, string, ...any) (dbsql.Result, error)
+	QueryContext(context.Context, string, ...any) (*dbsql.Rows, error)
+	QueryRowContext(context.Context, string, ...any) *dbsql.Row
+}
+
+// sqlitePaste is a row of the pastes table, along with the username of its owner.
+type sqlitePaste struct {
+	id         int64
+	reference  string
+	title      string
+	content    string
+	syntax     dbsql.NullString
+	tags       dbsql.NullString
+	expiration dbsql.NullString
+	public     bool
+	created    time.Time
+	updated    dbsql.NullString
+	deleted    dbsql.NullString
+	views      dbsql.NullInt64
+	password   dbsql.NullString
+	burnafter  dbsql.NullInt64
+	token      dbsql.NullString
+	parent     dbsql.NullString
+	owner      dbsql.NullInt64
+	username   dbsql.NullString
+	encrypted  bool
+}
+
+// pastes returns the pastes matching the clauses following the from clause.
+func (s *SQLiteStorage) pastes(ctx context.Context, clauses string, args ...any) ([]sqlitePaste, error) {
+	return queryPastes(ctx, s.db, clauses, args...)
+}
+
+func queryPastes(ctx context.Context, db sqliteQuerier, clauses string, args ...any) ([]sqlitePaste, error) {
+	rows, err := db.QueryContext(ctx, "select "+sqlitePasteColumns+" from "+sqlitePastes+" "+clauses, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var pastes []sqlitePaste
+	for rows.Next() {
+		var (
+			row     sqlitePaste
+			created string
+		)
+
+		err := rows.Scan(
+			&row.id, &row.reference, &row.title, &row.content, &row.syntax, &row.tags,
+			&row.expiration, &row.public, &created, &row.updated, &row.deleted, &row.views,
+			&row.password, &row.burnafter, &row.token, &row.parent, &row.owner, &row.username,
+			&row.encrypted,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		if row.created, err = time.Parse(sqliteTimeLayout, created); err != nil {
+			return nil, errors.Wrap(err, "invalid creation time")
+		}
+
+		pastes = append(pastes, row)
+	}
+
+	return pastes, rows.Err()
+}
+
+// peek returns the paste with the given reference, as long as it wasn't deleted and
+// didn't expire yet, without counting the read.
+func (s *SQLiteStorage) peek(ctx context.Context, db sqliteQuerier, ref string) (sqlitePaste, error) {
+	rows, err := queryPastes(
+		ctx, db,
+		"where pastes.reference = @reference and "+sqliteLive,
+		dbsql.Named("reference", ref),
+		dbsql.Named("now", sqlitenow()),
+	)
+	if err != nil {
+		return sqlitePaste{}, err
+	}
+
+	if len(rows) == 0 {
+		return sqlitePaste{}, pgx.ErrNoRows
+	}
+
+	return rows[0], nil
+}
+
+// read counts a read of the paste, burning it if it reached its read limit, and
+// returns the paste as it was right after the read. Burned pastes leave the search index.
+func (s *SQLiteStorage) read(ctx context.Context, tx *dbsql.Tx, ref string) (sqlitePaste, error) {
+	now := sqlitenow()
+
+	var id int64
+	err := tx.QueryRowContext(
+		ctx,
+		`update pastes
+		set views = coalesce(views, 0) + 1,
+			deleted_at = case
+				when burn_after is not null and coalesce(views, 0) + 1 >= burn_after then @now
+				else deleted_at
+			end
+		where reference = @reference
+			and deleted_at is null
+			and (expiration is null or expiration > @now)
+			and (burn_after is null or coalesce(views, 0) < burn_after)
+		returning id`,
+		dbsql.Named("reference", ref),
+		dbsql.Named("now", now),
+	).Scan(&id)
+	if err != nil {
+		return sqlitePaste{}, notfound(err)
+	}
+
+	rows, err := queryPastes(ctx, tx, "where pastes.id = @id", dbsql.Named("id", id))
+	if err != nil {
+		return sqlitePaste{}, err
+	}
+
+	if len(rows) == 0 {
+		return sqlitePaste{}, pgx.ErrNoRows
+	}
+
+	// burned pastes are dropped from the search index along with the read
+	if rows[0].deleted.Valid {
+		if err = s.unindex(ctx, tx, id); err != nil {
+			return sqlitePaste{}, err
+		}
+	}
+
+	return rows[0], nil
+}
+
+// authorize verifies the token against the hash stored for the paste, returning the paste.
+func (s *SQLiteStorage) authorize(ctx context.Context, db sqliteQuerier, ref, token string) (sqlitePaste, error) {
+	row, err := s.peek(ctx, db, ref)
+	if err != nil {
+		s.failed(ctx, err)
+		return sqlitePaste{}, err
+	}
+
+	if !row.token.Valid || token == "" || !s.cipher.Verify(token, row.token.String) {
+		return sqlitePaste{}, errors.Wrapf(errors.ErrForbidden, "invalid token for paste %s", ref)
+	}
+
+	return row, nil
+}
+
+// index stores the plaintext paste in the search index.
+// Private pastes are skipped, only their encrypted form is ever stored.
+func (s *SQLiteStorage) index(ctx context.Context, db sqliteQuerier, id int64, p paste.Paste) error {
+	if !p.Public {
+		return nil
+	}
+
+	content := searchable(p)
+
+	if err := s.unindex(ctx, db, id); err != nil {
+		return err
+	}
+
+	_, err := db.ExecContext(
+		ctx,
+		"insert into paste_search (rowid, title, tags, content) values (?, ?, ?, ?)",
+		id, p.Title, strings.Join(p.Tags, " "), content,
+	)
+	if err != nil {
+		return errors.Wrapf(err, "failed to index paste %d", id)
+	}
+
+	return nil
+}
+
+// unindex drops the paste from the search index.
+func (s *SQLiteStorage) unindex(ctx context.Context, db sqliteQuerier, id int64) error {
+	if _, err := db.ExecContext(ctx, "delete from paste_search where rowid = ?", id); err != nil {
+		return errors.Wrapf(err, "failed to drop paste %d from the search index", id)
+	}
+	return nil
+}
+
+// unindexdeleted drops every deleted paste from the search index.
+func (s *SQLiteStorage) unindexdeleted(ctx context.Context, db sqliteQuerier) error {
+	_, err := db.ExecContext(
+		ctx,
+		"delete from paste_search where rowid in (select id from pastes where deleted_at is not null)",
+	)
+	if err != nil {
+		return errors.Wrap(err, "failed to drop deleted pastes from the search index")
+	}
+	return nil
+}
+
+// revisions returns the stored revisions matching the clauses following the from clause.
+func (s *SQLiteStorage) revisions(ctx context.Context, clauses string, args ...any) ([]paste.Revision, error) {
+	rows, err := s.db.QueryContext(
+		ctx,
+		"select revision, title, content, syntax, tags, created_at from paste_revisions "+clauses,
+		args...,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	revisions := make([]paste.Revision, 0)
+	for rows.Next() {
+		var (
+			revision     paste.Revision
+			syntax, tags dbsql.NullString
+			created      string
+		)
+
+		if err := rows.Scan(&revision.Number, &revision.Title, &revision.Content, &syntax, &tags, &created); err != nil {
+			return nil, err
+		}
+
+		revision.Syntax = "plaintext"
+		if syntax.Valid {
+			revision.Syntax = syntax.String
+		}
+
+		revision.Tags = fromjsonarray(tags)
+
+		if revision.Creation, err = time.Parse(sqliteTimeLayout, created); err != nil {
+			return nil, errors.Wrap(err, "invalid revision time")
+		}
+
+		revisions = append(revisions, revision)
+	}
+
+	return revisions, rows.Err()
+}
+
+// domain returns the decrypted paste.
+// The content of password protected pastes is only revealed if the unlocker opens it.
+func (s *SQLiteStorage) domain(row sqlitePaste, unlock *unlocker) (paste.Paste, error) {
+	p := row.toDomain()
+	if err := s.decrypt(&p, unlock); err != nil {
+		return paste.Paste{}, errors.Wrap(err, "failed to decrypt data")
+	}
+	return p, nil
+}
+
+// domains returns the decrypted pastes, skipping the ones that can't be decrypted.
+func (s *SQLiteStorage) domains(rows []sqlitePaste) []paste.Paste {
+	pastes := make([]paste.Paste, 0, len(rows))
+	for _, row := range rows {
+		p, err := s.domain(row, s.cipher.unlocker())
+		if err != nil {
+			continue
+		}
+		pastes = append(pastes, p)
+	}
+	return pastes
+}
+
+// failed records the error metric matching the error returned by a query.
+func (s *SQLiteStorage) failed(ctx context.Context, err error) {
+	if errors.Is(err, pgx.ErrNoRows) {
+		s.metrics.PasteNotFound.Add(ctx, 1)
+		return
+	}
+	s.metrics.PasteErrors.Add(ctx, 1)
+}
+
+// encrypt encrypts the title and content of the paste, the content of client encrypted
+// pastes is kept as is.
+func (s *SQLiteStorage) encrypt(p *paste.Paste) error {
+	var errt, errc error
+	p.Title, errt = s.cipher.Encrypt(p.Title)
+	if !p.ClientEncrypted {
+		p.Content, errc = s.cipher.Encrypt(p.Content)
+	}
+	return errors.Join(errt, errc)
+}
+
+func (s *SQLiteStorage) decrypt(p *paste.Paste, unlock *unlocker) error {
+	var errt, errc error
+	p.Title, errt = s.cipher.Decrypt(p.Title)
+	if !p.ClientEncrypted {
+		p.Content, errc = s.cipher.Decrypt(p.Content)
+		p.Content = unlock.reveal(p.Content)
+	}
+	return errors.Join(errt, errc)
+}
+
+func (s *SQLiteStorage) decryptRevision(revision *paste.Revision, unlock *unlocker) error {
+	var errt, errc error
+	revision.Title, errt = s.cipher.Decrypt(revision.Title)
+	if !revision.ClientEncrypted {
+		revision.Content, errc = s.cipher.Decrypt(revision.Content)
+		revision.Content = unlock.reveal(revision.Content)
+	}
+	return errors.Join(errt, errc)
+}
+
+// insert stores the paste under its slug, or under a newly generated reference when it
+// doesn't have one. References are random, so whenever one is already taken a new one
+// is generated, up to attempts times. Returns the id and the reference of the paste.
+func (s *SQLiteStorage) insert(ctx context.Context, tx *dbsql.Tx, p paste.Paste, owner *int64, token, slug string, attempts int) (int64, string, error) {
+	if slug != "" {
+		attempts = 1
+	}
+
+	for range attempts {
+		ref := slug
+		if ref == "" {
+			generated, err := generateReferenceIdentifier(s.reflen)
+			if err != nil {
+				continue
+			}
+			ref = generated
+		}
+
+		result, err := tx.ExecContext(
+			ctx,
+			`insert into pastes
+			(reference, title, content, syntax, tags, expiration, public, password, burn_after, token, parent, owner_id, client_encrypted, created_at)
+			values (@reference, @title, @content, @syntax, @tags, @expiration, @public, @password, @burn_after, @token, @parent, @owner_id, @client_encrypted, @now)`,
+			dbsql.Named("reference", ref),
+			dbsql.Named("title", p.Title),
+			dbsql.Named("content", p.Content),
+			dbsql.Named("syntax", nullable(p.Syntax)),
+			dbsql.Named("tags", jsonarray(p.Tags)),
+			dbsql.Named("expiration", sqlitetime(p.Expiration)),
+			dbsql.Named("public", p.Public),
+			dbsql.Named("password", p.Password),
+			dbsql.Named("burn_after", p.BurnAfter),
+			dbsql.Named("token", token),
+			dbsql.Named("parent", p.Parent),
+			dbsql.Named("owner_id", owner),
+			dbsql.Named("client_encrypted", p.ClientEncrypted),
+			dbsql.Named("now", sqlitenow()),
+		)
+		// unlike postgres, sqlite only rolls back the failed statement, not the transaction
+		if sqliteDuplicate(err) {
+			if slug != "" {
+				return 0, "", slugTaken(slug, err)
+			}
+			s.metrics.PasteReferenceCollisions.Add(ctx, 1)
+			continue
+		}
+		if err != nil {
+			return 0, "", errors.Wrap(err, "failed to create paste")
+		}
+
+		id, err := result.LastInsertId()
+		if err != nil {
+			return 0, "", errors.Wrap(err, "failed to create paste")
+		}
+
+		return id, ref, nil
+	}
+
+	return 0, "", errors.Errorf("failed to generate unique ref in %d attempts", attempts)
+}
+
+func (row sqlitePaste) toDomain() paste.Paste {
+	p := paste.Paste{
+		Reference: row.reference,
+		Title:     row.title,
+		Content:   row.content,
+		Syntax:    "plaintext",
+		Tags:      fromjsonarray(row.tags),
+		Creation:  row.created,
+		Public:    row.public,
+		Views:     int(row.views.Int64),
+
+		ClientEncrypted: row.encrypted,
+	}
+
+	if row.syntax.Valid {
+		p.Syntax = row.syntax.String
+	}
+
+	if row.expiration.Valid {
+		if expiration, err := time.Parse(sqliteTimeLayout, row.expiration.String); err == nil {
+			p.Expiration = &expiration
+		}
+	}
+
+	if row.password.Valid {
+		p.Password = &row.password.String
+	}
+
+	if row.burnafter.Valid {
+		reads := int(row.burnafter.Int64)
+		p.BurnAfter = &reads
+	}
+
+	if row.parent.Valid {
+		p.Parent = &row.parent.String
+	}
+
+	if row.owner.Valid {
+		p.Owner = &user.User{ID: row.owner.Int64, Username: row.username.String}
+	}
+
+	return p
+}
+
+// revision returns the current state of the paste, still encrypted, as its latest revision.
+func (row sqlitePaste) revision(number int) paste.Revision {
+	creation := row.created
+	if row.updated.Valid {
+		if updated, err := time.Parse(sqliteTimeLayout, row.updated.String); err == nil {
+			creation = updated
+		}
+	}
+
+	p := row.toDomain()
+
+	return paste.Revision{
+		Number:   number,
+		Title:    p.Title,
+		Content:  p.Content,
+		Syntax:   p.Syntax,
+		Tags:     p.Tags,
+		Creation: creation,
+
+		ClientEncrypted: p.ClientEncrypted,
+	}
+}
+
+// sqlitenow returns the current time the way the sqlite storage stores it.
+func sqlitenow() string {
+	return time.Now().UTC().Format(sqliteTimeLayout)
+}
+
+// sqlitetime returns the time the way the sqlite storage stores it, or nil.
+func sqlitetime(t *time.Time) any {
+	if t == nil {
+		return nil
+	}
+	return t.UTC().Format(sqliteTimeLayout)
+}
+
+// nullable returns nil for empty strings, so they are stored as null.
+func nullable(value string) any {
+	if value == "" {
+		return nil
+	}
+	return value
+}
+
+// jsonarray encodes the values as a json array, sqlite has no array type.
+func jsonarray(values []string) any {
+	if values == nil {
+		return nil
+	}
+	encoded, _ := json.Marshal(values)
+	return string(encoded)
+}
+
+func fromjsonarray(value dbsql.NullString) []string {
+	if !value.Valid {
+		return nil
+	}
+	var values []string
+	if err := json.Unmarshal([]byte(value.String), &values); err != nil {
+		return nil
+	}
+	return values
+}
+
+// ftsquery translates a web search like query into the fts5 query syntax.
+// Words are quoted so they can't be mistaken for operators.
+func ftsquery(query string) string {
+	var include, exclude []string
+
+	for _, term := range searchterms(query) {
+		negated := strings.HasPrefix(term, "-")
+		term = strings.Trim(strings.TrimPrefix(term, "-"), `"`)
+		if strings.TrimSpace(term) == "" {
+			continue
+		}
+
+		quoted := `"` + strings.ReplaceAll(term, `"`, "") + `"`
+		if negated {
+			exclude = append(exclude, "NOT "+quoted)
+		} else {
+			include = append(include, quoted)
+		}
+	}
+
+	// fts5 doesn't support queries made of exclusions only
+	if len(include) == 0 {
+		return ""
+	}
+
+	return strings.Join(append(include, exclude...), " ")
+}
+
+// searchterms splits the query on spaces, keeping quoted phrases together.
+func searchterms(query string) []string {
+	var (
+		terms   []string
+		current strings.Builder
+		quoted  bool
+	)
+
+	for _, r := range query {
+		switch {
+		case r == '"':
+			quoted = !quoted
+			current.WriteRune(r)
+		case r == ' ' && !quoted:
+			if current.Len() > 0 {
+				terms = append(terms, current.String())
+				current.Reset()
+			}
+		default:
+			current.WriteRune(r)
+		}
+	}
+
+	if current.Len() > 0 {
+		terms = append(terms, current.String())
+	}
+
+	return terms
+}
+
+// notfound translates the not found error of database/sql into the one returned
+// by every storage.
+func notfound(err error) error {
+	if errors.Is(err, dbsql.ErrNoRows) {
+		return pgx.ErrNoRows
+	}
+	return err
+}
+
+// sqliteDuplicate reports whether the error is a unique constraint violation.
+func sqliteDuplicate(err error) bool {
+	var sqlerr *sqlitedriver.Error
+	return errors.As(err, &sqlerr) && sqlerr.Code() == sqlite3.SQLITE_CONSTRAINT_UNIQUE
+}
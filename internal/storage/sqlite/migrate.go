@@ -0,0 +1,33 @@
+// Package sqlite holds the schema of the sqlite storage backend.
+//
+// The migrations mirror the postgres ones in internal/storage/sql/migrations, one
+// for one and with the same versions, adapted to what sqlite supports: arrays are
+// stored as json and the search index is an fts5 table. They are embedded in the
+// binary and applied when the storage is opened, so there's nothing else to run
+// when self-hosting with sqlite. The ones reverting them live in migrations/down.
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"io/fs"
+
+	"github.com/aexvir/skladka/internal/storage/migrate"
+)
+
+//go:embed migrations/*.sql migrations/down/*.sql
+var migrations embed.FS
+
+// Migrator returns the migrator of the sqlite schema.
+func Migrator(db *sql.DB) *migrate.Migrator {
+	files, _ := fs.Sub(migrations, "migrations")
+	return migrate.New(db, files)
+}
+
+// Migrate applies the migrations that weren't applied to the database yet, in order,
+// each one in its own transaction. Applied migrations are tracked in the
+// schema_migrations table. Returns the versions of the applied migrations.
+func Migrate(ctx context.Context, db *sql.DB) ([]string, error) {
+	return Migrator(db).Up(ctx)
+}
@@ -0,0 +1,2 @@
+-- Create "pastes" table
+CREATE TABLE `pastes` (`id` integer NOT NULL PRIMARY KEY AUTOINCREMENT, `reference` varchar NOT NULL, `title` varchar NOT NULL, `content` text NOT NULL, `syntax` varchar NULL, `tags` json NULL, `expiration` text NULL, `public` boolean NOT NULL DEFAULT true, `created_at` text NOT NULL, `updated_at` text NULL, `deleted_at` text NULL);
@@ -0,0 +1,2 @@
+-- Add column "views" to table: "pastes"
+ALTER TABLE `pastes` ADD COLUMN `views` integer NULL DEFAULT 0;
@@ -0,0 +1,2 @@
+-- Add column "password" to table: "pastes"
+ALTER TABLE `pastes` ADD COLUMN `password` text NULL;
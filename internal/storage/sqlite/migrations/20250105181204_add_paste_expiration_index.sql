@@ -0,0 +1,2 @@
+-- Create index "pastes_expiration_idx" to table: "pastes"
+CREATE INDEX `pastes_expiration_idx` ON `pastes` (`expiration`) WHERE deleted_at IS NULL;
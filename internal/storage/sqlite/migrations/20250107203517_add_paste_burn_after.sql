@@ -0,0 +1,2 @@
+-- Add column "burn_after" to table: "pastes"
+ALTER TABLE `pastes` ADD COLUMN `burn_after` integer NULL;
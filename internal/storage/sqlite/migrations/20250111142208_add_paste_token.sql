@@ -0,0 +1,2 @@
+-- Add column "token" to table: "pastes"
+ALTER TABLE `pastes` ADD COLUMN `token` text NULL;
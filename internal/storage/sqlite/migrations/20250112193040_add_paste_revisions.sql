@@ -0,0 +1,4 @@
+-- Create "paste_revisions" table
+CREATE TABLE `paste_revisions` (`id` integer NOT NULL PRIMARY KEY AUTOINCREMENT, `paste_id` integer NOT NULL, `revision` integer NOT NULL, `title` varchar NOT NULL, `content` text NOT NULL, `syntax` varchar NULL, `tags` json NULL, `created_at` text NOT NULL, CONSTRAINT `paste_revisions_paste_id_fkey` FOREIGN KEY (`paste_id`) REFERENCES `pastes` (`id`) ON UPDATE NO ACTION ON DELETE CASCADE);
+-- Create index "paste_revisions_paste_id_revision_idx" to table: "paste_revisions"
+CREATE UNIQUE INDEX `paste_revisions_paste_id_revision_idx` ON `paste_revisions` (`paste_id`, `revision`);
@@ -0,0 +1,4 @@
+-- Add column "parent" to table: "pastes"
+ALTER TABLE `pastes` ADD COLUMN `parent` varchar NULL;
+-- Create index "pastes_parent_idx" to table: "pastes"
+CREATE INDEX `pastes_parent_idx` ON `pastes` (`parent`) WHERE deleted_at IS NULL;
@@ -0,0 +1,12 @@
+-- Create "users" table
+CREATE TABLE `users` (`id` integer NOT NULL PRIMARY KEY AUTOINCREMENT, `username` varchar NOT NULL, `password` text NOT NULL, `created_at` text NOT NULL, `deleted_at` text NULL);
+-- Create index "users_username_idx" to table: "users"
+CREATE UNIQUE INDEX `users_username_idx` ON `users` (`username`);
+-- Add column "owner_id" to table: "pastes"
+ALTER TABLE `pastes` ADD COLUMN `owner_id` integer NULL REFERENCES `users` (`id`) ON UPDATE NO ACTION ON DELETE SET NULL;
+-- Create index "pastes_owner_id_idx" to table: "pastes"
+CREATE INDEX `pastes_owner_id_idx` ON `pastes` (`owner_id`) WHERE deleted_at IS NULL;
+-- Create "sessions" table
+CREATE TABLE `sessions` (`id` integer NOT NULL PRIMARY KEY AUTOINCREMENT, `user_id` integer NOT NULL, `token` text NOT NULL, `created_at` text NOT NULL, `expires_at` text NOT NULL, CONSTRAINT `sessions_user_id_fkey` FOREIGN KEY (`user_id`) REFERENCES `users` (`id`) ON UPDATE NO ACTION ON DELETE CASCADE);
+-- Create index "sessions_token_idx" to table: "sessions"
+CREATE UNIQUE INDEX `sessions_token_idx` ON `sessions` (`token`);
@@ -0,0 +1,6 @@
+-- Create "api_tokens" table
+CREATE TABLE `api_tokens` (`id` integer NOT NULL PRIMARY KEY AUTOINCREMENT, `user_id` integer NOT NULL, `name` varchar NOT NULL, `token` text NOT NULL, `scopes` json NOT NULL, `created_at` text NOT NULL, `used_at` text NULL, CONSTRAINT `api_tokens_user_id_fkey` FOREIGN KEY (`user_id`) REFERENCES `users` (`id`) ON UPDATE NO ACTION ON DELETE CASCADE);
+-- Create index "api_tokens_token_idx" to table: "api_tokens"
+CREATE UNIQUE INDEX `api_tokens_token_idx` ON `api_tokens` (`token`);
+-- Create index "api_tokens_user_id_idx" to table: "api_tokens"
+CREATE INDEX `api_tokens_user_id_idx` ON `api_tokens` (`user_id`);
@@ -0,0 +1,3 @@
+-- Create index "pastes_listing_idx" to table: "pastes"
+CREATE INDEX `pastes_listing_idx` ON `pastes` (`created_at` DESC, `id` DESC) WHERE public = true AND deleted_at IS NULL;
+-- Tags are stored as json arrays, which sqlite can't index, so there's no "pastes_tags_idx"
@@ -0,0 +1,2 @@
+-- Create "paste_search" full-text index, its rows share the id of the paste they index
+CREATE VIRTUAL TABLE `paste_search` USING fts5(`title`, `tags`, `content`);
@@ -0,0 +1,2 @@
+-- Create index "pastes_reference_idx" to table: "pastes"
+CREATE UNIQUE INDEX `pastes_reference_idx` ON `pastes` (`reference`);
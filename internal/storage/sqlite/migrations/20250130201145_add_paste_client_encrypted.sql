@@ -0,0 +1,2 @@
+-- Add column "client_encrypted" to table: "pastes"
+ALTER TABLE `pastes` ADD COLUMN `client_encrypted` boolean NOT NULL DEFAULT false;
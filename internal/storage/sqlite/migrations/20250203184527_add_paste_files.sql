@@ -0,0 +1,4 @@
+-- Create "paste_files" table
+CREATE TABLE `paste_files` (`id` integer NOT NULL PRIMARY KEY AUTOINCREMENT, `paste_id` integer NOT NULL, `position` integer NOT NULL, `name` text NOT NULL, `syntax` varchar NULL, `content` text NOT NULL, CONSTRAINT `paste_files_paste_id_fkey` FOREIGN KEY (`paste_id`) REFERENCES `pastes` (`id`) ON UPDATE NO ACTION ON DELETE CASCADE);
+-- Create index "paste_files_paste_id_position_idx" to table: "paste_files"
+CREATE UNIQUE INDEX `paste_files_paste_id_position_idx` ON `paste_files` (`paste_id`, `position`);
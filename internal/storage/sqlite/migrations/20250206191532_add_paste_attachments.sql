@@ -0,0 +1,4 @@
+-- Create "paste_attachments" table
+CREATE TABLE `paste_attachments` (`id` integer NOT NULL PRIMARY KEY AUTOINCREMENT, `paste_id` integer NOT NULL, `position` integer NOT NULL, `name` text NOT NULL, `content_type` varchar NOT NULL, `size` integer NOT NULL, `blob` varchar NOT NULL, `secret` text NOT NULL, CONSTRAINT `paste_attachments_paste_id_fkey` FOREIGN KEY (`paste_id`) REFERENCES `pastes` (`id`) ON UPDATE NO ACTION ON DELETE CASCADE);
+-- Create index "paste_attachments_paste_id_position_idx" to table: "paste_attachments"
+CREATE UNIQUE INDEX `paste_attachments_paste_id_position_idx` ON `paste_attachments` (`paste_id`, `position`);
@@ -0,0 +1,2 @@
+-- Nothing to modify, "title" is an unbounded varchar in sqlite, which doesn't enforce
+-- lengths anyway; kept so the versions mirror the postgres migrations one for one
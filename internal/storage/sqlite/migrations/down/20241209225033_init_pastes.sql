@@ -0,0 +1,2 @@
+-- Drop "pastes" table
+DROP TABLE `pastes`;
@@ -0,0 +1,2 @@
+-- Drop column "views" from table: "pastes"
+ALTER TABLE `pastes` DROP COLUMN `views`;
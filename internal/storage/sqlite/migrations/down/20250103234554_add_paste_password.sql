@@ -0,0 +1,2 @@
+-- Drop column "password" from table: "pastes"
+ALTER TABLE `pastes` DROP COLUMN `password`;
@@ -0,0 +1,2 @@
+-- Drop index "pastes_expiration_idx" from table: "pastes"
+DROP INDEX `pastes_expiration_idx`;
@@ -0,0 +1,2 @@
+-- Drop column "burn_after" from table: "pastes"
+ALTER TABLE `pastes` DROP COLUMN `burn_after`;
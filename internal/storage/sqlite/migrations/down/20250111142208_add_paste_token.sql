@@ -0,0 +1,2 @@
+-- Drop column "token" from table: "pastes"
+ALTER TABLE `pastes` DROP COLUMN `token`;
@@ -0,0 +1,2 @@
+-- Drop "paste_revisions" table
+DROP TABLE `paste_revisions`;
@@ -0,0 +1,4 @@
+-- Drop index "pastes_parent_idx" from table: "pastes"
+DROP INDEX `pastes_parent_idx`;
+-- Drop column "parent" from table: "pastes"
+ALTER TABLE `pastes` DROP COLUMN `parent`;
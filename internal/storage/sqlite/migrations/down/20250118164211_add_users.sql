@@ -0,0 +1,10 @@
+-- Drop "sessions" table
+DROP TABLE `sessions`;
+-- Drop index "pastes_owner_id_idx" from table: "pastes"
+DROP INDEX `pastes_owner_id_idx`;
+-- Drop column "owner_id" from table: "pastes"
+ALTER TABLE `pastes` DROP COLUMN `owner_id`;
+-- Drop index "users_username_idx" from table: "users"
+DROP INDEX `users_username_idx`;
+-- Drop "users" table
+DROP TABLE `users`;
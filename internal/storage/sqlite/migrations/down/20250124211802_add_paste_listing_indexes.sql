@@ -0,0 +1,2 @@
+-- Drop index "pastes_listing_idx" from table: "pastes"
+DROP INDEX `pastes_listing_idx`;
@@ -0,0 +1,2 @@
+-- Drop "paste_search" full-text index
+DROP TABLE `paste_search`;
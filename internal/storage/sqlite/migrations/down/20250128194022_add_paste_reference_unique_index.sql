@@ -0,0 +1,2 @@
+-- Drop index "pastes_reference_idx" from table: "pastes"
+DROP INDEX `pastes_reference_idx`;
@@ -0,0 +1,2 @@
+-- Drop column "client_encrypted" from table: "pastes"
+ALTER TABLE `pastes` DROP COLUMN `client_encrypted`;
@@ -0,0 +1,2 @@
+-- Nothing to modify, sqlite has no default for "created_at", which the storage always
+-- sets in utc; kept so the versions mirror the postgres migrations one for one
@@ -0,0 +1,4 @@
+-- Drop index "pastes_owner_id_idx" from table: "pastes"
+DROP INDEX `pastes_owner_id_idx`;
+-- Create index "pastes_owner_id_idx" to table: "pastes"
+CREATE INDEX `pastes_owner_id_idx` ON `pastes` (`owner_id`) WHERE deleted_at IS NULL;
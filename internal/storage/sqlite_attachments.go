@@ -0,0 +1,88 @@
+package storage
+
+import (
+	"context"
+	dbsql "database/sql"
+
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/aexvir/skladka/internal/errors"
+	"github.com/aexvir/skladka/internal/paste"
+	"github.com/aexvir/skladka/internal/tracing"
+)
+
+// GetAttachment returns the attachment of the paste with the given name, along with its content.
+// Reading an attachment doesn't count as a read of the paste.
+func (s *SQLiteStorage) GetAttachment(ctx context.Context, ref, name string) (paste.Attachment, error) {
+	var err error
+	ctx, finish := tracing.FromContext(ctx, trace.SpanKindInternal, "SQLiteStorage.GetAttachment")
+	defer finish(&err)
+
+	row, err := s.peek(ctx, s.db, ref)
+	if err != nil {
+		s.failed(ctx, err)
+		return paste.Attachment{}, err
+	}
+
+	stored, err := s.attachments(ctx, s.db, row.id)
+	if err != nil {
+		s.metrics.PasteErrors.Add(ctx, 1)
+		return paste.Attachment{}, err
+	}
+
+	attachment, err := readattachment(ctx, s.blobs, s.cipher, stored, name)
+	if err != nil {
+		s.failed(ctx, err)
+		return paste.Attachment{}, err
+	}
+
+	return attachment, nil
+}
+
+// attachments returns the stored attachments of the paste, in order.
+func (s *SQLiteStorage) attachments(ctx context.Context, db sqliteQuerier, id int64) ([]storedAttachment, error) {
+	rows, err := db.QueryContext(
+		ctx,
+		"select name, content_type, size, blob, secret from paste_attachments where paste_id = @id order by position",
+		dbsql.Named("id", id),
+	)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to list attachments of paste %d", id)
+	}
+	defer rows.Close()
+
+	var stored []storedAttachment
+	for rows.Next() {
+		var a storedAttachment
+		if err := rows.Scan(&a.Name, &a.ContentType, &a.Size, &a.blob, &a.secret); err != nil {
+			return nil, errors.Wrapf(err, "failed to list attachments of paste %d", id)
+		}
+
+		stored = append(stored, a)
+	}
+
+	return stored, rows.Err()
+}
+
+// storeattachments stores the attachments of the paste, whose blobs were already put.
+func (s *SQLiteStorage) storeattachments(ctx context.Context, db sqliteQuerier, id int64, stored []storedAttachment) error {
+	for position, a := range stored {
+		_, err := db.ExecContext(
+			ctx,
+			`insert into paste_attachments (paste_id, position, name, content_type, size, blob, secret)
+			values (@id, @position, @name, @content_type, @size, @blob, @secret)`,
+			dbsql.Named("id", id),
+			dbsql.Named("position", position),
+			dbsql.Named("name", a.Name),
+			dbsql.Named("content_type", a.ContentType),
+			dbsql.Named("size", a.Size),
+			dbsql.Named("blob", a.blob),
+			dbsql.Named("secret", a.secret),
+		)
+		if err != nil {
+			return errors.Wrapf(err, "failed to store attachments of paste %d", id)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,100 @@
+package storage
+
+import (
+	"context"
+	dbsql "database/sql"
+
+	"github.com/aexvir/skladka/internal/errors"
+	"github.com/aexvir/skladka/internal/paste"
+)
+
+// document returns the decrypted paste along with its files and attachments.
+// The content of password protected pastes is only revealed if one of the secrets unlocks it.
+func (s *SQLiteStorage) document(ctx context.Context, db sqliteQuerier, row sqlitePaste, secrets ...string) (paste.Paste, error) {
+	// the key of the content also opens the files, so it's only derived once
+	unlock := s.cipher.unlocker(secrets...)
+	p, err := s.domain(row, unlock)
+	if err != nil {
+		return paste.Paste{}, err
+	}
+
+	files, err := s.files(ctx, db, row.id)
+	if err != nil {
+		s.metrics.PasteErrors.Add(ctx, 1)
+		return paste.Paste{}, err
+	}
+
+	if p.Files, err = s.cipher.openfiles(files, p.ClientEncrypted, unlock); err != nil {
+		return paste.Paste{}, errors.Wrap(err, "failed to decrypt files")
+	}
+
+	attachments, err := s.attachments(ctx, db, row.id)
+	if err != nil {
+		s.metrics.PasteErrors.Add(ctx, 1)
+		return paste.Paste{}, err
+	}
+
+	if p.Attachments, err = openattachments(s.cipher, attachments); err != nil {
+		return paste.Paste{}, err
+	}
+
+	return p, nil
+}
+
+// files returns the stored files of the paste, in order.
+func (s *SQLiteStorage) files(ctx context.Context, db sqliteQuerier, id int64) ([]paste.File, error) {
+	rows, err := db.QueryContext(
+		ctx,
+		"select name, syntax, content from paste_files where paste_id = @id order by position",
+		dbsql.Named("id", id),
+	)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to list files of paste %d", id)
+	}
+	defer rows.Close()
+
+	var files []paste.File
+	for rows.Next() {
+		var (
+			file   paste.File
+			syntax dbsql.NullString
+		)
+		if err := rows.Scan(&file.Name, &syntax, &file.Content); err != nil {
+			return nil, errors.Wrapf(err, "failed to list files of paste %d", id)
+		}
+
+		file.Syntax = "plaintext"
+		if syntax.Valid {
+			file.Syntax = syntax.String
+		}
+
+		files = append(files, file)
+	}
+
+	return files, rows.Err()
+}
+
+// storefiles replaces the stored files of the paste with the already encrypted ones.
+func (s *SQLiteStorage) storefiles(ctx context.Context, db sqliteQuerier, id int64, files []paste.File) error {
+	if _, err := db.ExecContext(ctx, "delete from paste_files where paste_id = @id", dbsql.Named("id", id)); err != nil {
+		return errors.Wrapf(err, "failed to store files of paste %d", id)
+	}
+
+	for position, file := range files {
+		_, err := db.ExecContext(
+			ctx,
+			`insert into paste_files (paste_id, position, name, syntax, content)
+			values (@id, @position, @name, @syntax, @content)`,
+			dbsql.Named("id", id),
+			dbsql.Named("position", position),
+			dbsql.Named("name", file.Name),
+			dbsql.Named("syntax", nullable(file.Syntax)),
+			dbsql.Named("content", file.Content),
+		)
+		if err != nil {
+			return errors.Wrapf(err, "failed to store files of paste %d", id)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,157 @@
+package storage
+
+import (
+	"context"
+	dbsql "database/sql"
+
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/aexvir/skladka/internal/errors"
+	"github.com/aexvir/skladka/internal/tracing"
+)
+
+// sqliteRotation lists the rows of a table that weren't encrypted with the key identified
+// by the prefix, and updates them once re-encrypted. The content of client encrypted pastes
+// isn't encrypted by the server, so only their titles, or names, are considered.
+type sqliteRotation struct {
+	table  string
+	list   string
+	update string
+}
+
+var sqliteRotations = []sqliteRotation{
+	{
+		table: "pastes",
+		list: `select id, title, content, client_encrypted
+		from pastes
+		where id > @after
+			and (instr(title, @prefix) != 1 or (not client_encrypted and instr(content, @prefix) != 1))
+		order by id
+		limit @batch`,
+		update: "update pastes set title = ?, content = ? where id = ?",
+	},
+	{
+		table: "paste_revisions",
+		list: `select paste_revisions.id, paste_revisions.title, paste_revisions.content, pastes.client_encrypted
+		from paste_revisions join pastes on pastes.id = paste_revisions.paste_id
+		where paste_revisions.id > @after
+			and (
+				instr(paste_revisions.title, @prefix) != 1
+				or (not pastes.client_encrypted and instr(paste_revisions.content, @prefix) != 1)
+			)
+		order by paste_revisions.id
+		limit @batch`,
+		update: "update paste_revisions set title = ?, content = ? where id = ?",
+	},
+	{
+		table: "paste_files",
+		list: `select paste_files.id, paste_files.name, paste_files.content, pastes.client_encrypted
+		from paste_files join pastes on pastes.id = paste_files.paste_id
+		where paste_files.id > @after
+			and (
+				instr(paste_files.name, @prefix) != 1
+				or (not pastes.client_encrypted and instr(paste_files.content, @prefix) != 1)
+			)
+		order by paste_files.id
+		limit @batch`,
+		update: "update paste_files set name = ?, content = ? where id = ?",
+	},
+	{
+		// only the sealed keys of attachments are re-encrypted, their blobs are left as they are
+		table: "paste_attachments",
+		list: `select id, name, secret, false
+		from paste_attachments
+		where id > @after
+			and (instr(name, @prefix) != 1 or instr(secret, @prefix) != 1)
+		order by id
+		limit @batch`,
+		update: "update paste_attachments set name = ?, secret = ? where id = ?",
+	},
+}
+
+// RotateKeys re-encrypts the pastes, revisions, files and attachments that weren't encrypted with the current key,
+// committing a transaction every batch rows. Returns the number of rows re-encrypted.
+// Sessions and api tokens are only stored as digests, so they're moved to the current key
+// the first time they're used after the rotation instead. Retired keys can be removed from
+// the config once the sessions created before have expired and the tokens have been used,
+// the ones left unused stop working.
+func (s *SQLiteStorage) RotateKeys(ctx context.Context, batch int) (int, error) {
+	var err error
+	ctx, finish := tracing.FromContext(ctx, trace.SpanKindInternal, "SQLiteStorage.RotateKeys")
+	defer finish(&err)
+
+	if batch <= 0 {
+		return 0, errors.Errorf("invalid batch size %d", batch)
+	}
+
+	var rotated int
+	for _, source := range sqliteRotations {
+		var after int64
+
+		for {
+			var rows []rotation
+			rows, err = s.rotatebatch(ctx, source, after, batch)
+			rotated += len(rows)
+			if err != nil {
+				return rotated, errors.Wrapf(err, "failed to rotate %s", source.table)
+			}
+
+			if len(rows) < batch {
+				break
+			}
+
+			after = rows[len(rows)-1].id
+		}
+	}
+
+	return rotated, nil
+}
+
+func (s *SQLiteStorage) rotatebatch(ctx context.Context, source sqliteRotation, after int64, batch int) ([]rotation, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to begin transaction")
+	}
+	defer tx.Rollback()
+
+	result, err := tx.QueryContext(
+		ctx, source.list,
+		dbsql.Named("after", after),
+		dbsql.Named("prefix", s.cipher.Envelope()),
+		dbsql.Named("batch", batch),
+	)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list rows to rotate")
+	}
+
+	var rows []rotation
+	for result.Next() {
+		var row rotation
+		if err = result.Scan(&row.id, &row.title, &row.content, &row.clientEncrypted); err != nil {
+			result.Close()
+			return nil, errors.Wrap(err, "failed to list rows to rotate")
+		}
+		rows = append(rows, row)
+	}
+	result.Close()
+	if err = result.Err(); err != nil {
+		return nil, errors.Wrap(err, "failed to list rows to rotate")
+	}
+
+	for _, row := range rows {
+		if err = row.reencrypt(s.cipher); err != nil {
+			return nil, err
+		}
+
+		_, err = tx.ExecContext(ctx, source.update, row.title, row.content, row.id)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to update row %d", row.id)
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, errors.Wrap(err, "failed to commit transaction")
+	}
+
+	return rows, nil
+}
@@ -0,0 +1,287 @@
+package storage_test
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aexvir/skladka/internal/config"
+	"github.com/aexvir/skladka/internal/errors"
+	"github.com/aexvir/skladka/internal/paste"
+	"github.com/aexvir/skladka/internal/storage"
+	"github.com/aexvir/skladka/internal/user"
+)
+
+func newSQLiteStorage(t *testing.T) *storage.SQLiteStorage {
+	return newSQLiteStorageWithConfig(t, testConfig())
+}
+
+// newSQLiteStorageWithConfig opens a sqlite storage with the config, in a temporary
+// database unless the config sets the storage up already.
+func newSQLiteStorageWithConfig(t *testing.T, cfg config.Config) *storage.SQLiteStorage {
+	if cfg.Storage.Driver == "" {
+		cfg.Storage = config.Storage{
+			Driver: "sqlite",
+			DSN:    filepath.Join(t.TempDir(), "skladka.db"),
+			Blobs:  config.Blobs{Driver: "filesystem", Path: t.TempDir()},
+		}
+	}
+
+	db, err := storage.NewSQLiteStorage(context.Background(), cfg)
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	return db
+}
+
+func TestSQLiteStoragePastes(t *testing.T) {
+	ctx := context.Background()
+	db := newSQLiteStorage(t)
+
+	ref, token, err := db.CreatePaste(
+		ctx, paste.Paste{
+			Title:   "hello world",
+			Content: "fmt.Println(\"hello\")",
+			Syntax:  "go",
+			Tags:    []string{"golang", "example"},
+			Public:  true,
+		},
+	)
+	require.NoError(t, err)
+
+	p, err := db.GetPaste(ctx, ref)
+	require.NoError(t, err)
+	require.Equal(t, "hello world", p.Title)
+	require.Equal(t, []string{"golang", "example"}, p.Tags)
+	require.Equal(t, 1, p.Views)
+
+	require.True(t, errors.IsForbidden(db.UpdatePaste(ctx, ref, "nope", paste.Paste{Content: "hijacked"})))
+	require.NoError(t, db.UpdatePaste(ctx, ref, token, paste.Paste{Title: "hello again", Content: "updated", Syntax: "go"}))
+
+	revisions, err := db.ListRevisions(ctx, ref, "")
+	require.NoError(t, err)
+	require.Len(t, revisions, 2)
+	require.Equal(t, "fmt.Println(\"hello\")", revisions[0].Content)
+	require.Equal(t, "updated", revisions[1].Content)
+
+	found, err := db.SearchPastes(ctx, "again -missing", 10)
+	require.NoError(t, err)
+	require.Len(t, found, 1)
+
+	page, err := db.ListPastes(ctx, paste.Filter{Syntax: "go"}, "", 10)
+	require.NoError(t, err)
+	require.Len(t, page.Pastes, 1)
+
+	require.NoError(t, db.DeletePaste(ctx, ref, token))
+
+	_, err = db.GetPaste(ctx, ref)
+	require.ErrorIs(t, err, pgx.ErrNoRows)
+}
+
+func TestSQLiteStorageReferenceCollisions(t *testing.T) {
+	ctx := context.Background()
+
+	// single character references collide all the time, forcing new ones to be generated
+	cfg := testConfig()
+	cfg.ReferenceLength = 1
+	db := newSQLiteStorageWithConfig(t, cfg)
+
+	refs := make(map[string]bool)
+	for range 16 {
+		ref, _, err := db.CreatePaste(ctx, paste.Paste{Content: "colliding"})
+		require.NoError(t, err)
+		require.Len(t, ref, 1)
+		require.False(t, refs[ref], "reference %s was handed out twice", ref)
+		refs[ref] = true
+	}
+}
+
+func TestSQLiteStorageBurnAfterReading(t *testing.T) {
+	ctx := context.Background()
+	db := newSQLiteStorage(t)
+
+	once := 1
+	ref, _, err := db.CreatePaste(ctx, paste.Paste{Content: "read once", BurnAfter: &once})
+	require.NoError(t, err)
+
+	p, err := db.GetPaste(ctx, ref)
+	require.NoError(t, err)
+	require.Equal(t, "read once", p.Content)
+
+	_, err = db.GetPaste(ctx, ref)
+	require.ErrorIs(t, err, pgx.ErrNoRows)
+}
+
+func TestSQLiteStorageUsers(t *testing.T) {
+	ctx := context.Background()
+	db := newSQLiteStorage(t)
+
+	account, err := db.CreateUser(ctx, "alice", "correct horse")
+	require.NoError(t, err)
+
+	_, err = db.CreateUser(ctx, "alice", "another one")
+	require.True(t, errors.IsBadRequest(err))
+
+	authenticated, err := db.Authenticate(ctx, "alice", "correct horse")
+	require.NoError(t, err)
+	require.Equal(t, account.ID, authenticated.ID)
+
+	session, _, err := db.CreateSession(ctx, account)
+	require.NoError(t, err)
+
+	current, err := db.GetSessionUser(ctx, session)
+	require.NoError(t, err)
+	require.Equal(t, "alice", current.Username)
+
+	_, secret, err := db.CreateAPIToken(ctx, account, "ci", []user.Scope{user.ScopeRead})
+	require.NoError(t, err)
+
+	owner, scopes, err := db.GetTokenUser(ctx, secret)
+	require.NoError(t, err)
+	require.Equal(t, account.ID, owner.ID)
+	require.Equal(t, []user.Scope{user.ScopeRead}, scopes)
+
+	require.NoError(t, db.DeleteSession(ctx, session))
+
+	_, err = db.GetSessionUser(ctx, session)
+	require.ErrorIs(t, err, pgx.ErrNoRows)
+}
+
+func TestSQLiteStorageRotateKeys(t *testing.T) {
+	ctx := context.Background()
+	dsn := filepath.Join(t.TempDir(), "skladka.db")
+	blobs := config.Blobs{Driver: "filesystem", Path: t.TempDir()}
+
+	open := func(cfg config.Config) *storage.SQLiteStorage {
+		cfg.Storage = config.Storage{Driver: "sqlite", DSN: dsn, Blobs: blobs}
+
+		db, err := storage.NewSQLiteStorage(ctx, cfg)
+		require.NoError(t, err)
+
+		return db
+	}
+
+	old := testConfig()
+	db := open(old)
+
+	ref, token, err := db.CreatePaste(ctx, paste.Paste{Title: "rotated", Content: "first"})
+	require.NoError(t, err)
+	require.NoError(t, db.UpdatePaste(ctx, ref, token, paste.Paste{Title: "rotated", Content: "second"}))
+
+	files := []paste.File{{Name: "a.txt", Syntax: "plaintext", Content: "a"}, {Name: "b.txt", Syntax: "plaintext", Content: "b"}}
+	multi, _, err := db.CreatePaste(ctx, paste.Paste{Title: "files", Files: files})
+	require.NoError(t, err)
+
+	image := []byte("\x89PNG\r\n\x1a\n")
+	attached, _, err := db.CreatePaste(
+		ctx, paste.Paste{
+			Title:       "attachments",
+			Attachments: []paste.Attachment{{Name: "image.png", ContentType: "image/png", Data: image}},
+		},
+	)
+	require.NoError(t, err)
+
+	account, err := db.CreateUser(ctx, "alice", "correct horse")
+	require.NoError(t, err)
+	session, _, err := db.CreateSession(ctx, account)
+	require.NoError(t, err)
+	unused, _, err := db.CreateSession(ctx, account)
+	require.NoError(t, err)
+	_, secret, err := db.CreateAPIToken(ctx, account, "ci", []user.Scope{user.ScopeRead})
+	require.NoError(t, err)
+	require.NoError(t, db.Close())
+
+	// the new key decrypts the old data while the old one is retired
+	rotated := testConfig()
+	rotated.EncryptionKey = "rotatedkey=="
+	rotated.EncryptionKeyID = "2"
+	rotated.RetiredEncryptionKeys = []string{"1:" + old.EncryptionKey + ":" + old.EncryptionSalt}
+	db = open(rotated)
+
+	// sessions and tokens are digested again with the new key once used
+	current, err := db.GetSessionUser(ctx, session)
+	require.NoError(t, err)
+	require.Equal(t, account.ID, current.ID)
+
+	current, _, err = db.GetTokenUser(ctx, secret)
+	require.NoError(t, err)
+	require.Equal(t, account.ID, current.ID)
+
+	count, err := db.RotateKeys(ctx, 1)
+	require.NoError(t, err)
+	require.Equal(t, 7, count, "the pastes, the previous revision, the files and the attachment are re-encrypted")
+
+	count, err = db.RotateKeys(ctx, 1)
+	require.NoError(t, err)
+	require.Zero(t, count)
+	require.NoError(t, db.Close())
+
+	// once rotated the old key isn't needed anymore
+	rotated.RetiredEncryptionKeys = nil
+	db = open(rotated)
+	t.Cleanup(func() { db.Close() })
+
+	current, err = db.GetSessionUser(ctx, session)
+	require.NoError(t, err)
+	require.Equal(t, account.ID, current.ID)
+
+	current, scopes, err := db.GetTokenUser(ctx, secret)
+	require.NoError(t, err)
+	require.Equal(t, account.ID, current.ID)
+	require.Equal(t, []user.Scope{user.ScopeRead}, scopes)
+
+	// sessions left unused while the old key was retired are gone along with it
+	_, err = db.GetSessionUser(ctx, unused)
+	require.ErrorIs(t, err, pgx.ErrNoRows)
+
+	p, err := db.GetPaste(ctx, ref)
+	require.NoError(t, err)
+	require.Equal(t, "second", p.Content)
+
+	p, err = db.GetPaste(ctx, multi)
+	require.NoError(t, err)
+	require.Equal(t, files, p.Files)
+
+	// the blobs of attachments are left as they are, their keys are re-encrypted
+	attachment, err := db.GetAttachment(ctx, attached, "image.png")
+	require.NoError(t, err)
+	require.Equal(t, image, attachment.Data)
+
+	revisions, err := db.ListRevisions(ctx, ref, "")
+	require.NoError(t, err)
+	require.Equal(t, "first", revisions[0].Content)
+}
+
+func TestNewMigrator(t *testing.T) {
+	ctx := context.Background()
+
+	cfg := testConfig()
+	cfg.Storage = config.Storage{
+		Driver: "sqlite",
+		DSN:    filepath.Join(t.TempDir(), "skladka.db"),
+		Blobs:  config.Blobs{Driver: "memory"},
+	}
+
+	db, err := storage.NewSQLiteStorage(ctx, cfg)
+	require.NoError(t, err)
+	require.NoError(t, db.Close())
+
+	// the storage applies the migrations when opened, so nothing is left pending
+	migrator, conn, err := storage.NewMigrator(ctx, cfg)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	migrations, err := migrator.Status(ctx)
+	require.NoError(t, err)
+	require.NotEmpty(t, migrations)
+	for _, migration := range migrations {
+		require.False(t, migration.Pending(), migration.Version)
+	}
+
+	cfg.Storage = config.Storage{Driver: "memory"}
+	_, _, err = storage.NewMigrator(ctx, cfg)
+	require.Error(t, err)
+}
@@ -0,0 +1,379 @@
+package storage
+
+import (
+	"context"
+	dbsql "database/sql"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/aexvir/skladka/internal/errors"
+	"github.com/aexvir/skladka/internal/tracing"
+	"github.com/aexvir/skladka/internal/user"
+)
+
+// CreateUser registers a new user, storing the password hashed.
+// Usernames are unique, registering a taken username fails with a bad request error.
+func (s *SQLiteStorage) CreateUser(ctx context.Context, username, password string) (user.User, error) {
+	var err error
+	ctx, finish := tracing.FromContext(ctx, trace.SpanKindInternal, "SQLiteStorage.CreateUser")
+	defer finish(&err)
+
+	var empty user.User
+
+	if err = user.ValidateCredentials(username, password); err != nil {
+		return empty, errors.NewHTTPError(http.StatusBadRequest, err.Error(), err)
+	}
+
+	now := time.Now().UTC()
+
+	result, err := s.db.ExecContext(
+		ctx,
+		"insert into users (username, password, created_at) values (?, ?, ?)",
+		username, s.cipher.Hash(password), sqlitetime(&now),
+	)
+	if err != nil {
+		if sqliteDuplicate(err) {
+			return empty, errors.NewHTTPError(http.StatusBadRequest, "username is already taken", err)
+		}
+		s.metrics.UserErrors.Add(ctx, 1)
+		return empty, errors.Wrap(err, "failed to create user")
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		s.metrics.UserErrors.Add(ctx, 1)
+		return empty, errors.Wrap(err, "failed to create user")
+	}
+
+	s.metrics.UserRegistered.Add(ctx, 1)
+
+	return user.User{ID: id, Username: username, Creation: now.Truncate(time.Microsecond)}, nil
+}
+
+// Authenticate verifies the credentials of a user.
+// Returns nil if the user doesn't exist or the password doesn't match.
+func (s *SQLiteStorage) Authenticate(ctx context.Context, username, password string) (*user.User, error) {
+	var err error
+	ctx, finish := tracing.FromContext(ctx, trace.SpanKindInternal, "SQLiteStorage.Authenticate")
+	defer finish(&err)
+
+	var hash string
+	account, err := s.user(
+		ctx,
+		"select id, username, created_at, password from users where username = ? and deleted_at is null",
+		[]any{username}, &hash,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		s.metrics.UserErrors.Add(ctx, 1)
+		return nil, errors.Wrap(err, "failed to get user")
+	}
+
+	if !s.cipher.Verify(password, hash) {
+		return nil, nil
+	}
+
+	return &account, nil
+}
+
+// CreateSession starts a new session for the user, returning the secret session
+// token and when it expires. Only a digest of the token is stored.
+func (s *SQLiteStorage) CreateSession(ctx context.Context, account user.User) (string, time.Time, error) {
+	var err error
+	ctx, finish := tracing.FromContext(ctx, trace.SpanKindInternal, "SQLiteStorage.CreateSession")
+	defer finish(&err)
+
+	token, err := generateToken()
+	if err != nil {
+		s.metrics.UserErrors.Add(ctx, 1)
+		return "", time.Time{}, errors.Wrap(err, "failed to generate token")
+	}
+
+	expiration := time.Now().UTC().Add(s.sessions)
+
+	_, err = s.db.ExecContext(
+		ctx,
+		"insert into sessions (user_id, token, created_at, expires_at) values (?, ?, ?, ?)",
+		account.ID, s.cipher.Digest(token), sqlitenow(), sqlitetime(&expiration),
+	)
+	if err != nil {
+		s.metrics.UserErrors.Add(ctx, 1)
+		return "", time.Time{}, errors.Wrap(err, "failed to create session")
+	}
+
+	s.metrics.SessionCreated.Add(ctx, 1)
+
+	return token, expiration, nil
+}
+
+// GetSessionUser returns the user the session token belongs to.
+// Expired sessions are reported as not found.
+func (s *SQLiteStorage) GetSessionUser(ctx context.Context, token string) (user.User, error) {
+	var err error
+	ctx, finish := tracing.FromContext(ctx, trace.SpanKindInternal, "SQLiteStorage.GetSessionUser")
+	defer finish(&err)
+
+	// sessions created before a key rotation are stored with the digest of a retired key,
+	// which is replaced by the digest of the current one once found, as only digests are
+	// stored and they can't be rotated in bulk
+	var account user.User
+	digests := s.cipher.Digests(token)
+	for i, digest := range digests {
+		account, err = s.user(
+			ctx,
+			`select users.id, users.username, users.created_at
+			from sessions join users on users.id = sessions.user_id
+			where sessions.token = ? and sessions.expires_at > ? and users.deleted_at is null`,
+			[]any{digest, sqlitenow()},
+		)
+		if errors.Is(err, pgx.ErrNoRows) {
+			continue
+		}
+
+		if err == nil && i > 0 {
+			if err = s.redigest(ctx, "sessions", digest, digests[0]); err != nil {
+				return user.User{}, err
+			}
+		}
+		break
+	}
+
+	return account, err
+}
+
+// DeleteSession ends the session with the given token.
+func (s *SQLiteStorage) DeleteSession(ctx context.Context, token string) error {
+	var err error
+	ctx, finish := tracing.FromContext(ctx, trace.SpanKindInternal, "SQLiteStorage.DeleteSession")
+	defer finish(&err)
+
+	for _, digest := range s.cipher.Digests(token) {
+		if _, err = s.db.ExecContext(ctx, "delete from sessions where token = ?", digest); err != nil {
+			s.metrics.UserErrors.Add(ctx, 1)
+			return errors.Wrap(err, "failed to delete session")
+		}
+	}
+
+	return nil
+}
+
+// CreateAPIToken creates a personal access token for the user, limited to the given scopes.
+// Returns the token alongside its secret, which is only known at this point as just a digest
+// of it is stored.
+func (s *SQLiteStorage) CreateAPIToken(ctx context.Context, account user.User, name string, scopes []user.Scope) (user.Token, string, error) {
+	var err error
+	ctx, finish := tracing.FromContext(ctx, trace.SpanKindInternal, "SQLiteStorage.CreateAPIToken")
+	defer finish(&err)
+
+	if err = user.ValidateToken(name, scopes); err != nil {
+		return user.Token{}, "", errors.NewHTTPError(http.StatusBadRequest, err.Error(), err)
+	}
+
+	secret, err := generateToken()
+	if err != nil {
+		s.metrics.UserErrors.Add(ctx, 1)
+		return user.Token{}, "", errors.Wrap(err, "failed to generate token")
+	}
+	secret = apiTokenPrefix + secret
+
+	raw := make([]string, len(scopes))
+	for i, scope := range scopes {
+		raw[i] = string(scope)
+	}
+
+	now := time.Now().UTC()
+
+	result, err := s.db.ExecContext(
+		ctx,
+		"insert into api_tokens (user_id, name, token, scopes, created_at) values (?, ?, ?, ?, ?)",
+		account.ID, name, s.cipher.Digest(secret), jsonarray(raw), sqlitetime(&now),
+	)
+	if err != nil {
+		s.metrics.UserErrors.Add(ctx, 1)
+		return user.Token{}, "", errors.Wrap(err, "failed to create api token")
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		s.metrics.UserErrors.Add(ctx, 1)
+		return user.Token{}, "", errors.Wrap(err, "failed to create api token")
+	}
+
+	s.metrics.TokenCreated.Add(ctx, 1)
+
+	token := user.Token{
+		ID:       id,
+		Name:     name,
+		Scopes:   scopes,
+		Creation: now.Truncate(time.Microsecond),
+	}
+
+	return token, secret, nil
+}
+
+// ListAPITokens returns the personal access tokens of the user, newest first.
+func (s *SQLiteStorage) ListAPITokens(ctx context.Context, account user.User) ([]user.Token, error) {
+	var err error
+	ctx, finish := tracing.FromContext(ctx, trace.SpanKindInternal, "SQLiteStorage.ListAPITokens")
+	defer finish(&err)
+
+	rows, err := s.db.QueryContext(
+		ctx,
+		`select id, name, scopes, created_at, used_at
+		from api_tokens
+		where user_id = ?
+		order by created_at desc, id desc`,
+		account.ID,
+	)
+	if err != nil {
+		s.metrics.UserErrors.Add(ctx, 1)
+		return nil, errors.Wrap(err, "failed to list api tokens")
+	}
+	defer rows.Close()
+
+	tokens := make([]user.Token, 0)
+	for rows.Next() {
+		var (
+			token   user.Token
+			scopes  string
+			created string
+			used    dbsql.NullString
+		)
+
+		if err = rows.Scan(&token.ID, &token.Name, &scopes, &created, &used); err != nil {
+			return nil, errors.Wrap(err, "failed to list api tokens")
+		}
+
+		if err = json.Unmarshal([]byte(scopes), &token.Scopes); err != nil {
+			return nil, errors.Wrap(err, "invalid token scopes")
+		}
+
+		if token.Creation, err = time.Parse(sqliteTimeLayout, created); err != nil {
+			return nil, errors.Wrap(err, "invalid token creation time")
+		}
+
+		if used.Valid {
+			if lastuse, err := time.Parse(sqliteTimeLayout, used.String); err == nil {
+				token.LastUse = &lastuse
+			}
+		}
+
+		tokens = append(tokens, token)
+	}
+
+	return tokens, rows.Err()
+}
+
+// RevokeAPIToken deletes a personal access token of the user.
+// Tokens of other users are reported as not found.
+func (s *SQLiteStorage) RevokeAPIToken(ctx context.Context, account user.User, id int64) error {
+	var err error
+	ctx, finish := tracing.FromContext(ctx, trace.SpanKindInternal, "SQLiteStorage.RevokeAPIToken")
+	defer finish(&err)
+
+	result, err := s.db.ExecContext(ctx, "delete from api_tokens where id = ? and user_id = ?", id, account.ID)
+	if err != nil {
+		s.metrics.UserErrors.Add(ctx, 1)
+		return errors.Wrap(err, "failed to revoke api token")
+	}
+
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return errors.Wrap(err, "failed to revoke api token")
+	}
+
+	if deleted == 0 {
+		return errors.NewHTTPError(http.StatusNotFound, "token not found", nil)
+	}
+
+	return nil
+}
+
+// GetTokenUser returns the user a personal access token belongs to and the scopes
+// granted to the token, recording its use.
+func (s *SQLiteStorage) GetTokenUser(ctx context.Context, secret string) (user.User, []user.Scope, error) {
+	var err error
+	ctx, finish := tracing.FromContext(ctx, trace.SpanKindInternal, "SQLiteStorage.GetTokenUser")
+	defer finish(&err)
+
+	// tokens created before a key rotation are stored with the digest of a retired key,
+	// which is replaced by the digest of the current one once found
+	var (
+		account user.User
+		scopes  string
+	)
+	digests := s.cipher.Digests(secret)
+	for i, digest := range digests {
+		account, err = s.user(
+			ctx,
+			`update api_tokens
+			set used_at = ?
+			where token = ?
+			returning user_id,
+				(select username from users where users.id = api_tokens.user_id),
+				(select created_at from users where users.id = api_tokens.user_id),
+				scopes`,
+			[]any{sqlitenow(), digest}, &scopes,
+		)
+		if errors.Is(err, pgx.ErrNoRows) {
+			continue
+		}
+
+		if err == nil && i > 0 {
+			if err = s.redigest(ctx, "api_tokens", digest, digests[0]); err != nil {
+				return user.User{}, nil, err
+			}
+		}
+		break
+	}
+	if err != nil {
+		return user.User{}, nil, err
+	}
+
+	var granted []user.Scope
+	if err = json.Unmarshal([]byte(scopes), &granted); err != nil {
+		return user.User{}, nil, errors.Wrap(err, "invalid token scopes")
+	}
+
+	return account, granted, nil
+}
+
+// redigest replaces the retired digest of a session or token found by it with the
+// digest of the current key, so it keeps working once the retired key is dropped.
+func (s *SQLiteStorage) redigest(ctx context.Context, table, retired, current string) error {
+	_, err := s.db.ExecContext(ctx, "update "+table+" set token = ? where token = ?", current, retired)
+	if err != nil {
+		s.metrics.UserErrors.Add(ctx, 1)
+		return errors.Wrapf(err, "failed to rotate %s digest", table)
+	}
+
+	return nil
+}
+
+// user runs a query returning the id, username and creation time of a single user,
+// followed by the extra columns scanned into dest.
+func (s *SQLiteStorage) user(ctx context.Context, query string, args []any, dest ...any) (user.User, error) {
+	var (
+		account user.User
+		created string
+	)
+
+	err := s.db.
+		QueryRowContext(ctx, query, args...).
+		Scan(append([]any{&account.ID, &account.Username, &created}, dest...)...)
+	if err != nil {
+		return user.User{}, notfound(err)
+	}
+
+	if account.Creation, err = time.Parse(sqliteTimeLayout, created); err != nil {
+		return user.User{}, errors.Wrap(err, "invalid user creation time")
+	}
+
+	return account, nil
+}
@@ -0,0 +1,790 @@
+// Package storagetest holds the contract every storage backend has to fulfill.
+//
+// Backends plug into the suite by handing over a function opening a ready to use
+// storage, which is called once per test:
+//
+//	func TestSQLiteStorageContract(t *testing.T) {
+//		storagetest.Run(t, func(t *testing.T) storagetest.Storage {
+//			return newSQLiteStorage(t)
+//		})
+//	}
+//
+// The suite doesn't assume the storage is empty, every paste it creates is tagged
+// with a tag unique to the test, so it can run against shared databases too.
+package storagetest
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aexvir/skladka/internal/errors"
+	"github.com/aexvir/skladka/internal/paste"
+	"github.com/aexvir/skladka/internal/user"
+)
+
+// Storage is the part of the storage contract covered by the suite.
+type Storage interface {
+	CreatePaste(ctx context.Context, p paste.Paste) (string, string, error)
+	GetPaste(ctx context.Context, ref string) (paste.Paste, error)
+	GetPasteWithPassword(ctx context.Context, ref, password string) (*paste.Paste, error)
+	GetPasteWithToken(ctx context.Context, ref, token string) (paste.Paste, error)
+	PeekPaste(ctx context.Context, ref, password string) (*paste.Paste, error)
+	GetAttachment(ctx context.Context, ref, name string) (paste.Attachment, error)
+	ListPastes(ctx context.Context, filter paste.Filter, after string, limit int) (paste.Page, error)
+	UpdatePaste(ctx context.Context, ref, token string, p paste.Paste) error
+	UpdateOwnerPaste(ctx context.Context, account user.User, ref string, p paste.Paste) error
+	DeletePaste(ctx context.Context, ref, token string) error
+	DeleteOwnerPastes(ctx context.Context, account user.User, refs []string) (int64, error)
+	ListRevisions(ctx context.Context, ref, secret string) ([]paste.Revision, error)
+	ReapExpiredPastes(ctx context.Context) (int64, error)
+	CreateUser(ctx context.Context, username, password string) (user.User, error)
+}
+
+// Indexer is implemented by storages keeping a separate search index, reporting whether
+// the paste with the given reference has a document in it. The suite uses it to verify
+// pastes leave the index once they're gone; storages without one don't implement it.
+type Indexer interface {
+	Indexed(ctx context.Context, ref string) (bool, error)
+}
+
+// Run verifies the storage opened by open behaves the way every backend must.
+func Run(t *testing.T, open func(t *testing.T) Storage) {
+	tests := map[string]func(*testing.T, Storage, string){
+		"create and get":     testCreateAndGet,
+		"missing paste":      testMissingPaste,
+		"list":               testList,
+		"password":           testPassword,
+		"locked content":     testLockedContent,
+		"client encrypted":   testClientEncrypted,
+		"view counting":      testViewCounting,
+		"burn after reading": testBurnAfterReading,
+		"peek":               testPeek,
+		"expiration":         testExpiration,
+		"search index":       testSearchIndex,
+		"unique references":  testUniqueReferences,
+		"slugs":              testSlugs,
+		"encryption":         testEncryption,
+		"owner token":        testOwnerToken,
+		"owner account":      testOwnerAccount,
+		"revisions":          testRevisions,
+		"files":              testFiles,
+		"locked files":       testLockedFiles,
+		"attachments":        testAttachments,
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			test(t, open(t), tag(t))
+		})
+	}
+}
+
+func testCreateAndGet(t *testing.T, s Storage, tag string) {
+	ctx := context.Background()
+
+	ref, token, err := s.CreatePaste(
+		ctx, paste.Paste{
+			Title:   "hello world",
+			Content: "fmt.Println(\"hello\")",
+			Syntax:  "go",
+			Tags:    []string{tag, "golang"},
+			Public:  true,
+		},
+	)
+	require.NoError(t, err)
+	require.NotEmpty(t, ref)
+	require.NotEmpty(t, token)
+
+	p, err := s.GetPaste(ctx, ref)
+	require.NoError(t, err)
+	require.Equal(t, ref, p.Reference)
+	require.Equal(t, "hello world", p.Title)
+	require.Equal(t, "fmt.Println(\"hello\")", p.Content)
+	require.Equal(t, "go", p.Syntax)
+	require.Equal(t, []string{tag, "golang"}, p.Tags)
+	require.True(t, p.Public)
+	require.Nil(t, p.Expiration)
+	require.Nil(t, p.Password)
+	require.WithinDuration(t, time.Now(), p.Creation, time.Minute)
+
+	// pastes without syntax are plain text
+	ref, _, err = s.CreatePaste(ctx, paste.Paste{Content: "plain", Tags: []string{tag}})
+	require.NoError(t, err)
+
+	p, err = s.GetPaste(ctx, ref)
+	require.NoError(t, err)
+	require.Equal(t, "plaintext", p.Syntax)
+	require.False(t, p.Public)
+}
+
+func testMissingPaste(t *testing.T, s Storage, _ string) {
+	ctx := context.Background()
+
+	_, err := s.GetPaste(ctx, "missing")
+	require.ErrorIs(t, err, pgx.ErrNoRows)
+
+	_, err = s.GetPasteWithPassword(ctx, "missing", "password")
+	require.ErrorIs(t, err, pgx.ErrNoRows)
+
+	// forks of missing pastes are rejected
+	parent := "missing"
+	_, _, err = s.CreatePaste(ctx, paste.Paste{Content: "fork", Parent: &parent})
+	require.True(t, errors.IsBadRequest(err))
+}
+
+func testList(t *testing.T, s Storage, tag string) {
+	ctx := context.Background()
+
+	var listed []string
+	for _, syntax := range []string{"go", "python", "go"} {
+		ref, _, err := s.CreatePaste(ctx, paste.Paste{Content: syntax, Syntax: syntax, Tags: []string{tag}, Public: true})
+		require.NoError(t, err)
+		listed = append(listed, ref)
+	}
+
+	_, _, err := s.CreatePaste(ctx, paste.Paste{Content: "unlisted", Syntax: "go", Tags: []string{tag}})
+	require.NoError(t, err)
+
+	// newest first, one paste per page
+	var (
+		refs  []string
+		after string
+	)
+	for range len(listed) + 1 {
+		page, err := s.ListPastes(ctx, paste.Filter{Tag: tag}, after, 1)
+		require.NoError(t, err)
+
+		for _, p := range page.Pastes {
+			refs = append(refs, p.Reference)
+		}
+
+		if page.Next == "" {
+			break
+		}
+		after = page.Next
+	}
+	require.Equal(t, []string{listed[2], listed[1], listed[0]}, refs)
+
+	page, err := s.ListPastes(ctx, paste.Filter{Tag: tag, Syntax: "go"}, "", 10)
+	require.NoError(t, err)
+	require.Len(t, page.Pastes, 2)
+	require.Empty(t, page.Next)
+
+	future := time.Now().Add(time.Hour)
+	page, err = s.ListPastes(ctx, paste.Filter{Tag: tag, Since: &future}, "", 10)
+	require.NoError(t, err)
+	require.Empty(t, page.Pastes)
+
+	_, err = s.ListPastes(ctx, paste.Filter{Tag: tag}, "invalid", 10)
+	require.True(t, errors.IsBadRequest(err))
+}
+
+func testPassword(t *testing.T, s Storage, tag string) {
+	ctx := context.Background()
+
+	password := "hunter2"
+	ref, _, err := s.CreatePaste(ctx, paste.Paste{Content: "secret", Password: &password, Tags: []string{tag}})
+	require.NoError(t, err)
+
+	p, err := s.GetPaste(ctx, ref)
+	require.NoError(t, err)
+	require.NotNil(t, p.Password)
+	require.NotEqual(t, password, *p.Password, "passwords must be stored hashed")
+	require.Empty(t, p.Content, "the content is only readable with the password")
+
+	unlocked, err := s.GetPasteWithPassword(ctx, ref, "wrong")
+	require.NoError(t, err)
+	require.Nil(t, unlocked)
+
+	unlocked, err = s.GetPasteWithPassword(ctx, ref, password)
+	require.NoError(t, err)
+	require.NotNil(t, unlocked)
+	require.Equal(t, "secret", unlocked.Content)
+
+	ref, _, err = s.CreatePaste(ctx, paste.Paste{Content: "open", Tags: []string{tag}})
+	require.NoError(t, err)
+
+	_, err = s.GetPasteWithPassword(ctx, ref, password)
+	require.Error(t, err)
+}
+
+func testLockedContent(t *testing.T, s Storage, tag string) {
+	ctx := context.Background()
+
+	password := "hunter2"
+	ref, token, err := s.CreatePaste(ctx, paste.Paste{Content: "first", Password: &password, Tags: []string{tag}})
+	require.NoError(t, err)
+
+	// owners unlock their pastes with the token, which is enough to edit them too
+	owned, err := s.GetPasteWithToken(ctx, ref, token)
+	require.NoError(t, err)
+	require.Equal(t, "first", owned.Content)
+
+	require.NoError(t, s.UpdatePaste(ctx, ref, token, paste.Paste{Content: "second"}))
+
+	unlocked, err := s.GetPasteWithPassword(ctx, ref, password)
+	require.NoError(t, err)
+	require.NotNil(t, unlocked)
+	require.Equal(t, "second", unlocked.Content)
+
+	tests := map[string]struct {
+		secret   string
+		contents []string
+	}{
+		"password":  {secret: password, contents: []string{"first", "second"}},
+		"token":     {secret: token, contents: []string{"first", "second"}},
+		"no secret": {secret: "", contents: []string{"", ""}},
+		"wrong":     {secret: "wrong", contents: []string{"", ""}},
+	}
+
+	for name, test := range tests {
+		t.Run(
+			name, func(t *testing.T) {
+				revisions, err := s.ListRevisions(ctx, ref, test.secret)
+				require.NoError(t, err)
+				require.Len(t, revisions, len(test.contents))
+
+				for i, content := range test.contents {
+					require.Equal(t, content, revisions[i].Content)
+				}
+			},
+		)
+	}
+}
+
+func testClientEncrypted(t *testing.T, s Storage, tag string) {
+	ctx := context.Background()
+
+	// the nonce and ciphertext are opaque to the server, any bytes will do
+	sealed := base64.StdEncoding.EncodeToString([]byte("nonce-012345sealed content and tag"))
+	resealed := base64.StdEncoding.EncodeToString([]byte("nonce-543210sealed edited content and tag"))
+
+	password := "hunter2"
+	ref, token, err := s.CreatePaste(
+		ctx, paste.Paste{Title: "sealed", Content: sealed, Password: &password, ClientEncrypted: true, Tags: []string{tag}},
+	)
+	require.NoError(t, err)
+
+	// the ciphertext is handed out as is, only the client can decrypt it
+	unlocked, err := s.GetPasteWithPassword(ctx, ref, password)
+	require.NoError(t, err)
+	require.NotNil(t, unlocked)
+	require.True(t, unlocked.ClientEncrypted)
+	require.Equal(t, "sealed", unlocked.Title)
+	require.Equal(t, sealed, unlocked.Content)
+
+	err = s.UpdatePaste(ctx, ref, token, paste.Paste{Title: "sealed", Content: "plaintext"})
+	require.True(t, errors.IsBadRequest(err), "updates must be client encrypted as well")
+
+	require.NoError(t, s.UpdatePaste(ctx, ref, token, paste.Paste{Title: "sealed", Content: resealed}))
+
+	revisions, err := s.ListRevisions(ctx, ref, "")
+	require.NoError(t, err)
+	require.Len(t, revisions, 2)
+	require.Equal(t, sealed, revisions[0].Content)
+	require.Equal(t, resealed, revisions[1].Content)
+	require.True(t, revisions[0].ClientEncrypted)
+}
+
+func testViewCounting(t *testing.T, s Storage, tag string) {
+	ctx := context.Background()
+
+	ref, token, err := s.CreatePaste(ctx, paste.Paste{Content: "counted", Tags: []string{tag}})
+	require.NoError(t, err)
+
+	for views := 1; views <= 3; views++ {
+		p, err := s.GetPaste(ctx, ref)
+		require.NoError(t, err)
+		require.Equal(t, views, p.Views)
+	}
+
+	// owners reading their paste don't count
+	p, err := s.GetPasteWithToken(ctx, ref, token)
+	require.NoError(t, err)
+	require.Equal(t, 3, p.Views)
+
+	// reads of password protected pastes count once the password is verified
+	password := "hunter2"
+	ref, _, err = s.CreatePaste(ctx, paste.Paste{Content: "locked", Password: &password, Tags: []string{tag}})
+	require.NoError(t, err)
+
+	p, err = s.GetPaste(ctx, ref)
+	require.NoError(t, err)
+	require.Equal(t, 0, p.Views)
+
+	unlocked, err := s.GetPasteWithPassword(ctx, ref, password)
+	require.NoError(t, err)
+	require.Equal(t, 1, unlocked.Views)
+}
+
+func testBurnAfterReading(t *testing.T, s Storage, tag string) {
+	ctx := context.Background()
+
+	reads := 2
+	ref, _, err := s.CreatePaste(ctx, paste.Paste{Content: "burned", BurnAfter: &reads, Tags: []string{tag}})
+	require.NoError(t, err)
+
+	for range reads {
+		p, err := s.GetPaste(ctx, ref)
+		require.NoError(t, err)
+		require.Equal(t, "burned", p.Content)
+	}
+
+	_, err = s.GetPaste(ctx, ref)
+	require.ErrorIs(t, err, pgx.ErrNoRows)
+}
+
+func testPeek(t *testing.T, s Storage, tag string) {
+	ctx := context.Background()
+
+	once := 1
+	ref, _, err := s.CreatePaste(ctx, paste.Paste{Content: "peeked", BurnAfter: &once, Tags: []string{tag}})
+	require.NoError(t, err)
+
+	// peeking neither counts the read nor burns the paste
+	for range 2 {
+		p, err := s.PeekPaste(ctx, ref, "")
+		require.NoError(t, err)
+		require.NotNil(t, p)
+		require.Equal(t, "peeked", p.Content)
+		require.Zero(t, p.Views)
+	}
+
+	p, err := s.GetPaste(ctx, ref)
+	require.NoError(t, err)
+	require.Equal(t, 1, p.Views)
+
+	password := "hunter2"
+	ref, _, err = s.CreatePaste(ctx, paste.Paste{Content: "locked", Password: &password, Tags: []string{tag}})
+	require.NoError(t, err)
+
+	locked, err := s.PeekPaste(ctx, ref, "wrong")
+	require.NoError(t, err)
+	require.Nil(t, locked)
+
+	unlocked, err := s.PeekPaste(ctx, ref, password)
+	require.NoError(t, err)
+	require.NotNil(t, unlocked)
+	require.Equal(t, "locked", unlocked.Content)
+	require.Zero(t, unlocked.Views)
+}
+
+func testExpiration(t *testing.T, s Storage, tag string) {
+	ctx := context.Background()
+
+	past, future := time.Now().Add(-time.Minute), time.Now().Add(time.Hour)
+
+	expired, _, err := s.CreatePaste(ctx, paste.Paste{Content: "expired", Expiration: &past, Tags: []string{tag}, Public: true})
+	require.NoError(t, err)
+
+	live, _, err := s.CreatePaste(ctx, paste.Paste{Content: "live", Expiration: &future, Tags: []string{tag}, Public: true})
+	require.NoError(t, err)
+
+	_, err = s.GetPaste(ctx, expired)
+	require.ErrorIs(t, err, pgx.ErrNoRows)
+
+	p, err := s.GetPaste(ctx, live)
+	require.NoError(t, err)
+	require.NotNil(t, p.Expiration)
+	require.WithinDuration(t, future, *p.Expiration, time.Millisecond)
+
+	page, err := s.ListPastes(ctx, paste.Filter{Tag: tag}, "", 10)
+	require.NoError(t, err)
+	require.Len(t, page.Pastes, 1)
+	require.Equal(t, live, page.Pastes[0].Reference)
+
+	reaped, err := s.ReapExpiredPastes(ctx)
+	require.NoError(t, err)
+	require.GreaterOrEqual(t, reaped, int64(1))
+
+	_, err = s.GetPaste(ctx, live)
+	require.NoError(t, err)
+}
+
+func testSearchIndex(t *testing.T, s Storage, tag string) {
+	indexer, ok := s.(Indexer)
+	if !ok {
+		t.Skip("storage doesn't keep a search index")
+	}
+
+	ctx := context.Background()
+
+	indexed := func(ref string) bool {
+		found, err := indexer.Indexed(ctx, ref)
+		require.NoError(t, err)
+		return found
+	}
+
+	// deleted pastes
+	ref, token, err := s.CreatePaste(ctx, paste.Paste{Content: "deleted", Tags: []string{tag}, Public: true})
+	require.NoError(t, err)
+	require.True(t, indexed(ref))
+
+	require.NoError(t, s.DeletePaste(ctx, ref, token))
+	require.False(t, indexed(ref), "deleted pastes must leave the search index")
+
+	// pastes deleted by their owner
+	owner, err := s.CreateUser(ctx, tag, "correct horse battery")
+	require.NoError(t, err)
+
+	ref, _, err = s.CreatePaste(ctx, paste.Paste{Content: "owned", Owner: &owner, Tags: []string{tag}, Public: true})
+	require.NoError(t, err)
+	require.True(t, indexed(ref))
+
+	deleted, err := s.DeleteOwnerPastes(ctx, owner, []string{ref})
+	require.NoError(t, err)
+	require.Equal(t, int64(1), deleted)
+	require.False(t, indexed(ref), "pastes deleted by their owner must leave the search index")
+
+	// burned pastes
+	once := 1
+	ref, _, err = s.CreatePaste(ctx, paste.Paste{Content: "burned", BurnAfter: &once, Tags: []string{tag}, Public: true})
+	require.NoError(t, err)
+	require.True(t, indexed(ref))
+
+	_, err = s.GetPaste(ctx, ref)
+	require.NoError(t, err)
+	require.False(t, indexed(ref), "burned pastes must leave the search index")
+
+	// reaped pastes
+	past := time.Now().Add(-time.Minute)
+	ref, _, err = s.CreatePaste(ctx, paste.Paste{Content: "reaped", Expiration: &past, Tags: []string{tag}, Public: true})
+	require.NoError(t, err)
+
+	_, err = s.ReapExpiredPastes(ctx)
+	require.NoError(t, err)
+	require.False(t, indexed(ref), "reaped pastes must leave the search index")
+}
+
+func testUniqueReferences(t *testing.T, s Storage, tag string) {
+	ctx := context.Background()
+
+	refs := make(map[string]string)
+	for i := range 20 {
+		content := strings.Repeat("x", i+1)
+
+		ref, _, err := s.CreatePaste(ctx, paste.Paste{Content: content, Tags: []string{tag}})
+		require.NoError(t, err)
+		require.NotContains(t, refs, ref)
+
+		refs[ref] = content
+	}
+
+	for ref, content := range refs {
+		p, err := s.GetPaste(ctx, ref)
+		require.NoError(t, err)
+		require.Equal(t, content, p.Content)
+	}
+}
+
+func testSlugs(t *testing.T, s Storage, tag string) {
+	ctx := context.Background()
+
+	// slugs are normalized into the reference of the paste
+	ref, _, err := s.CreatePaste(ctx, paste.Paste{Content: "notes", Slug: " Notes " + tag + "! ", Tags: []string{tag}})
+	require.NoError(t, err)
+	require.Equal(t, "notes-"+tag, ref)
+
+	p, err := s.GetPaste(ctx, ref)
+	require.NoError(t, err)
+	require.Equal(t, "notes", p.Content)
+
+	tests := map[string]string{
+		"taken":     "NOTES " + tag,
+		"reserved":  "Archive",
+		"too short": "a!",
+		"too long":  strings.Repeat("long", 10),
+		"no words":  "---",
+	}
+
+	for name, slug := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, _, err := s.CreatePaste(ctx, paste.Paste{Content: "rejected", Slug: slug, Tags: []string{tag}})
+			require.True(t, errors.IsBadRequest(err), "unexpected error %v", err)
+		})
+	}
+}
+
+func testEncryption(t *testing.T, s Storage, tag string) {
+	ctx := context.Background()
+
+	tests := map[string]paste.Paste{
+		"empty title": {Content: "content"},
+		"unicode":     {Title: "příliš žluťoučký kůň", Content: "úpěl ďábelské ódy 🐴"},
+		"multiline":   {Title: "lines", Content: "first\n\tsecond\r\nthird\n"},
+		"large":       {Title: "large", Content: strings.Repeat("skladka ", 64*1024)},
+		"long title":  {Title: strings.Repeat("ž", 255), Content: "the encrypted title outgrows the plaintext"},
+		"binary-ish":  {Title: "nul", Content: "before\x00after"},
+	}
+
+	for name, original := range tests {
+		t.Run(name, func(t *testing.T) {
+			original.Tags = []string{tag}
+
+			ref, token, err := s.CreatePaste(ctx, original)
+			require.NoError(t, err)
+
+			p, err := s.GetPaste(ctx, ref)
+			require.NoError(t, err)
+			require.Equal(t, original.Title, p.Title)
+			require.Equal(t, original.Content, p.Content)
+
+			updated := original
+			updated.Content = original.Content + " edited"
+			require.NoError(t, s.UpdatePaste(ctx, ref, token, updated))
+
+			p, err = s.GetPasteWithToken(ctx, ref, token)
+			require.NoError(t, err)
+			require.Equal(t, updated.Content, p.Content)
+		})
+	}
+}
+
+func testOwnerToken(t *testing.T, s Storage, tag string) {
+	ctx := context.Background()
+
+	ref, token, err := s.CreatePaste(ctx, paste.Paste{Content: "owned", Tags: []string{tag}})
+	require.NoError(t, err)
+
+	_, err = s.GetPasteWithToken(ctx, ref, "wrong")
+	require.True(t, errors.IsForbidden(err))
+
+	err = s.UpdatePaste(ctx, ref, "wrong", paste.Paste{Content: "hijacked"})
+	require.True(t, errors.IsForbidden(err))
+
+	err = s.DeletePaste(ctx, ref, "")
+	require.True(t, errors.IsForbidden(err))
+
+	require.NoError(t, s.DeletePaste(ctx, ref, token))
+
+	_, err = s.GetPaste(ctx, ref)
+	require.ErrorIs(t, err, pgx.ErrNoRows)
+}
+
+func testOwnerAccount(t *testing.T, s Storage, tag string) {
+	ctx := context.Background()
+
+	owner, err := s.CreateUser(ctx, tag+"-owner", "correct horse battery")
+	require.NoError(t, err)
+	stranger, err := s.CreateUser(ctx, tag+"-stranger", "correct horse battery")
+	require.NoError(t, err)
+
+	ref, _, err := s.CreatePaste(ctx, paste.Paste{Content: "draft", Owner: &owner, Tags: []string{tag}})
+	require.NoError(t, err)
+
+	err = s.UpdateOwnerPaste(ctx, stranger, ref, paste.Paste{Content: "hijacked", Tags: []string{tag}})
+	require.True(t, errors.IsForbidden(err), "only the owner can edit the paste, got %v", err)
+
+	require.NoError(t, s.UpdateOwnerPaste(ctx, owner, ref, paste.Paste{Content: "final", Tags: []string{tag}}))
+
+	p, err := s.GetPaste(ctx, ref)
+	require.NoError(t, err)
+	require.Equal(t, "final", p.Content)
+
+	revisions, err := s.ListRevisions(ctx, ref, "")
+	require.NoError(t, err)
+	require.Len(t, revisions, 2)
+
+	// protected pastes are locked with their owner token, the account alone can't relock them
+	password := "hunter2"
+	ref, _, err = s.CreatePaste(ctx, paste.Paste{Content: "locked", Password: &password, Owner: &owner, Tags: []string{tag}})
+	require.NoError(t, err)
+
+	err = s.UpdateOwnerPaste(ctx, owner, ref, paste.Paste{Content: "relocked", Tags: []string{tag}})
+	require.True(t, errors.IsForbidden(err), "protected pastes require their token, got %v", err)
+}
+
+func testRevisions(t *testing.T, s Storage, tag string) {
+	ctx := context.Background()
+
+	original := paste.Paste{Title: "draft", Content: "first", Syntax: "go", Tags: []string{tag}}
+	ref, token, err := s.CreatePaste(ctx, original)
+	require.NoError(t, err)
+
+	// updates not changing anything don't create revisions
+	require.NoError(t, s.UpdatePaste(ctx, ref, token, original))
+
+	edited := paste.Paste{Title: "final", Content: "second", Syntax: "go", Tags: []string{tag}}
+	require.NoError(t, s.UpdatePaste(ctx, ref, token, edited))
+
+	revisions, err := s.ListRevisions(ctx, ref, "")
+	require.NoError(t, err)
+	require.Len(t, revisions, 2)
+
+	require.Equal(t, 1, revisions[0].Number)
+	require.Equal(t, "draft", revisions[0].Title)
+	require.Equal(t, "first", revisions[0].Content)
+
+	require.Equal(t, 2, revisions[1].Number)
+	require.Equal(t, "final", revisions[1].Title)
+	require.Equal(t, "second", revisions[1].Content)
+}
+
+func testFiles(t *testing.T, s Storage, tag string) {
+	ctx := context.Background()
+
+	files := []paste.File{
+		{Name: "main.go", Syntax: "go", Content: "package main"},
+		{Name: "go.mod", Syntax: "plaintext", Content: "module example"},
+	}
+
+	ref, token, err := s.CreatePaste(ctx, paste.Paste{Title: "project", Files: files, Public: true, Tags: []string{tag}})
+	require.NoError(t, err)
+
+	// the first file is the content of the paste for anyone unaware of files
+	got, err := s.GetPaste(ctx, ref)
+	require.NoError(t, err)
+	require.Equal(t, files, got.Files)
+	require.Equal(t, "package main", got.Content)
+	require.Equal(t, "go", got.Syntax)
+
+	// listings leave the files out
+	page, err := s.ListPastes(ctx, paste.Filter{Tag: tag}, "", 10)
+	require.NoError(t, err)
+	require.Len(t, page.Pastes, 1)
+	require.Empty(t, page.Pastes[0].Files)
+
+	// updates without files only edit the first one
+	require.NoError(t, s.UpdatePaste(ctx, ref, token, paste.Paste{Title: "project", Content: "package app", Syntax: "go", Tags: []string{tag}}))
+
+	got, err = s.GetPasteWithToken(ctx, ref, token)
+	require.NoError(t, err)
+	require.Equal(t, []paste.File{{Name: "main.go", Syntax: "go", Content: "package app"}, files[1]}, got.Files)
+
+	// the files are replaced as a whole
+	replaced := []paste.File{{Name: "README.md", Syntax: "markdown", Content: "# example"}}
+	require.NoError(t, s.UpdatePaste(ctx, ref, token, paste.Paste{Title: "project", Files: replaced, Tags: []string{tag}}))
+
+	got, err = s.GetPasteWithToken(ctx, ref, token)
+	require.NoError(t, err)
+	require.Equal(t, replaced, got.Files)
+	require.Equal(t, "# example", got.Content)
+	require.Equal(t, "markdown", got.Syntax)
+
+	// and dropped with an empty list
+	require.NoError(t, s.UpdatePaste(ctx, ref, token, paste.Paste{Title: "project", Content: "plain", Files: []paste.File{}, Tags: []string{tag}}))
+
+	got, err = s.GetPasteWithToken(ctx, ref, token)
+	require.NoError(t, err)
+	require.Empty(t, got.Files)
+	require.Equal(t, "plain", got.Content)
+}
+
+func testLockedFiles(t *testing.T, s Storage, tag string) {
+	ctx := context.Background()
+
+	files := []paste.File{
+		{Name: "a.txt", Syntax: "plaintext", Content: "first"},
+		{Name: "b.txt", Syntax: "plaintext", Content: "second"},
+	}
+
+	password := "hunter2"
+	ref, token, err := s.CreatePaste(ctx, paste.Paste{Files: files, Password: &password, Tags: []string{tag}})
+	require.NoError(t, err)
+
+	// names are listed without the password, contents are withheld
+	peeked, err := s.GetPaste(ctx, ref)
+	require.NoError(t, err)
+	require.Len(t, peeked.Files, 2)
+	require.Equal(t, "b.txt", peeked.Files[1].Name)
+	require.Empty(t, peeked.Files[1].Content)
+
+	owned, err := s.GetPasteWithToken(ctx, ref, token)
+	require.NoError(t, err)
+	require.Equal(t, files, owned.Files)
+
+	edited := []paste.File{files[0], {Name: "b.txt", Syntax: "plaintext", Content: "edited"}}
+	require.NoError(t, s.UpdatePaste(ctx, ref, token, paste.Paste{Files: edited}))
+
+	unlocked, err := s.GetPasteWithPassword(ctx, ref, password)
+	require.NoError(t, err)
+	require.NotNil(t, unlocked)
+	require.Equal(t, edited, unlocked.Files)
+}
+
+func testAttachments(t *testing.T, s Storage, tag string) {
+	ctx := context.Background()
+
+	image := []byte("\x89PNG\r\n\x1a\n not really an image")
+	archive := []byte{0x1f, 0x8b, 0x08, 0x00, 0xff}
+
+	ref, token, err := s.CreatePaste(
+		ctx, paste.Paste{
+			Title:   "screenshots",
+			Content: "see attached",
+			Public:  true,
+			Tags:    []string{tag},
+			Attachments: []paste.Attachment{
+				{Name: "screenshot.png", ContentType: "image/png", Data: image},
+				{Name: "logs.tar.gz", ContentType: "application/x-gzip", Data: archive},
+			},
+		},
+	)
+	require.NoError(t, err)
+
+	// reads of the paste list the attachments without their contents
+	got, err := s.GetPaste(ctx, ref)
+	require.NoError(t, err)
+	require.Equal(
+		t, []paste.Attachment{
+			{Name: "screenshot.png", ContentType: "image/png", Size: int64(len(image))},
+			{Name: "logs.tar.gz", ContentType: "application/x-gzip", Size: int64(len(archive))},
+		},
+		got.Attachments,
+	)
+	require.Equal(t, 1, got.Views)
+
+	attachment, err := s.GetAttachment(ctx, ref, "screenshot.png")
+	require.NoError(t, err)
+	require.Equal(t, image, attachment.Data)
+	require.Equal(t, "image/png", attachment.ContentType)
+
+	attachment, err = s.GetAttachment(ctx, ref, "logs.tar.gz")
+	require.NoError(t, err)
+	require.Equal(t, archive, attachment.Data)
+
+	_, err = s.GetAttachment(ctx, ref, "missing.png")
+	require.ErrorIs(t, err, pgx.ErrNoRows)
+
+	// reading attachments doesn't count as reading the paste
+	got, err = s.GetPasteWithToken(ctx, ref, token)
+	require.NoError(t, err)
+	require.Equal(t, 1, got.Views)
+	require.Len(t, got.Attachments, 2)
+
+	// listings leave the attachments out
+	page, err := s.ListPastes(ctx, paste.Filter{Tag: tag}, "", 10)
+	require.NoError(t, err)
+	require.Len(t, page.Pastes, 1)
+	require.Empty(t, page.Pastes[0].Attachments)
+
+	// updates keep the attachments
+	require.NoError(t, s.UpdatePaste(ctx, ref, token, paste.Paste{Title: "screenshots", Content: "edited", Tags: []string{tag}}))
+
+	attachment, err = s.GetAttachment(ctx, ref, "screenshot.png")
+	require.NoError(t, err)
+	require.Equal(t, image, attachment.Data)
+
+	// and they're gone along with the paste
+	require.NoError(t, s.DeletePaste(ctx, ref, token))
+
+	_, err = s.GetAttachment(ctx, ref, "screenshot.png")
+	require.ErrorIs(t, err, pgx.ErrNoRows)
+}
+
+// tag returns a tag unique to the test, isolating its pastes from any other ones.
+func tag(t *testing.T) string {
+	buf := make([]byte, 6)
+	_, err := rand.Read(buf)
+	require.NoError(t, err)
+	return "contract-" + hex.EncodeToString(buf)
+}
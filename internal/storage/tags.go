@@ -0,0 +1,40 @@
+package storage
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/aexvir/skladka/internal/errors"
+	"github.com/aexvir/skladka/internal/paste"
+	"github.com/aexvir/skladka/internal/storage/sql"
+	"github.com/aexvir/skladka/internal/tracing"
+)
+
+// ListTags returns at most limit tags used on public pastes, most used first.
+// If a prefix is given, only the tags starting with it are returned, which is
+// what tag autocompletion relies on.
+func (s *PostgresStorage) ListTags(ctx context.Context, prefix string, limit int) ([]paste.Tag, error) {
+	var err error
+	ctx, finish := tracing.FromContext(ctx, trace.SpanKindInternal, "PostgresStorage.ListTags")
+	defer finish(&err)
+
+	rows, err := s.db.ListPublicTags(
+		ctx, sql.ListPublicTagsParams{
+			Prefix:   pgtype.Text{String: prefix, Valid: prefix != ""},
+			PageSize: int32(limit),
+		},
+	)
+	if err != nil {
+		s.metrics.PasteErrors.Add(ctx, 1)
+		return nil, errors.Wrap(err, "failed to list tags")
+	}
+
+	tags := make([]paste.Tag, len(rows))
+	for i, row := range rows {
+		tags[i] = row.ToDomain()
+	}
+
+	return tags, nil
+}
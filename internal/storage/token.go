@@ -0,0 +1,19 @@
+package storage
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+)
+
+const tokenLength = 24
+
+// generateToken returns a random url safe secret used to authorize
+// changes to a paste by its owner.
+func generateToken() (string, error) {
+	buf := make([]byte, tokenLength)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
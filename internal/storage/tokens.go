@@ -0,0 +1,144 @@
+package storage
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/jackc/pgx/v5"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/aexvir/skladka/internal/errors"
+	"github.com/aexvir/skladka/internal/storage/sql"
+	"github.com/aexvir/skladka/internal/tracing"
+	"github.com/aexvir/skladka/internal/user"
+)
+
+// apiTokenPrefix makes personal access tokens recognizable, e.g. by secret scanners.
+const apiTokenPrefix = "skd_"
+
+// CreateAPIToken creates a personal access token for the user, limited to the given scopes.
+// Returns the token alongside its secret, which is only known at this point as just a digest
+// of it is stored.
+func (s *PostgresStorage) CreateAPIToken(ctx context.Context, account user.User, name string, scopes []user.Scope) (user.Token, string, error) {
+	var err error
+	ctx, finish := tracing.FromContext(ctx, trace.SpanKindInternal, "PostgresStorage.CreateAPIToken")
+	defer finish(&err)
+
+	if err = user.ValidateToken(name, scopes); err != nil {
+		return user.Token{}, "", errors.NewHTTPError(http.StatusBadRequest, err.Error(), err)
+	}
+
+	secret, err := generateToken()
+	if err != nil {
+		s.metrics.UserErrors.Add(ctx, 1)
+		return user.Token{}, "", errors.Wrap(err, "failed to generate token")
+	}
+	secret = apiTokenPrefix + secret
+
+	raw := make([]string, len(scopes))
+	for i, scope := range scopes {
+		raw[i] = string(scope)
+	}
+
+	row, err := s.db.CreateAPIToken(
+		ctx, sql.CreateAPITokenParams{
+			UserID: account.ID,
+			Name:   name,
+			Token:  s.cipher.Digest(secret),
+			Scopes: raw,
+		},
+	)
+	if err != nil {
+		s.metrics.UserErrors.Add(ctx, 1)
+		return user.Token{}, "", errors.Wrap(err, "failed to create api token")
+	}
+
+	s.metrics.TokenCreated.Add(ctx, 1)
+
+	return row.ToDomain(), secret, nil
+}
+
+// ListAPITokens returns the personal access tokens of the user, newest first.
+func (s *PostgresStorage) ListAPITokens(ctx context.Context, account user.User) ([]user.Token, error) {
+	var err error
+	ctx, finish := tracing.FromContext(ctx, trace.SpanKindInternal, "PostgresStorage.ListAPITokens")
+	defer finish(&err)
+
+	rows, err := s.db.ListAPITokens(ctx, account.ID)
+	if err != nil {
+		s.metrics.UserErrors.Add(ctx, 1)
+		return nil, errors.Wrap(err, "failed to list api tokens")
+	}
+
+	tokens := make([]user.Token, len(rows))
+	for i, row := range rows {
+		tokens[i] = row.ToDomain()
+	}
+
+	return tokens, nil
+}
+
+// RevokeAPIToken deletes a personal access token of the user.
+// Tokens of other users are reported as not found.
+func (s *PostgresStorage) RevokeAPIToken(ctx context.Context, account user.User, id int64) error {
+	var err error
+	ctx, finish := tracing.FromContext(ctx, trace.SpanKindInternal, "PostgresStorage.RevokeAPIToken")
+	defer finish(&err)
+
+	deleted, err := s.db.DeleteAPIToken(ctx, sql.DeleteAPITokenParams{ID: id, UserID: account.ID})
+	if err != nil {
+		s.metrics.UserErrors.Add(ctx, 1)
+		return errors.Wrap(err, "failed to revoke api token")
+	}
+
+	if deleted == 0 {
+		return errors.NewHTTPError(http.StatusNotFound, "token not found", nil)
+	}
+
+	return nil
+}
+
+// GetTokenUser returns the user a personal access token belongs to and the scopes
+// granted to the token, recording its use.
+func (s *PostgresStorage) GetTokenUser(ctx context.Context, secret string) (user.User, []user.Scope, error) {
+	var err error
+	ctx, finish := tracing.FromContext(ctx, trace.SpanKindInternal, "PostgresStorage.GetTokenUser")
+	defer finish(&err)
+
+	// tokens created before a key rotation are stored with the digest of a retired key,
+	// which is replaced by the digest of the current one once found, as only digests are
+	// stored and they can't be rotated in bulk
+	var row sql.UseAPITokenRow
+	digests := s.cipher.Digests(secret)
+	for i, digest := range digests {
+		row, err = s.db.UseAPIToken(ctx, digest)
+		if errors.Is(err, pgx.ErrNoRows) {
+			continue
+		}
+
+		if err == nil && i > 0 {
+			err = s.db.RotateAPITokenDigest(ctx, sql.RotateAPITokenDigestParams{Digest: digests[0], Retired: digest})
+			if err != nil {
+				s.metrics.UserErrors.Add(ctx, 1)
+				return user.User{}, nil, errors.Wrap(err, "failed to rotate token digest")
+			}
+		}
+		break
+	}
+	if err != nil {
+		return user.User{}, nil, err
+	}
+
+	account := sql.User{
+		ID:        row.ID,
+		Username:  row.Username,
+		CreatedAt: row.CreatedAt,
+	}
+
+	scopes := make([]user.Scope, len(row.Scopes))
+	for i, scope := range row.Scopes {
+		scopes[i] = user.Scope(scope)
+	}
+
+	return account.ToDomain(), scopes, nil
+}
@@ -0,0 +1,203 @@
+package storage
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/aexvir/skladka/internal/errors"
+	"github.com/aexvir/skladka/internal/paste"
+	"github.com/aexvir/skladka/internal/storage/sql"
+	"github.com/aexvir/skladka/internal/tracing"
+	"github.com/aexvir/skladka/internal/user"
+)
+
+// CreateUser registers a new user, storing the password hashed.
+// Usernames are unique, registering a taken username fails with a bad request error.
+func (s *PostgresStorage) CreateUser(ctx context.Context, username, password string) (user.User, error) {
+	var err error
+	ctx, finish := tracing.FromContext(ctx, trace.SpanKindInternal, "PostgresStorage.CreateUser")
+	defer finish(&err)
+
+	var empty user.User
+
+	if err = user.ValidateCredentials(username, password); err != nil {
+		return empty, errors.NewHTTPError(http.StatusBadRequest, err.Error(), err)
+	}
+
+	row, err := s.db.CreateUser(
+		ctx, sql.CreateUserParams{
+			Username: username,
+			Password: s.cipher.Hash(password),
+		},
+	)
+	if err != nil {
+		if duplicate(err) {
+			return empty, errors.NewHTTPError(http.StatusBadRequest, "username is already taken", err)
+		}
+		s.metrics.UserErrors.Add(ctx, 1)
+		return empty, errors.Wrap(err, "failed to create user")
+	}
+
+	s.metrics.UserRegistered.Add(ctx, 1)
+
+	return row.ToDomain(), nil
+}
+
+// Authenticate verifies the credentials of a user.
+// Returns nil if the user doesn't exist or the password doesn't match.
+func (s *PostgresStorage) Authenticate(ctx context.Context, username, password string) (*user.User, error) {
+	var err error
+	ctx, finish := tracing.FromContext(ctx, trace.SpanKindInternal, "PostgresStorage.Authenticate")
+	defer finish(&err)
+
+	row, err := s.db.GetUserByUsername(ctx, username)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		s.metrics.UserErrors.Add(ctx, 1)
+		return nil, errors.Wrap(err, "failed to get user")
+	}
+
+	if !s.cipher.Verify(password, row.Password) {
+		return nil, nil
+	}
+
+	account := row.ToDomain()
+	return &account, nil
+}
+
+// CreateSession starts a new session for the user, returning the secret session
+// token and when it expires. Only a digest of the token is stored.
+func (s *PostgresStorage) CreateSession(ctx context.Context, account user.User) (string, time.Time, error) {
+	var err error
+	ctx, finish := tracing.FromContext(ctx, trace.SpanKindInternal, "PostgresStorage.CreateSession")
+	defer finish(&err)
+
+	token, err := generateToken()
+	if err != nil {
+		s.metrics.UserErrors.Add(ctx, 1)
+		return "", time.Time{}, errors.Wrap(err, "failed to generate token")
+	}
+
+	// sessions expire in utc, the same way pastes do
+	expiration := time.Now().UTC().Add(s.sessions)
+
+	err = s.db.CreateSession(
+		ctx, sql.CreateSessionParams{
+			UserID:    account.ID,
+			Token:     s.cipher.Digest(token),
+			ExpiresAt: pgtype.Timestamp{Time: expiration, Valid: true},
+		},
+	)
+	if err != nil {
+		s.metrics.UserErrors.Add(ctx, 1)
+		return "", time.Time{}, errors.Wrap(err, "failed to create session")
+	}
+
+	s.metrics.SessionCreated.Add(ctx, 1)
+
+	return token, expiration, nil
+}
+
+// GetSessionUser returns the user the session token belongs to.
+// Expired sessions are reported as not found.
+func (s *PostgresStorage) GetSessionUser(ctx context.Context, token string) (user.User, error) {
+	var err error
+	ctx, finish := tracing.FromContext(ctx, trace.SpanKindInternal, "PostgresStorage.GetSessionUser")
+	defer finish(&err)
+
+	// sessions created before a key rotation are stored with the digest of a retired key,
+	// which is replaced by the digest of the current one once found, as only digests are
+	// stored and they can't be rotated in bulk
+	var row sql.User
+	digests := s.cipher.Digests(token)
+	for i, digest := range digests {
+		row, err = s.db.GetSessionUser(ctx, digest)
+		if errors.Is(err, pgx.ErrNoRows) {
+			continue
+		}
+
+		if err == nil && i > 0 {
+			err = s.db.RotateSessionDigest(ctx, sql.RotateSessionDigestParams{Digest: digests[0], Retired: digest})
+			if err != nil {
+				s.metrics.UserErrors.Add(ctx, 1)
+				return user.User{}, errors.Wrap(err, "failed to rotate session digest")
+			}
+		}
+		break
+	}
+	if err != nil {
+		return user.User{}, err
+	}
+
+	return row.ToDomain(), nil
+}
+
+// DeleteSession ends the session with the given token.
+func (s *PostgresStorage) DeleteSession(ctx context.Context, token string) error {
+	var err error
+	ctx, finish := tracing.FromContext(ctx, trace.SpanKindInternal, "PostgresStorage.DeleteSession")
+	defer finish(&err)
+
+	for _, digest := range s.cipher.Digests(token) {
+		if err = s.db.DeleteSession(ctx, digest); err != nil {
+			s.metrics.UserErrors.Add(ctx, 1)
+			return errors.Wrap(err, "failed to delete session")
+		}
+	}
+
+	return nil
+}
+
+// owners fills in the usernames of the owners of the pastes.
+// Pastes only carry the id of their owner when they are read from the database.
+func (s *PostgresStorage) owners(ctx context.Context, pastes ...*paste.Paste) error {
+	ids := make([]int64, 0, len(pastes))
+	for _, p := range pastes {
+		if p.Owner != nil {
+			ids = append(ids, p.Owner.ID)
+		}
+	}
+
+	if len(ids) == 0 {
+		return nil
+	}
+
+	rows, err := s.db.ListUsernames(ctx, ids)
+	if err != nil {
+		return errors.Wrap(err, "failed to list usernames")
+	}
+
+	usernames := make(map[int64]string, len(rows))
+	for _, row := range rows {
+		usernames[row.ID] = row.Username
+	}
+
+	for _, p := range pastes {
+		if p.Owner != nil {
+			p.Owner.Username = usernames[p.Owner.ID]
+		}
+	}
+
+	return nil
+}
+
+// owns verifies the paste belongs to the user, so they can modify it without its owner token.
+// Password protected pastes are locked with their owner token, which is required regardless.
+func owns(p paste.Paste, account user.User) error {
+	if p.Owner == nil || p.Owner.ID != account.ID {
+		return errors.Wrapf(errors.ErrForbidden, "paste %s isn't owned by %s", p.Reference, account.Username)
+	}
+
+	if p.Password != nil {
+		return errors.Wrapf(errors.ErrForbidden, "paste %s is password protected, its token is required", p.Reference)
+	}
+
+	return nil
+}
@@ -0,0 +1,40 @@
+package user
+
+import (
+	"context"
+	"slices"
+)
+
+const (
+	ctxKeyUser   = "user"
+	ctxKeyScopes = "scopes"
+)
+
+// NewContext returns a new context.Context that carries the authenticated user.
+func NewContext(parent context.Context, user *User) context.Context {
+	return context.WithValue(parent, ctxKeyUser, user)
+}
+
+// FromContext extracts the authenticated user from the provided context.
+// It returns nil for anonymous requests.
+func FromContext(ctx context.Context) *User {
+	user, _ := ctx.Value(ctxKeyUser).(*User)
+	return user
+}
+
+// NewScopedContext returns a new context.Context that carries a user authenticated
+// with a personal access token, limited to the scopes granted to the token.
+func NewScopedContext(parent context.Context, user *User, scopes []Scope) context.Context {
+	return context.WithValue(NewContext(parent, user), ctxKeyScopes, scopes)
+}
+
+// Permits reports whether the request the context belongs to can act with the scope.
+// Only requests authenticated with a personal access token are limited by scopes,
+// anonymous requests and user sessions are permitted everything.
+func Permits(ctx context.Context, scope Scope) bool {
+	scopes, ok := ctx.Value(ctxKeyScopes).([]Scope)
+	if !ok {
+		return true
+	}
+	return slices.Contains(scopes, scope)
+}
@@ -0,0 +1,27 @@
+// Package user provides the data structures for user accounts of the skladka service.
+// It defines the User type which represents a registered account, owning the pastes
+// created while logged in.
+//
+// Users register and log in with a username and a password. Passwords are never kept
+// in the User type, the storage layer only stores them hashed.
+//
+// The authenticated user of a request is carried in its context, injected by the
+// session middleware of the api package:
+//
+//	ctx = user.NewContext(ctx, &account)
+//
+//	// anywhere else in the code
+//	if account := user.FromContext(ctx); account != nil {
+//		fmt.Println("logged in as", account.Username)
+//	}
+//
+// Users can also create personal access tokens for scripts and ci jobs. Each token is
+// granted a set of scopes (read, write and delete) limiting what requests authenticated
+// with it are permitted to do, which can be checked with user.Permits.
+//
+// Field validation rules:
+//   - Username: Required, 3 to 32 lowercase letters, digits, dashes or underscores
+//   - Password: Required on registration, at least 8 characters long
+//   - Token name: Required, at most 64 characters long
+//   - Token scopes: At least one of read, write or delete
+package user
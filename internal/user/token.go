@@ -0,0 +1,86 @@
+package user
+
+import (
+	"slices"
+	"time"
+
+	"github.com/aexvir/skladka/internal/errors"
+)
+
+const maxTokenNameLength = 64
+
+// Scope limits what a personal access token is allowed to do.
+type Scope string
+
+const (
+	// ScopeRead allows reading pastes.
+	ScopeRead Scope = "read"
+	// ScopeWrite allows creating and editing pastes.
+	ScopeWrite Scope = "write"
+	// ScopeDelete allows deleting pastes.
+	ScopeDelete Scope = "delete"
+)
+
+// Scopes lists every scope a token can be granted.
+var Scopes = []Scope{ScopeRead, ScopeWrite, ScopeDelete}
+
+// Token is a personal access token, letting scripts and ci jobs act on behalf
+// of its user. The secret itself is never part of it, it's only shown on creation.
+type Token struct {
+	ID       int64      `json:"id"`
+	Name     string     `json:"name"`
+	Scopes   []Scope    `json:"scopes"`
+	Creation time.Time  `json:"creation"`
+	LastUse  *time.Time `json:"last_use"`
+}
+
+// Allows reports whether the token was granted the scope.
+func (t Token) Allows(scope Scope) bool {
+	return slices.Contains(t.Scopes, scope)
+}
+
+// ParseScopes converts the raw scope names into scopes, dropping duplicates.
+// It returns an error if any of them is unknown or none is given.
+func ParseScopes(raw []string) ([]Scope, error) {
+	scopes := make([]Scope, 0, len(raw))
+
+	for _, name := range raw {
+		scope := Scope(name)
+		if !slices.Contains(Scopes, scope) {
+			return nil, errors.Errorf("unknown scope %q", name)
+		}
+		if !slices.Contains(scopes, scope) {
+			scopes = append(scopes, scope)
+		}
+	}
+
+	if len(scopes) == 0 {
+		return nil, errors.New("at least one scope is required")
+	}
+
+	return scopes, nil
+}
+
+// ValidateToken checks if the name and scopes of a new token meet the validation rules.
+// It returns an error if any rule is violated.
+func ValidateToken(name string, scopes []Scope) error {
+	var errs []error
+
+	if name == "" {
+		errs = append(errs, errors.New("token name is required"))
+	}
+
+	if len(name) > maxTokenNameLength {
+		errs = append(errs, errors.Errorf("token name must be at most %d characters long", maxTokenNameLength))
+	}
+
+	if len(scopes) == 0 {
+		errs = append(errs, errors.New("at least one scope is required"))
+	}
+
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+
+	return nil
+}
@@ -0,0 +1,38 @@
+package user
+
+import (
+	"regexp"
+	"time"
+
+	"github.com/aexvir/skladka/internal/errors"
+)
+
+const minPasswordLength = 8
+
+var username = regexp.MustCompile(`^[a-z0-9_-]{3,32}$`)
+
+type User struct {
+	ID       int64     `json:"-"`
+	Username string    `json:"username"`
+	Creation time.Time `json:"creation"`
+}
+
+// ValidateCredentials checks if the username and password meet the registration rules.
+// It returns an error if any rule is violated.
+func ValidateCredentials(name, password string) error {
+	var errs []error
+
+	if !username.MatchString(name) {
+		errs = append(errs, errors.New("username must be 3 to 32 lowercase letters, digits, dashes or underscores"))
+	}
+
+	if len(password) < minPasswordLength {
+		errs = append(errs, errors.Errorf("password must be at least %d characters long", minPasswordLength))
+	}
+
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+
+	return nil
+}
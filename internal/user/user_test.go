@@ -0,0 +1,70 @@
+package user_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/aexvir/skladka/internal/user"
+)
+
+func TestValidateCredentials(t *testing.T) {
+	tests := map[string]struct {
+		username string
+		password string
+		valid    bool
+	}{
+		"valid":           {username: "aexvir", password: "muchosecreto", valid: true},
+		"short username":  {username: "ab", password: "muchosecreto"},
+		"long username":   {username: "abcdefghijklmnopqrstuvwxyz0123456", password: "muchosecreto"},
+		"uppercase":       {username: "Aexvir", password: "muchosecreto"},
+		"spaces":          {username: "aex vir", password: "muchosecreto"},
+		"short password":  {username: "aexvir", password: "secret"},
+		"symbols allowed": {username: "aex_vir-1", password: "muchosecreto", valid: true},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := user.ValidateCredentials(test.username, test.password)
+			if test.valid {
+				require.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestContext(t *testing.T) {
+	require.Nil(t, user.FromContext(context.Background()))
+
+	account := &user.User{ID: 1, Username: "aexvir"}
+	ctx := user.NewContext(context.Background(), account)
+	require.Equal(t, account, user.FromContext(ctx))
+}
+
+func TestParseScopes(t *testing.T) {
+	scopes, err := user.ParseScopes([]string{"read", "write", "read"})
+	require.NoError(t, err)
+	require.Equal(t, []user.Scope{user.ScopeRead, user.ScopeWrite}, scopes)
+
+	_, err = user.ParseScopes([]string{"read", "admin"})
+	require.Error(t, err)
+
+	_, err = user.ParseScopes(nil)
+	require.Error(t, err)
+}
+
+func TestPermits(t *testing.T) {
+	account := &user.User{ID: 1, Username: "aexvir"}
+
+	// anonymous requests and sessions aren't limited by scopes
+	require.True(t, user.Permits(context.Background(), user.ScopeDelete))
+	require.True(t, user.Permits(user.NewContext(context.Background(), account), user.ScopeDelete))
+
+	ctx := user.NewScopedContext(context.Background(), account, []user.Scope{user.ScopeRead})
+	require.Equal(t, account, user.FromContext(ctx))
+	require.True(t, user.Permits(ctx, user.ScopeRead))
+	require.False(t, user.Permits(ctx, user.ScopeDelete))
+}
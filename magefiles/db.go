@@ -61,14 +61,16 @@ func Migration(ctx context.Context, name string) error {
 }
 
 // apply migration to the database specified as argument
+// the migrations are applied by skladka itself, the same way deployments do
 func Migrate(ctx context.Context, connstr string) error {
 	return harness.Run(
 		ctx,
-		"atlas",
+		"go",
 		harness.WithArgs(
-			"migrate", "apply",
-			fmt.Sprintf("--url=%s", connstr),
-			"--dir=file://internal/storage/sql/migrations",
+			"run", "./cmd",
+			"--driver=postgres",
+			fmt.Sprintf("--dsn=%s", connstr),
+			"migrate", "up",
 		),
 	)
 }